@@ -2,17 +2,49 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/vgeshiktor/nba-stats/internal/app"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
 )
 
 func main() {
+	// "nba-stats migrate ..." manages the schema without starting the server.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.RunMigrateCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// "nba-stats apikey ..." issues and revokes API keys.
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := app.RunAPIKeyCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// "nba-stats totals ..." checks and rebuilds the season totals aggregates are read from.
+	if len(os.Args) > 1 && os.Args[1] == "totals" {
+		if err := app.RunTotalsCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize the application via our abstraction layer.
 	server := app.Initialize()
 
-	// Log and start the server.
-	logger.Info("Starting server on " + server.Addr)
-	if err := server.ListenAndServe(); err != nil {
-		logger.Error("Server failed: " + err.Error())
+	// Serve until SIGTERM or an interrupt, then shut down gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if err := server.Run(ctx); err != nil {
+		logger.Error("Server failed", "error", err)
 	}
 }
@@ -0,0 +1,79 @@
+// internal/api/conditional.go
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache-Control policies of the read endpoints. Responses depend on the caller's
+// credentials, so only private caches may keep them.
+const (
+	// Players, teams and games that are not final change rarely, through the catalog
+	// endpoints.
+	cacheControlCatalog = "private, max-age=60"
+	// A final game can no longer be changed.
+	cacheControlFinal = "private, max-age=86400, immutable"
+	// Aggregates change whenever stats are logged, so they are revalidated every time.
+	cacheControlStats = "private, no-cache"
+)
+
+// writeCacheable writes v as the JSON response to a GET or HEAD request, with a strong
+// ETag computed from the response body, Last-Modified set to lastModified if it is not
+// nil, and the given Cache-Control. It answers 304 Not Modified instead when the
+// request's If-None-Match or, without one, its If-Modified-Since shows the client's
+// copy is current. Other preconditions and Range requests are not supported, so the
+// whole body is always sent.
+func writeCacheable(w http.ResponseWriter, r *http.Request, v any, lastModified *time.Time, cacheControl string) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeServiceError(w, r, err, "Error encoding response")
+		return
+	}
+	body = append(body, '\n')
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if lastModified != nil && !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// notModified reports whether the client's copy of a representation with the given
+// ETag and modification time is current. If-None-Match matches an ETag listed in it,
+// compared weakly, or any ETag for "*"; If-Modified-Since, which is only considered
+// without If-None-Match, matches a modification time no later than its own, to the
+// second.
+func notModified(r *http.Request, etag string, lastModified *time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified == nil || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.Truncate(time.Second).After(since)
+}
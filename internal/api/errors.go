@@ -0,0 +1,109 @@
+// internal/api/errors.go
+package api
+
+import (
+	errs "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/errors"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// Problem types reported for each domain error kind, and for requests over a rate
+// limit. Any other error is reported with the generic "about:blank" type.
+const (
+	problemInvalidInput    = "urn:nba-stats:problem:invalid-input"
+	problemUnauthenticated = "urn:nba-stats:problem:unauthenticated"
+	problemForbidden       = "urn:nba-stats:problem:forbidden"
+	problemNotFound        = "urn:nba-stats:problem:not-found"
+	problemConflict        = "urn:nba-stats:problem:conflict"
+	problemValidation      = "urn:nba-stats:problem:validation-failed"
+	problemRateLimited     = "urn:nba-stats:problem:rate-limited"
+	problemCanceled        = "urn:nba-stats:problem:canceled"
+)
+
+// classify maps an error returned by a service to the HTTP status code and problem
+// type reporting it.
+func classify(err error) (int, string) {
+	switch {
+	case errs.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest, problemInvalidInput
+	case errs.Is(err, domain.ErrUnauthenticated):
+		return http.StatusUnauthorized, problemUnauthenticated
+	case errs.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, problemForbidden
+	case errs.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, problemNotFound
+	case errs.Is(err, domain.ErrConflict):
+		return http.StatusConflict, problemConflict
+	case errs.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity, problemValidation
+	}
+	return http.StatusInternalServerError, "about:blank"
+}
+
+// writeError writes a problem details response of the generic type.
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, detail string) {
+	writeProblem(w, r, errors.NewProblem(statusCode, detail))
+}
+
+// writeProblem writes a problem details response, identifying the occurrence by the
+// request ID.
+func writeProblem(w http.ResponseWriter, r *http.Request, problem *errors.Problem) {
+	problem.Instance = logger.RequestIDFromContext(r.Context())
+	errors.WriteProblem(w, problem)
+}
+
+// writeServiceError writes the response for an error returned by a service. Domain
+// errors are reported with their own message. Any other error is logged, recorded on
+// the span of the request and reported as a 500 with the given message only, so that
+// database details are not exposed, or as a 503 if the request was canceled or timed
+// out, which caused the error.
+// A *domain.ValidationError or *domain.BatchError also lists every invalid field.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, internalMessage string) {
+	statusCode, problemType := classify(err)
+	if statusCode == http.StatusInternalServerError {
+		tracing.SpanFromContext(r.Context()).RecordError(err)
+	}
+	if statusCode == http.StatusInternalServerError && r.Context().Err() != nil {
+		logger.FromContext(r.Context()).Info("Request ended before it completed", "operation", internalMessage, "error", err)
+		problem := errors.NewProblem(http.StatusServiceUnavailable, "The request was canceled or timed out before it completed")
+		problem.Type = problemCanceled
+		writeProblem(w, r, problem)
+		return
+	}
+	if statusCode == http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error(internalMessage, "error", err)
+		writeError(w, r, statusCode, internalMessage)
+		return
+	}
+
+	if statusCode == http.StatusUnprocessableEntity {
+		countValidationRejections(err)
+	}
+
+	problem := errors.NewProblem(statusCode, err.Error())
+	problem.Type = problemType
+	var domainErr *domain.Error
+	if errs.As(err, &domainErr) {
+		problem.Detail = domainErr.Message
+	}
+	var validationErr *domain.ValidationError
+	if errs.As(err, &validationErr) {
+		problem.Errors = validationErr.Fields
+	}
+	var batchErr *domain.BatchError
+	if errs.As(err, &batchErr) {
+		// Fields of a rejected line are named after its position, e.g. "lines[2].fouls".
+		for _, line := range batchErr.Lines {
+			for _, f := range line.Errors {
+				f.Field = fmt.Sprintf("lines[%d].%s", line.Index, f.Field)
+				problem.Errors = append(problem.Errors, f)
+			}
+		}
+	}
+	writeProblem(w, r, problem)
+}
@@ -3,43 +3,83 @@ package api
 
 import (
 	"encoding/json"
-	errs "errors"
-	"database/sql"
+	"fmt"
 	"net/http"
+	"net/netip"
 	"strings"
+	"sync/atomic"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/service"
-	"github.com/vgeshiktor/nba-stats/pkg/errors"
-	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
 )
 
 // Handler aggregates all service dependencies for handling API requests.
 type Handler struct {
 	// Services for stats logging and aggregation.
-	PlayerStatsService service.PlayerStatsService
-	AggregationService service.AggregationService
-
-	// Services for managing players, teams, and games.
+	PlayerStatsService   service.PlayerStatsService
+	AggregationService   service.AggregationService
+	AdvancedStatsService service.AdvancedStatsService
+	LeaderService        service.LeaderService
+	StandingsService     service.StandingsService
+	GameLogService       service.GameLogService
+
+	// Services for managing players, teams, games, and seasons.
 	PlayerService service.PlayerService
 	TeamService   service.TeamService
 	GameService   service.GameService
+	SeasonService service.SeasonService
+
+	// Service for authenticating requests and managing API keys.
+	AuthService service.AuthService
+
+	// Rate limits of the routes; the zero value disables rate limiting.
+	RateLimits RateLimits
+
+	// Draining is set once the server starts shutting down, to fail readiness probes.
+	Draining atomic.Bool
+}
+
+// RateLimits configures the requests per client allowed by each class of routes, and
+// the requests allowed from each IP address that fail to authenticate. A zero limit
+// disables limiting for its class.
+type RateLimits struct {
+	Store      ratelimit.Store
+	IP         ratelimit.Limit // Requests from an IP address without valid credentials.
+	Reads      ratelimit.Limit // Reads of entities and lists.
+	Aggregates ratelimit.Limit // Aggregates, advanced stats, leaders and standings, which scan many rows.
+	Writes     ratelimit.Limit // Requests that change data.
+
+	// Proxies whose X-Forwarded-For header identifies the client's IP address.
+	TrustedProxies []netip.Prefix
 }
 
 // NewHandler creates a new API handler instance.
 func NewHandler(
 	playerStatsService service.PlayerStatsService,
 	aggregationService service.AggregationService,
+	advancedStatsService service.AdvancedStatsService,
+	leaderService service.LeaderService,
+	standingsService service.StandingsService,
+	gameLogService service.GameLogService,
 	playerService service.PlayerService,
 	teamService service.TeamService,
 	gameService service.GameService,
+	seasonService service.SeasonService,
+	authService service.AuthService,
 ) *Handler {
 	return &Handler{
-		PlayerStatsService: playerStatsService,
-		AggregationService: aggregationService,
-		PlayerService:      playerService,
-		TeamService:        teamService,
-		GameService:        gameService,
+		PlayerStatsService:   playerStatsService,
+		AggregationService:   aggregationService,
+		AdvancedStatsService: advancedStatsService,
+		LeaderService:        leaderService,
+		StandingsService:     standingsService,
+		GameLogService:       gameLogService,
+		PlayerService:        playerService,
+		TeamService:          teamService,
+		GameService:          gameService,
+		SeasonService:        seasonService,
+		AuthService:          authService,
 	}
 }
 
@@ -48,13 +88,16 @@ func (h *Handler) LogPlayerStats(w http.ResponseWriter, r *http.Request) {
 	var stats domain.PlayerGameStats
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&stats); err != nil {
-		errors.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := h.PlayerStatsService.LogPlayerStats(&stats); err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error logging player stats: "+err.Error())
+	if !h.authorizeScorekeeper(w, r, stats.GameID) {
+		return
+	}
+	if err := h.PlayerStatsService.LogPlayerStats(r.Context(), &stats); err != nil {
+		writeServiceError(w, r, err, "Error logging player stats")
 		return
 	}
 
@@ -62,63 +105,202 @@ func (h *Handler) LogPlayerStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "Player stats logged successfully"})
 }
 
-// GetPlayerAggregate handles GET /api/v1/player-stats/player/{playerId} to fetch player aggregates.
+// authorizeScorekeeper checks that the caller may log stats for the game, writing a
+// 403 response and returning false if not.
+func (h *Handler) authorizeScorekeeper(w http.ResponseWriter, r *http.Request, gameID string) bool {
+	principal, _ := PrincipalFromContext(r.Context())
+	if err := h.GameService.AuthorizeScorekeeper(r.Context(), principal, gameID); err != nil {
+		writeServiceError(w, r, err, "Error authorizing scorekeeper")
+		return false
+	}
+	return true
+}
+
+// statsFilterFromQuery reads the optional season and season_type query parameters.
+func statsFilterFromQuery(r *http.Request) domain.StatsFilter {
+	return domain.StatsFilter{
+		SeasonID:   r.URL.Query().Get("season"),
+		SeasonType: r.URL.Query().Get("season_type"),
+	}
+}
+
+// LogBoxScore handles POST /api/v1/games/{gameId}/box-score to log every player line
+// of a game at once. Nothing is stored if any line is rejected; the response then
+// lists the rejected lines.
+func (h *Handler) LogBoxScore(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	var boxScore domain.BoxScore
+	if err := json.NewDecoder(r.Body).Decode(&boxScore); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if !h.authorizeScorekeeper(w, r, gameID) {
+		return
+	}
+	if err := h.PlayerStatsService.LogBoxScore(r.Context(), gameID, &boxScore); err != nil {
+		writeServiceError(w, r, err, "Error logging box score")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"message": fmt.Sprintf("Logged %d player lines", len(boxScore.Lines))})
+}
+
+// GetBoxScore handles GET /api/v1/games/{gameId}/box-score to fetch every player line
+// of a game, grouped by team with the team totals, under the game's header. Like the
+// aggregates, it changes whenever stats are logged and is revalidated every time.
+func (h *Handler) GetBoxScore(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	if gameID == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
+		return
+	}
+
+	boxScore, err := h.GameLogService.GetBoxScore(r.Context(), gameID)
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching box score")
+		return
+	}
+
+	writeCacheable(w, r, boxScore, nil, cacheControlStats)
+}
+
+// GetPlayerAggregate handles GET /api/v1/player-stats/player/{playerId} to fetch player aggregates,
+// optionally restricted with ?season= (an ID such as 2025-26, or "current") and ?season_type=.
 func (h *Handler) GetPlayerAggregate(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 6 {
 
-		errors.WriteError(w, http.StatusBadRequest, "Player ID not provided")
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
 		return
 	}
 	playerID := parts[5]
 
-	logger.Info("get player aggreggate for id:  %s", playerID)
+	aggregate, err := h.AggregationService.GetPlayerAggregate(r.Context(), playerID, statsFilterFromQuery(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching player aggregate")
+		return
+	}
+
+	writeCacheable(w, r, aggregate, nil, cacheControlStats)
+}
+
+// GetPlayerAdvanced handles GET /api/v1/player-stats/player/{playerId}/advanced to fetch
+// advanced metrics, optionally restricted by the season and season_type query parameters.
+func (h *Handler) GetPlayerAdvanced(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 5)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	advanced, err := h.AdvancedStatsService.GetPlayerAdvanced(r.Context(), playerID, statsFilterFromQuery(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching advanced stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(advanced)
+}
+
+// GetLeaders handles GET /api/v1/leaders to rank players or teams by a single stat.
+func (h *Handler) GetLeaders(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	minGames, err := queryInt(r, "min_games")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	minMinutes, err := queryFloat(r, "min_minutes")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	var ascending bool
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		ascending = true
+	default:
+		writeError(w, r, http.StatusBadRequest, `query parameter "order" must be "asc" or "desc"`)
+		return
+	}
+	filter := domain.LeaderFilter{
+		StatsFilter: statsFilterFromQuery(r),
+		Stat:        r.URL.Query().Get("stat"),
+		Scope:       r.URL.Query().Get("scope"),
+		MinGames:    minGames,
+		MinMinutes:  minMinutes,
+		Ascending:   ascending,
+		Limit:       limit,
+		Offset:      offset,
+	}
 
-	aggregate, err := h.AggregationService.GetPlayerAggregate(playerID)
+	leaders, err := h.LeaderService.GetLeaders(r.Context(), filter)
 	if err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error fetching player aggregate: "+err.Error())
+		writeServiceError(w, r, err, "Error fetching leaders")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(aggregate)
+	json.NewEncoder(w).Encode(leaders)
+}
+
+// GetStandings handles GET /api/v1/standings to rank the teams of a season by their
+// final regular-season games, within each conference, division or the whole league as
+// selected by ?group=. Without ?season= the current season is used.
+func (h *Handler) GetStandings(w http.ResponseWriter, r *http.Request) {
+	filter := domain.StandingsFilter{
+		SeasonID: r.URL.Query().Get("season"),
+		Group:    r.URL.Query().Get("group"),
+	}
+
+	standings, err := h.StandingsService.GetStandings(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Error computing standings")
+		return
+	}
+
+	writeCacheable(w, r, standings, nil, cacheControlStats)
 }
 
-// GetTeamAggregate handles GET /api/v1/player-stats/team/{teamId} to fetch team aggregates.
+// GetTeamAggregate handles GET /api/v1/player-stats/team/{teamId} to fetch team aggregates,
+// optionally restricted with ?season= and ?season_type=.
 func (h *Handler) GetTeamAggregate(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 6 {
-		errors.WriteError(w, http.StatusBadRequest, "Team ID not provided")
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
 		return
 	}
 	teamID := parts[5]
 
-	logger.Info("get team aggreggate for id:  %s", teamID)
-
-	aggregate, err := h.AggregationService.GetTeamAggregate(teamID)
+	aggregate, err := h.AggregationService.GetTeamAggregate(r.Context(), teamID, statsFilterFromQuery(r))
 	if err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error fetching team aggregate: "+err.Error())
+		writeServiceError(w, r, err, "Error fetching team aggregate")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(aggregate)
+	writeCacheable(w, r, aggregate, nil, cacheControlStats)
 }
 
 // CreatePlayer handles POST /api/v1/players to create a new player.
 func (h *Handler) CreatePlayer(w http.ResponseWriter, r *http.Request) {
 	var player domain.Player
-	logger.Info("Received body: %v", r.Body)
 	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
-		errors.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	logger.Info("Trying to  create player: %v", player)
-
-	if err := h.PlayerService.CreatePlayer(&player); err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error creating player: "+err.Error())
+	if err := h.PlayerService.CreatePlayer(r.Context(), &player); err != nil {
+		writeServiceError(w, r, err, "Error creating player")
 		return
 	}
 
@@ -130,38 +312,31 @@ func (h *Handler) CreatePlayer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetPlayer(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
-		errors.WriteError(w, http.StatusBadRequest, "Player ID not provided")
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
 		return
 	}
 	playerID := parts[4]
 
-	player, err := h.PlayerService.GetPlayerByID(playerID)
+	player, err := h.PlayerService.GetPlayerByID(r.Context(), playerID)
 	if err != nil {
-		// Check if the error is due to the record not being found.
-		if errs.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
-			http.Error(w, "Player not found", http.StatusNotFound)
-			return
-		}
-
-		errors.WriteError(w, http.StatusInternalServerError, "Error fetching player: "+err.Error())
+		writeServiceError(w, r, err, "Error fetching player")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(player)
+	writeCacheable(w, r, player, player.UpdatedAt, cacheControlCatalog)
 }
 
 // CreateTeam handles POST /api/v1/teams to create a new team.
 func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 	var team domain.Team
 	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		errors.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := h.TeamService.CreateTeam(&team); err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error creating team: "+err.Error())
+	if err := h.TeamService.CreateTeam(r.Context(), &team); err != nil {
+		writeServiceError(w, r, err, "Error creating team")
 		return
 	}
 
@@ -173,32 +348,31 @@ func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
-		errors.WriteError(w, http.StatusBadRequest, "Team ID not provided")
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
 		return
 	}
 	teamID := parts[4]
 
-	team, err := h.TeamService.GetTeamByID(teamID)
+	team, err := h.TeamService.GetTeamByID(r.Context(), teamID)
 	if err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error fetching team: "+err.Error())
+		writeServiceError(w, r, err, "Error fetching team")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(team)
+	writeCacheable(w, r, team, team.UpdatedAt, cacheControlCatalog)
 }
 
 // CreateGame handles POST /api/v1/games to create a new game.
 func (h *Handler) CreateGame(w http.ResponseWriter, r *http.Request) {
 	var game domain.Game
 	if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
-		errors.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := h.GameService.CreateGame(&game); err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error creating game: "+err.Error())
+	if err := h.GameService.CreateGame(r.Context(), &game); err != nil {
+		writeServiceError(w, r, err, "Error creating game")
 		return
 	}
 
@@ -210,17 +384,600 @@ func (h *Handler) CreateGame(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetGame(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
-		errors.WriteError(w, http.StatusBadRequest, "Game ID not provided")
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
 		return
 	}
 	gameID := parts[4]
 
-	game, err := h.GameService.GetGameByID(gameID)
+	game, err := h.GameService.GetGameByID(r.Context(), gameID)
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching game")
+		return
+	}
+
+	cacheControl := cacheControlCatalog
+	if game.Status == domain.GameStatusFinal {
+		cacheControl = cacheControlFinal
+	}
+	writeCacheable(w, r, game, game.UpdatedAt, cacheControl)
+}
+
+// ListPlayers handles GET /api/v1/players to list players, optionally filtered by team_id and name.
+func (h *Handler) ListPlayers(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := domain.PlayerFilter{
+		TeamID: r.URL.Query().Get("team_id"),
+		Name:   r.URL.Query().Get("name"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	players, err := h.PlayerService.ListPlayers(r.Context(), filter)
 	if err != nil {
-		errors.WriteError(w, http.StatusInternalServerError, "Error fetching game: "+err.Error())
+		writeServiceError(w, r, err, "Error listing players")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(players)
+}
+
+// UpdatePlayer handles PUT /api/v1/players/{playerId} to replace a player.
+func (h *Handler) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	var player domain.Player
+	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	if player.ID != "" && player.ID != playerID {
+		writeError(w, r, http.StatusBadRequest, "Player ID in body does not match the URL")
+		return
+	}
+	player.ID = playerID
+
+	if err := h.PlayerService.UpdatePlayer(r.Context(), &player); err != nil {
+		writeServiceError(w, r, err, "Error updating player")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+// PatchPlayer handles PATCH /api/v1/players/{playerId} to partially update a player.
+func (h *Handler) PatchPlayer(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	var patch domain.PlayerPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	player, err := h.PlayerService.PatchPlayer(r.Context(), playerID, &patch)
+	if err != nil {
+		writeServiceError(w, r, err, "Error updating player")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+// DeletePlayer handles DELETE /api/v1/players/{playerId} to remove a player and their stats.
+func (h *Handler) DeletePlayer(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	if err := h.PlayerService.DeletePlayer(r.Context(), playerID); err != nil {
+		writeServiceError(w, r, err, "Error deleting player")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordTransaction handles POST /api/v1/players/{playerId}/transactions to trade, sign
+// or waive a player from the transaction's date on.
+func (h *Handler) RecordTransaction(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	var transaction domain.RosterTransaction
+	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	if transaction.PlayerID != "" && transaction.PlayerID != playerID {
+		writeError(w, r, http.StatusBadRequest, "Player ID in body does not match the URL")
+		return
+	}
+	transaction.PlayerID = playerID
+
+	if err := h.PlayerService.RecordTransaction(r.Context(), &transaction); err != nil {
+		writeServiceError(w, r, err, "Error recording roster transaction")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// ListMemberships handles GET /api/v1/players/{playerId}/memberships to list the teams
+// a player has been on, oldest first.
+func (h *Handler) ListMemberships(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+
+	memberships, err := h.PlayerService.ListMemberships(r.Context(), playerID)
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing roster memberships")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(memberships)
+}
+
+// ListPlayerGames handles GET /api/v1/players/{playerId}/games to list a player's stat
+// lines game by game; see gameLogFilterFromQuery for the query parameters.
+func (h *Handler) ListPlayerGames(w http.ResponseWriter, r *http.Request) {
+	playerID := pathSegment(r, 4)
+	if playerID == "" {
+		writeError(w, r, http.StatusBadRequest, "Player ID not provided")
+		return
+	}
+	filter, err := gameLogFilterFromQuery(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log, err := h.GameLogService.ListPlayerGames(r.Context(), playerID, filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching player game log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+// gameLogFilterFromQuery reads a game log filter from the season, season_type, from,
+// to, opponent, location, sort, order, limit and cursor query parameters.
+func gameLogFilterFromQuery(r *http.Request) (domain.GameLogFilter, error) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return domain.GameLogFilter{}, err
+	}
+	from, to, err := queryDateRange(r)
+	if err != nil {
+		return domain.GameLogFilter{}, err
+	}
+	var ascending bool
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		ascending = true
+	default:
+		return domain.GameLogFilter{}, fmt.Errorf(`query parameter "order" must be "asc" or "desc"`)
+	}
+	return domain.GameLogFilter{
+		StatsFilter: statsFilterFromQuery(r),
+		OpponentID:  r.URL.Query().Get("opponent"),
+		Location:    r.URL.Query().Get("location"),
+		From:        from,
+		To:          to,
+		Sort:        r.URL.Query().Get("sort"),
+		Ascending:   ascending,
+		Cursor:      r.URL.Query().Get("cursor"),
+		Limit:       limit,
+	}, nil
+}
+
+// ListTeams handles GET /api/v1/teams to list teams, optionally filtered by name.
+func (h *Handler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := domain.TeamFilter{
+		Name:   r.URL.Query().Get("name"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	teams, err := h.TeamService.ListTeams(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing teams")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teams)
+}
+
+// UpdateTeam handles PUT /api/v1/teams/{teamId} to replace a team.
+func (h *Handler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	teamID := pathSegment(r, 4)
+	if teamID == "" {
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
+		return
+	}
+
+	var team domain.Team
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	if team.ID != "" && team.ID != teamID {
+		writeError(w, r, http.StatusBadRequest, "Team ID in body does not match the URL")
+		return
+	}
+	team.ID = teamID
+
+	if err := h.TeamService.UpdateTeam(r.Context(), &team); err != nil {
+		writeServiceError(w, r, err, "Error updating team")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// PatchTeam handles PATCH /api/v1/teams/{teamId} to partially update a team.
+func (h *Handler) PatchTeam(w http.ResponseWriter, r *http.Request) {
+	teamID := pathSegment(r, 4)
+	if teamID == "" {
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
+		return
+	}
+
+	var patch domain.TeamPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	team, err := h.TeamService.PatchTeam(r.Context(), teamID, &patch)
+	if err != nil {
+		writeServiceError(w, r, err, "Error updating team")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// DeleteTeam handles DELETE /api/v1/teams/{teamId} to remove a team without players or games.
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamID := pathSegment(r, 4)
+	if teamID == "" {
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
+		return
+	}
+
+	if err := h.TeamService.DeleteTeam(r.Context(), teamID); err != nil {
+		writeServiceError(w, r, err, "Error deleting team")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTeamGames handles GET /api/v1/teams/{teamId}/games to list the stat lines logged
+// for a team game by game, with the same query parameters as ListPlayerGames.
+func (h *Handler) ListTeamGames(w http.ResponseWriter, r *http.Request) {
+	teamID := pathSegment(r, 4)
+	if teamID == "" {
+		writeError(w, r, http.StatusBadRequest, "Team ID not provided")
+		return
+	}
+	filter, err := gameLogFilterFromQuery(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log, err := h.GameLogService.ListTeamGames(r.Context(), teamID, filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching team game log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+// ListGames handles GET /api/v1/games to list games, optionally filtered by team_id, season,
+// season_type and a from/to date range.
+func (h *Handler) ListGames(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := queryDateRange(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := domain.GameFilter{
+		TeamID:     r.URL.Query().Get("team_id"),
+		SeasonID:   r.URL.Query().Get("season"),
+		SeasonType: r.URL.Query().Get("season_type"),
+		Status:     r.URL.Query().Get("status"),
+		From:       from,
+		To:         to,
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	games, err := h.GameService.ListGames(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing games")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(games)
+}
+
+// UpdateGame handles PUT /api/v1/games/{gameId} to replace a game.
+func (h *Handler) UpdateGame(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	if gameID == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
+		return
+	}
+
+	var game domain.Game
+	if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	if game.ID != "" && game.ID != gameID {
+		writeError(w, r, http.StatusBadRequest, "Game ID in body does not match the URL")
+		return
+	}
+	game.ID = gameID
+
+	if err := h.GameService.UpdateGame(r.Context(), &game); err != nil {
+		writeServiceError(w, r, err, "Error updating game")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game)
+}
+
+// PatchGame handles PATCH /api/v1/games/{gameId} to partially update a game.
+func (h *Handler) PatchGame(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	if gameID == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
+		return
+	}
+
+	var patch domain.GamePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	game, err := h.GameService.PatchGame(r.Context(), gameID, &patch)
+	if err != nil {
+		writeServiceError(w, r, err, "Error updating game")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(game)
 }
+
+// DeleteGame handles DELETE /api/v1/games/{gameId} to remove a game and its stats.
+func (h *Handler) DeleteGame(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	if gameID == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
+		return
+	}
+
+	if err := h.GameService.DeleteGame(r.Context(), gameID); err != nil {
+		writeServiceError(w, r, err, "Error deleting game")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListScorekeepers handles GET /api/v1/games/{gameId}/scorekeepers to list the
+// subjects allowed to log stats for a game.
+func (h *Handler) ListScorekeepers(w http.ResponseWriter, r *http.Request) {
+	gameID := pathSegment(r, 4)
+	if gameID == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID not provided")
+		return
+	}
+
+	subjects, err := h.GameService.ListScorekeepers(r.Context(), gameID)
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing scorekeepers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subjects)
+}
+
+// AssignScorekeeper handles PUT /api/v1/games/{gameId}/scorekeepers/{subject} to allow
+// a principal to log stats for a game.
+func (h *Handler) AssignScorekeeper(w http.ResponseWriter, r *http.Request) {
+	gameID, subject := pathSegment(r, 4), pathSegment(r, 6)
+	if gameID == "" || subject == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID or scorekeeper not provided")
+		return
+	}
+
+	if err := h.GameService.AssignScorekeeper(r.Context(), gameID, subject); err != nil {
+		writeServiceError(w, r, err, "Error assigning scorekeeper")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnassignScorekeeper handles DELETE /api/v1/games/{gameId}/scorekeepers/{subject} to
+// remove a scorekeeper from a game.
+func (h *Handler) UnassignScorekeeper(w http.ResponseWriter, r *http.Request) {
+	gameID, subject := pathSegment(r, 4), pathSegment(r, 6)
+	if gameID == "" || subject == "" {
+		writeError(w, r, http.StatusBadRequest, "Game ID or scorekeeper not provided")
+		return
+	}
+
+	if err := h.GameService.UnassignScorekeeper(r.Context(), gameID, subject); err != nil {
+		writeServiceError(w, r, err, "Error unassigning scorekeeper")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateSeason handles POST /api/v1/seasons to create a new season.
+func (h *Handler) CreateSeason(w http.ResponseWriter, r *http.Request) {
+	var season domain.Season
+	if err := json.NewDecoder(r.Body).Decode(&season); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.SeasonService.CreateSeason(r.Context(), &season); err != nil {
+		writeServiceError(w, r, err, "Error creating season")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(season)
+}
+
+// ListSeasons handles GET /api/v1/seasons to list all seasons.
+func (h *Handler) ListSeasons(w http.ResponseWriter, r *http.Request) {
+	seasons, err := h.SeasonService.ListSeasons(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing seasons")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(seasons)
+}
+
+// GetSeason handles GET /api/v1/seasons/{seasonId} to retrieve season details.
+func (h *Handler) GetSeason(w http.ResponseWriter, r *http.Request) {
+	seasonID := pathSegment(r, 4)
+	if seasonID == "" {
+		writeError(w, r, http.StatusBadRequest, "Season ID not provided")
+		return
+	}
+
+	season, err := h.SeasonService.GetSeasonByID(r.Context(), seasonID)
+	if err != nil {
+		writeServiceError(w, r, err, "Error fetching season")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
+
+// createAPIKeyRequest is the body of POST /api/v1/api-keys.
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateAPIKey handles POST /api/v1/api-keys to issue a new API key. The key itself
+// is only included in this response.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	apiKey, key, err := h.AuthService.CreateAPIKey(r.Context(), req.Name, req.Scopes)
+	if err != nil {
+		writeServiceError(w, r, err, "Error creating API key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		*domain.APIKey
+		Key string `json:"key"`
+	}{apiKey, key})
+}
+
+// ListAPIKeys handles GET /api/v1/api-keys to list every API key, without the keys themselves.
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.AuthService.ListAPIKeys(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err, "Error listing API keys")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/api-keys/{keyId} to revoke an API key.
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := pathSegment(r, 4)
+	if keyID == "" {
+		writeError(w, r, http.StatusBadRequest, "API key ID not provided")
+		return
+	}
+
+	if err := h.AuthService.RevokeAPIKey(r.Context(), keyID); err != nil {
+		writeServiceError(w, r, err, "Error revoking API key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 )
 
 // jsonResponse is a helper function for writing JSON responses.
@@ -18,10 +19,16 @@ func LivenessProbeHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
-// ReadinessProbeHandler checks database connectivity.
-func ReadinessProbeHandler(db *sql.DB) http.HandlerFunc {
+// ReadinessProbeHandler checks database connectivity. Once draining is set, because
+// the server is shutting down, it reports the server as unavailable so that no new
+// requests are routed to it; a nil draining flag is never set.
+func ReadinessProbeHandler(db *sql.DB, draining *atomic.Bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := db.Ping(); err != nil {
+		if draining != nil && draining.Load() {
+			jsonResponse(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+			return
+		}
+		if err := db.PingContext(r.Context()); err != nil {
 			jsonResponse(w, http.StatusServiceUnavailable, map[string]string{
 				"status": "unavailable",
 				"error":  err.Error(),
@@ -0,0 +1,41 @@
+// internal/api/metrics.go
+package api
+
+import (
+	errs "errors"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
+)
+
+var (
+	httpRequests = metrics.NewCounter("nba_stats_http_requests_total",
+		"HTTP requests served, by method, route template and status code.", "method", "route", "status")
+	httpRequestDuration = metrics.NewHistogram("nba_stats_http_request_duration_seconds",
+		"Latency of HTTP requests, by method and route template.", metrics.DefaultBuckets, "method", "route")
+	validationRejections = metrics.NewCounter("nba_stats_validation_rejections_total",
+		"Validation rules broken by rejected requests, by field and rule.", "field", "rule")
+)
+
+// countValidationRejections counts every rule a rejected entity or batch breaks. A
+// validation error that does not name its fields, such as a reference to a missing
+// record found by the database, is counted without a field.
+func countValidationRejections(err error) {
+	var validationErr *domain.ValidationError
+	var batchErr *domain.BatchError
+	switch {
+	case errs.As(err, &validationErr):
+		for _, f := range validationErr.Fields {
+			validationRejections.Inc(f.Field, f.Code)
+		}
+	case errs.As(err, &batchErr):
+		// Fields of batch lines are counted without the position of the line.
+		for _, line := range batchErr.Lines {
+			for _, f := range line.Errors {
+				validationRejections.Inc("lines[]."+f.Field, f.Code)
+			}
+		}
+	default:
+		validationRejections.Inc("", domain.CodeInvalid)
+	}
+}
@@ -3,11 +3,20 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
 	"github.com/vgeshiktor/nba-stats/pkg/errors"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 
 	"github.com/google/uuid"
 )
@@ -15,47 +24,319 @@ import (
 // contextKey is a custom type for storing values in context.
 type contextKey string
 
-// RequestIDKey is the context key for the unique request ID.
-const RequestIDKey contextKey = "requestID"
+// PrincipalKey is the context key for the authenticated *domain.Principal.
+const PrincipalKey contextKey = "principal"
 
-// LoggingMiddleware logs the details of each incoming request.
+// APIKeyHeader is the request header carrying an API key.
+const APIKeyHeader = "X-API-Key"
+
+// PrincipalFromContext returns the principal a request was authenticated as, if any.
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	principal, ok := ctx.Value(PrincipalKey).(*domain.Principal)
+	return principal, ok
+}
+
+// requestLogSampler thins out the logs of successful requests, the hottest path of
+// the service: per second, the first 100 of each message are logged, then every 100th.
+var requestLogSampler = logger.NewSampler(100, 100, time.Second)
+
+// LoggingMiddleware logs each request once it completes, with its status code and
+// duration, at INFO for successful requests and WARN for failed ones. The request ID,
+// principal and trace stored in the request context are part of each entry.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		// Retrieve request ID from context (if available)
-		reqID, _ := r.Context().Value(RequestIDKey).(string)
-		logger.Info("[RequestID: %s] Started %s %s", reqID, r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-		logger.Info("[RequestID: %s] Completed %s %s in %v", reqID, r.Method, r.URL.Path, time.Since(start))
+		log := logger.FromContext(r.Context())
+		log.Debug("Request started", "method", r.Method, "path", r.URL.Path)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		fields := []any{"method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", time.Since(start)}
+		if recorder.status >= http.StatusInternalServerError {
+			log.Warn("Request failed", fields...)
+			return
+		}
+		log.Sampled(requestLogSampler).Info("Request completed", fields...)
 	})
 }
 
-// AuthenticationMiddleware checks for the presence of an Authorization header.
-func AuthenticationMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("checking authentication...")
-		// Simple authentication: verify that the Authorization header is set.
-		if r.Header.Get("Authorization") == "" {
-			errors.WriteError(w, http.StatusUnauthorized, "Unauthorized: missing token")
-			return
-		}
-		logger.Info("Authentication successful")
-		// In a real-world scenario, add token validation logic here.
-		next.ServeHTTP(w, r)
+// MetricsMiddleware returns middleware that counts the requests to a route and their
+// status codes, and observes their latency. The route is the template of the URL,
+// e.g. "/api/v1/players/{id}", rather than the URL itself, so that the number of
+// series stays bounded.
+func MetricsMiddleware(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			httpRequests.Inc(r.Method, route, strconv.Itoa(recorder.status))
+			httpRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+		})
+	}
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// AuthenticationMiddleware returns middleware that authenticates each request with
+// either an API key in the X-API-Key header or a JWT in an "Authorization: Bearer"
+// header, and stores the principal in the request context. Requests without valid
+// credentials are rejected with 401 Unauthorized.
+func AuthenticationMiddleware(authService service.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var principal *domain.Principal
+			var err error
+			if key := r.Header.Get(APIKeyHeader); key != "" {
+				principal, err = authService.AuthenticateAPIKey(r.Context(), key)
+			} else if token, ok := bearerToken(r); ok {
+				principal, err = authService.AuthenticateToken(r.Context(), token)
+			} else {
+				err = domain.Errorf(domain.ErrUnauthenticated, "Unauthorized: missing bearer token or API key")
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="nba-stats"`)
+				writeServiceError(w, r, err, "Error authenticating request")
+				return
+			}
+			ctx := context.WithValue(r.Context(), PrincipalKey, principal)
+			ctx = logger.ContextWith(ctx, "principal", principal.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope returns middleware that lets a request through only if the principal
+// stored by AuthenticationMiddleware was granted the scope. Other requests are
+// rejected with 403 Forbidden.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeServiceError(w, r, domain.Errorf(domain.ErrUnauthenticated, "Unauthorized: request is not authenticated"), "Error authorizing request")
+				return
+			}
+			if !principal.HasScope(scope) {
+				writeServiceError(w, r, domain.Errorf(domain.ErrForbidden, "Forbidden: %s %s requires the %s scope", r.Method, r.URL.Path, scope), "Error authorizing request")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitMiddleware returns middleware that allows each client the given rate of
+// requests. Clients are identified by their principal, or by their IP address if the
+// request is not authenticated, and get a bucket per name in the store, so that routes
+// limited under different names are limited independently. Every response reports
+// the client's bucket in RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers; requests over the limit are rejected with 429 Too Many Requests and a
+// Retry-After header. If the store fails, requests are let through.
+func RateLimitMiddleware(store ratelimit.Store, name string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return rateLimit(store, limit, func(r *http.Request) string {
+		return name + ":" + clientKey(r)
 	})
 }
 
-// RequestTracingMiddleware assigns a unique request ID to each request.
+// IPRateLimitMiddleware returns middleware that authenticates requests with
+// authenticate and limits the requests from each IP address that it does not
+// authenticate: those without credentials and failed authentication attempts. Each
+// request takes a token from the bucket of its address before its credentials are
+// checked, so that a limited address cannot keep guessing them, and the token is
+// refunded once the request is authenticated, so that clients sharing an address are
+// limited by their own principals only. The address is taken from X-Forwarded-For
+// only for requests from one of the trusted proxies; see clientIP.
+func IPRateLimitMiddleware(store ratelimit.Store, limit ratelimit.Limit, trustedProxies []netip.Prefix, authenticate func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if store == nil || !limit.Enabled() {
+			return authenticate(next)
+		}
+		key := func(r *http.Request) string {
+			return "ip:" + clientIP(r, trustedProxies)
+		}
+		refund := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := store.Refund(key(r), limit); err != nil {
+				logger.FromContext(r.Context()).Warn("Rate limit store failed to refund a token", "error", err)
+			}
+			// The IP bucket does not limit the request, so it is not reported either.
+			w.Header().Del("RateLimit-Limit")
+			w.Header().Del("RateLimit-Remaining")
+			w.Header().Del("RateLimit-Reset")
+			next.ServeHTTP(w, r)
+		})
+		return rateLimit(store, limit, key)(authenticate(refund))
+	}
+}
+
+// rateLimit returns middleware that takes a token from the bucket of each request's
+// key, as described for RateLimitMiddleware.
+func rateLimit(store ratelimit.Store, limit ratelimit.Limit, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if store == nil || !limit.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, err := store.Take(key(r), limit)
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("Rate limit store failed, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(int(decision.Reset.Seconds())))
+			if !decision.Allowed {
+				retryAfter := int(decision.RetryAfter.Seconds())
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				problem := errors.NewProblem(http.StatusTooManyRequests,
+					fmt.Sprintf("Rate limit of %d requests exceeded; retry in %d seconds", decision.Limit, retryAfter))
+				problem.Type = problemRateLimited
+				writeProblem(w, r, problem)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey identifies the client of a request for rate limiting: its principal's
+// subject, or its remote IP address if the request is not authenticated.
+func clientKey(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return principal.Subject
+	}
+	return "ip:" + clientIP(r, nil)
+}
+
+// clientIP returns the IP address of the client of a request: its remote address,
+// unless that is one of the trusted proxies. Then it is the last address in the
+// X-Forwarded-For headers that is not a trusted proxy, since the proxies append the
+// address they received the request from and anything before that may be forged.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !trustedProxy(addr, trustedProxies) {
+		return host
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		host = hop.String()
+		if !trustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+	return host
+}
+
+// trustedProxy reports whether addr is in one of the trusted proxies' prefixes.
+func trustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequestIDHeader is the request and response header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestTracingMiddleware identifies each request by the ID in its X-Request-ID
+// header, or by a new unique ID if it has none or an invalid one, and echoes the ID
+// in the response. A valid traceparent header makes the spans of the request part of
+// the caller's trace. Both are stored in the request context, from which the logger
+// adds them to every entry.
 func RequestTracingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := uuid.New().String()
-		// Optionally, add the request ID to response headers for downstream tracing.
-		w.Header().Set("X-Request-ID", requestID)
-		ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := logger.ContextWithRequestID(r.Context(), requestID)
+		if sc, ok := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader)); ok {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// validRequestID reports whether a request ID chosen by the client is short and made
+// of characters that are safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// SpanMiddleware returns middleware that records a span for each request to a route,
+// named after its method and the route template, e.g. "GET /api/v1/players/{id}".
+// writeServiceError marks the span as failed by the errors it hides from the client.
+func SpanMiddleware(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracing.Start(r.Context(), r.Method+" "+route)
+			defer span.End()
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.route", route)
+			span.SetAttribute("request.id", logger.RequestIDFromContext(ctx))
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+			span.SetAttribute("http.status_code", strconv.Itoa(recorder.status))
+		})
+	}
+}
+
+// TimeoutMiddleware returns middleware that cancels the context of each request after
+// the given timeout, so that queries still running once the response can no longer be
+// written are canceled.
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // ChainMiddleware applies a list of middleware functions to an http.Handler.
 func ChainMiddleware(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	// Apply middleware in reverse order so that the first middleware
@@ -0,0 +1,89 @@
+// internal/api/params.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pathSegment returns the i-th slash-separated segment of the request path,
+// or an empty string if the path is shorter.
+func pathSegment(r *http.Request, i int) string {
+	parts := strings.Split(r.URL.Path, "/")
+	if i >= len(parts) {
+		return ""
+	}
+	return parts[i]
+}
+
+// queryInt parses an optional integer query parameter.
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %q must be an integer", name)
+	}
+	return n, nil
+}
+
+// queryFloat parses an optional floating-point query parameter.
+func queryFloat(r *http.Request, name string) (float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %q must be a number", name)
+	}
+	return f, nil
+}
+
+// queryDateRange parses the optional "from" and "to" query parameters. Each may be
+// given as an RFC 3339 timestamp or as a plain date (YYYY-MM-DD, UTC); a plain
+// "to" date includes the whole day.
+func queryDateRange(r *http.Request) (from, to time.Time, err error) {
+	parse := func(name string) (time.Time, bool, error) {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			return time.Time{}, false, nil
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, false, nil
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("query parameter %q must be a date (YYYY-MM-DD) or RFC 3339 timestamp", name)
+		}
+		return t, true, nil
+	}
+
+	if from, _, err = parse("from"); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	var dateOnly bool
+	if to, dateOnly, err = parse("to"); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if dateOnly {
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+	return from, to, nil
+}
+
+// queryPage parses the limit and offset query parameters.
+func queryPage(r *http.Request) (limit, offset int, err error) {
+	if limit, err = queryInt(r, "limit"); err != nil {
+		return 0, 0, err
+	}
+	if offset, err = queryInt(r, "offset"); err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
@@ -4,91 +4,212 @@ package api
 import (
 	"database/sql"
 	"net/http"
+	"sort"
+	"strings"
 
-	"github.com/vgeshiktor/nba-stats/pkg/errors"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
 )
 
+// route is the handler of one HTTP method of an endpoint, the scope a caller must be
+// granted to use it, and the rate limit applied to each caller.
+type route struct {
+	scope   string
+	limit   func(http.Handler) http.Handler
+	handler http.HandlerFunc
+}
+
+// methods describes an endpoint by the route of each HTTP method it supports.
+type methods map[string]route
+
+// handler returns a handler dispatching each request to the route for its method,
+// after applying the route's rate limit and checking its scope with RequireScope. The
+// middleware of each route is chained once, here. Other methods are rejected with 405
+// Method Not Allowed and an Allow header listing the supported ones.
+func (m methods) handler() http.Handler {
+	handlers := make(map[string]http.Handler, len(m))
+	allowed := make([]string, 0, len(m))
+	for method, rt := range m {
+		handlers[method] = ChainMiddleware(rt.handler, rt.limit, RequireScope(rt.scope))
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	allow := strings.Join(allowed, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.Method]
+		if !ok {
+			w.Header().Set("Allow", allow)
+			writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // RegisterRoutes maps URL endpoints to the corresponding handler functions.
-// Each endpoint is wrapped with Logging, Authentication, and RequestTracing middleware.
+// Each endpoint is wrapped with RequestTracing, Span, Metrics, RateLimit,
+// Authentication and Logging middleware; authentication uses the handler's
+// AuthService, and spans and metrics are named after the route template the endpoint
+// is registered under. Requests that fail to authenticate count against the IP limit
+// of the handler's RateLimits, which is checked before their credentials, so that
+// unauthenticated floods and credential guessing are limited too. Each route declares the scope it requires,
+// which is checked once the request is authenticated, and the class of the handler's
+// RateLimits it counts against. The metrics of the process are served at /metrics.
 func RegisterRoutes(mux *http.ServeMux, handler *Handler, db *sql.DB) {
+	limits := handler.RateLimits
+
 	// Define a middleware chain.
-	chain := func(h http.Handler) http.Handler {
-		return ChainMiddleware(h, RequestTracingMiddleware, AuthenticationMiddleware, LoggingMiddleware)
+	authenticate := IPRateLimitMiddleware(limits.Store, limits.IP, limits.TrustedProxies, AuthenticationMiddleware(handler.AuthService))
+	chain := func(route string, m methods) http.Handler {
+		return ChainMiddleware(m.handler(), RequestTracingMiddleware, SpanMiddleware(route), MetricsMiddleware(route), authenticate, LoggingMiddleware)
 	}
+	const (
+		read  = domain.ScopeStatsRead
+		write = domain.ScopeStatsWrite
+		admin = domain.ScopeCatalogAdmin
+	)
+	reads := RateLimitMiddleware(limits.Store, "reads", limits.Reads)
+	aggregates := RateLimitMiddleware(limits.Store, "aggregates", limits.Aggregates)
+	writes := RateLimitMiddleware(limits.Store, "writes", limits.Writes)
 
-	// Player stats endpoints.
-	mux.Handle("/api/v1/player-stats", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.LogPlayerStats(w, r)
+	// Player stats endpoints. Stats may only be logged for games the caller is
+	// assigned to as scorekeeper, which the handlers check.
+	mux.Handle("/api/v1/player-stats", chain("/api/v1/player-stats", methods{
+		http.MethodPost: {write, writes, handler.LogPlayerStats},
+	}))
+	playerAggregate := chain("/api/v1/player-stats/player/{id}", methods{
+		http.MethodGet: {read, aggregates, handler.GetPlayerAggregate},
+	})
+	playerAdvanced := chain("/api/v1/player-stats/player/{id}/advanced", methods{
+		http.MethodGet: {read, aggregates, handler.GetPlayerAdvanced},
+	})
+	mux.Handle("/api/v1/player-stats/player/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pathSegment(r, 6) == "advanced" {
+			playerAdvanced.ServeHTTP(w, r)
 			return
 		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
+		playerAggregate.ServeHTTP(w, r)
+	}))
+	mux.Handle("/api/v1/player-stats/team/", chain("/api/v1/player-stats/team/{id}", methods{
+		http.MethodGet: {read, aggregates, handler.GetTeamAggregate},
+	}))
+	mux.Handle("/api/v1/leaders", chain("/api/v1/leaders", methods{
+		http.MethodGet: {read, aggregates, handler.GetLeaders},
+	}))
+	mux.Handle("/api/v1/standings", chain("/api/v1/standings", methods{
+		http.MethodGet: {read, aggregates, handler.GetStandings},
+	}))
 
-	mux.Handle("/api/v1/player-stats/player/", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			handler.GetPlayerAggregate(w, r)
-			return
+	// Player management endpoints, including roster transactions and history, and game logs.
+	mux.Handle("/api/v1/players", chain("/api/v1/players", methods{
+		http.MethodGet:  {read, reads, handler.ListPlayers},
+		http.MethodPost: {admin, writes, handler.CreatePlayer},
+	}))
+	players := chain("/api/v1/players/{id}", methods{
+		http.MethodGet:    {read, reads, handler.GetPlayer},
+		http.MethodPut:    {admin, writes, handler.UpdatePlayer},
+		http.MethodPatch:  {admin, writes, handler.PatchPlayer},
+		http.MethodDelete: {admin, writes, handler.DeletePlayer},
+	})
+	transactions := chain("/api/v1/players/{id}/transactions", methods{
+		http.MethodPost: {admin, writes, handler.RecordTransaction},
+	})
+	memberships := chain("/api/v1/players/{id}/memberships", methods{
+		http.MethodGet: {read, reads, handler.ListMemberships},
+	})
+	playerGames := chain("/api/v1/players/{id}/games", methods{
+		http.MethodGet: {read, reads, handler.ListPlayerGames},
+	})
+	mux.Handle("/api/v1/players/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch pathSegment(r, 5) {
+		case "transactions":
+			transactions.ServeHTTP(w, r)
+		case "memberships":
+			memberships.ServeHTTP(w, r)
+		case "games":
+			playerGames.ServeHTTP(w, r)
+		default:
+			players.ServeHTTP(w, r)
 		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
+	}))
 
-	mux.Handle("/api/v1/player-stats/team/", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			handler.GetTeamAggregate(w, r)
+	// Team management endpoints, and game logs.
+	mux.Handle("/api/v1/teams", chain("/api/v1/teams", methods{
+		http.MethodGet:  {read, reads, handler.ListTeams},
+		http.MethodPost: {admin, writes, handler.CreateTeam},
+	}))
+	teams := chain("/api/v1/teams/{id}", methods{
+		http.MethodGet:    {read, reads, handler.GetTeam},
+		http.MethodPut:    {admin, writes, handler.UpdateTeam},
+		http.MethodPatch:  {admin, writes, handler.PatchTeam},
+		http.MethodDelete: {admin, writes, handler.DeleteTeam},
+	})
+	teamGames := chain("/api/v1/teams/{id}/games", methods{
+		http.MethodGet: {read, reads, handler.ListTeamGames},
+	})
+	mux.Handle("/api/v1/teams/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pathSegment(r, 5) == "games" {
+			teamGames.ServeHTTP(w, r)
 			return
 		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
+		teams.ServeHTTP(w, r)
+	}))
 
-	// Player management endpoints.
-	mux.Handle("/api/v1/players", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.CreatePlayer(w, r)
-			return
+	// Game management endpoints, including box scores and scorekeeper assignments.
+	mux.Handle("/api/v1/games", chain("/api/v1/games", methods{
+		http.MethodGet:  {read, reads, handler.ListGames},
+		http.MethodPost: {admin, writes, handler.CreateGame},
+	}))
+	games := chain("/api/v1/games/{id}", methods{
+		http.MethodGet:    {read, reads, handler.GetGame},
+		http.MethodPut:    {admin, writes, handler.UpdateGame},
+		http.MethodPatch:  {admin, writes, handler.PatchGame},
+		http.MethodDelete: {admin, writes, handler.DeleteGame},
+	})
+	boxScores := chain("/api/v1/games/{id}/box-score", methods{
+		http.MethodGet:  {read, reads, handler.GetBoxScore},
+		http.MethodPost: {write, writes, handler.LogBoxScore},
+	})
+	scorekeepers := chain("/api/v1/games/{id}/scorekeepers", methods{
+		http.MethodGet: {admin, reads, handler.ListScorekeepers},
+	})
+	scorekeeper := chain("/api/v1/games/{id}/scorekeepers/{subject}", methods{
+		http.MethodPut:    {admin, writes, handler.AssignScorekeeper},
+		http.MethodDelete: {admin, writes, handler.UnassignScorekeeper},
+	})
+	mux.Handle("/api/v1/games/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case pathSegment(r, 5) == "box-score":
+			boxScores.ServeHTTP(w, r)
+		case pathSegment(r, 5) == "scorekeepers" && pathSegment(r, 6) == "":
+			scorekeepers.ServeHTTP(w, r)
+		case pathSegment(r, 5) == "scorekeepers":
+			scorekeeper.ServeHTTP(w, r)
+		default:
+			games.ServeHTTP(w, r)
 		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
-	mux.Handle("/api/v1/players/", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			handler.GetPlayer(w, r)
-			return
-		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
+	}))
 
-	// Team management endpoints.
-	mux.Handle("/api/v1/teams", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.CreateTeam(w, r)
-			return
-		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
-	mux.Handle("/api/v1/teams/", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			handler.GetTeam(w, r)
-			return
-		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
+	// Season management endpoints.
+	mux.Handle("/api/v1/seasons", chain("/api/v1/seasons", methods{
+		http.MethodGet:  {read, reads, handler.ListSeasons},
+		http.MethodPost: {admin, writes, handler.CreateSeason},
+	}))
+	mux.Handle("/api/v1/seasons/", chain("/api/v1/seasons/{id}", methods{
+		http.MethodGet: {read, reads, handler.GetSeason},
+	}))
+
+	// API key management endpoints.
+	mux.Handle("/api/v1/api-keys", chain("/api/v1/api-keys", methods{
+		http.MethodGet:  {admin, reads, handler.ListAPIKeys},
+		http.MethodPost: {admin, writes, handler.CreateAPIKey},
+	}))
+	mux.Handle("/api/v1/api-keys/", chain("/api/v1/api-keys/{id}", methods{
+		http.MethodDelete: {admin, writes, handler.RevokeAPIKey},
+	}))
 
-	// Game management endpoints.
-	mux.Handle("/api/v1/games", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.CreateGame(w, r)
-			return
-		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
-	mux.Handle("/api/v1/games/", chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			handler.GetGame(w, r)
-			return
-		}
-		errors.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	})))
-	
 	mux.HandleFunc("/health/live", LivenessProbeHandler)
-	mux.HandleFunc("/health/ready", ReadinessProbeHandler(db))
+	mux.HandleFunc("/health/ready", ReadinessProbeHandler(db, &handler.Draining))
+	mux.Handle("/metrics", metrics.Handler())
 }
@@ -2,15 +2,21 @@
 package app
 
 import (
+	"database/sql"
 	"net/http"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/api"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 	"github.com/vgeshiktor/nba-stats/internal/service"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // AppConfig holds the configuration settings for the application.
@@ -20,6 +26,43 @@ type AppConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	MigrationsDir   string
+	MigrateOnStart  bool
+
+	// JWT verification; bearer tokens are rejected unless at least one key is configured.
+	JWTIssuer             string
+	JWTAudience           string
+	JWTLeeway             time.Duration
+	JWTHS256Secret        string
+	JWTRS256PublicKeyFile string
+	JWTJWKSFile           string
+
+	// Requests per client allowed by each class of routes, and per IP address without
+	// valid credentials; a zero limit disables limiting.
+	IPRateLimit        ratelimit.Limit
+	ReadRateLimit      ratelimit.Limit
+	AggregateRateLimit ratelimit.Limit
+	WriteRateLimit     ratelimit.Limit
+
+	// Proxies, as IP addresses or CIDR prefixes, whose X-Forwarded-For header is trusted
+	// to identify the client's IP address.
+	TrustedProxies []netip.Prefix
+
+	// Graceful shutdown: how long the server keeps serving after readiness probes start
+	// failing, then how long it waits for in-flight requests.
+	ShutdownDrainDelay time.Duration
+	ShutdownTimeout    time.Duration
+
+	// Where spans are exported: "none", "stdout" or "file" (TracingFile).
+	TracingExporter string
+	TracingFile     string
+
+	// Minimum level of the log entries written.
+	LogLevel logger.Level
+
+	// How long aggregates are cached, and how many at most; a zero TTL disables caching.
+	AggregateCacheTTL  time.Duration
+	AggregateCacheSize int
 }
 
 // NewConfig reads environment variables and returns an AppConfig.
@@ -38,12 +81,44 @@ func NewConfig() AppConfig {
 	maxIdleConns := getEnvAsInt("DB_MAX_IDLE_CONNS", 25)
 	connMaxLifetime := getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute)
 
+	// Read migrations directory from environment variable (default if not set)
+	migrationsDir := os.Getenv("MIGRATIONS_DIR")
+	if migrationsDir == "" {
+		migrationsDir = "migrations"
+	}
+	migrateOnStart := getEnvAsBool("MIGRATE_ON_START", true)
+
+	tracingFile := os.Getenv("TRACING_FILE")
+	if tracingFile == "" {
+		tracingFile = "traces.jsonl"
+	}
+
 	return AppConfig{
-		Port:            port,
-		DBConnStr:       dbConnStr,
-		MaxOpenConns:    maxOpenConns,
-		MaxIdleConns:    maxIdleConns,
-		ConnMaxLifetime: connMaxLifetime,
+		Port:                  port,
+		DBConnStr:             dbConnStr,
+		MaxOpenConns:          maxOpenConns,
+		MaxIdleConns:          maxIdleConns,
+		ConnMaxLifetime:       connMaxLifetime,
+		MigrationsDir:         migrationsDir,
+		MigrateOnStart:        migrateOnStart,
+		JWTIssuer:             os.Getenv("JWT_ISSUER"),
+		JWTAudience:           os.Getenv("JWT_AUDIENCE"),
+		JWTLeeway:             getEnvAsDuration("JWT_LEEWAY", 30*time.Second),
+		JWTHS256Secret:        os.Getenv("JWT_HS256_SECRET"),
+		JWTRS256PublicKeyFile: os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"),
+		JWTJWKSFile:           os.Getenv("JWT_JWKS_FILE"),
+		IPRateLimit:           getEnvAsRateLimit("RATE_LIMIT_IP", 3000, 300),
+		ReadRateLimit:         getEnvAsRateLimit("RATE_LIMIT_READS", 1200, 200),
+		AggregateRateLimit:    getEnvAsRateLimit("RATE_LIMIT_AGGREGATES", 300, 50),
+		WriteRateLimit:        getEnvAsRateLimit("RATE_LIMIT_WRITES", 600, 100),
+		TrustedProxies:        getEnvAsPrefixes("TRUSTED_PROXIES"),
+		ShutdownDrainDelay:    getEnvAsDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		ShutdownTimeout:       getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		TracingExporter:       os.Getenv("TRACING_EXPORTER"),
+		TracingFile:           tracingFile,
+		LogLevel:              getEnvAsLogLevel("LOG_LEVEL", logger.LevelInfo),
+		AggregateCacheTTL:     getEnvAsDuration("AGGREGATE_CACHE_TTL", 30*time.Second),
+		AggregateCacheSize:    getEnvAsInt("AGGREGATE_CACHE_SIZE", 10000),
 	}
 }
 
@@ -55,12 +130,60 @@ func getEnvAsInt(name string, defaultValue int) int {
 	}
 	val, err := strconv.Atoi(valStr)
 	if err != nil {
-		logger.Error("Invalid value for %s, using default: %d", name, defaultValue)
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", valStr, "default", defaultValue)
 		return defaultValue
 	}
 	return val
 }
 
+// getEnvAsBool retrieves an environment variable as a boolean.
+func getEnvAsBool(name string, defaultValue bool) bool {
+	valStr := os.Getenv(name)
+	if valStr == "" {
+		return defaultValue
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", valStr, "default", defaultValue)
+		return defaultValue
+	}
+	return val
+}
+
+// getEnvAsRateLimit reads a rate limit from the environment variables NAME_PER_MINUTE
+// and NAME_BURST.
+func getEnvAsRateLimit(name string, perMinute, burst int) ratelimit.Limit {
+	return ratelimit.PerMinute(getEnvAsInt(name+"_PER_MINUTE", perMinute), getEnvAsInt(name+"_BURST", burst))
+}
+
+// getEnvAsPrefixes reads a comma-separated list of IP addresses and CIDR prefixes from
+// an environment variable; invalid entries are skipped.
+func getEnvAsPrefixes(name string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				logger.Warn("Invalid environment variable entry, skipping it", "name", name, "value", entry)
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// openDB establishes the database connection with the configured pool settings.
+func openDB(config AppConfig) (*sql.DB, error) {
+	return repository.NewDB(config.DBConnStr, config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime)
+}
+
 // getEnvAsDuration retrieves an environment variable as a time.Duration.
 func getEnvAsDuration(name string, defaultValue time.Duration) time.Duration {
 	valStr := os.Getenv(name)
@@ -69,33 +192,49 @@ func getEnvAsDuration(name string, defaultValue time.Duration) time.Duration {
 	}
 	val, err := time.ParseDuration(valStr)
 	if err != nil {
-		logger.Error("Invalid value for %s, using default: %v", name, defaultValue)
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", valStr, "default", defaultValue)
+		return defaultValue
+	}
+	return val
+}
+
+// getEnvAsLogLevel retrieves an environment variable as a log level, e.g. "debug".
+func getEnvAsLogLevel(name string, defaultValue logger.Level) logger.Level {
+	valStr := os.Getenv(name)
+	if valStr == "" {
+		return defaultValue
+	}
+	val, err := logger.ParseLevel(valStr)
+	if err != nil {
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", valStr, "default", defaultValue)
 		return defaultValue
 	}
 	return val
 }
 
 // Initialize sets up the database connection, repositories, services, API handlers, and HTTP router.
-func Initialize() *http.Server {
+func Initialize() *Server {
 	// Load configuration
 	config := NewConfig()
+	logger.SetLevel(config.LogLevel)
+
+	tracing.SetExporter(newTraceExporter(config))
 
 	// Establish database connection with the configured pool settings.
-	db, err := repository.NewDB(config.DBConnStr, config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime)
+	db, err := openDB(config)
 	if err != nil {
-		logger.Error("Failed to connect to the database (conn str: %v): %v", config.DBConnStr, err)
+		logger.Error("Failed to connect to the database", "error", err)
 	}
 
-	// Read schema path from environment variable (default if not set)
-	schemaPath := os.Getenv("DB_SCHEMA_PATH")
-	if schemaPath == "" {
-		schemaPath = "migrations/db_schema.sql" // Default fallback
+	if db != nil {
+		repository.RegisterDBMetrics(metrics.Default, db)
 	}
 
-	// Run migrations to create necessary tables
-	err = RunMigrations(db, schemaPath)
-	if err != nil {
-		logger.Error("Failed to run migrations: %v", err)
+	// Apply pending migrations unless they are run separately with the migrate subcommand.
+	if config.MigrateOnStart {
+		if err := RunMigrations(db, config.MigrationsDir, config.DBConnStr); err != nil {
+			logger.Error("Failed to run migrations", "error", err)
+		}
 	}
 
 	// Initialize repositories
@@ -103,30 +242,71 @@ func Initialize() *http.Server {
 	teamRepo := repository.NewTeamRepository(db)
 	gameRepo := repository.NewGameRepository(db)
 	statsRepo := repository.NewPlayerStatsRepository(db)
+	seasonRepo := repository.NewSeasonRepository(db)
+	leaderRepo := repository.NewLeaderRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 	// Initialize service layers
-	statsService := service.NewPlayerStatsService(playerRepo, teamRepo, gameRepo, statsRepo)
-	aggregationService := service.NewAggregationService(statsRepo)
-	playerService := service.NewPlayerService(playerRepo)
+	aggregationService := service.NewAggregationService(statsRepo, seasonRepo)
+	var invalidators []service.StatsInvalidator
+	if config.AggregateCacheTTL > 0 {
+		// Logging stats, roster moves and deleting or moving games discard the cached
+		// aggregates of the players and teams involved.
+		cache := service.NewAggregationCache(aggregationService, config.AggregateCacheTTL, config.AggregateCacheSize, nil)
+		service.RegisterCacheMetrics(metrics.Default, cache)
+		aggregationService = cache
+		invalidators = append(invalidators, cache)
+	}
+	statsService := service.NewPlayerStatsService(playerRepo, teamRepo, gameRepo, statsRepo, invalidators...)
+	advancedStatsService := service.NewAdvancedStatsService(playerRepo, statsRepo, seasonRepo)
+	leaderService := service.NewLeaderService(leaderRepo, seasonRepo)
+	standingsService := service.NewStandingsService(gameRepo, teamRepo, seasonRepo)
+	gameLogService := service.NewGameLogService(statsRepo, playerRepo, teamRepo, gameRepo, seasonRepo)
+	playerService := service.NewPlayerService(playerRepo, invalidators...)
 	teamService := service.NewTeamService(teamRepo)
-	gameService := service.NewGameService(gameRepo)
+	gameService := service.NewGameService(gameRepo, seasonRepo, invalidators...)
+	seasonService := service.NewSeasonService(seasonRepo)
+	authService := service.NewAuthService(newJWTVerifier(config), apiKeyRepo)
 
 	// Initialize API handlers and register routes
 	apiHandler := api.NewHandler(
 		statsService,
 		aggregationService,
+		advancedStatsService,
+		leaderService,
+		standingsService,
+		gameLogService,
 		playerService,
 		teamService,
 		gameService,
+		seasonService,
+		authService,
 	)
+	apiHandler.RateLimits = api.RateLimits{
+		Store:      ratelimit.NewMemoryStore(nil),
+		IP:         config.IPRateLimit,
+		Reads:      config.ReadRateLimit,
+		Aggregates: config.AggregateRateLimit,
+		Writes:     config.WriteRateLimit,
+
+		TrustedProxies: config.TrustedProxies,
+	}
 	mux := http.NewServeMux()
 	api.RegisterRoutes(mux, apiHandler, db)
 
-	// Create and return the configured HTTP server
-	return &http.Server{
-		Addr:         ":" + config.Port,
-		Handler:      mux,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+	// Create and return the configured HTTP server. Requests are canceled, along with
+	// their queries, once their response could no longer be written.
+	const writeTimeout = 10 * time.Second
+	return &Server{
+		Server: &http.Server{
+			Addr:         ":" + config.Port,
+			Handler:      api.TimeoutMiddleware(writeTimeout)(mux),
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: writeTimeout,
+		},
+		handler:         apiHandler,
+		db:              db,
+		drainDelay:      config.ShutdownDrainDelay,
+		shutdownTimeout: config.ShutdownTimeout,
 	}
 }
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+)
+
+// apiKeyUsage describes the arguments of the apikey subcommand.
+const apiKeyUsage = "usage: apikey create NAME SCOPE... | list | revoke ID"
+
+// newJWTVerifier builds a verifier from the configured HS256 secret, RS256 public key
+// file and JWKS file. It returns nil, so that bearer tokens are rejected, if no key
+// is configured. Key files that cannot be loaded are logged and skipped.
+func newJWTVerifier(config AppConfig) *jwt.Verifier {
+	var keys []jwt.Key
+	if config.JWTHS256Secret != "" {
+		keys = append(keys, jwt.Key{Algorithm: jwt.HS256, Secret: []byte(config.JWTHS256Secret)})
+	}
+	if config.JWTRS256PublicKeyFile != "" {
+		publicKey, err := loadRSAPublicKey(config.JWTRS256PublicKeyFile)
+		if err != nil {
+			logger.Error("Failed to load JWT public key", "file", config.JWTRS256PublicKeyFile, "error", err)
+		} else {
+			keys = append(keys, jwt.Key{Algorithm: jwt.RS256, PublicKey: publicKey})
+		}
+	}
+	if config.JWTJWKSFile != "" {
+		jwks, err := loadJWKS(config.JWTJWKSFile)
+		if err != nil {
+			logger.Error("Failed to load JWKS", "file", config.JWTJWKSFile, "error", err)
+		}
+		keys = append(keys, jwks...)
+	}
+
+	if len(keys) == 0 {
+		logger.Warn("No JWT keys configured; only API keys are accepted")
+		return nil
+	}
+	logger.Info("Accepting JWTs", "keys", len(keys))
+	return jwt.NewVerifier(jwt.Config{
+		Keys:     keys,
+		Issuer:   config.JWTIssuer,
+		Audience: config.JWTAudience,
+		Leeway:   config.JWTLeeway,
+	})
+}
+
+// loadRSAPublicKey reads a PEM encoded RSA public key file.
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKey(data)
+}
+
+// loadJWKS reads the keys of a local JWKS file.
+func loadJWKS(path string) ([]jwt.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseJWKS(data)
+}
+
+// RunAPIKeyCommand runs the apikey subcommand with the given arguments against the
+// configured database, writing its report to out. It is how the first API key is
+// issued, since the API key endpoints themselves require authentication.
+func RunAPIKeyCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+	config := NewConfig()
+	logger.SetLevel(config.LogLevel)
+	db, err := openDB(config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	authService := service.NewAuthService(nil, repository.NewAPIKeyRepository(db))
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "create" && len(args) >= 3:
+		apiKey, key, err := authService.CreateAPIKey(ctx, args[1], args[2:])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created API key %s (%s) with scopes %s\n%s\n", apiKey.ID, apiKey.Name, strings.Join(apiKey.Scopes, " "), key)
+		fmt.Fprintln(out, "store this key now; it cannot be shown again")
+		return nil
+	case args[0] == "list" && len(args) == 1:
+		keys, err := authService.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			state := "active"
+			if k.RevokedAt != nil {
+				state = "revoked " + k.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%s  %s...  %-20s %-30s created %s  %s\n", k.ID, k.Prefix, k.Name, strings.Join(k.Scopes, " "), k.CreatedAt.Format(time.RFC3339), state)
+		}
+		return nil
+	case args[0] == "revoke" && len(args) == 2:
+		if err := authService.RevokeAPIKey(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "revoked API key %s\n", args[1])
+		return nil
+	}
+	return errors.New(apiKeyUsage)
+}
@@ -2,26 +2,105 @@ package app
 
 import (
 	"database/sql"
-	"os"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/vgeshiktor/nba-stats/internal/migrate"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
 )
 
-// RunMigrations reads the SQL migration file and executes it against the database.
-func RunMigrations(db *sql.DB, schemaPath string) error {
-	cwd, _ := os.Getwd()
-	logger.Info("current folder for migration: %s", cwd)
-	logger.Info("Running migrations: %s", schemaPath)
-	schema, err := os.ReadFile(schemaPath)
+// migrateUsage describes the arguments of the migrate subcommand.
+const migrateUsage = "usage: migrate up | down N | status | to VERSION"
+
+// newMigrator loads the migrations in dir for the dialect of connStr and returns a
+// Migrator for db.
+func newMigrator(db *sql.DB, dir string, connStr string) (*migrate.Migrator, error) {
+	dialect, _ := repository.ParseConnStr(connStr)
+	migrations, err := migrate.Load(dir, string(dialect))
+	if err != nil {
+		return nil, err
+	}
+	var locker migrate.Locker = migrate.AdvisoryLock{Key: migrate.DefaultAdvisoryLockKey}
+	if dialect == repository.DialectSQLite {
+		locker = migrate.NoLock{}
+	}
+	return migrate.New(db, migrations, locker), nil
+}
+
+// RunMigrations applies every pending migration in dir against the database.
+func RunMigrations(db *sql.DB, dir string, connStr string) error {
+	logger.Info("Running migrations", "dir", dir)
+	migrator, err := newMigrator(db, dir, connStr)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		return err
+	}
+	logger.Info("Database migrations applied successfully", "applied", applied)
+	return nil
+}
+
+// RunMigrateCommand runs the migrate subcommand with the given arguments against the
+// configured database, writing its report to out.
+func RunMigrateCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	config := NewConfig()
+	logger.SetLevel(config.LogLevel)
+	db, err := openDB(config)
 	if err != nil {
 		return err
 	}
+	defer db.Close()
 
-	// Execute the migration script. Using Exec ensures that all statements in the file are run.
-	_, err = db.Exec(string(schema))
+	migrator, err := newMigrator(db, config.MigrationsDir, config.DBConnStr)
 	if err != nil {
 		return err
 	}
 
-	logger.Info("Database migrations applied successfully.")
-	return nil
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		n, err := migrator.Up()
+		fmt.Fprintf(out, "applied %d migrations\n", n)
+		return err
+	case args[0] == "down" && len(args) == 2:
+		steps, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+		n, err := migrator.Down(steps)
+		fmt.Fprintf(out, "reverted %d migrations\n", n)
+		return err
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+		n, err := migrator.To(version)
+		fmt.Fprintf(out, "applied or reverted %d migrations\n", n)
+		return err
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				state += " (modified since applied)"
+			}
+			fmt.Fprintf(out, "%04d %-30s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
 }
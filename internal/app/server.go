@@ -0,0 +1,69 @@
+// internal/app/server.go
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/api"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+)
+
+// Server is the HTTP server of the application along with the resources it shuts down.
+type Server struct {
+	*http.Server
+
+	handler         *api.Handler
+	db              *sql.DB
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+}
+
+// Run serves requests until ctx is done, then shuts down gracefully: readiness probes
+// fail at once so that the load balancer stops routing requests to the server, which
+// keeps serving them for the drain delay, then stops accepting connections and waits
+// up to the shutdown timeout for in-flight requests before closing the database.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server", "addr", s.Addr)
+		serveErr <- s.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		s.closeDB()
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down: draining", "drain_delay", s.drainDelay)
+	s.handler.Draining.Store(true)
+	time.Sleep(s.drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	err := s.Shutdown(shutdownCtx)
+	if err != nil {
+		logger.Error("Failed to finish in-flight requests", "error", err)
+	}
+	if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) {
+		logger.Error("Server failed", "error", serveErr)
+	}
+	s.closeDB()
+	logger.Info("Server stopped")
+	return err
+}
+
+// closeDB closes the database connection, if it was opened.
+func (s *Server) closeDB() {
+	if s.db == nil {
+		return
+	}
+	if err := s.db.Close(); err != nil {
+		logger.Error("Failed to close the database", "error", err)
+	}
+}
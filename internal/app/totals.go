@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+)
+
+// totalsUsage describes the arguments of the totals subcommand.
+const totalsUsage = "usage: totals check | rebuild"
+
+// RunTotalsCommand runs the totals subcommand with the given arguments against the
+// configured database, writing its report to out. check lists the season totals that
+// disagree with the stat lines and fails if there are any; rebuild recomputes them all.
+func RunTotalsCommand(args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(totalsUsage)
+	}
+	config := NewConfig()
+	logger.SetLevel(config.LogLevel)
+	db, err := openDB(config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	totals := repository.NewSeasonTotalsRepository(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "check":
+		drifts, err := totals.CheckSeasonTotals(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range drifts {
+			fmt.Fprintf(out, "%s: stored %s, computed %s\n", totalsOwner(d.Computed), totalsSummary(d.Stored), totalsSummary(d.Computed))
+		}
+		if len(drifts) > 0 {
+			return fmt.Errorf("%d season totals differ from the stat lines; run \"totals rebuild\"", len(drifts))
+		}
+		fmt.Fprintln(out, "season totals match the stat lines")
+		return nil
+	case "rebuild":
+		if err := totals.RebuildSeasonTotals(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "rebuilt season totals")
+		return nil
+	}
+	return errors.New(totalsUsage)
+}
+
+// totalsOwner names the player or team, season and season type of a season total.
+func totalsOwner(agg domain.AggregateStats) string {
+	owner := "player " + agg.PlayerID
+	if agg.TeamID != "" {
+		owner = "team " + agg.TeamID
+	}
+	season := agg.SeasonID
+	if season == "" {
+		season = "(no season)"
+	}
+	return owner + " " + season + " " + agg.SeasonType
+}
+
+// totalsSummary formats the main totals of a season total.
+func totalsSummary(agg domain.AggregateStats) string {
+	return fmt.Sprintf("%d games %d pts %d reb %d ast %.1f min",
+		agg.GamesPlayed, agg.TotalPoints, agg.TotalRebounds, agg.TotalAssists, agg.TotalMinutes)
+}
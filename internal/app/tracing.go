@@ -0,0 +1,31 @@
+// internal/app/tracing.go
+package app
+
+import (
+	"os"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// newTraceExporter returns the exporter selected by TRACING_EXPORTER: "stdout" writes
+// spans to standard output and "file" appends them to TRACING_FILE, one JSON object
+// per line. It returns nil, so that spans are discarded, for "none" or if the file
+// cannot be opened.
+func newTraceExporter(config AppConfig) tracing.Exporter {
+	switch config.TracingExporter {
+	case "", "none":
+		return nil
+	case "stdout":
+		return tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		file, err := os.OpenFile(config.TracingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			logger.Error("Failed to open trace file, spans are discarded", "file", config.TracingFile, "error", err)
+			return nil
+		}
+		return tracing.NewWriterExporter(file)
+	}
+	logger.Error("Unknown TRACING_EXPORTER, spans are discarded", "exporter", config.TracingExporter)
+	return nil
+}
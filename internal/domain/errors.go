@@ -1,11 +1,112 @@
 // internal/domain/errors.go
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-// Predefined errors for domain-related issues.
+// Predefined errors for domain-related issues. Repositories and services report
+// failures wrapping one of these, and the API maps each to an HTTP status code.
 var (
+	// ErrInvalidInput reports a malformed request, such as a missing ID or an unknown filter value.
 	ErrInvalidInput = errors.New("invalid input")
-	ErrNotFound     = errors.New("resource not found")
-	ErrDBFailure    = errors.New("database error")
+	// ErrValidation reports a well-formed entity that breaks a domain rule, or refers to
+	// another entity that does not exist.
+	ErrValidation = errors.New("validation failed")
+	// ErrNotFound reports that the requested entity does not exist.
+	ErrNotFound = errors.New("resource not found")
+	// ErrUnauthenticated reports a request without valid credentials.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden reports an authenticated caller that is not allowed to make the request.
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict reports that the request conflicts with stored data, such as a duplicate ID.
+	ErrConflict  = errors.New("resource conflict")
+	ErrDBFailure = errors.New("database error")
 )
+
+// Error is a domain error with a message that is safe to show to API clients.
+// It wraps one of the predefined errors above, which classifies it.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+// Errorf returns an *Error of the given kind with a formatted message.
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFoundError reports that no entity of the given kind, such as "player", has the given ID.
+func NotFoundError(entity, id string) error {
+	return Errorf(ErrNotFound, "%s %s not found", entity, id)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Codes classifying why a field is invalid, reported in FieldError.Code.
+const (
+	CodeRequired   = "required"     // The field is missing or empty.
+	CodeInvalid    = "invalid"      // The field is malformed or has an unknown value.
+	CodeOutOfRange = "out_of_range" // The field is outside its allowed range.
+	CodeMismatch   = "mismatch"     // The field contradicts other fields of the entity.
+	CodeDuplicate  = "duplicate"    // The field repeats a value that must be unique.
+	CodeNotFound   = "not_found"    // The field refers to an entity that does not exist.
+)
+
+// FieldError describes one rule broken by one field of an entity.
+type FieldError struct {
+	Field   string `json:"field"` // JSON name of the field, e.g. "minutes_played".
+	Code    string `json:"code"`  // One of the Code constants.
+	Message string `json:"message"`
+}
+
+// ValidationError reports every rule an entity breaks. It wraps ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// InvalidField returns a *ValidationError for a single field breaking a rule.
+func InvalidField(field, code, format string, args ...interface{}) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Code: code, Message: fmt.Sprintf(format, args...)}}}
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// LineError describes why a single line of a batch was rejected.
+type LineError struct {
+	Index    int          `json:"index"`               // Position of the line in the batch.
+	PlayerID string       `json:"player_id,omitempty"` // Player the line was recorded for.
+	Errors   []FieldError `json:"errors"`              // Every rule the line breaks.
+}
+
+// BatchError rejects a whole batch because one or more of its lines are invalid.
+// It wraps ErrValidation.
+type BatchError struct {
+	Lines []LineError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of the submitted lines are invalid", len(e.Lines))
+}
+
+func (e *BatchError) Unwrap() error {
+	return ErrValidation
+}
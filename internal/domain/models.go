@@ -2,65 +2,712 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
 // Player represents an NBA player.
 type Player struct {
-	ID     string `json:"id"`      // Unique identifier for the player.
-	Name   string `json:"name"`    // Player's full name.
-	TeamID string `json:"team_id"` // Associated team identifier.
+	ID        string     `json:"id"`                   // Unique identifier for the player.
+	Name      string     `json:"name"`                 // Player's full name.
+	TeamID    string     `json:"team_id"`              // Current team; empty for a free agent.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // When the player was last created or changed, if known.
+}
+
+// Roster transaction types put a player on a team, move them to another or take them
+// off their team.
+const (
+	TransactionSigning = "signing" // A free agent joins a team, or a two-way player converts to a standard contract.
+	TransactionTwoWay  = "two_way" // A free agent joins a team on a two-way contract.
+	TransactionTrade   = "trade"   // The player moves from their team to another.
+	TransactionWaiver  = "waiver"  // The player is released and becomes a free agent.
+)
+
+// TransactionTypes lists the valid roster transaction types.
+var TransactionTypes = []string{TransactionSigning, TransactionTwoWay, TransactionTrade, TransactionWaiver}
+
+// RosterTransaction changes a player's team from its date on.
+type RosterTransaction struct {
+	PlayerID   string     `json:"player_id"`              // Player the transaction applies to.
+	Type       string     `json:"type"`                   // One of TransactionTypes.
+	TeamID     string     `json:"team_id,omitempty"`      // Team the player joins; empty for a waiver.
+	FromTeamID string     `json:"from_team_id,omitempty"` // Team the player leaves, set by the server.
+	Date       time.Time  `json:"date"`                   // When the transaction takes effect.
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`  // When the transaction was recorded, set by the server.
+}
+
+// RosterMembership is a stint of a player on a team, between the transactions that put
+// them on it and took them off it.
+type RosterMembership struct {
+	PlayerID string     `json:"player_id"`          // Identifier of the player.
+	TeamID   string     `json:"team_id"`            // Identifier of the team.
+	JoinedBy string     `json:"joined_by"`          // Type of the transaction that started the stint.
+	Start    *time.Time `json:"start,omitempty"`    // Start of the stint; nil if before the player's recorded history.
+	End      *time.Time `json:"end,omitempty"`      // End of the stint; nil for the player's current team.
+	EndedBy  string     `json:"ended_by,omitempty"` // Type of the transaction that ended the stint.
 }
 
 // Team represents an NBA team.
 type Team struct {
-	ID   string `json:"id"`   // Unique identifier for the team.
-	Name string `json:"name"` // Name of the team.
+	ID         string     `json:"id"`                   // Unique identifier for the team.
+	Name       string     `json:"name"`                 // Name of the team.
+	Conference string     `json:"conference,omitempty"` // ConferenceEast or ConferenceWest, if known.
+	Division   string     `json:"division,omitempty"`   // One of the conference's Divisions, if known.
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"` // When the team was last created or changed, if known.
+}
+
+// Conferences of the league.
+const (
+	ConferenceEast = "East"
+	ConferenceWest = "West"
+)
+
+// Divisions lists the divisions of each conference.
+var Divisions = map[string][]string{
+	ConferenceEast: {"Atlantic", "Central", "Southeast"},
+	ConferenceWest: {"Northwest", "Pacific", "Southwest"},
+}
+
+// Season types distinguish regular-season games from playoff games.
+const (
+	SeasonTypeRegular  = "regular"
+	SeasonTypePlayoffs = "playoffs"
+)
+
+// Leader scopes select whether a leaderboard ranks players or teams.
+const (
+	LeaderScopePlayer = "player"
+	LeaderScopeTeam   = "team"
+)
+
+// Season represents an NBA season, e.g. "2025-26".
+type Season struct {
+	ID            string    `json:"id"`             // Season identifier in "YYYY-YY" form, e.g. "2025-26".
+	StartDate     time.Time `json:"start_date"`     // First day of the regular season.
+	EndDate       time.Time `json:"end_date"`       // Last day of the season, including the playoffs.
+	PlayoffsStart time.Time `json:"playoffs_start"` // First day of the playoffs; games from this day on are playoff games.
+}
+
+// Contains reports whether t falls on or between the season's start and end dates.
+func (s Season) Contains(t time.Time) bool {
+	return !t.Before(s.StartDate) && t.Before(s.EndDate.AddDate(0, 0, 1))
+}
+
+// TypeOn returns the season type of a game played at t.
+func (s Season) TypeOn(t time.Time) string {
+	if !s.PlayoffsStart.IsZero() && !t.Before(s.PlayoffsStart) {
+		return SeasonTypePlayoffs
+	}
+	return SeasonTypeRegular
+}
+
+// Game statuses. A game is scheduled until it starts, live while it is played and
+// final once it is over. A game that cannot be played as scheduled is postponed, and
+// later rescheduled, or cancelled.
+const (
+	GameStatusScheduled = "scheduled"
+	GameStatusLive      = "live"
+	GameStatusFinal     = "final"
+	GameStatusPostponed = "postponed"
+	GameStatusCancelled = "cancelled"
+)
+
+// GameStatuses lists every game status.
+var GameStatuses = []string{GameStatusScheduled, GameStatusLive, GameStatusFinal, GameStatusPostponed, GameStatusCancelled}
+
+// gameTransitions lists the statuses each status may change to. Final and cancelled
+// games keep their status.
+var gameTransitions = map[string][]string{
+	GameStatusScheduled: {GameStatusLive, GameStatusPostponed, GameStatusCancelled},
+	GameStatusLive:      {GameStatusFinal, GameStatusPostponed},
+	GameStatusPostponed: {GameStatusScheduled, GameStatusLive, GameStatusCancelled},
+}
+
+// CanChangeGameStatus reports whether a game may go from one status to another.
+// Keeping the same status is always allowed.
+func CanChangeGameStatus(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range gameTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckStatusChange returns ErrConflict if a game cannot go from one status to
+// another: a final game can no longer be changed, and other changes must be allowed by
+// CanChangeGameStatus.
+func CheckStatusChange(gameID, from, to string) error {
+	if from == GameStatusFinal {
+		return Errorf(ErrConflict, "game %s is final and can no longer be changed", gameID)
+	}
+	if !CanChangeGameStatus(from, to) {
+		return Errorf(ErrConflict, "game %s cannot go from %s to %s", gameID, from, to)
+	}
+	return nil
+}
+
+// CheckStatsAccepted returns ErrConflict if stats cannot be logged for a game with the
+// given status because it has not started, was cancelled or is final. A final game's
+// score was checked against its stat lines when it was made final, and can no longer
+// change.
+func CheckStatsAccepted(gameID, status string) error {
+	switch status {
+	case GameStatusScheduled, GameStatusCancelled, GameStatusFinal:
+		return Errorf(ErrConflict, "stats cannot be logged for game %s, which is %s", gameID, status)
+	}
+	return nil
+}
+
+// RegulationPeriods is the number of quarters in a game; later periods are overtimes.
+const RegulationPeriods = 4
+
+// Length in minutes of regulation, the four quarters together, and of each overtime.
+const (
+	RegulationMinutes = 48
+	OvertimeMinutes   = 5
+)
+
+// PeriodScore is the points each team scored in a period of a game.
+type PeriodScore struct {
+	Period int `json:"period"` // 1 to 4 for the quarters, 5 and on for overtimes.
+	Home   int `json:"home"`   // Points scored by the home team.
+	Away   int `json:"away"`   // Points scored by the away team.
 }
 
 // Game represents a single NBA game.
 type Game struct {
-	ID       string    `json:"id"`        // Unique identifier for the game.
-	Date     time.Time `json:"date"`      // Date and time of the game.
-	HomeTeam string    `json:"home_team"` // Home team identifier.
-	AwayTeam string    `json:"away_team"` // Away team identifier.
+	ID         string        `json:"id"`                    // Unique identifier for the game.
+	Date       time.Time     `json:"date"`                  // Date and time of the game.
+	HomeTeam   string        `json:"home_team"`             // Home team identifier.
+	AwayTeam   string        `json:"away_team"`             // Away team identifier.
+	SeasonID   string        `json:"season_id,omitempty"`   // Season the game belongs to, if known.
+	SeasonType string        `json:"season_type,omitempty"` // SeasonTypeRegular or SeasonTypePlayoffs.
+	Status     string        `json:"status"`                // One of GameStatuses; a final game can no longer be changed.
+	HomeScore  int           `json:"home_score"`            // Points scored by the home team.
+	AwayScore  int           `json:"away_score"`            // Points scored by the away team.
+	Periods    []PeriodScore `json:"periods,omitempty"`     // Score by period, in order, if recorded.
+	Overtimes  int           `json:"overtimes"`             // Overtime periods played; derived from Periods.
+	Winner     string        `json:"winner,omitempty"`      // Team that won a final game; derived from the score.
+	UpdatedAt  *time.Time    `json:"updated_at,omitempty"`  // When the game was last created or changed, if known.
+}
+
+// DeriveResult sets the game's overtimes from its periods and its winner from its
+// score and status.
+func (g *Game) DeriveResult() {
+	g.Overtimes = 0
+	if len(g.Periods) > RegulationPeriods {
+		g.Overtimes = len(g.Periods) - RegulationPeriods
+	}
+	g.Winner = ""
+	if g.Status == GameStatusFinal {
+		switch {
+		case g.HomeScore > g.AwayScore:
+			g.Winner = g.HomeTeam
+		case g.AwayScore > g.HomeScore:
+			g.Winner = g.AwayTeam
+		}
+	}
+}
+
+// Minutes returns the length of the game in minutes: regulation and the overtimes its
+// periods record so far.
+func (g *Game) Minutes() float64 {
+	minutes := RegulationMinutes
+	if len(g.Periods) > RegulationPeriods {
+		minutes += OvertimeMinutes * (len(g.Periods) - RegulationPeriods)
+	}
+	return float64(minutes)
+}
+
+// CheckScore returns a validation error if the game's score does not match the points
+// of its stat lines, summed by team. A game without stat lines may have any score.
+func (g *Game) CheckScore(points map[string]int) error {
+	if len(points) == 0 {
+		return nil
+	}
+	var fields []FieldError
+	if points[g.HomeTeam] != g.HomeScore {
+		fields = append(fields, FieldError{Field: "home_score", Code: CodeMismatch,
+			Message: fmt.Sprintf("home_score %d does not match the %d points of %s's stat lines", g.HomeScore, points[g.HomeTeam], g.HomeTeam)})
+	}
+	if points[g.AwayTeam] != g.AwayScore {
+		fields = append(fields, FieldError{Field: "away_score", Code: CodeMismatch,
+			Message: fmt.Sprintf("away_score %d does not match the %d points of %s's stat lines", g.AwayScore, points[g.AwayTeam], g.AwayTeam)})
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
 }
 
 // PlayerGameStats holds the statistics for a player in a specific game.
-type 	PlayerGameStats struct {
-	ID            string  `json:"id,omitempty"` // Unique identifier for the stats record (optional).
-	PlayerID      string  `json:"player_id"`    // Identifier of the player.
-	GameID        string  `json:"game_id"`      // Identifier of the game.
-	Points        int     `json:"points"`       // Points scored.
-	Rebounds      int     `json:"rebounds"`     // Rebounds recorded.
-	Assists       int     `json:"assists"`      // Assists made.
-	Steals        int     `json:"steals"`       // Steals recorded.
-	Blocks        int     `json:"blocks"`       // Blocks recorded.
-	Fouls         int     `json:"fouls"`        // Fouls committed (maximum allowed value: 6).
-	Turnovers     int     `json:"turnovers"`    // Turnovers committed.
-	MinutesPlayed float64 `json:"minutes_played"` // Minutes played in the game (range: 0 to 48.0).
+type PlayerGameStats struct {
+	ID            string  `json:"id,omitempty"`      // Unique identifier for the stats record (optional).
+	PlayerID      string  `json:"player_id"`         // Identifier of the player.
+	GameID        string  `json:"game_id"`           // Identifier of the game.
+	TeamID        string  `json:"team_id,omitempty"` // Team the player was on at game time, set by the server.
+	Points        int     `json:"points"`            // Points scored.
+	Rebounds      int     `json:"rebounds"`          // Rebounds recorded.
+	Assists       int     `json:"assists"`           // Assists made.
+	Steals        int     `json:"steals"`            // Steals recorded.
+	Blocks        int     `json:"blocks"`            // Blocks recorded.
+	Fouls         int     `json:"fouls"`             // Fouls committed (maximum allowed value: 6).
+	Turnovers     int     `json:"turnovers"`         // Turnovers committed.
+	MinutesPlayed float64 `json:"minutes_played"`    // Minutes played in the game (range: 0 to 48, plus 5 per overtime).
+	Starter       *bool   `json:"starter,omitempty"` // Whether the player started the game, if known.
+
+	// Shooting and rebounding splits. They are optional as a group: a line that
+	// leaves them all at zero records points and rebounds without a breakdown.
+	FieldGoalsMade         int `json:"field_goals_made"`         // Field goals made, including three-pointers.
+	FieldGoalsAttempted    int `json:"field_goals_attempted"`    // Field goals attempted, including three-pointers.
+	ThreePointersMade      int `json:"three_pointers_made"`      // Three-point field goals made.
+	ThreePointersAttempted int `json:"three_pointers_attempted"` // Three-point field goals attempted.
+	FreeThrowsMade         int `json:"free_throws_made"`         // Free throws made.
+	FreeThrowsAttempted    int `json:"free_throws_attempted"`    // Free throws attempted.
+	OffensiveRebounds      int `json:"offensive_rebounds"`       // Offensive rebounds.
+	DefensiveRebounds      int `json:"defensive_rebounds"`       // Defensive rebounds.
+}
+
+// HasShootingSplits reports whether the line records how its points were scored.
+func (s *PlayerGameStats) HasShootingSplits() bool {
+	return s.FieldGoalsMade != 0 || s.FieldGoalsAttempted != 0 ||
+		s.ThreePointersMade != 0 || s.ThreePointersAttempted != 0 ||
+		s.FreeThrowsMade != 0 || s.FreeThrowsAttempted != 0
+}
+
+// HasReboundSplits reports whether the line breaks rebounds down into offensive and defensive.
+func (s *PlayerGameStats) HasReboundSplits() bool {
+	return s.OffensiveRebounds != 0 || s.DefensiveRebounds != 0
+}
+
+// Stat returns the value of the counting stat or minutes named by its JSON field, such
+// as "points" or "minutes_played". It returns false for any other name.
+func (s *PlayerGameStats) Stat(name string) (float64, bool) {
+	switch name {
+	case "points":
+		return float64(s.Points), true
+	case "rebounds":
+		return float64(s.Rebounds), true
+	case "assists":
+		return float64(s.Assists), true
+	case "steals":
+		return float64(s.Steals), true
+	case "blocks":
+		return float64(s.Blocks), true
+	case "fouls":
+		return float64(s.Fouls), true
+	case "turnovers":
+		return float64(s.Turnovers), true
+	case "minutes_played":
+		return s.MinutesPlayed, true
+	case "field_goals_made":
+		return float64(s.FieldGoalsMade), true
+	case "field_goals_attempted":
+		return float64(s.FieldGoalsAttempted), true
+	case "three_pointers_made":
+		return float64(s.ThreePointersMade), true
+	case "three_pointers_attempted":
+		return float64(s.ThreePointersAttempted), true
+	case "free_throws_made":
+		return float64(s.FreeThrowsMade), true
+	case "free_throws_attempted":
+		return float64(s.FreeThrowsAttempted), true
+	case "offensive_rebounds":
+		return float64(s.OffensiveRebounds), true
+	case "defensive_rebounds":
+		return float64(s.DefensiveRebounds), true
+	}
+	return 0, false
+}
+
+// GameLogSortDate sorts a game log by game date; a log can also be sorted by any stat
+// accepted by PlayerGameStats.Stat.
+const GameLogSortDate = "date"
+
+// Game locations select a team's home or away games.
+const (
+	GameLocationHome = "home"
+	GameLocationAway = "away"
+)
+
+// GameLogEntry is a stat line together with the game it was logged in, seen from the
+// team the player was on.
+type GameLogEntry struct {
+	PlayerGameStats
+	Date       time.Time `json:"date"`                  // Date and time of the game.
+	SeasonID   string    `json:"season_id,omitempty"`   // Season the game belongs to, if known.
+	SeasonType string    `json:"season_type,omitempty"` // SeasonTypeRegular or SeasonTypePlayoffs.
+	OpponentID string    `json:"opponent_id"`           // Team the player's team played against.
+	Home       bool      `json:"home"`                  // Whether the player's team was the home team.
+}
+
+// GameLog is one page of a game log. NextCursor, if set, fetches the page that follows.
+type GameLog struct {
+	Lines      []GameLogEntry `json:"lines"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// GameLogCursor is the position of a line in a game log, after which the next page
+// starts. Lines are ordered by the sort value and then by ID.
+type GameLogCursor struct {
+	Value float64   // The line's value of the sorted stat; unused when sorting by date.
+	Date  time.Time // The date of the line's game; used when sorting by date.
+	ID    string    // The line's ID.
+}
+
+// BoxScore is the set of player lines for a single game, ingested together.
+type BoxScore struct {
+	Lines []PlayerGameStats `json:"lines"`
+}
+
+// GameBoxScore is every line logged for a game, grouped by team, under the game's header.
+type GameBoxScore struct {
+	Game  Game           `json:"game"`  // The game, with its date, teams, status and score.
+	Teams []TeamBoxScore `json:"teams"` // The home team, the away team, then any other team with lines.
+}
+
+// TeamBoxScore is a team's lines in a game and their totals.
+type TeamBoxScore struct {
+	TeamID   string         `json:"team_id"`
+	TeamName string         `json:"team_name,omitempty"`
+	Home     bool           `json:"home"`   // Whether the team was the home team.
+	Lines    []BoxScoreLine `json:"lines"`  // Starters, then the bench, then lines not marked either way.
+	Totals   BoxScoreTotals `json:"totals"` // Sums of the lines.
+}
+
+// BoxScoreLine is a player's line in a box score.
+type BoxScoreLine struct {
+	PlayerGameStats
+	PlayerName string `json:"player_name,omitempty"`
+}
+
+// BoxScoreTotals sums the lines of a team in a game. The percentages are 0 when there
+// were no attempts.
+type BoxScoreTotals struct {
+	Points                 int     `json:"points"`
+	Rebounds               int     `json:"rebounds"`
+	Assists                int     `json:"assists"`
+	Steals                 int     `json:"steals"`
+	Blocks                 int     `json:"blocks"`
+	Fouls                  int     `json:"fouls"`
+	Turnovers              int     `json:"turnovers"`
+	MinutesPlayed          float64 `json:"minutes_played"`
+	FieldGoalsMade         int     `json:"field_goals_made"`
+	FieldGoalsAttempted    int     `json:"field_goals_attempted"`
+	ThreePointersMade      int     `json:"three_pointers_made"`
+	ThreePointersAttempted int     `json:"three_pointers_attempted"`
+	FreeThrowsMade         int     `json:"free_throws_made"`
+	FreeThrowsAttempted    int     `json:"free_throws_attempted"`
+	OffensiveRebounds      int     `json:"offensive_rebounds"`
+	DefensiveRebounds      int     `json:"defensive_rebounds"`
+	FieldGoalPct           float64 `json:"field_goal_pct"`
+	ThreePointPct          float64 `json:"three_point_pct"`
+	FreeThrowPct           float64 `json:"free_throw_pct"`
 }
 
 // AggregateStats represents aggregated season statistics for a player or team.
 type AggregateStats struct {
 	// Either PlayerID or TeamID will be set.
-	PlayerID      string  `json:"player_id,omitempty"`
-	TeamID        string  `json:"team_id,omitempty"`
-	GamesPlayed   int     `json:"games_played"`
-	TotalPoints   int     `json:"total_points"`
-	TotalRebounds int     `json:"total_rebounds"`
-	TotalAssists  int     `json:"total_assists"`
-	TotalSteals   int     `json:"total_steals"`
-	TotalBlocks   int     `json:"total_blocks"`
-	TotalFouls    int     `json:"total_fouls"`
-	TotalTurnovers int    `json:"total_turnovers"`
-	TotalMinutes  float64 `json:"total_minutes"`
-	AvgPoints     float64 `json:"avg_points"`
-	AvgRebounds   float64 `json:"avg_rebounds"`
-	AvgAssists    float64 `json:"avg_assists"`
-	AvgSteals     float64 `json:"avg_steals"`
-	AvgBlocks     float64 `json:"avg_blocks"`
-	AvgFouls      float64 `json:"avg_fouls"`
-	AvgTurnovers  float64 `json:"avg_turnovers"`
-	AvgMinutes    float64 `json:"avg_minutes"`
+	PlayerID string `json:"player_id,omitempty"`
+	TeamID   string `json:"team_id,omitempty"`
+	// SeasonID and SeasonType echo the filter the aggregate was computed with;
+	// both are empty for career totals.
+	SeasonID       string  `json:"season_id,omitempty"`
+	SeasonType     string  `json:"season_type,omitempty"`
+	GamesPlayed    int     `json:"games_played"`
+	TotalPoints    int     `json:"total_points"`
+	TotalRebounds  int     `json:"total_rebounds"`
+	TotalAssists   int     `json:"total_assists"`
+	TotalSteals    int     `json:"total_steals"`
+	TotalBlocks    int     `json:"total_blocks"`
+	TotalFouls     int     `json:"total_fouls"`
+	TotalTurnovers int     `json:"total_turnovers"`
+	TotalMinutes   float64 `json:"total_minutes"`
+	AvgPoints      float64 `json:"avg_points"`
+	AvgRebounds    float64 `json:"avg_rebounds"`
+	AvgAssists     float64 `json:"avg_assists"`
+	AvgSteals      float64 `json:"avg_steals"`
+	AvgBlocks      float64 `json:"avg_blocks"`
+	AvgFouls       float64 `json:"avg_fouls"`
+	AvgTurnovers   float64 `json:"avg_turnovers"`
+	AvgMinutes     float64 `json:"avg_minutes"`
+
+	// Shooting and rebounding split totals and shooting percentages (0 to 1).
+	// Percentages are 0 when there were no attempts.
+	TotalFieldGoalsMade         int     `json:"total_field_goals_made"`
+	TotalFieldGoalsAttempted    int     `json:"total_field_goals_attempted"`
+	TotalThreePointersMade      int     `json:"total_three_pointers_made"`
+	TotalThreePointersAttempted int     `json:"total_three_pointers_attempted"`
+	TotalFreeThrowsMade         int     `json:"total_free_throws_made"`
+	TotalFreeThrowsAttempted    int     `json:"total_free_throws_attempted"`
+	TotalOffensiveRebounds      int     `json:"total_offensive_rebounds"`
+	TotalDefensiveRebounds      int     `json:"total_defensive_rebounds"`
+	FieldGoalPct                float64 `json:"field_goal_pct"`
+	ThreePointPct               float64 `json:"three_point_pct"`
+	FreeThrowPct                float64 `json:"free_throw_pct"`
+}
+
+// Standings groups select how standings are ranked: across the league, within each
+// conference or within each division.
+const (
+	StandingsGroupLeague     = "league"
+	StandingsGroupConference = "conference"
+	StandingsGroupDivision   = "division"
+)
+
+// StandingsFilter selects the standings to compute.
+type StandingsFilter struct {
+	SeasonID string // Season whose regular-season games count; empty for the current season.
+	Group    string // One of the StandingsGroup values; defaults to conference.
+}
+
+// Standings are a season's standings, ranked within each group.
+type Standings struct {
+	SeasonID string           `json:"season_id"`
+	Group    string           `json:"group"`
+	Groups   []StandingsGroup `json:"groups"`
+}
+
+// StandingsGroup is a conference, a division or the whole league, with its teams in
+// rank order.
+type StandingsGroup struct {
+	Name  string     `json:"name"` // The conference or division, "league", or "" for teams in none.
+	Teams []Standing `json:"teams"`
+}
+
+// Record is a number of wins and losses.
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+// Pct returns the fraction of games won, or 0 if no games were played.
+func (r Record) Pct() float64 {
+	if r.Wins+r.Losses == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Wins+r.Losses)
+}
+
+// Standing is a team's record over the final regular-season games of a season.
+type Standing struct {
+	Rank       int    `json:"rank"` // Position within the group, after tie-breakers.
+	TeamID     string `json:"team_id"`
+	TeamName   string `json:"team_name,omitempty"`
+	Conference string `json:"conference,omitempty"`
+	Division   string `json:"division,omitempty"`
+	Wins       int    `json:"wins"`
+	Losses     int    `json:"losses"`
+	// WinPct is the fraction of games won, rounded to 3 decimals.
+	WinPct float64 `json:"win_pct"`
+	// GamesBehind is how many games the team trails the group's first team by.
+	GamesBehind float64 `json:"games_behind"`
+	// Records at home, away, over the last 10 games, and against teams of the same
+	// conference and division.
+	Home             Record `json:"home"`
+	Away             Record `json:"away"`
+	LastTen          Record `json:"last_ten"`
+	ConferenceRecord Record `json:"conference_record"`
+	DivisionRecord   Record `json:"division_record"`
+	// Streak is the current run of wins or losses, e.g. "W3" or "L1"; empty before the
+	// first game.
+	Streak          string `json:"streak"`
+	PointsFor       int    `json:"points_for"`
+	PointsAgainst   int    `json:"points_against"`
+	PointDifference int    `json:"point_differential"`
+}
+
+// TotalsDrift is a row of the season totals kept per player or team that disagrees with
+// the totals computed from the stat lines. Either side has zero totals if it has no row.
+type TotalsDrift struct {
+	Stored   AggregateStats `json:"stored"`
+	Computed AggregateStats `json:"computed"`
+}
+
+// AdvancedStats represents efficiency and rate metrics derived from a player's
+// aggregate and the aggregate of the player's team over the same games.
+type AdvancedStats struct {
+	PlayerID    string  `json:"player_id"`
+	TeamID      string  `json:"team_id,omitempty"`
+	SeasonID    string  `json:"season_id,omitempty"`
+	SeasonType  string  `json:"season_type,omitempty"`
+	GamesPlayed int     `json:"games_played"`
+	Minutes     float64 `json:"minutes"`
+	// Possessions is the estimated number of team possessions the player was on the floor for.
+	Possessions float64 `json:"possessions"`
+
+	TrueShootingPct     float64 `json:"true_shooting_pct"`
+	EffectiveFGPct      float64 `json:"effective_fg_pct"`
+	AssistTurnoverRatio float64 `json:"assist_turnover_ratio"`
+	// UsageRate is the percentage (0 to 100) of team possessions the player
+	// used while on the floor.
+	UsageRate float64 `json:"usage_rate"`
+	// PER is a PER-style rating: Hollinger's game score scaled to 36 minutes.
+	// Unlike true PER it is not adjusted for pace or league averages.
+	PER float64 `json:"per"`
+
+	Per36  RateStats `json:"per_36"`
+	Per100 RateStats `json:"per_100_possessions"`
+}
+
+// Leader is a leaderboard entry: an aggregate together with its rank and the
+// value of the stat it was ranked by. Tied values share a rank.
+type Leader struct {
+	Rank  int     `json:"rank"`
+	Value float64 `json:"value"`
+	AggregateStats
+}
+
+// RateStats holds counting stats normalized to a fixed amount of minutes or possessions.
+type RateStats struct {
+	Points    float64 `json:"points"`
+	Rebounds  float64 `json:"rebounds"`
+	Assists   float64 `json:"assists"`
+	Steals    float64 `json:"steals"`
+	Blocks    float64 `json:"blocks"`
+	Turnovers float64 `json:"turnovers"`
+}
+
+// PlayerFilter narrows the set of players returned by a list query.
+type PlayerFilter struct {
+	IDs    []string // Only players with one of these IDs.
+	TeamID string   // Only players assigned to this team.
+	Name   string   // Case-insensitive substring match on the player's name.
+	Limit  int      // Maximum number of players to return.
+	Offset int      // Number of players to skip.
+}
+
+// TeamFilter narrows the set of teams returned by a list query.
+type TeamFilter struct {
+	Name   string // Case-insensitive substring match on the team's name.
+	Limit  int    // Maximum number of teams to return.
+	Offset int    // Number of teams to skip.
+}
+
+// StatsFilter restricts which games contribute to an aggregate.
+// Zero values mean "no restriction".
+type StatsFilter struct {
+	SeasonID   string // Only games belonging to this season.
+	SeasonType string // Only regular-season or only playoff games.
+}
+
+// LeaderFilter selects and orders the entries of a leaderboard.
+type LeaderFilter struct {
+	StatsFilter
+	Stat       string  // AggregateStats JSON field to rank by, e.g. "avg_assists".
+	Scope      string  // LeaderScopePlayer or LeaderScopeTeam.
+	MinGames   int     // Only entries with at least this many games played.
+	MinMinutes float64 // Only entries with at least this many total minutes.
+	Ascending  bool    // Rank the lowest values first instead of the highest.
+	Limit      int
+	Offset     int
+}
+
+// GameLogFilter selects and orders the lines of a player's or team's game log.
+type GameLogFilter struct {
+	StatsFilter
+	PlayerID   string    // Only lines of this player.
+	TeamID     string    // Only lines logged for this team.
+	GameID     string    // Only lines of this game.
+	OpponentID string    // Only games against this team.
+	Location   string    // GameLocationHome or GameLocationAway; empty for both.
+	From       time.Time // Only games on or after this time (ignored when zero).
+	To         time.Time // Only games on or before this time (ignored when zero).
+	Sort       string    // GameLogSortDate (the default) or a stat accepted by PlayerGameStats.Stat.
+	Ascending  bool      // Sort the lowest values, or the oldest games, first.
+	Cursor     string    // NextCursor of the previous page; empty for the first page.
+	Limit      int       // Maximum number of lines to return.
+}
+
+// GameFilter narrows the set of games returned by a list query.
+type GameFilter struct {
+	TeamID     string    // Only games where this team played home or away.
+	SeasonID   string    // Only games belonging to this season.
+	SeasonType string    // Only regular-season or only playoff games.
+	Status     string    // Only games with this status.
+	From       time.Time // Only games on or after this time (ignored when zero).
+	To         time.Time // Only games on or before this time (ignored when zero).
+	Limit      int       // Maximum number of games to return.
+	Offset     int       // Number of games to skip.
+}
+
+// PlayerPatch holds a partial update for a player; nil fields are left unchanged.
+type PlayerPatch struct {
+	Name   *string `json:"name,omitempty"`
+	TeamID *string `json:"team_id,omitempty"`
+}
+
+// TeamPatch holds a partial update for a team; nil fields are left unchanged.
+type TeamPatch struct {
+	Name       *string `json:"name,omitempty"`
+	Conference *string `json:"conference,omitempty"`
+	Division   *string `json:"division,omitempty"`
+}
+
+// GamePatch holds a partial update for a game; nil fields are left unchanged.
+type GamePatch struct {
+	Date       *time.Time     `json:"date,omitempty"`
+	HomeTeam   *string        `json:"home_team,omitempty"`
+	AwayTeam   *string        `json:"away_team,omitempty"`
+	SeasonID   *string        `json:"season_id,omitempty"`
+	SeasonType *string        `json:"season_type,omitempty"`
+	Status     *string        `json:"status,omitempty"`
+	HomeScore  *int           `json:"home_score,omitempty"`
+	AwayScore  *int           `json:"away_score,omitempty"`
+	Periods    *[]PeriodScore `json:"periods,omitempty"`
+}
+
+// Authentication methods a Principal may have used.
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// Scopes grant access to groups of endpoints. ScopeCatalogAdmin implies every other
+// scope, and ScopeStatsWrite implies ScopeStatsRead.
+const (
+	ScopeStatsRead    = "stats:read"    // Read stats, leaderboards, players, teams, games and seasons.
+	ScopeStatsWrite   = "stats:write"   // Log stats for the games the caller is assigned to as scorekeeper.
+	ScopeCatalogAdmin = "catalog:admin" // Manage players, teams, games, seasons, scorekeepers and API keys; log stats for any game.
+)
+
+// Scopes lists every scope.
+var Scopes = []string{ScopeStatsRead, ScopeStatsWrite, ScopeCatalogAdmin}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string   `json:"subject"` // JWT subject, or "api-key:{id}" for an API key.
+	Name    string   `json:"name"`    // Display name; the subject for JWTs, the key's name for API keys.
+	Method  string   `json:"method"`  // AuthMethodJWT or AuthMethodAPIKey.
+	Scopes  []string `json:"scopes"`  // Scopes granted to the caller.
+}
+
+// HasScope reports whether the principal was granted the scope, directly or through
+// a scope that implies it.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		switch {
+		case granted == scope, granted == ScopeCatalogAdmin:
+			return true
+		case granted == ScopeStatsWrite && scope == ScopeStatsRead:
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a credential for machine clients. Only a hash of the key itself is
+// stored; the key is shown once, when it is created.
+type APIKey struct {
+	ID        string     `json:"id"`                   // Unique identifier for the key.
+	Name      string     `json:"name"`                 // Describes who or what uses the key.
+	Prefix    string     `json:"prefix"`               // First characters of the key, to recognize it.
+	Hash      string     `json:"-"`                    // Hex SHA-256 digest of the key.
+	Scopes    []string   `json:"scopes"`               // Scopes granted to requests using the key.
+	CreatedAt time.Time  `json:"created_at"`           // When the key was created.
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // When the key was revoked, if it was.
 }
@@ -0,0 +1,366 @@
+// internal/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+)
+
+// fileNamePattern matches migration files such as "0002_add_seasons.up.sql", and
+// dialect-specific variants such as "0002_add_seasons.up.sqlite.sql".
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)(?:\.(\w+))?\.sql$`)
+
+// Migration is a numbered schema change with the scripts that apply and revert it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the contents of the up script, so that edits to a migration
+// that has already been applied can be detected.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Status describes a known migration and whether it has been applied.
+type Status struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+	// Modified reports that the migration file changed after it was applied.
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Load reads the migrations in dir for the given SQL dialect. A script named for the
+// dialect, such as "0001_init.up.sqlite.sql", replaces the portable "0001_init.up.sql",
+// and scripts named for other dialects are ignored. Every version needs both an up and
+// a down script, and versions must be unique.
+func Load(dir string, dialect string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	dialectSpecific := map[string]bool{}
+	for _, entry := range entries {
+		m := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil || (m[4] != "" && m[4] != dialect) {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		script, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		key := m[1] + "." + m[3]
+		if dialectSpecific[key] {
+			continue
+		}
+		dialectSpecific[key] = m[4] != ""
+		if m[3] == "up" {
+			mig.Up = string(script)
+		} else {
+			mig.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Locker serializes migration runs across processes. Lock and Unlock are called on
+// the same connection, which is held for the whole run.
+type Locker interface {
+	Lock(ctx context.Context, conn *sql.Conn) error
+	Unlock(ctx context.Context, conn *sql.Conn) error
+}
+
+// AdvisoryLock is a Locker backed by a PostgreSQL session-level advisory lock.
+type AdvisoryLock struct {
+	Key int64
+}
+
+// DefaultAdvisoryLockKey identifies this service's migration lock.
+const DefaultAdvisoryLockKey int64 = 0x6e62615f6d6967 // "nba_mig"
+
+// Lock blocks until the advisory lock is acquired.
+func (l AdvisoryLock) Lock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, l.Key)
+	return err
+}
+
+// Unlock releases the advisory lock.
+func (l AdvisoryLock) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.Key)
+	return err
+}
+
+// NoLock is a Locker for databases without advisory locks, such as SQLite. Each migration
+// still runs in a transaction that holds SQLite's database-wide write lock, and a second
+// process that races to apply the same version fails on the schema_migrations key.
+type NoLock struct{}
+
+// Lock does nothing.
+func (NoLock) Lock(context.Context, *sql.Conn) error { return nil }
+
+// Unlock does nothing.
+func (NoLock) Unlock(context.Context, *sql.Conn) error { return nil }
+
+// Migrator applies and reverts migrations, recording applied versions in schema_migrations.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	locker     Locker
+}
+
+// New returns a Migrator for the given migrations, which must be sorted by version.
+func New(db *sql.DB, migrations []Migration, locker Locker) *Migrator {
+	return &Migrator{db: db, migrations: migrations, locker: locker}
+}
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// Status lists every known migration, and any applied version whose file is missing.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.locked(func(conn *sql.Conn) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := Status{Version: mig.Version, Name: mig.Name}
+			if a, ok := applied[mig.Version]; ok {
+				s.Applied = true
+				s.AppliedAt = a.appliedAt
+				s.Modified = a.checksum != mig.Checksum()
+				delete(applied, mig.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for version, a := range applied {
+			statuses = append(statuses, Status{Version: version, Name: "(missing)", Applied: true, AppliedAt: a.appliedAt})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+		return nil
+	})
+	return statuses, err
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up() (int, error) {
+	if len(m.migrations) == 0 {
+		return 0, nil
+	}
+	return m.To(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the n most recently applied migrations and returns how many were reverted.
+func (m *Migrator) Down(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("number of migrations to revert must be positive, got %d", n)
+	}
+	count := 0
+	err := m.locked(func(conn *sql.Conn) error {
+		applied, err := m.verify(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < n; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.revert(conn, m.migrations[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// To migrates up or down so that exactly the migrations up to and including version
+// are applied, and returns how many migrations were applied or reverted.
+// Version 0 reverts every migration.
+func (m *Migrator) To(version int) (int, error) {
+	if version != 0 && m.find(version) < 0 {
+		return 0, fmt.Errorf("unknown migration version %d", version)
+	}
+	count := 0
+	err := m.locked(func(conn *sql.Conn) error {
+		applied, err := m.verify(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.revert(conn, mig); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(conn, mig); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// locked runs fn on a dedicated connection while holding the migration lock,
+// after making sure the schema_migrations table exists.
+func (m *Migrator) locked(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.locker.Lock(ctx, conn); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer m.locker.Unlock(ctx, conn)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// applied returns the rows of schema_migrations keyed by version.
+func (m *Migrator) applied(conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(context.Background(), `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// verify returns the applied migrations, refusing to continue if an applied
+// migration has been edited or its files have been removed.
+func (m *Migrator) verify(conn *sql.Conn) (map[int]appliedMigration, error) {
+	applied, err := m.applied(conn)
+	if err != nil {
+		return nil, err
+	}
+	for version, a := range applied {
+		i := m.find(version)
+		if i < 0 {
+			return nil, fmt.Errorf("migration %d has been applied but its files are missing", version)
+		}
+		if a.checksum != m.migrations[i].Checksum() {
+			return nil, fmt.Errorf("migration %d_%s has been modified since it was applied", version, m.migrations[i].Name)
+		}
+	}
+	return applied, nil
+}
+
+// apply runs a migration's up script and records it, in a single transaction.
+func (m *Migrator) apply(conn *sql.Conn, mig Migration) error {
+	logger.Info("Applying migration", "version", mig.Version, "name", mig.Name)
+	return inTx(conn, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(mig.Up); err != nil {
+			return fmt.Errorf("applying migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err := tx.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+			mig.Version, mig.Name, mig.Checksum(), time.Now().UTC())
+		return err
+	})
+}
+
+// revert runs a migration's down script and forgets it, in a single transaction.
+func (m *Migrator) revert(conn *sql.Conn, mig Migration) error {
+	logger.Info("Reverting migration", "version", mig.Version, "name", mig.Name)
+	return inTx(conn, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(mig.Down); err != nil {
+			return fmt.Errorf("reverting migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		return err
+	})
+}
+
+// find returns the index of the migration with the given version, or -1.
+func (m *Migrator) find(version int) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// inTx runs fn inside a transaction on conn, committing on success and
+// rolling back if fn returns an error.
+func inTx(conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
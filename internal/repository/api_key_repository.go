@@ -0,0 +1,109 @@
+// internal/repository/api_key_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// APIKeyRepository defines operations on APIKey data.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) error
+}
+
+type apiKeyRepo struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository returns a new instance of APIKeyRepository.
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+// CreateAPIKey inserts a new API key record into the database.
+// It returns domain.ErrConflict if a key with the same ID or hash already exists.
+func (r *apiKeyRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	ctx, span := tracing.Start(ctx, "APIKeyRepository.CreateAPIKey")
+	defer span.End()
+
+	query := `INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, " "), key.CreatedAt)
+	return writeError(err, "API key", key.ID)
+}
+
+// GetAPIKeyByHash retrieves the API key with the given hash, revoked or not.
+// It returns domain.ErrNotFound if no key has the given hash.
+func (r *apiKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyRepository.GetAPIKeyByHash")
+	defer span.End()
+
+	query := `SELECT id, name, prefix, key_hash, scopes, created_at, revoked_at FROM api_keys WHERE key_hash = $1`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		return nil, notFound(err, "API key", "with this hash")
+	}
+	return key, nil
+}
+
+// ListAPIKeys retrieves every API key, including revoked ones, newest first.
+func (r *apiKeyRepo) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyRepository.ListAPIKeys")
+	defer span.End()
+
+	query := `SELECT id, name, prefix, key_hash, scopes, created_at, revoked_at FROM api_keys ORDER BY created_at DESC, id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks an API key as revoked at the given time. Revoking a key that
+// is already revoked keeps its original revocation time.
+// It returns domain.ErrNotFound if no key with the given ID exists.
+func (r *apiKeyRepo) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	ctx, span := tracing.Start(ctx, "APIKeyRepository.RevokeAPIKey")
+	defer span.End()
+
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`
+	res, err := r.db.ExecContext(ctx, query, at, id)
+	if err == nil {
+		err = expectAffected(res)
+	}
+	return notFound(err, "API key", id)
+}
+
+// scanAPIKey reads an API key from a row selected as
+// (id, name, prefix, key_hash, scopes, created_at, revoked_at). Scopes are stored
+// space-separated.
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
@@ -8,26 +8,33 @@ import (
 	_ "github.com/lib/pq" // PostgreSQL driver
 
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
 )
 
+// querySampler thins out the logs of queries, which are run for nearly every request:
+// per second, the first 100 of each query are logged, then every 100th.
+var querySampler = logger.NewSampler(100, 100, time.Second)
+
 // NewDB establishes a database connection using the provided connection string
-// and applies connection pool settings.
+// and applies connection pool settings. See ParseConnStr for the SQLite forms.
 func NewDB(connStr string, maxOpenConns, maxIdleConns int, connMaxLifetime time.Duration) (*sql.DB, error) {
-	var driverName string
-	if connStr == ":memory:" {
-		driverName = "sqlite3"
+	dialect, dsn := ParseConnStr(connStr)
+
+	var db *sql.DB
+	var err error
+	if dialect == DialectSQLite {
+		db, err = openSQLite(dsn)
 	} else {
-		driverName = "postgres"
+		db, err = sql.Open("postgres", dsn)
 	}
-
-	db, err := sql.Open(driverName, connStr)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ping the database to verify connectivity.
-	if err := db.Ping(); err != nil {
-		return nil, err
+	// An in-memory SQLite database exists only inside its connection, so the pool must
+	// hold exactly one connection and never recycle it.
+	if dialect == DialectSQLite && IsInMemory(dsn) {
+		maxOpenConns, maxIdleConns, connMaxLifetime = 1, 1, 0
 	}
 
 	// Set connection pool parameters.
@@ -35,7 +42,33 @@ func NewDB(connStr string, maxOpenConns, maxIdleConns int, connMaxLifetime time.
 	db.SetMaxIdleConns(maxIdleConns)
 	db.SetConnMaxLifetime(connMaxLifetime)
 
-	logger.Info("Database connection pool established (MaxOpenConns: %d, MaxIdleConns: %d, ConnMaxLifetime: %s)",
-		maxOpenConns, maxIdleConns, connMaxLifetime)
+	// Ping the database to verify connectivity.
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logger.Info("Database connection pool established", "dialect", dialect, "max_open_conns", maxOpenConns,
+		"max_idle_conns", maxIdleConns, "conn_max_lifetime", connMaxLifetime)
 	return db, nil
-}
\ No newline at end of file
+}
+
+// RegisterDBMetrics exposes the connection pool statistics of db (sql.DB.Stats) as
+// metrics in the given registry.
+func RegisterDBMetrics(registry *metrics.Registry, db *sql.DB) {
+	stat := func(value func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return value(db.Stats()) }
+	}
+	registry.NewGaugeFunc("nba_stats_db_max_open_connections", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	registry.NewGaugeFunc("nba_stats_db_open_connections", "Established connections to the database, in use or idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	registry.NewGaugeFunc("nba_stats_db_in_use_connections", "Connections to the database currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	registry.NewGaugeFunc("nba_stats_db_idle_connections", "Idle connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	registry.NewCounterFunc("nba_stats_db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	registry.NewCounterFunc("nba_stats_db_wait_duration_seconds_total", "Time spent waiting for connections from the pool.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+}
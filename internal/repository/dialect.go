@@ -0,0 +1,66 @@
+// internal/repository/dialect.go
+package repository
+
+import "strings"
+
+// Dialect identifies the SQL database behind a connection string.
+type Dialect string
+
+const (
+	// DialectPostgres is PostgreSQL, used in production.
+	DialectPostgres Dialect = "postgres"
+	// DialectSQLite is SQLite, used for local development and tests.
+	DialectSQLite Dialect = "sqlite"
+)
+
+// sqliteMemoryDSN is the DSN of a private in-memory SQLite database.
+const sqliteMemoryDSN = ":memory:"
+
+// ParseConnStr returns the dialect of connStr and the DSN to hand to its driver.
+// SQLite is selected by ":memory:", "sqlite::memory:", "sqlite://path/to/file.db"
+// or a "file:" URI; anything else is treated as a PostgreSQL connection string.
+func ParseConnStr(connStr string) (Dialect, string) {
+	switch {
+	case connStr == sqliteMemoryDSN || connStr == "sqlite::memory:":
+		return DialectSQLite, sqliteMemoryDSN
+	case strings.HasPrefix(connStr, "sqlite://"):
+		return DialectSQLite, strings.TrimPrefix(connStr, "sqlite://")
+	case strings.HasPrefix(connStr, "file:"):
+		return DialectSQLite, connStr
+	}
+	return DialectPostgres, connStr
+}
+
+// IsInMemory reports whether dsn names a private in-memory SQLite database, which
+// lives only as long as its single connection.
+func IsInMemory(dsn string) bool {
+	return dsn == sqliteMemoryDSN
+}
+
+// Rebind rewrites the $N placeholders used throughout this package into the form
+// the dialect binds by number. SQLite numbers "$N" parameters in order of first
+// appearance rather than by N, so they become "?N"; PostgreSQL queries are unchanged.
+// Placeholders inside quoted literals and identifiers are left alone.
+func Rebind(dialect Dialect, query string) string {
+	if dialect != DialectSQLite || !strings.Contains(query, "$") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query))
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			c = '?'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+// internal/repository/errors.go
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+)
+
+// constraint identifies the kind of integrity constraint a statement violated.
+type constraint int
+
+const (
+	noConstraint constraint = iota
+	uniqueConstraint
+	foreignKeyConstraint
+)
+
+// PostgreSQL error codes for constraint violations.
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// violatedConstraint reports which integrity constraint, if any, err is a violation of.
+func violatedConstraint(err error) constraint {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return uniqueConstraint
+		case pqForeignKeyViolation:
+			return foreignKeyConstraint
+		}
+		return noConstraint
+	}
+	return sqliteConstraint(err)
+}
+
+// notFound translates sql.ErrNoRows, returned when no row has the given ID, into a
+// domain.ErrNotFound naming the entity. Other errors are returned unchanged.
+func notFound(err error, entity, id string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.NotFoundError(entity, id)
+	}
+	return err
+}
+
+// writeError translates the error of a statement writing the entity with the given ID:
+// a duplicate ID becomes domain.ErrConflict, a reference to a missing row becomes
+// domain.ErrValidation, and sql.ErrNoRows becomes domain.ErrNotFound.
+func writeError(err error, entity, id string) error {
+	switch violatedConstraint(err) {
+	case uniqueConstraint:
+		return domain.Errorf(domain.ErrConflict, "%s %s already exists", entity, id)
+	case foreignKeyConstraint:
+		return domain.Errorf(domain.ErrValidation, "%s %s refers to a record that does not exist", entity, id)
+	}
+	return notFound(err, entity, id)
+}
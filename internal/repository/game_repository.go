@@ -2,14 +2,26 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // GameRepository defines operations on Game data.
 type GameRepository interface {
-	CreateGame(game *domain.Game) error
-	GetGameByID(id string) (*domain.Game, error)
+	CreateGame(ctx context.Context, game *domain.Game) error
+	GetGameByID(ctx context.Context, id string) (*domain.Game, error)
+	ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error)
+	UpdateGame(ctx context.Context, game *domain.Game) error
+	DeleteGame(ctx context.Context, id string) error
+	AssignScorekeeper(ctx context.Context, gameID, subject string) error
+	UnassignScorekeeper(ctx context.Context, gameID, subject string) error
+	ListScorekeepers(ctx context.Context, gameID string) ([]string, error)
+	IsScorekeeper(ctx context.Context, gameID, subject string) (bool, error)
+	TeamPoints(ctx context.Context, gameID string) (map[string]int, error)
+	GameParticipants(ctx context.Context, gameID string) (playerIDs, teamIDs []string, err error)
 }
 
 type gameRepo struct {
@@ -21,20 +33,336 @@ func NewGameRepository(db *sql.DB) GameRepository {
 	return &gameRepo{db: db}
 }
 
-// CreateGame inserts a new game record into the database.
-func (r *gameRepo) CreateGame(game *domain.Game) error {
-	query := `INSERT INTO games (id, date, home_team, away_team) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, game.ID, game.Date, game.HomeTeam, game.AwayTeam)
-	return err
+// CreateGame inserts a new game record, and its periods, into the database.
+// It returns domain.ErrConflict if a game with the same ID already exists.
+func (r *gameRepo) CreateGame(ctx context.Context, game *domain.Game) error {
+	ctx, span := tracing.Start(ctx, "GameRepository.CreateGame")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `INSERT INTO games (id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		_, err := tx.ExecContext(ctx, query, game.ID, game.Date, game.HomeTeam, game.AwayTeam, nullString(game.SeasonID), game.SeasonType, game.Status, game.HomeScore, game.AwayScore, game.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return insertPeriods(ctx, tx, game)
+	})
+	return writeError(err, "game", game.ID)
 }
 
 // GetGameByID retrieves a game by its ID.
-func (r *gameRepo) GetGameByID(id string) (*domain.Game, error) {
-	query := `SELECT id, date, home_team, away_team FROM games WHERE id = $1`
-	row := r.db.QueryRow(query, id)
+// It returns domain.ErrNotFound if no game with the given ID exists.
+func (r *gameRepo) GetGameByID(ctx context.Context, id string) (*domain.Game, error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.GetGameByID")
+	defer span.End()
+
+	query := `SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games WHERE id = $1`
+	game, err := scanGame(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err, "game", id)
+	}
+	games := []domain.Game{*game}
+	if err := r.loadPeriods(ctx, games); err != nil {
+		return nil, err
+	}
+	return &games[0], nil
+}
+
+// ListGames retrieves the games matching the filter, most recent first.
+func (r *gameRepo) ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.ListGames")
+	defer span.End()
+
+	var b filterBuilder
+	if filter.TeamID != "" {
+		b.add("(home_team = $%[1]d OR away_team = $%[1]d)", filter.TeamID)
+	}
+	if filter.SeasonID != "" {
+		b.add("season_id = $%[1]d", filter.SeasonID)
+	}
+	if filter.SeasonType != "" {
+		b.add("season_type = $%[1]d", filter.SeasonType)
+	}
+	if filter.Status != "" {
+		b.add("status = $%[1]d", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		b.add("date >= $%[1]d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		b.add("date <= $%[1]d", filter.To)
+	}
+	query := b.paginate(`SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games`+b.where()+` ORDER BY date DESC, id`, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []domain.Game{}
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.loadPeriods(ctx, games); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// UpdateGame overwrites an existing game's date, teams, season, status, score and
+// periods. A game moved to another season, or season type, moves the stats recorded
+// for it, so the season totals of its players and their teams are recomputed in the
+// same transaction. The game is locked, as by lockGames, while the change of its
+// status is checked with domain.CheckStatusChange and the score of a final game with
+// Game.CheckScore, so that no stat line can be added in between.
+// It returns domain.ErrNotFound if no game with the given ID exists.
+func (r *gameRepo) UpdateGame(ctx context.Context, game *domain.Game) error {
+	ctx, span := tracing.Start(ctx, "GameRepository.UpdateGame")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockGames(ctx, tx, game.ID); err != nil {
+			return err
+		}
+		old, err := scanGame(tx.QueryRowContext(ctx, `SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games WHERE id = $1`, game.ID))
+		if err != nil {
+			return err
+		}
+		if err := domain.CheckStatusChange(game.ID, old.Status, game.Status); err != nil {
+			return err
+		}
+		if game.Status == domain.GameStatusFinal {
+			points, err := teamPoints(ctx, tx, game.ID)
+			if err != nil {
+				return err
+			}
+			if err := game.CheckScore(points); err != nil {
+				return err
+			}
+		}
+		query := `UPDATE games SET date = $1, home_team = $2, away_team = $3, season_id = $4, season_type = $5, status = $6, home_score = $7, away_score = $8, updated_at = $9 WHERE id = $10`
+		if _, err := tx.ExecContext(ctx, query, game.Date, game.HomeTeam, game.AwayTeam, nullString(game.SeasonID), game.SeasonType, game.Status, game.HomeScore, game.AwayScore, game.UpdatedAt, game.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM game_periods WHERE game_id = $1`, game.ID); err != nil {
+			return err
+		}
+		if err := insertPeriods(ctx, tx, game); err != nil {
+			return err
+		}
+		if old.SeasonID == game.SeasonID && old.SeasonType == game.SeasonType {
+			return nil
+		}
+		players, teams, err := gameParticipants(ctx, tx, game.ID)
+		if err != nil {
+			return err
+		}
+		return refreshSeasonTotals(ctx, tx, players, teams)
+	})
+	return writeError(err, "game", game.ID)
+}
+
+// DeleteGame removes a game together with all player statistics recorded for it in a single transaction,
+// and recomputes the season totals of its players and their teams.
+// It returns domain.ErrNotFound if no game with the given ID exists.
+func (r *gameRepo) DeleteGame(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "GameRepository.DeleteGame")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		players, teams, err := gameParticipants(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM player_game_stats WHERE game_id = $1`, id); err != nil {
+			return err
+		}
+		if err := refreshSeasonTotals(ctx, tx, players, teams); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM game_scorekeepers WHERE game_id = $1`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM game_periods WHERE game_id = $1`, id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM games WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		return expectAffected(res)
+	})
+	return notFound(err, "game", id)
+}
+
+// AssignScorekeeper allows the principal with the given subject to log stats for a game.
+// Assigning a scorekeeper twice is not an error.
+// It returns domain.ErrNotFound if no game with the given ID exists.
+func (r *gameRepo) AssignScorekeeper(ctx context.Context, gameID, subject string) error {
+	ctx, span := tracing.Start(ctx, "GameRepository.AssignScorekeeper")
+	defer span.End()
+
+	if _, err := r.GetGameByID(ctx, gameID); err != nil {
+		return err
+	}
+	query := `INSERT INTO game_scorekeepers (game_id, subject) VALUES ($1, $2) ON CONFLICT (game_id, subject) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, gameID, subject)
+	return writeError(err, "scorekeeper", subject)
+}
+
+// UnassignScorekeeper removes a scorekeeper from a game.
+// It returns domain.ErrNotFound if the subject is not assigned to the game.
+func (r *gameRepo) UnassignScorekeeper(ctx context.Context, gameID, subject string) error {
+	ctx, span := tracing.Start(ctx, "GameRepository.UnassignScorekeeper")
+	defer span.End()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM game_scorekeepers WHERE game_id = $1 AND subject = $2`, gameID, subject)
+	if err == nil {
+		err = expectAffected(res)
+	}
+	return notFound(err, "scorekeeper", subject+" of game "+gameID)
+}
+
+// ListScorekeepers retrieves the subjects assigned to a game, in order.
+func (r *gameRepo) ListScorekeepers(ctx context.Context, gameID string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.ListScorekeepers")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT subject FROM game_scorekeepers WHERE game_id = $1 ORDER BY subject`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []string{}
+	for rows.Next() {
+		var subject string
+		if err := rows.Scan(&subject); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, rows.Err()
+}
+
+// IsScorekeeper reports whether the subject is assigned to the game.
+func (r *gameRepo) IsScorekeeper(ctx context.Context, gameID, subject string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.IsScorekeeper")
+	defer span.End()
+
+	var assigned bool
+	query := `SELECT EXISTS (SELECT 1 FROM game_scorekeepers WHERE game_id = $1 AND subject = $2)`
+	err := r.db.QueryRowContext(ctx, query, gameID, subject).Scan(&assigned)
+	return assigned, err
+}
+
+// TeamPoints sums the points of the stat lines logged for a game by the team each line
+// was logged for. Teams without lines are left out.
+func (r *gameRepo) TeamPoints(ctx context.Context, gameID string) (map[string]int, error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.TeamPoints")
+	defer span.End()
+
+	return teamPoints(ctx, r.db, gameID)
+}
+
+// teamPoints sums the points of a game's stat lines by team.
+func teamPoints(ctx context.Context, q queryer, gameID string) (map[string]int, error) {
+	rows, err := q.QueryContext(ctx, `SELECT team_id, SUM(points)
+		FROM player_game_stats WHERE game_id = $1 GROUP BY team_id`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make(map[string]int)
+	for rows.Next() {
+		var team string
+		var sum int
+		if err := rows.Scan(&team, &sum); err != nil {
+			return nil, err
+		}
+		points[team] = sum
+	}
+	return points, rows.Err()
+}
+
+// GameParticipants returns the players with stat lines in a game and the teams the
+// lines are attributed to.
+func (r *gameRepo) GameParticipants(ctx context.Context, gameID string) (playerIDs, teamIDs []string, err error) {
+	ctx, span := tracing.Start(ctx, "GameRepository.GameParticipants")
+	defer span.End()
+
+	return gameParticipants(ctx, r.db, gameID)
+}
+
+// loadPeriods reads the periods of the games, in order, and derives their results.
+func (r *gameRepo) loadPeriods(ctx context.Context, games []domain.Game) error {
+	byID := make(map[string]*domain.Game, len(games))
+	ids := make([]string, len(games))
+	for i := range games {
+		byID[games[i].ID] = &games[i]
+		ids[i] = games[i].ID
+	}
+	if len(games) > 0 {
+		var b filterBuilder
+		b.in("game_id", ids)
+		rows, err := r.db.QueryContext(ctx, `SELECT game_id, period, home_points, away_points FROM game_periods`+b.where()+` ORDER BY game_id, period`, b.args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var gameID string
+			var period domain.PeriodScore
+			if err := rows.Scan(&gameID, &period.Period, &period.Home, &period.Away); err != nil {
+				return err
+			}
+			game := byID[gameID]
+			game.Periods = append(game.Periods, period)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	for i := range games {
+		games[i].DeriveResult()
+	}
+	return nil
+}
+
+// insertPeriods stores the periods of a game.
+func insertPeriods(ctx context.Context, tx *sql.Tx, game *domain.Game) error {
+	for _, period := range game.Periods {
+		query := `INSERT INTO game_periods (game_id, period, home_points, away_points) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.ExecContext(ctx, query, game.ID, period.Period, period.Home, period.Away); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanGame reads a game from a row selected as (id, date, home_team, away_team, season_id,
+// season_type, status, home_score, away_score, updated_at).
+func scanGame(row rowScanner) (*domain.Game, error) {
 	var game domain.Game
-	if err := row.Scan(&game.ID, &game.Date, &game.HomeTeam, &game.AwayTeam); err != nil {
+	var seasonID sql.NullString
+	var updatedAt sql.NullTime
+	if err := row.Scan(&game.ID, &game.Date, &game.HomeTeam, &game.AwayTeam, &seasonID, &game.SeasonType, &game.Status, &game.HomeScore, &game.AwayScore, &updatedAt); err != nil {
 		return nil, err
 	}
+	game.SeasonID = seasonID.String
+	if updatedAt.Valid {
+		game.UpdatedAt = &updatedAt.Time
+	}
 	return &game, nil
 }
@@ -0,0 +1,135 @@
+// internal/repository/leader_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// LeaderRepository defines operations for ranking players and teams.
+type LeaderRepository interface {
+	FetchLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error)
+}
+
+type leaderRepo struct {
+	db *sql.DB
+}
+
+// NewLeaderRepository returns a new instance of LeaderRepository.
+func NewLeaderRepository(db *sql.DB) LeaderRepository {
+	return &leaderRepo{db: db}
+}
+
+// leaderStats maps each rankable AggregateStats field to the SQL expression computing it
+// over a group of player_game_stats rows.
+var leaderStats = buildLeaderStats()
+
+func buildLeaderStats() map[string]string {
+	const games = "COUNT(DISTINCT game_id)"
+	stats := map[string]string{
+		"games_played":    games,
+		"total_minutes":   "SUM(minutes_played)",
+		"avg_minutes":     "SUM(minutes_played) * 1.0 / " + games,
+		"field_goal_pct":  "COALESCE(SUM(field_goals_made) * 1.0 / NULLIF(SUM(field_goals_attempted), 0), 0)",
+		"three_point_pct": "COALESCE(SUM(three_pointers_made) * 1.0 / NULLIF(SUM(three_pointers_attempted), 0), 0)",
+		"free_throw_pct":  "COALESCE(SUM(free_throws_made) * 1.0 / NULLIF(SUM(free_throws_attempted), 0), 0)",
+	}
+	for _, column := range []string{"points", "rebounds", "assists", "steals", "blocks", "fouls", "turnovers"} {
+		stats["total_"+column] = "SUM(" + column + ")"
+		stats["avg_"+column] = "SUM(" + column + ") * 1.0 / " + games
+	}
+	for _, column := range []string{
+		"field_goals_made", "field_goals_attempted", "three_pointers_made", "three_pointers_attempted",
+		"free_throws_made", "free_throws_attempted", "offensive_rebounds", "defensive_rebounds",
+	} {
+		stats["total_"+column] = "SUM(" + column + ")"
+	}
+	return stats
+}
+
+// IsLeaderStat reports whether stat can be ranked by FetchLeaders.
+func IsLeaderStat(stat string) bool {
+	_, ok := leaderStats[stat]
+	return ok
+}
+
+// FetchLeaders ranks players or teams by a single aggregate stat in one grouped query.
+// Entries below the filter's qualification thresholds are excluded, tied values share a
+// rank and ties are listed in ID order so that pages are stable.
+func (r *leaderRepo) FetchLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error) {
+	ctx, span := tracing.Start(ctx, "LeaderRepository.FetchLeaders")
+	defer span.End()
+
+	expr, ok := leaderStats[filter.Stat]
+	if !ok {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "unknown stat %q", filter.Stat)
+	}
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+
+	groupColumn := "player_id"
+	if filter.Scope == domain.LeaderScopeTeam {
+		groupColumn = "team_id"
+	}
+	from := "player_game_stats"
+
+	var b filterBuilder
+	if filter.SeasonID != "" {
+		b.add("g.season_id = $%[1]d", filter.SeasonID)
+	}
+	if filter.SeasonType != "" {
+		b.add("g.season_type = $%[1]d", filter.SeasonType)
+	}
+	if len(b.conds) > 0 {
+		from += " INNER JOIN games g ON game_id = g.id"
+	}
+	where := b.where()
+
+	// HAVING placeholders continue the numbering of the WHERE placeholders.
+	having := filterBuilder{args: b.args}
+	if filter.MinGames > 0 {
+		having.add("COUNT(DISTINCT game_id) >= $%[1]d", filter.MinGames)
+	}
+	if filter.MinMinutes > 0 {
+		having.add("SUM(minutes_played) >= $%[1]d", filter.MinMinutes)
+	}
+	havingClause := ""
+	if len(having.conds) > 0 {
+		havingClause = " HAVING " + strings.Join(having.conds, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT %[1]s, RANK() OVER (ORDER BY %[2]s %[3]s) AS leader_rank, %[2]s AS value, %[4]s
+		FROM %[5]s%[6]s
+		GROUP BY %[1]s%[7]s
+		ORDER BY leader_rank, %[1]s`, groupColumn, expr, direction, aggregateColumns, from, where, havingClause)
+	query = having.paginate(query, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, having.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaders := []domain.Leader{}
+	for rows.Next() {
+		var id string
+		leader := domain.Leader{AggregateStats: domain.AggregateStats{SeasonID: filter.SeasonID, SeasonType: filter.SeasonType}}
+		if err := scanAggregate(rows, &leader.AggregateStats, &id, &leader.Rank, &leader.Value); err != nil {
+			return nil, err
+		}
+		if filter.Scope == domain.LeaderScopeTeam {
+			leader.TeamID = id
+		} else {
+			leader.PlayerID = id
+		}
+		leaders = append(leaders, leader)
+	}
+	return leaders, rows.Err()
+}
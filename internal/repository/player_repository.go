@@ -2,16 +2,27 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // PlayerRepository defines operations on Player data.
 type PlayerRepository interface {
-	CreatePlayer(player *domain.Player) error
-	GetPlayerByID(id string) (*domain.Player, error)
+	CreatePlayer(ctx context.Context, player *domain.Player) error
+	GetPlayerByID(ctx context.Context, id string) (*domain.Player, error)
+	ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error)
+	UpdatePlayer(ctx context.Context, player *domain.Player) error
+	DeletePlayer(ctx context.Context, id string) error
+	RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error
+	ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error)
+	LineTeams(ctx context.Context, playerID string) ([]string, error)
+	TeamsOn(ctx context.Context, playerIDs []string, date time.Time) (map[string]string, error)
 }
 
 type playerRepo struct {
@@ -23,27 +34,336 @@ func NewPlayerRepository(db *sql.DB) PlayerRepository {
 	return &playerRepo{db: db}
 }
 
-// CreatePlayer inserts a new player record into the database.
-func (r *playerRepo) CreatePlayer(player *domain.Player) error {
-	query := `INSERT INTO players (id, name, team_id) VALUES ($1, $2, $3)`
-	logger.Info("Running query: %s", query)
-	_, err := r.db.Exec(query, player.ID, player.Name, player.TeamID)
-	if err != nil {
-		logger.Error("Failed to insert player %v to the db",  player)
-	} else {
-		logger.Info("Successfully created player %v", player)
+// CreatePlayer inserts a new player record into the database, together with their
+// stint on their team, which goes back to before the player's recorded history.
+// It returns domain.ErrConflict if a player with the same ID already exists.
+func (r *playerRepo) CreatePlayer(ctx context.Context, player *domain.Player) error {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.CreatePlayer")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `INSERT INTO players (id, name, team_id, updated_at) VALUES ($1, $2, $3, $4)`
+		logger.FromContext(ctx).Sampled(querySampler).Debug("Running query", "query", query)
+		if _, err := tx.ExecContext(ctx, query, player.ID, player.Name, player.TeamID, player.UpdatedAt); err != nil {
+			return err
+		}
+		if player.TeamID == "" {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO roster_memberships (player_id, seq, team_id, joined_by) VALUES ($1, 1, $2, $3)`,
+			player.ID, player.TeamID, domain.TransactionSigning)
+		return err
+	})
+	if err == nil {
+		logger.FromContext(ctx).Debug("Inserted player", "player_id", player.ID)
 	}
-	return err
+	return writeError(err, "player", player.ID)
 }
 
 // GetPlayerByID retrieves a player by its ID.
-func (r *playerRepo) GetPlayerByID(id string) (*domain.Player, error) {
-	query := `SELECT id, name, team_id FROM players WHERE id = $1`
-	logger.Info("Running query: %s", query)
-	row := r.db.QueryRow(query, id)
+// It returns domain.ErrNotFound if no player with the given ID exists.
+func (r *playerRepo) GetPlayerByID(ctx context.Context, id string) (*domain.Player, error) {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.GetPlayerByID")
+	defer span.End()
+
+	query := `SELECT id, name, team_id, updated_at FROM players WHERE id = $1`
+	logger.FromContext(ctx).Sampled(querySampler).Debug("Running query", "query", query)
+	player, err := scanPlayer(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err, "player", id)
+	}
+	return player, nil
+}
+
+// ListPlayers retrieves the players matching the filter, ordered by name.
+func (r *playerRepo) ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error) {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.ListPlayers")
+	defer span.End()
+
+	var b filterBuilder
+	if filter.IDs != nil {
+		b.in("id", filter.IDs)
+	}
+	if filter.TeamID != "" {
+		b.add("team_id = $%[1]d", filter.TeamID)
+	}
+	if filter.Name != "" {
+		b.add("LOWER(name) LIKE '%%' || LOWER($%[1]d) || '%%'", filter.Name)
+	}
+	query := b.paginate(`SELECT id, name, team_id, updated_at FROM players`+b.where()+` ORDER BY name, id`, filter.Limit, filter.Offset)
+	logger.FromContext(ctx).Sampled(querySampler).Debug("Running query", "query", query)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []domain.Player{}
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, *player)
+	}
+	return players, rows.Err()
+}
+
+// UpdatePlayer overwrites an existing player's name and team. A change of team is
+// recorded as a transaction effective at the update: a signing for a free agent, a
+// waiver for a player left without a team, and a trade otherwise. See
+// RecordTransaction.
+// It returns domain.ErrNotFound if no player with the given ID exists.
+func (r *playerRepo) UpdatePlayer(ctx context.Context, player *domain.Player) error {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.UpdatePlayer")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var oldTeamID string
+		if err := tx.QueryRowContext(ctx, `SELECT team_id FROM players WHERE id = $1`, player.ID).Scan(&oldTeamID); err != nil {
+			return err
+		}
+		query := `UPDATE players SET name = $1, team_id = $2, updated_at = $3 WHERE id = $4`
+		logger.FromContext(ctx).Sampled(querySampler).Debug("Running query", "query", query)
+		if _, err := tx.ExecContext(ctx, query, player.Name, player.TeamID, player.UpdatedAt, player.ID); err != nil {
+			return err
+		}
+		if oldTeamID == player.TeamID {
+			return nil
+		}
+		transaction := &domain.RosterTransaction{
+			PlayerID:   player.ID,
+			Type:       domain.TransactionTrade,
+			TeamID:     player.TeamID,
+			FromTeamID: oldTeamID,
+			Date:       time.Now().UTC(),
+		}
+		if player.UpdatedAt != nil {
+			transaction.Date = *player.UpdatedAt
+		}
+		switch {
+		case oldTeamID == "":
+			transaction.Type = domain.TransactionSigning
+		case player.TeamID == "":
+			transaction.Type = domain.TransactionWaiver
+		}
+		return moveRoster(ctx, tx, transaction)
+	})
+	return writeError(err, "player", player.ID)
+}
+
+// DeletePlayer removes a player together with all of its game statistics and roster
+// history in a single transaction, and removes the statistics from the season totals
+// of the teams they were logged for.
+// It returns domain.ErrNotFound if no player with the given ID exists.
+func (r *playerRepo) DeletePlayer(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.DeletePlayer")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		teamIDs, err := lineTeams(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, query := range []string{
+			`DELETE FROM player_game_stats WHERE player_id = $1`,
+			`DELETE FROM player_season_totals WHERE player_id = $1`,
+			`DELETE FROM roster_memberships WHERE player_id = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, id); err != nil {
+				return err
+			}
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM players WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res); err != nil {
+			return err
+		}
+		return teamTotals.refresh(ctx, tx, teamIDs)
+	})
+	return notFound(err, "player", id)
+}
+
+// LineTeams returns the teams a player's stat lines are attributed to.
+func (r *playerRepo) LineTeams(ctx context.Context, playerID string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.LineTeams")
+	defer span.End()
+
+	return lineTeams(ctx, r.db, playerID)
+}
+
+// lineTeams returns the teams the player's stat lines are attributed to.
+func lineTeams(ctx context.Context, q queryer, playerID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT DISTINCT team_id FROM player_game_stats WHERE player_id = $1`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teamIDs []string
+	for rows.Next() {
+		var teamID string
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs, rows.Err()
+}
+
+// RecordTransaction applies a roster transaction to a player in a single transaction.
+// The player's current stint ends and, unless they are waived, a stint on the new team
+// starts, both on the transaction's date; the player's stat lines of games from that
+// date on are attributed to the new team, and the season totals of both teams are
+// recomputed. The transaction's FromTeamID is set to the team the player leaves.
+// It returns domain.ErrNotFound if no player with the given ID exists, and
+// domain.ErrConflict if the transaction predates the player's current stint or waives
+// a player with stat lines from its date on.
+func (r *playerRepo) RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.RecordTransaction")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `SELECT team_id FROM players WHERE id = $1`, transaction.PlayerID).Scan(&transaction.FromTeamID); err != nil {
+			return err
+		}
+		query := `UPDATE players SET team_id = $1, updated_at = $2 WHERE id = $3`
+		logger.FromContext(ctx).Sampled(querySampler).Debug("Running query", "query", query)
+		if _, err := tx.ExecContext(ctx, query, transaction.TeamID, transaction.RecordedAt, transaction.PlayerID); err != nil {
+			return err
+		}
+		return moveRoster(ctx, tx, transaction)
+	})
+	if err == nil {
+		logger.FromContext(ctx).Debug("Recorded roster transaction", "player_id", transaction.PlayerID, "type", transaction.Type)
+	}
+	return notFound(err, "player", transaction.PlayerID)
+}
+
+// moveRoster moves a player whose team was just set to transaction.TeamID: it ends
+// their last stint, if still current, and starts one on the new team, if any, on the
+// transaction's date. The player's stat lines of games from that date on are
+// attributed to the new team, and the season totals of transaction.FromTeamID and of
+// the new team are recomputed.
+func moveRoster(ctx context.Context, tx *sql.Tx, transaction *domain.RosterTransaction) error {
+	var seq int
+	var start, end sql.NullTime
+	err := tx.QueryRowContext(ctx, `SELECT seq, start_date, end_date FROM roster_memberships
+		WHERE player_id = $1 ORDER BY seq DESC LIMIT 1`, transaction.PlayerID).Scan(&seq, &start, &end)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	date := transaction.Date
+	if (end.Valid && date.Before(end.Time)) || (!end.Valid && start.Valid && !date.After(start.Time)) {
+		return domain.Errorf(domain.ErrConflict, "player %s already changed teams after %s", transaction.PlayerID, date.Format(time.RFC3339))
+	}
+
+	if transaction.TeamID == "" {
+		var lines int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM player_game_stats ps JOIN games g ON g.id = ps.game_id
+			WHERE ps.player_id = $1 AND g.date >= $2`, transaction.PlayerID, date).Scan(&lines); err != nil {
+			return err
+		}
+		if lines > 0 {
+			return domain.Errorf(domain.ErrConflict, "player %s has %d stat lines from %s on", transaction.PlayerID, lines, date.Format(time.RFC3339))
+		}
+	}
+	if seq > 0 && !end.Valid {
+		if _, err := tx.ExecContext(ctx, `UPDATE roster_memberships SET end_date = $1, ended_by = $2 WHERE player_id = $3 AND seq = $4`,
+			date, transaction.Type, transaction.PlayerID, seq); err != nil {
+			return err
+		}
+	}
+	if transaction.TeamID != "" {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO roster_memberships (player_id, seq, team_id, joined_by, start_date) VALUES ($1, $2, $3, $4, $5)`,
+			transaction.PlayerID, seq+1, transaction.TeamID, transaction.Type, date); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE player_game_stats SET team_id = $1
+			WHERE player_id = $2 AND game_id IN (SELECT id FROM games WHERE date >= $3)`,
+			transaction.TeamID, transaction.PlayerID, date); err != nil {
+			return err
+		}
+	}
+
+	var teamIDs []string
+	for _, id := range []string{transaction.FromTeamID, transaction.TeamID} {
+		if id != "" {
+			teamIDs = append(teamIDs, id)
+		}
+	}
+	return teamTotals.refresh(ctx, tx, teamIDs)
+}
+
+// ListMemberships returns a player's stints on teams, oldest first.
+func (r *playerRepo) ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error) {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.ListMemberships")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT player_id, team_id, joined_by, start_date, end_date, ended_by
+		FROM roster_memberships WHERE player_id = $1 ORDER BY seq`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []domain.RosterMembership{}
+	for rows.Next() {
+		var m domain.RosterMembership
+		var start, end sql.NullTime
+		if err := rows.Scan(&m.PlayerID, &m.TeamID, &m.JoinedBy, &start, &end, &m.EndedBy); err != nil {
+			return nil, err
+		}
+		if start.Valid {
+			m.Start = &start.Time
+		}
+		if end.Valid {
+			m.End = &end.Time
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, rows.Err()
+}
+
+// TeamsOn returns the team each of the players was on at the given time, keyed by
+// player ID. Players who were not on a team at the time are left out.
+func (r *playerRepo) TeamsOn(ctx context.Context, playerIDs []string, date time.Time) (map[string]string, error) {
+	ctx, span := tracing.Start(ctx, "PlayerRepository.TeamsOn")
+	defer span.End()
+
+	var b filterBuilder
+	b.in("player_id", playerIDs)
+	b.add("(start_date IS NULL OR start_date <= $%[1]d)", date)
+	b.add("(end_date IS NULL OR end_date > $%[1]d)", date)
+	rows, err := r.db.QueryContext(ctx, `SELECT player_id, team_id FROM roster_memberships`+b.where(), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make(map[string]string, len(playerIDs))
+	for rows.Next() {
+		var playerID, teamID string
+		if err := rows.Scan(&playerID, &teamID); err != nil {
+			return nil, err
+		}
+		teams[playerID] = teamID
+	}
+	return teams, rows.Err()
+}
+
+// scanPlayer reads a player from a row selected as (id, name, team_id, updated_at).
+func scanPlayer(row rowScanner) (*domain.Player, error) {
 	var player domain.Player
-	if err := row.Scan(&player.ID, &player.Name, &player.TeamID); err != nil {
+	var updatedAt sql.NullTime
+	if err := row.Scan(&player.ID, &player.Name, &player.TeamID, &updatedAt); err != nil {
 		return nil, err
 	}
+	if updatedAt.Valid {
+		player.UpdatedAt = &updatedAt.Time
+	}
 	return &player, nil
 }
@@ -2,18 +2,46 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // PlayerStatsRepository defines operations for player game statistics.
 type PlayerStatsRepository interface {
-	InsertPlayerStats(stats *domain.PlayerGameStats) error
-	FetchPlayerAggregate(playerID string) (*domain.AggregateStats, error)
-	FetchTeamAggregate(teamID string) (*domain.AggregateStats, error)
+	InsertPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error
+	InsertBoxScore(ctx context.Context, lines []domain.PlayerGameStats) error
+	FetchPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error)
+	FetchTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error)
+	FetchGameLog(ctx context.Context, filter domain.GameLogFilter, after *domain.GameLogCursor) ([]domain.GameLogEntry, error)
 }
 
+// aggregateColumns are the summed columns of an aggregate query, in the order
+// scanAggregate reads them. They are unqualified so that they can be selected
+// from player_game_stats joined with games.
+const aggregateColumns = `
+	COUNT(DISTINCT game_id) as games_played,
+	SUM(points) as total_points,
+	SUM(rebounds) as total_rebounds,
+	SUM(assists) as total_assists,
+	SUM(steals) as total_steals,
+	SUM(blocks) as total_blocks,
+	SUM(fouls) as total_fouls,
+	SUM(turnovers) as total_turnovers,
+	SUM(minutes_played) as total_minutes,
+	SUM(field_goals_made) as total_field_goals_made,
+	SUM(field_goals_attempted) as total_field_goals_attempted,
+	SUM(three_pointers_made) as total_three_pointers_made,
+	SUM(three_pointers_attempted) as total_three_pointers_attempted,
+	SUM(free_throws_made) as total_free_throws_made,
+	SUM(free_throws_attempted) as total_free_throws_attempted,
+	SUM(offensive_rebounds) as total_offensive_rebounds,
+	SUM(defensive_rebounds) as total_defensive_rebounds`
+
 type playerStatsRepo struct {
 	db *sql.DB
 }
@@ -23,82 +51,281 @@ func NewPlayerStatsRepository(db *sql.DB) PlayerStatsRepository {
 	return &playerStatsRepo{db: db}
 }
 
-// InsertPlayerStats stores a player's game statistics.
-func (r *playerStatsRepo) InsertPlayerStats(stats *domain.PlayerGameStats) error {
-	query := `
-		INSERT INTO player_game_stats 
-		(id, player_id, game_id, points, rebounds, assists, steals, blocks, fouls, turnovers, minutes_played)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+// insertPlayerStatsQuery inserts a single player_game_stats row; see playerStatsArgs.
+const insertPlayerStatsQuery = `
+		INSERT INTO player_game_stats
+		(id, player_id, game_id, team_id, points, rebounds, assists, steals, blocks, fouls, turnovers, minutes_played,
+		field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+		free_throws_made, free_throws_attempted, offensive_rebounds, defensive_rebounds, starter)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 	`
-	_, err := r.db.Exec(query, stats.ID, stats.PlayerID, stats.GameID, stats.Points, stats.Rebounds,
-		stats.Assists, stats.Steals, stats.Blocks, stats.Fouls, stats.Turnovers, stats.MinutesPlayed)
-	return err
+
+// playerStatsArgs returns the arguments of insertPlayerStatsQuery for stats.
+func playerStatsArgs(stats *domain.PlayerGameStats) []interface{} {
+	return []interface{}{stats.ID, stats.PlayerID, stats.GameID, stats.TeamID, stats.Points, stats.Rebounds,
+		stats.Assists, stats.Steals, stats.Blocks, stats.Fouls, stats.Turnovers, stats.MinutesPlayed,
+		stats.FieldGoalsMade, stats.FieldGoalsAttempted, stats.ThreePointersMade, stats.ThreePointersAttempted,
+		stats.FreeThrowsMade, stats.FreeThrowsAttempted, stats.OffensiveRebounds, stats.DefensiveRebounds,
+		nullBool(stats.Starter)}
 }
 
-// FetchPlayerAggregate calculates and returns aggregated statistics for a player.
-func (r *playerStatsRepo) FetchPlayerAggregate(playerID string) (*domain.AggregateStats, error) {
-	query := `
-		SELECT 
-			COUNT(DISTINCT game_id) as games_played,
-			SUM(points) as total_points,
-			SUM(rebounds) as total_rebounds,
-			SUM(assists) as total_assists,
-			SUM(steals) as total_steals,
-			SUM(blocks) as total_blocks,
-			SUM(fouls) as total_fouls,
-			SUM(turnovers) as total_turnovers,
-			SUM(minutes_played) as total_minutes
-		FROM player_game_stats
-		WHERE player_id = $1
-	`
-	row := r.db.QueryRow(query, playerID)
+// InsertPlayerStats stores a player's game statistics and adds them to the season
+// totals of the player and their team, in a single transaction that holds the lock
+// of the game.
+// It returns domain.ErrConflict if a line with the same ID already exists or the game
+// no longer accepts stats, and domain.ErrValidation if the player or game does not
+// exist.
+func (r *playerStatsRepo) InsertPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error {
+	ctx, span := tracing.Start(ctx, "PlayerStatsRepository.InsertPlayerStats")
+	defer span.End()
 
-	var agg domain.AggregateStats
-	agg.PlayerID = playerID
-	err := row.Scan(&agg.GamesPlayed, &agg.TotalPoints, &agg.TotalRebounds, &agg.TotalAssists,
-		&agg.TotalSteals, &agg.TotalBlocks, &agg.TotalFouls, &agg.TotalTurnovers, &agg.TotalMinutes)
-	if err != nil {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockGames(ctx, tx, stats.GameID); err != nil {
+			return err
+		}
+		if err := checkStatsAccepted(ctx, tx, stats.GameID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, insertPlayerStatsQuery, playerStatsArgs(stats)...); err != nil {
+			return writeError(err, "stats line", stats.ID)
+		}
+		return addLineToSeasonTotals(ctx, tx, stats.ID)
+	})
+}
+
+// InsertBoxScore stores every line of a box score and adds it to the season totals
+// in a single transaction, so that either all lines are stored or none are.
+// Constraint violations are reported as for InsertPlayerStats.
+func (r *playerStatsRepo) InsertBoxScore(ctx context.Context, lines []domain.PlayerGameStats) error {
+	ctx, span := tracing.Start(ctx, "PlayerStatsRepository.InsertBoxScore")
+	defer span.End()
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		gameIDs := make([]string, len(lines))
+		for i, line := range lines {
+			gameIDs[i] = line.GameID
+		}
+		if err := lockGames(ctx, tx, gameIDs...); err != nil {
+			return err
+		}
+		if err := checkStatsAccepted(ctx, tx, gameIDs...); err != nil {
+			return err
+		}
+		stmt, err := tx.PrepareContext(ctx, insertPlayerStatsQuery)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for i := range lines {
+			if _, err := stmt.ExecContext(ctx, playerStatsArgs(&lines[i])...); err != nil {
+				return writeError(err, "stats line", lines[i].ID)
+			}
+			if err := addLineToSeasonTotals(ctx, tx, lines[i].ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FetchPlayerAggregate returns aggregated statistics for a player, restricted to the
+// games selected by the filter. They are read from the player's season totals, one row
+// per season and season type.
+func (r *playerStatsRepo) FetchPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "PlayerStatsRepository.FetchPlayerAggregate")
+	defer span.End()
+
+	query, args := seasonTotalsQuery(playerTotals, playerID, filter)
+	row := r.db.QueryRowContext(ctx, query, args...)
+
+	agg := domain.AggregateStats{PlayerID: playerID, SeasonID: filter.SeasonID, SeasonType: filter.SeasonType}
+	if err := scanAggregate(row, &agg); err != nil {
 		return nil, err
 	}
-	if agg.GamesPlayed > 0 {
-		agg.AvgPoints = float64(agg.TotalPoints) / float64(agg.GamesPlayed)
-		agg.AvgRebounds = float64(agg.TotalRebounds) / float64(agg.GamesPlayed)
-		agg.AvgAssists = float64(agg.TotalAssists) / float64(agg.GamesPlayed)
-		agg.AvgSteals = float64(agg.TotalSteals) / float64(agg.GamesPlayed)
-		agg.AvgBlocks = float64(agg.TotalBlocks) / float64(agg.GamesPlayed)
-		agg.AvgFouls = float64(agg.TotalFouls) / float64(agg.GamesPlayed)
-		agg.AvgTurnovers = float64(agg.TotalTurnovers) / float64(agg.GamesPlayed)
-		agg.AvgMinutes = agg.TotalMinutes / float64(agg.GamesPlayed)
+	return &agg, nil
+}
+
+// FetchTeamAggregate returns aggregated statistics for the lines logged for a team,
+// restricted to the games selected by the filter. They are read from the team's season
+// totals, one row per season and season type.
+func (r *playerStatsRepo) FetchTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "PlayerStatsRepository.FetchTeamAggregate")
+	defer span.End()
+
+	query, args := seasonTotalsQuery(teamTotals, teamID, filter)
+	row := r.db.QueryRowContext(ctx, query, args...)
+
+	agg := domain.AggregateStats{TeamID: teamID, SeasonID: filter.SeasonID, SeasonType: filter.SeasonType}
+	if err := scanAggregate(row, &agg); err != nil {
+		return nil, err
 	}
 	return &agg, nil
 }
 
-// FetchTeamAggregate calculates and returns aggregated statistics for a team by joining player data.
-func (r *playerStatsRepo) FetchTeamAggregate(teamID string) (*domain.AggregateStats, error) {
-	query := `
-		SELECT 
-			COUNT(DISTINCT game_id) as games_played,
-			SUM(ps.points) as total_points,
-			SUM(ps.rebounds) as total_rebounds,
-			SUM(ps.assists) as total_assists,
-			SUM(ps.steals) as total_steals,
-			SUM(ps.blocks) as total_blocks,
-			SUM(ps.fouls) as total_fouls,
-			SUM(ps.turnovers) as total_turnovers,
-			SUM(ps.minutes_played) as total_minutes
-		FROM player_game_stats ps
-		INNER JOIN players p ON ps.player_id = p.id
-		WHERE p.team_id = $1
-	`
-	row := r.db.QueryRow(query, teamID)
+// gameLogColumns are the columns of a game log query, in the order scanGameLogEntry
+// reads them. The opponent is the team of the game the line's team did not play for.
+const gameLogColumns = `ps.id, ps.player_id, ps.game_id, ps.team_id, ps.points, ps.rebounds, ps.assists,
+	ps.steals, ps.blocks, ps.fouls, ps.turnovers, ps.minutes_played, ps.field_goals_made,
+	ps.field_goals_attempted, ps.three_pointers_made, ps.three_pointers_attempted, ps.free_throws_made,
+	ps.free_throws_attempted, ps.offensive_rebounds, ps.defensive_rebounds, ps.starter,
+	g.date, g.season_id, g.season_type, ` + gameLogOpponent + `, g.home_team = ps.team_id`
+
+// gameLogOpponent is the SQL expression of the opponent of a line's team.
+const gameLogOpponent = "CASE WHEN g.home_team = ps.team_id THEN g.away_team ELSE g.home_team END"
+
+// FetchGameLog returns the stat lines selected by the filter, each with its game, in
+// the filter's sort order. Lines with the same sort value are ordered by ID, so that a
+// page that starts after a cursor neither repeats nor skips lines however many lines
+// are logged in the meantime. The sort must be GameLogSortDate or a stat accepted by
+// PlayerGameStats.Stat; anything else is reported as domain.ErrInvalidInput.
+func (r *playerStatsRepo) FetchGameLog(ctx context.Context, filter domain.GameLogFilter, after *domain.GameLogCursor) ([]domain.GameLogEntry, error) {
+	ctx, span := tracing.Start(ctx, "PlayerStatsRepository.FetchGameLog")
+	defer span.End()
+
+	column := "g.date"
+	if filter.Sort != domain.GameLogSortDate {
+		if _, ok := (&domain.PlayerGameStats{}).Stat(filter.Sort); !ok {
+			return nil, domain.Errorf(domain.ErrInvalidInput, "unknown sort %q", filter.Sort)
+		}
+		// Stat names are the names of their columns.
+		column = "ps." + filter.Sort
+	}
+	direction, before := "DESC", "<"
+	if filter.Ascending {
+		direction, before = "ASC", ">"
+	}
 
-	var agg domain.AggregateStats
-	agg.TeamID = teamID
-	err := row.Scan(&agg.GamesPlayed, &agg.TotalPoints, &agg.TotalRebounds, &agg.TotalAssists,
-		&agg.TotalSteals, &agg.TotalBlocks, &agg.TotalFouls, &agg.TotalTurnovers, &agg.TotalMinutes)
+	var b filterBuilder
+	if filter.PlayerID != "" {
+		b.add("ps.player_id = $%[1]d", filter.PlayerID)
+	}
+	if filter.TeamID != "" {
+		b.add("ps.team_id = $%[1]d", filter.TeamID)
+	}
+	if filter.GameID != "" {
+		b.add("ps.game_id = $%[1]d", filter.GameID)
+	}
+	if filter.OpponentID != "" {
+		b.add(gameLogOpponent+" = $%[1]d", filter.OpponentID)
+	}
+	switch filter.Location {
+	case domain.GameLocationHome:
+		b.conds = append(b.conds, "g.home_team = ps.team_id")
+	case domain.GameLocationAway:
+		b.conds = append(b.conds, "g.away_team = ps.team_id")
+	}
+	if filter.SeasonID != "" {
+		b.add("g.season_id = $%[1]d", filter.SeasonID)
+	}
+	if filter.SeasonType != "" {
+		b.add("g.season_type = $%[1]d", filter.SeasonType)
+	}
+	if !filter.From.IsZero() {
+		b.add("g.date >= $%[1]d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		b.add("g.date <= $%[1]d", filter.To)
+	}
+	if after != nil {
+		var value interface{} = after.Value
+		if column == "g.date" {
+			value = after.Date
+		}
+		b.args = append(b.args, value, after.ID)
+		b.conds = append(b.conds, fmt.Sprintf("(%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND ps.id %[2]s $%[4]d))",
+			column, before, len(b.args)-1, len(b.args)))
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM player_game_stats ps INNER JOIN games g ON ps.game_id = g.id%s
+		ORDER BY %s %s, ps.id %s`, gameLogColumns, b.where(), column, direction, direction)
+	query = b.paginate(query, filter.Limit, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	entries := []domain.GameLogEntry{}
+	for rows.Next() {
+		entry, err := scanGameLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// scanGameLogEntry reads the gameLogColumns of a row.
+func scanGameLogEntry(row rowScanner) (*domain.GameLogEntry, error) {
+	var e domain.GameLogEntry
+	var starter sql.NullBool
+	var seasonID sql.NullString
+	if err := row.Scan(&e.ID, &e.PlayerID, &e.GameID, &e.TeamID, &e.Points, &e.Rebounds, &e.Assists,
+		&e.Steals, &e.Blocks, &e.Fouls, &e.Turnovers, &e.MinutesPlayed, &e.FieldGoalsMade,
+		&e.FieldGoalsAttempted, &e.ThreePointersMade, &e.ThreePointersAttempted, &e.FreeThrowsMade,
+		&e.FreeThrowsAttempted, &e.OffensiveRebounds, &e.DefensiveRebounds, &starter,
+		&e.Date, &seasonID, &e.SeasonType, &e.OpponentID, &e.Home); err != nil {
+		return nil, err
+	}
+	if starter.Valid {
+		e.Starter = &starter.Bool
+	}
+	e.SeasonID = seasonID.String
+	return &e, nil
+}
+
+// seasonTotalsQuery returns a query summing the season totals of id in table t over
+// the seasons selected by the filter, as the columns scanAggregate reads.
+func seasonTotalsQuery(t totalsTable, id string, filter domain.StatsFilter) (string, []interface{}) {
+	var b filterBuilder
+	b.add(t.key+" = $%[1]d", id)
+	if filter.SeasonID != "" {
+		b.add("season_id = $%[1]d", filter.SeasonID)
+	}
+	if filter.SeasonType != "" {
+		b.add("season_type = $%[1]d", filter.SeasonType)
+	}
+	sums := make([]string, len(seasonTotalsColumns))
+	for i, column := range seasonTotalsColumns {
+		sums[i] = "SUM(" + column + ")"
+	}
+	return `SELECT SUM(games_played), ` + strings.Join(sums, ", ") + ` FROM ` + t.name + b.where(), b.args
+}
+
+// scanAggregate reads the aggregateColumns of a row into agg and derives per-game
+// averages and shooting percentages. Any leading destinations are scanned from the
+// columns selected before aggregateColumns.
+func scanAggregate(row rowScanner, agg *domain.AggregateStats, leading ...interface{}) error {
+	var totalMinutes sql.NullFloat64
+	var totals [8]sql.NullInt64
+	var splits [8]sql.NullInt64
+	dest := append(leading, &totals[0], &totals[1], &totals[2], &totals[3],
+		&totals[4], &totals[5], &totals[6], &totals[7], &totalMinutes,
+		&splits[0], &splits[1], &splits[2], &splits[3],
+		&splits[4], &splits[5], &splits[6], &splits[7])
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	agg.GamesPlayed = int(totals[0].Int64)
+	agg.TotalPoints = int(totals[1].Int64)
+	agg.TotalRebounds = int(totals[2].Int64)
+	agg.TotalAssists = int(totals[3].Int64)
+	agg.TotalSteals = int(totals[4].Int64)
+	agg.TotalBlocks = int(totals[5].Int64)
+	agg.TotalFouls = int(totals[6].Int64)
+	agg.TotalTurnovers = int(totals[7].Int64)
+	agg.TotalMinutes = totalMinutes.Float64
+	agg.TotalFieldGoalsMade = int(splits[0].Int64)
+	agg.TotalFieldGoalsAttempted = int(splits[1].Int64)
+	agg.TotalThreePointersMade = int(splits[2].Int64)
+	agg.TotalThreePointersAttempted = int(splits[3].Int64)
+	agg.TotalFreeThrowsMade = int(splits[4].Int64)
+	agg.TotalFreeThrowsAttempted = int(splits[5].Int64)
+	agg.TotalOffensiveRebounds = int(splits[6].Int64)
+	agg.TotalDefensiveRebounds = int(splits[7].Int64)
+
 	if agg.GamesPlayed > 0 {
 		agg.AvgPoints = float64(agg.TotalPoints) / float64(agg.GamesPlayed)
 		agg.AvgRebounds = float64(agg.TotalRebounds) / float64(agg.GamesPlayed)
@@ -109,5 +336,16 @@ func (r *playerStatsRepo) FetchTeamAggregate(teamID string) (*domain.AggregateSt
 		agg.AvgTurnovers = float64(agg.TotalTurnovers) / float64(agg.GamesPlayed)
 		agg.AvgMinutes = agg.TotalMinutes / float64(agg.GamesPlayed)
 	}
-	return &agg, nil
+	agg.FieldGoalPct = pct(agg.TotalFieldGoalsMade, agg.TotalFieldGoalsAttempted)
+	agg.ThreePointPct = pct(agg.TotalThreePointersMade, agg.TotalThreePointersAttempted)
+	agg.FreeThrowPct = pct(agg.TotalFreeThrowsMade, agg.TotalFreeThrowsAttempted)
+	return nil
+}
+
+// pct returns made/attempted as a fraction, or 0 when nothing was attempted.
+func pct(made, attempted int) float64 {
+	if attempted == 0 {
+		return 0
+	}
+	return float64(made) / float64(attempted)
 }
@@ -0,0 +1,89 @@
+// internal/repository/query.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// filterBuilder accumulates WHERE conditions together with their positional arguments.
+type filterBuilder struct {
+	conds []string
+	args  []interface{}
+}
+
+// add appends a condition that references a single new argument. The condition
+// must use %[1]d for the placeholder index, e.g. "team_id = $%[1]d".
+func (b *filterBuilder) add(cond string, arg interface{}) {
+	b.args = append(b.args, arg)
+	b.conds = append(b.conds, fmt.Sprintf(cond, len(b.args)))
+}
+
+// in appends a condition matching column against any of values, with one placeholder per value.
+// An empty list matches nothing.
+func (b *filterBuilder) in(column string, values []string) {
+	if len(values) == 0 {
+		b.conds = append(b.conds, "1 = 0")
+		return
+	}
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		b.args = append(b.args, v)
+		placeholders[i] = fmt.Sprintf("$%d", len(b.args))
+	}
+	b.conds = append(b.conds, column+" IN ("+strings.Join(placeholders, ", ")+")")
+}
+
+// where renders the accumulated conditions as a WHERE clause (empty if none).
+func (b *filterBuilder) where() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+// paginate appends LIMIT/OFFSET clauses to the query. A non-positive limit
+// disables pagination entirely, since OFFSET is not portable without LIMIT.
+func (b *filterBuilder) paginate(query string, limit, offset int) string {
+	if limit <= 0 {
+		return query
+	}
+	b.args = append(b.args, limit)
+	query += fmt.Sprintf(" LIMIT $%d", len(b.args))
+	if offset > 0 {
+		b.args = append(b.args, offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(b.args))
+	}
+	return query
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullTime maps the zero time to SQL NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullString maps the empty string to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullBool maps a nil *bool to SQL NULL.
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
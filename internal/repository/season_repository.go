@@ -0,0 +1,105 @@
+// internal/repository/season_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// SeasonRepository defines operations on Season data.
+type SeasonRepository interface {
+	CreateSeason(ctx context.Context, season *domain.Season) error
+	GetSeasonByID(ctx context.Context, id string) (*domain.Season, error)
+	GetSeasonByDate(ctx context.Context, date time.Time) (*domain.Season, error)
+	ListSeasons(ctx context.Context) ([]domain.Season, error)
+}
+
+type seasonRepo struct {
+	db *sql.DB
+}
+
+// NewSeasonRepository returns a new instance of SeasonRepository.
+func NewSeasonRepository(db *sql.DB) SeasonRepository {
+	return &seasonRepo{db: db}
+}
+
+// CreateSeason inserts a new season record into the database.
+// It returns domain.ErrConflict if a season with the same ID already exists.
+func (r *seasonRepo) CreateSeason(ctx context.Context, season *domain.Season) error {
+	ctx, span := tracing.Start(ctx, "SeasonRepository.CreateSeason")
+	defer span.End()
+
+	query := `INSERT INTO seasons (id, start_date, end_date, playoffs_start) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.ExecContext(ctx, query, season.ID, season.StartDate, season.EndDate, nullTime(season.PlayoffsStart))
+	return writeError(err, "season", season.ID)
+}
+
+// GetSeasonByID retrieves a season by its ID.
+// It returns domain.ErrNotFound if no season with the given ID exists.
+func (r *seasonRepo) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	ctx, span := tracing.Start(ctx, "SeasonRepository.GetSeasonByID")
+	defer span.End()
+
+	query := `SELECT id, start_date, end_date, playoffs_start FROM seasons WHERE id = $1`
+	season, err := scanSeason(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err, "season", id)
+	}
+	return season, nil
+}
+
+// GetSeasonByDate retrieves the season whose date range contains the given time.
+// End dates are inclusive, so a game on the last day of the season still belongs to it.
+// It returns domain.ErrNotFound if no season contains the time.
+func (r *seasonRepo) GetSeasonByDate(ctx context.Context, date time.Time) (*domain.Season, error) {
+	ctx, span := tracing.Start(ctx, "SeasonRepository.GetSeasonByDate")
+	defer span.End()
+
+	query := `SELECT id, start_date, end_date, playoffs_start FROM seasons WHERE start_date <= $1 ORDER BY start_date DESC LIMIT 1`
+	season, err := scanSeason(r.db.QueryRowContext(ctx, query, date))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !season.Contains(date)) {
+		return nil, domain.Errorf(domain.ErrNotFound, "no season contains %s", date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// ListSeasons retrieves all seasons, most recent first.
+func (r *seasonRepo) ListSeasons(ctx context.Context) ([]domain.Season, error) {
+	ctx, span := tracing.Start(ctx, "SeasonRepository.ListSeasons")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, start_date, end_date, playoffs_start FROM seasons ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []domain.Season{}
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, *season)
+	}
+	return seasons, rows.Err()
+}
+
+// scanSeason reads a season from a row selected as (id, start_date, end_date, playoffs_start).
+func scanSeason(row rowScanner) (*domain.Season, error) {
+	var season domain.Season
+	var playoffsStart sql.NullTime
+	if err := row.Scan(&season.ID, &season.StartDate, &season.EndDate, &playoffsStart); err != nil {
+		return nil, err
+	}
+	season.PlayoffsStart = playoffsStart.Time
+	return &season, nil
+}
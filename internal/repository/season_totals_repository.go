@@ -0,0 +1,310 @@
+// internal/repository/season_totals_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// SeasonTotalsRepository maintains the season totals that aggregates are read from.
+// The totals are kept up to date by the writes of the other repositories; these
+// operations recompute them from the stat lines and find where they differ.
+type SeasonTotalsRepository interface {
+	RebuildSeasonTotals(ctx context.Context) error
+	CheckSeasonTotals(ctx context.Context) ([]domain.TotalsDrift, error)
+}
+
+type seasonTotalsRepo struct {
+	db *sql.DB
+}
+
+// NewSeasonTotalsRepository returns a new instance of SeasonTotalsRepository.
+func NewSeasonTotalsRepository(db *sql.DB) SeasonTotalsRepository {
+	return &seasonTotalsRepo{db: db}
+}
+
+// RebuildSeasonTotals recomputes every player and team season total from the stat
+// lines, in a single transaction.
+func (r *seasonTotalsRepo) RebuildSeasonTotals(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "SeasonTotalsRepository.RebuildSeasonTotals")
+	defer span.End()
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, t := range []totalsTable{playerTotals, teamTotals} {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+t.name); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, t.insertComputedQuery("")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CheckSeasonTotals compares every player and team season total with the totals
+// computed from the stat lines, and returns the rows that differ, players first.
+func (r *seasonTotalsRepo) CheckSeasonTotals(ctx context.Context) ([]domain.TotalsDrift, error) {
+	ctx, span := tracing.Start(ctx, "SeasonTotalsRepository.CheckSeasonTotals")
+	defer span.End()
+
+	drifts := []domain.TotalsDrift{}
+	for _, t := range []totalsTable{playerTotals, teamTotals} {
+		stored, order, err := t.load(ctx, r.db, `SELECT `+t.key+`, season_id, season_type, games_played, `+
+			strings.Join(seasonTotalsColumns, ", ")+` FROM `+t.name)
+		if err != nil {
+			return nil, err
+		}
+		computed, computedOrder, err := t.load(ctx, r.db, t.computeQuery(""))
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range computedOrder {
+			if _, ok := stored[key]; !ok {
+				order = append(order, key)
+			}
+		}
+		for _, key := range order {
+			s, sok := stored[key]
+			c, cok := computed[key]
+			// A row missing on either side is all zeroes for the same owner and season.
+			if !sok {
+				s.PlayerID, s.TeamID, s.SeasonID, s.SeasonType = c.PlayerID, c.TeamID, c.SeasonID, c.SeasonType
+			}
+			if !cok {
+				c.PlayerID, c.TeamID, c.SeasonID, c.SeasonType = s.PlayerID, s.TeamID, s.SeasonID, s.SeasonType
+			}
+			if !sameTotals(s, c) {
+				drifts = append(drifts, domain.TotalsDrift{Stored: s, Computed: c})
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// seasonTotalsColumns are the stats summed into the season totals, named as in
+// player_game_stats and in the order scanAggregate reads them after games_played.
+var seasonTotalsColumns = []string{"points", "rebounds", "assists", "steals", "blocks", "fouls", "turnovers",
+	"minutes_played", "field_goals_made", "field_goals_attempted", "three_pointers_made", "three_pointers_attempted",
+	"free_throws_made", "free_throws_attempted", "offensive_rebounds", "defensive_rebounds"}
+
+// totalsTable describes a table of season totals and how stat lines, selected as ps
+// joined with their game as g, are attributed to its rows. A team's totals are those
+// of the lines of players on its roster at game time, which each line records.
+type totalsTable struct {
+	name       string // Table name.
+	key        string // Column identifying whose totals a row holds.
+	owner      string // Expression selecting whose totals a line counts towards.
+	otherLines string // Other lines, as o, of the same owner as ps.
+}
+
+var (
+	playerTotals = totalsTable{
+		name:       "player_season_totals",
+		key:        "player_id",
+		owner:      "ps.player_id",
+		otherLines: "player_game_stats o WHERE o.player_id = ps.player_id",
+	}
+	teamTotals = totalsTable{
+		name:       "team_season_totals",
+		key:        "team_id",
+		owner:      "ps.team_id",
+		otherLines: "player_game_stats o WHERE o.team_id = ps.team_id",
+	}
+)
+
+// computeQuery returns a query summing the stat lines matching where (a WHERE clause
+// on ps or g, or "") into rows of the table.
+func (t totalsTable) computeQuery(where string) string {
+	sums := make([]string, len(seasonTotalsColumns))
+	for i, column := range seasonTotalsColumns {
+		sums[i] = "SUM(ps." + column + ")"
+	}
+	return `SELECT ` + t.owner + `, COALESCE(g.season_id, ''), g.season_type, COUNT(DISTINCT ps.game_id), ` +
+		strings.Join(sums, ", ") + `
+		FROM player_game_stats ps JOIN games g ON g.id = ps.game_id` + where + `
+		GROUP BY ` + t.owner + `, COALESCE(g.season_id, ''), g.season_type`
+}
+
+// insertColumns lists the columns of the table, as inserted by every query.
+func (t totalsTable) insertColumns() string {
+	return t.key + `, season_id, season_type, games_played, ` + strings.Join(seasonTotalsColumns, ", ")
+}
+
+// insertComputedQuery returns a statement inserting the totals computed by computeQuery.
+func (t totalsTable) insertComputedQuery(where string) string {
+	return `INSERT INTO ` + t.name + ` (` + t.insertColumns() + `) ` + t.computeQuery(where)
+}
+
+// addLineQuery returns a statement adding the stat line with the ID $1 to the row of
+// its owner, season and season type. The game counts as played unless the owner has
+// another line in it, which relies on the transaction holding the lock of lockGames.
+func (t totalsTable) addLineQuery() string {
+	values := make([]string, len(seasonTotalsColumns))
+	updates := make([]string, len(seasonTotalsColumns)+1)
+	updates[0] = fmt.Sprintf("games_played = %[1]s.games_played + excluded.games_played", t.name)
+	for i, column := range seasonTotalsColumns {
+		values[i] = "ps." + column
+		updates[i+1] = fmt.Sprintf("%[2]s = %[1]s.%[2]s + excluded.%[2]s", t.name, column)
+	}
+	return `INSERT INTO ` + t.name + ` (` + t.insertColumns() + `)
+		SELECT ` + t.owner + `, COALESCE(g.season_id, ''), g.season_type,
+			CASE WHEN EXISTS (SELECT 1 FROM ` + t.otherLines + ` AND o.game_id = ps.game_id AND o.id <> ps.id) THEN 0 ELSE 1 END, ` +
+		strings.Join(values, ", ") + `
+		FROM player_game_stats ps JOIN games g ON g.id = ps.game_id
+		WHERE ps.id = $1
+		ON CONFLICT (` + t.key + `, season_id, season_type) DO UPDATE SET ` + strings.Join(updates, ", ")
+}
+
+// refresh recomputes the rows of the given owners from their stat lines, removing
+// those left without any.
+func (t totalsTable) refresh(ctx context.Context, tx *sql.Tx, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var del filterBuilder
+	del.in(t.key, ids)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+t.name+del.where(), del.args...); err != nil {
+		return err
+	}
+	var ins filterBuilder
+	ins.in(t.owner, ids)
+	_, err := tx.ExecContext(ctx, t.insertComputedQuery(ins.where()), ins.args...)
+	return err
+}
+
+// load reads rows selected as the table's columns, keyed by owner, season and season
+// type, along with the keys in the order they were read.
+func (t totalsTable) load(ctx context.Context, db *sql.DB, query string) (map[string]domain.AggregateStats, []string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]domain.AggregateStats)
+	var order []string
+	for rows.Next() {
+		var agg domain.AggregateStats
+		var owner string
+		if err := scanAggregate(rows, &agg, &owner, &agg.SeasonID, &agg.SeasonType); err != nil {
+			return nil, nil, err
+		}
+		if t.key == "team_id" {
+			agg.TeamID = owner
+		} else {
+			agg.PlayerID = owner
+		}
+		key := owner + "\xff" + agg.SeasonID + "\xff" + agg.SeasonType
+		totals[key] = agg
+		order = append(order, key)
+	}
+	return totals, order, rows.Err()
+}
+
+// sameTotals reports whether two aggregates have the same totals. Minutes are compared
+// with a tolerance, since adding them line by line may round differently than summing.
+func sameTotals(a, b domain.AggregateStats) bool {
+	if math.Abs(a.TotalMinutes-b.TotalMinutes) > 1e-6 {
+		return false
+	}
+	b.TotalMinutes, b.AvgMinutes = a.TotalMinutes, a.AvgMinutes
+	a.PlayerID, a.TeamID, a.SeasonID, a.SeasonType = "", "", "", ""
+	b.PlayerID, b.TeamID, b.SeasonID, b.SeasonType = "", "", "", ""
+	return a == b
+}
+
+// lockGames locks the rows of the given games until the transaction ends, in ID order
+// so that transactions locking several games cannot deadlock. Transactions adding stat
+// lines to a game take the lock before inserting them, so that under READ COMMITTED
+// each sees the lines of the transactions that came before it when deciding whether
+// the game is the first of a player or team, instead of counting it twice. The no-op
+// update locks the row under both PostgreSQL and SQLite, which has no FOR UPDATE.
+func lockGames(ctx context.Context, tx *sql.Tx, gameIDs ...string) error {
+	ids := append([]string(nil), gameIDs...)
+	sort.Strings(ids)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE games SET status = status WHERE id = $1`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatsAccepted reads the status of games locked by lockGames and returns
+// domain.ErrConflict if one of them no longer accepts stats, because it was made final
+// or cancelled after the service checked. Unknown games are left to the foreign key.
+func checkStatsAccepted(ctx context.Context, tx *sql.Tx, gameIDs ...string) error {
+	checked := make(map[string]bool, len(gameIDs))
+	for _, id := range gameIDs {
+		if checked[id] {
+			continue
+		}
+		checked[id] = true
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM games WHERE id = $1`, id).Scan(&status)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := domain.CheckStatsAccepted(id, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addLineToSeasonTotals adds a stat line that was just inserted to the season totals
+// of its player and of its team.
+func addLineToSeasonTotals(ctx context.Context, tx *sql.Tx, lineID string) error {
+	for _, t := range []totalsTable{playerTotals, teamTotals} {
+		if _, err := tx.ExecContext(ctx, t.addLineQuery(), lineID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gameParticipants returns the players with stat lines in a game and their teams.
+func gameParticipants(ctx context.Context, q queryer, gameID string) (players, teams []string, err error) {
+	rows, err := q.QueryContext(ctx, `SELECT DISTINCT player_id, team_id FROM player_game_stats WHERE game_id = $1`, gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var player, team string
+		if err := rows.Scan(&player, &team); err != nil {
+			return nil, nil, err
+		}
+		players = append(players, player)
+		if !seen[team] {
+			seen[team] = true
+			teams = append(teams, team)
+		}
+	}
+	return players, teams, rows.Err()
+}
+
+// refreshSeasonTotals recomputes the season totals of the given players and teams.
+func refreshSeasonTotals(ctx context.Context, tx *sql.Tx, players, teams []string) error {
+	if err := playerTotals.refresh(ctx, tx, players); err != nil {
+		return err
+	}
+	return teamTotals.refresh(ctx, tx, teams)
+}
@@ -0,0 +1,123 @@
+//go:build cgo
+
+// internal/repository/sqlite.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the name the wrapped SQLite driver is registered under.
+const sqliteDriverName = "nba-stats-sqlite3"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{&sqlite3.SQLiteDriver{ConnectHook: configureSQLiteConn}})
+}
+
+// configureSQLiteConn applies the settings every SQLite connection needs to behave
+// like PostgreSQL: enforced foreign keys, and waiting on a locked database instead of
+// failing. File databases use write-ahead logging so that readers do not block writers.
+func configureSQLiteConn(conn *sqlite3.SQLiteConn) error {
+	pragmas := []string{"PRAGMA foreign_keys = ON", "PRAGMA busy_timeout = 5000"}
+	if conn.GetFilename("main") != "" {
+		pragmas = append(pragmas, "PRAGMA journal_mode = WAL")
+	}
+	for _, pragma := range pragmas {
+		if _, err := conn.Exec(pragma, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openSQLite opens the SQLite database named by dsn. Transactions on file databases
+// take the write lock up front, so that concurrent writers queue on busy_timeout
+// rather than failing when a read lock cannot be upgraded.
+func openSQLite(dsn string) (*sql.DB, error) {
+	if !IsInMemory(dsn) {
+		dsn = withQueryParam(dsn, "_txlock=immediate")
+	}
+	return sql.Open(sqliteDriverName, dsn)
+}
+
+// withQueryParam appends a query parameter to a DSN.
+func withQueryParam(dsn, param string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+	return dsn + "?" + param
+}
+
+// sqliteDriver wraps the SQLite driver so that queries written for PostgreSQL run unchanged.
+type sqliteDriver struct {
+	*sqlite3.SQLiteDriver
+}
+
+// Open opens a connection that rebinds placeholders and normalizes times.
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// sqliteConn is a SQLite connection that accepts PostgreSQL-style $N placeholders.
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// Prepare prepares a statement after rebinding its placeholders.
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(Rebind(DialectSQLite, query))
+}
+
+// PrepareContext prepares a statement after rebinding its placeholders.
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, Rebind(DialectSQLite, query))
+}
+
+// ExecContext executes a statement after rebinding its placeholders.
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, Rebind(DialectSQLite, query), args)
+}
+
+// QueryContext runs a query after rebinding its placeholders.
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, Rebind(DialectSQLite, query), args)
+}
+
+// CheckNamedValue stores times in UTC. SQLite keeps timestamps as text, so values
+// written with different zone offsets would otherwise not compare chronologically.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if t, ok := nv.Value.(time.Time); ok {
+		nv.Value = t.UTC()
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// sqliteConstraint reports which integrity constraint, if any, a SQLite error is a violation of.
+func sqliteConstraint(err error) constraint {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return noConstraint
+	}
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return uniqueConstraint
+	case sqlite3.ErrConstraintForeignKey:
+		return foreignKeyConstraint
+	}
+	return noConstraint
+}
@@ -0,0 +1,20 @@
+//go:build !cgo
+
+// internal/repository/sqlite_nocgo.go
+package repository
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// openSQLite reports that SQLite is unavailable: the driver is written in C and
+// needs a build with CGO_ENABLED=1.
+func openSQLite(dsn string) (*sql.DB, error) {
+	return nil, errors.New("SQLite support requires a binary built with CGO_ENABLED=1")
+}
+
+// sqliteConstraint reports no constraint violations, since SQLite is unavailable.
+func sqliteConstraint(err error) constraint {
+	return noConstraint
+}
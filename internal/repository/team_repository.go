@@ -2,14 +2,20 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // TeamRepository defines operations on Team data.
 type TeamRepository interface {
-	CreateTeam(team *domain.Team) error
-	GetTeamByID(id string) (*domain.Team, error)
+	CreateTeam(ctx context.Context, team *domain.Team) error
+	GetTeamByID(ctx context.Context, id string) (*domain.Team, error)
+	ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error)
+	UpdateTeam(ctx context.Context, team *domain.Team) error
+	DeleteTeam(ctx context.Context, id string) error
 }
 
 type teamRepo struct {
@@ -22,19 +28,110 @@ func NewTeamRepository(db *sql.DB) TeamRepository {
 }
 
 // CreateTeam inserts a new team record into the database.
-func (r *teamRepo) CreateTeam(team *domain.Team) error {
-	query := `INSERT INTO teams (id, name) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, team.ID, team.Name)
-	return err
+// It returns domain.ErrConflict if a team with the same ID already exists.
+func (r *teamRepo) CreateTeam(ctx context.Context, team *domain.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamRepository.CreateTeam")
+	defer span.End()
+
+	query := `INSERT INTO teams (id, name, conference, division, updated_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.ExecContext(ctx, query, team.ID, team.Name, team.Conference, team.Division, team.UpdatedAt)
+	return writeError(err, "team", team.ID)
 }
 
 // GetTeamByID retrieves a team by its ID.
-func (r *teamRepo) GetTeamByID(id string) (*domain.Team, error) {
-	query := `SELECT id, name FROM teams WHERE id = $1`
-	row := r.db.QueryRow(query, id)
+// It returns domain.ErrNotFound if no team with the given ID exists.
+func (r *teamRepo) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamRepository.GetTeamByID")
+	defer span.End()
+
+	query := `SELECT id, name, conference, division, updated_at FROM teams WHERE id = $1`
+	team, err := scanTeam(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err, "team", id)
+	}
+	return team, nil
+}
+
+// ListTeams retrieves the teams matching the filter, ordered by name.
+func (r *teamRepo) ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamRepository.ListTeams")
+	defer span.End()
+
+	var b filterBuilder
+	if filter.Name != "" {
+		b.add("LOWER(name) LIKE '%%' || LOWER($%[1]d) || '%%'", filter.Name)
+	}
+	query := b.paginate(`SELECT id, name, conference, division, updated_at FROM teams`+b.where()+` ORDER BY name, id`, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []domain.Team{}
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+	return teams, rows.Err()
+}
+
+// UpdateTeam overwrites an existing team's name, conference and division.
+// It returns domain.ErrNotFound if no team with the given ID exists.
+func (r *teamRepo) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamRepository.UpdateTeam")
+	defer span.End()
+
+	query := `UPDATE teams SET name = $1, conference = $2, division = $3, updated_at = $4 WHERE id = $5`
+	res, err := r.db.ExecContext(ctx, query, team.Name, team.Conference, team.Division, team.UpdatedAt, team.ID)
+	if err == nil {
+		err = expectAffected(res)
+	}
+	return writeError(err, "team", team.ID)
+}
+
+// DeleteTeam removes a team. Teams that still have players on their roster or
+// games on their schedule are not deleted and domain.ErrConflict is returned,
+// so that no player or game is left pointing at a missing team.
+// It returns domain.ErrNotFound if no team with the given ID exists.
+func (r *teamRepo) DeleteTeam(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "TeamRepository.DeleteTeam")
+	defer span.End()
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var players, games int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM players WHERE team_id = $1`, id).Scan(&players); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM games WHERE home_team = $1 OR away_team = $1`, id).Scan(&games); err != nil {
+			return err
+		}
+		if players > 0 || games > 0 {
+			return domain.Errorf(domain.ErrConflict, "team %s still has %d players and %d games", id, players, games)
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM teams WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		return expectAffected(res)
+	})
+	return notFound(err, "team", id)
+}
+
+// scanTeam reads a team from a row selected as (id, name, conference, division,
+// updated_at).
+func scanTeam(row rowScanner) (*domain.Team, error) {
 	var team domain.Team
-	if err := row.Scan(&team.ID, &team.Name); err != nil {
+	var updatedAt sql.NullTime
+	if err := row.Scan(&team.ID, &team.Name, &team.Conference, &team.Division, &updatedAt); err != nil {
 		return nil, err
 	}
+	if updatedAt.Valid {
+		team.UpdatedAt = &updatedAt.Time
+	}
 	return &team, nil
 }
@@ -0,0 +1,34 @@
+// internal/repository/tx.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// withTx runs fn inside a database transaction, committing on success and
+// rolling back if fn returns an error. The transaction is rolled back if ctx is
+// canceled before it commits.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// expectAffected returns sql.ErrNoRows when a write statement matched no rows.
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
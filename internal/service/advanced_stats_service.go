@@ -0,0 +1,138 @@
+// internal/service/advanced_stats_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// AdvancedStatsService defines operations for retrieving advanced player metrics.
+type AdvancedStatsService interface {
+	GetPlayerAdvanced(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AdvancedStats, error)
+}
+
+type advancedStatsService struct {
+	playerRepo repository.PlayerRepository
+	statsRepo  repository.PlayerStatsRepository
+	seasonRepo repository.SeasonRepository
+}
+
+// NewAdvancedStatsService creates a new instance of AdvancedStatsService.
+func NewAdvancedStatsService(playerRepo repository.PlayerRepository, statsRepo repository.PlayerStatsRepository, seasonRepo repository.SeasonRepository) AdvancedStatsService {
+	return &advancedStatsService{playerRepo: playerRepo, statsRepo: statsRepo, seasonRepo: seasonRepo}
+}
+
+// GetPlayerAdvanced computes advanced metrics for a player over the games selected by the filter.
+// Team-context metrics are computed against the player's current team over the same games.
+func (s *advancedStatsService) GetPlayerAdvanced(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AdvancedStats, error) {
+	ctx, span := tracing.Start(ctx, "AdvancedStatsService.GetPlayerAdvanced")
+	defer span.End()
+
+	if playerID == "" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "player ID cannot be empty")
+	}
+	filter, err := resolveStatsFilter(ctx, s.seasonRepo, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	player, err := s.playerRepo.GetPlayerByID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Debug("Computing advanced stats", "player_id", playerID, "filter", filter)
+
+	playerAgg, err := s.statsRepo.FetchPlayerAggregate(ctx, playerID, filter)
+	if err != nil {
+		return nil, err
+	}
+	teamAgg, err := s.statsRepo.FetchTeamAggregate(ctx, player.TeamID, filter)
+	if err != nil {
+		return nil, err
+	}
+	aggregateQueries.Inc("advanced")
+	return ComputeAdvancedStats(playerAgg, teamAgg), nil
+}
+
+// ComputeAdvancedStats derives advanced metrics from a player's aggregate and the
+// aggregate of the player's team over the same games. Every metric whose
+// denominator is zero is reported as 0.
+//
+// The formulas follow the common basketball-reference definitions:
+//
+//	TS%   = PTS / (2 * (FGA + 0.44*FTA))
+//	eFG%  = (FGM + 0.5*3PM) / FGA
+//	USG%  = 100 * (FGA + 0.44*FTA + TOV) * (TmMP/5) / (MP * (TmFGA + 0.44*TmFTA + TmTOV))
+//	Poss  = (TmFGA + 0.44*TmFTA - TmORB + TmTOV) * MP / (TmMP/5)
+//
+// Team minutes are the summed minutes of the team's players, so TmMP/5 is the
+// length of the team's games.
+func ComputeAdvancedStats(player, team *domain.AggregateStats) *domain.AdvancedStats {
+	adv := &domain.AdvancedStats{
+		PlayerID:    player.PlayerID,
+		TeamID:      team.TeamID,
+		SeasonID:    player.SeasonID,
+		SeasonType:  player.SeasonType,
+		GamesPlayed: player.GamesPlayed,
+		Minutes:     player.TotalMinutes,
+	}
+
+	fga := float64(player.TotalFieldGoalsAttempted)
+	fta := float64(player.TotalFreeThrowsAttempted)
+	tov := float64(player.TotalTurnovers)
+
+	adv.TrueShootingPct = ratio(float64(player.TotalPoints), 2*(fga+0.44*fta))
+	adv.EffectiveFGPct = ratio(float64(player.TotalFieldGoalsMade)+0.5*float64(player.TotalThreePointersMade), fga)
+	adv.AssistTurnoverRatio = ratio(float64(player.TotalAssists), tov)
+
+	teamGameMinutes := team.TotalMinutes / 5
+	teamUses := float64(team.TotalFieldGoalsAttempted) + 0.44*float64(team.TotalFreeThrowsAttempted) + float64(team.TotalTurnovers)
+	adv.UsageRate = 100 * ratio((fga+0.44*fta+tov)*teamGameMinutes, player.TotalMinutes*teamUses)
+
+	teamPossessions := teamUses - float64(team.TotalOffensiveRebounds)
+	adv.Possessions = ratio(teamPossessions*player.TotalMinutes, teamGameMinutes)
+
+	adv.PER = ratio(gameScore(player)*36, player.TotalMinutes)
+	adv.Per36 = rates(player, ratio(36, player.TotalMinutes))
+	adv.Per100 = rates(player, ratio(100, adv.Possessions))
+	return adv
+}
+
+// gameScore returns Hollinger's game score summed over the aggregate's games.
+func gameScore(agg *domain.AggregateStats) float64 {
+	return float64(agg.TotalPoints) +
+		0.4*float64(agg.TotalFieldGoalsMade) -
+		0.7*float64(agg.TotalFieldGoalsAttempted) -
+		0.4*float64(agg.TotalFreeThrowsAttempted-agg.TotalFreeThrowsMade) +
+		0.7*float64(agg.TotalOffensiveRebounds) +
+		0.3*float64(agg.TotalDefensiveRebounds) +
+		float64(agg.TotalSteals) +
+		0.7*float64(agg.TotalAssists) +
+		0.7*float64(agg.TotalBlocks) -
+		0.4*float64(agg.TotalFouls) -
+		float64(agg.TotalTurnovers)
+}
+
+// rates scales the aggregate's counting stats by factor.
+func rates(agg *domain.AggregateStats, factor float64) domain.RateStats {
+	return domain.RateStats{
+		Points:    float64(agg.TotalPoints) * factor,
+		Rebounds:  float64(agg.TotalRebounds) * factor,
+		Assists:   float64(agg.TotalAssists) * factor,
+		Steals:    float64(agg.TotalSteals) * factor,
+		Blocks:    float64(agg.TotalBlocks) * factor,
+		Turnovers: float64(agg.TotalTurnovers) * factor,
+	}
+}
+
+// ratio returns num/den, or 0 when den is not positive.
+func ratio(num, den float64) float64 {
+	if den <= 0 {
+		return 0
+	}
+	return num / den
+}
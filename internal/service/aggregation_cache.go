@@ -0,0 +1,263 @@
+// internal/service/aggregation_cache.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// StatsInvalidator is told when the stat lines of a player, whose team is given too,
+// are stored, moved or deleted, so that anything derived from their stats can be
+// discarded. Either ID may be empty when only a player's or a team's stats changed.
+type StatsInvalidator interface {
+	InvalidateStats(playerID, teamID string)
+}
+
+// AggregationCache is an AggregationService that keeps the aggregates it returns for
+// a while. Invalidating a player's or team's stats discards their aggregates at once.
+type AggregationCache interface {
+	AggregationService
+	StatsInvalidator
+	Stats() CacheStats
+}
+
+// CacheStats counts how an AggregationCache answered requests.
+type CacheStats struct {
+	Hits      uint64 // Served from a cached aggregate.
+	Misses    uint64 // Fetched from the underlying service.
+	Coalesced uint64 // Served by waiting for an identical fetch already in flight.
+	Entries   int    // Aggregates cached, including expired ones not yet evicted.
+}
+
+type aggregationCache struct {
+	next       AggregationService
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]map[domain.StatsFilter]cacheEntry // By owner, then filter.
+	size     int
+	inflight map[cacheKey]*cacheFlight
+	stats    CacheStats
+}
+
+// cacheKey identifies an aggregate: its owner, "player:ID" or "team:ID", and filter.
+type cacheKey struct {
+	owner  string
+	filter domain.StatsFilter
+}
+
+type cacheEntry struct {
+	agg     domain.AggregateStats
+	expires time.Time
+}
+
+// cacheFlight is a fetch in flight, which requests for the same aggregate wait for.
+type cacheFlight struct {
+	done     chan struct{}
+	agg      *domain.AggregateStats
+	err      error
+	canceled bool // The request that fetched ended before the fetch completed.
+}
+
+// NewAggregationCache returns an AggregationCache in front of next, keeping at most
+// maxEntries aggregates for ttl each. It reads the time from clock, or from time.Now
+// if clock is nil.
+func NewAggregationCache(next AggregationService, ttl time.Duration, maxEntries int, clock func() time.Time) AggregationCache {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &aggregationCache{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        clock,
+		entries:    make(map[string]map[domain.StatsFilter]cacheEntry),
+		inflight:   make(map[cacheKey]*cacheFlight),
+	}
+}
+
+// RegisterCacheMetrics exposes the statistics of cache (AggregationCache.Stats) as
+// metrics in the given registry.
+func RegisterCacheMetrics(registry *metrics.Registry, cache AggregationCache) {
+	stat := func(value func(CacheStats) float64) func() float64 {
+		return func() float64 { return value(cache.Stats()) }
+	}
+	registry.NewCounterFunc("nba_stats_aggregate_cache_hits_total", "Aggregate requests served from the cache.",
+		stat(func(s CacheStats) float64 { return float64(s.Hits) }))
+	registry.NewCounterFunc("nba_stats_aggregate_cache_misses_total", "Aggregate requests fetched from the database.",
+		stat(func(s CacheStats) float64 { return float64(s.Misses) }))
+	registry.NewCounterFunc("nba_stats_aggregate_cache_coalesced_total", "Aggregate requests that waited for an identical request's fetch.",
+		stat(func(s CacheStats) float64 { return float64(s.Coalesced) }))
+	registry.NewGaugeFunc("nba_stats_aggregate_cache_entries", "Aggregates held in the cache.",
+		stat(func(s CacheStats) float64 { return float64(s.Entries) }))
+}
+
+// GetPlayerAggregate returns the player's cached aggregate, or fetches it.
+func (c *aggregationCache) GetPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "AggregationCache.GetPlayerAggregate")
+	defer span.End()
+
+	return c.get(ctx, span, cacheKey{"player:" + playerID, filter}, func(ctx context.Context) (*domain.AggregateStats, error) {
+		return c.next.GetPlayerAggregate(ctx, playerID, filter)
+	})
+}
+
+// GetTeamAggregate returns the team's cached aggregate, or fetches it.
+func (c *aggregationCache) GetTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "AggregationCache.GetTeamAggregate")
+	defer span.End()
+
+	return c.get(ctx, span, cacheKey{"team:" + teamID, filter}, func(ctx context.Context) (*domain.AggregateStats, error) {
+		return c.next.GetTeamAggregate(ctx, teamID, filter)
+	})
+}
+
+// InvalidateStats discards the cached aggregates of the player and of the team. Fetches
+// of them already in flight are not cached, nor joined by later requests.
+func (c *aggregationCache) InvalidateStats(playerID, teamID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var owners []string
+	if playerID != "" {
+		owners = append(owners, "player:"+playerID)
+	}
+	if teamID != "" {
+		owners = append(owners, "team:"+teamID)
+	}
+	for _, owner := range owners {
+		c.size -= len(c.entries[owner])
+		delete(c.entries, owner)
+		for key := range c.inflight {
+			if key.owner == owner {
+				delete(c.inflight, key)
+			}
+		}
+	}
+}
+
+// invalidateStats tells each invalidator that the stats of the players and of the
+// teams changed.
+func invalidateStats(invalidators []StatsInvalidator, playerIDs, teamIDs []string) {
+	for _, invalidator := range invalidators {
+		for _, id := range playerIDs {
+			invalidator.InvalidateStats(id, "")
+		}
+		for _, id := range teamIDs {
+			invalidator.InvalidateStats("", id)
+		}
+	}
+}
+
+// Stats returns the cache's statistics so far.
+func (c *aggregationCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.size
+	return stats
+}
+
+// get returns the cached aggregate for key if it has not expired. Otherwise it waits
+// for the fetch of key in flight, or calls fetch and caches what it returns. Errors
+// are not cached.
+func (c *aggregationCache) get(ctx context.Context, span *tracing.Span, key cacheKey, fetch func(context.Context) (*domain.AggregateStats, error)) (*domain.AggregateStats, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key.owner][key.filter]; ok && c.now().Before(entry.expires) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		span.SetAttribute("cache.result", "hit")
+		agg := entry.agg
+		return &agg, nil
+	}
+	if flight, ok := c.inflight[key]; ok {
+		c.stats.Coalesced++
+		c.mu.Unlock()
+		span.SetAttribute("cache.result", "coalesced")
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if flight.err == nil {
+			agg := *flight.agg
+			return &agg, nil
+		}
+		// The request that fetched was canceled, but this one was not: try again.
+		if flight.canceled && ctx.Err() == nil {
+			return c.get(ctx, span, key, fetch)
+		}
+		return nil, flight.err
+	}
+	flight := &cacheFlight{done: make(chan struct{})}
+	c.inflight[key] = flight
+	c.stats.Misses++
+	c.mu.Unlock()
+	span.SetAttribute("cache.result", "miss")
+
+	flight.agg, flight.err = fetch(ctx)
+	flight.canceled = flight.err != nil && ctx.Err() != nil
+
+	c.mu.Lock()
+	if c.inflight[key] == flight {
+		delete(c.inflight, key)
+		if flight.err == nil {
+			c.store(key, *flight.agg)
+		}
+	}
+	c.mu.Unlock()
+	close(flight.done)
+
+	if flight.err != nil {
+		return nil, flight.err
+	}
+	agg := *flight.agg
+	return &agg, nil
+}
+
+// store caches an aggregate, evicting expired aggregates first and then arbitrary ones
+// if the cache is full. c.mu must be held.
+func (c *aggregationCache) store(key cacheKey, agg domain.AggregateStats) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	now := c.now()
+	if c.size >= c.maxEntries {
+		for owner, byFilter := range c.entries {
+			for filter, entry := range byFilter {
+				if !now.Before(entry.expires) {
+					delete(byFilter, filter)
+					c.size--
+				}
+			}
+			if len(byFilter) == 0 {
+				delete(c.entries, owner)
+			}
+		}
+	}
+	for owner, byFilter := range c.entries {
+		if c.size < c.maxEntries {
+			break
+		}
+		c.size -= len(byFilter)
+		delete(c.entries, owner)
+	}
+
+	byFilter, ok := c.entries[key.owner]
+	if !ok {
+		byFilter = make(map[domain.StatsFilter]cacheEntry)
+		c.entries[key.owner] = byFilter
+	}
+	if _, ok := byFilter[key.filter]; !ok {
+		c.size++
+	}
+	byFilter[key.filter] = cacheEntry{agg: agg, expires: now.Add(c.ttl)}
+}
@@ -2,41 +2,69 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"time"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
 // AggregationService defines operations for retrieving aggregate statistics.
 type AggregationService interface {
-	GetPlayerAggregate(playerID string) (*domain.AggregateStats, error)
-	GetTeamAggregate(teamID string) (*domain.AggregateStats, error)
+	GetPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error)
+	GetTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error)
 }
 
 type aggregationService struct {
-	statsRepo repository.PlayerStatsRepository
+	statsRepo  repository.PlayerStatsRepository
+	seasonRepo repository.SeasonRepository
 }
 
 // NewAggregationService creates a new instance of AggregationService.
-func NewAggregationService(statsRepo repository.PlayerStatsRepository) AggregationService {
-	return &aggregationService{statsRepo: statsRepo}
+func NewAggregationService(statsRepo repository.PlayerStatsRepository, seasonRepo repository.SeasonRepository) AggregationService {
+	return &aggregationService{statsRepo: statsRepo, seasonRepo: seasonRepo}
 }
 
-// GetPlayerAggregate retrieves the season averages for a specific player.
-func (s *aggregationService) GetPlayerAggregate(playerID string) (*domain.AggregateStats, error) {
+// GetPlayerAggregate retrieves the averages for a specific player over the games selected by the filter.
+func (s *aggregationService) GetPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "AggregationService.GetPlayerAggregate")
+	defer span.End()
+
 	if playerID == "" {
-		return nil, errors.New("player ID cannot be empty")
+		return nil, domain.Errorf(domain.ErrInvalidInput, "player ID cannot be empty")
+	}
+	filter, err := resolveStatsFilter(ctx, s.seasonRepo, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Debug("Fetching player aggregate", "player_id", playerID, "filter", filter)
+	agg, err := s.statsRepo.FetchPlayerAggregate(ctx, playerID, filter)
+	if err != nil {
+		return nil, err
 	}
-	logger.Info("Fetching player aggregate by id: %s", playerID)
-	return s.statsRepo.FetchPlayerAggregate(playerID)
+	aggregateQueries.Inc("player")
+	return agg, nil
 }
 
-// GetTeamAggregate retrieves the season averages for a specific team.
-func (s *aggregationService) GetTeamAggregate(teamID string) (*domain.AggregateStats, error) {
+// GetTeamAggregate retrieves the averages for a specific team over the games selected by the filter.
+func (s *aggregationService) GetTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	ctx, span := tracing.Start(ctx, "AggregationService.GetTeamAggregate")
+	defer span.End()
+
 	if teamID == "" {
-		return nil, errors.New("team ID cannot be empty")
+		return nil, domain.Errorf(domain.ErrInvalidInput, "team ID cannot be empty")
+	}
+	filter, err := resolveStatsFilter(ctx, s.seasonRepo, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Debug("Fetching team aggregate", "team_id", teamID, "filter", filter)
+	agg, err := s.statsRepo.FetchTeamAggregate(ctx, teamID, filter)
+	if err != nil {
+		return nil, err
 	}
-	logger.Info("Fetching team aggregate by id: %s",  teamID)
-	return s.statsRepo.FetchTeamAggregate(teamID)
+	aggregateQueries.Inc("team")
+	return agg, nil
 }
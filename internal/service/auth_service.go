@@ -0,0 +1,153 @@
+// internal/service/auth_service.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+	"github.com/vgeshiktor/nba-stats/pkg/validator"
+)
+
+// APIKeyPrefix starts every API key, so that leaked keys are easy to recognize.
+const APIKeyPrefix = "nbak_"
+
+// apiKeyDisplayLength is the number of leading characters of a key stored to recognize it.
+const apiKeyDisplayLength = len(APIKeyPrefix) + 6
+
+// AuthService authenticates API clients and manages their API keys.
+type AuthService interface {
+	AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error)
+	CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+type authService struct {
+	verifier   *jwt.Verifier
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAuthService creates a new instance of AuthService. Bearer tokens are rejected
+// if verifier is nil, i.e. if no JWT signing keys are configured.
+func NewAuthService(verifier *jwt.Verifier, apiKeyRepo repository.APIKeyRepository) AuthService {
+	return &authService{verifier: verifier, apiKeyRepo: apiKeyRepo}
+}
+
+// AuthenticateToken verifies a JWT and returns the principal named by its subject,
+// granted the scopes of its "scope" claim. An invalid token is reported as
+// domain.ErrUnauthenticated.
+func (s *authService) AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.AuthenticateToken")
+	defer span.End()
+
+	if s.verifier == nil {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "bearer tokens are not accepted; use an API key")
+	}
+	claims, err := s.verifier.Verify(token)
+	if err != nil {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "%v", err)
+	}
+	if claims.Subject == "" {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "token has no subject")
+	}
+	return &domain.Principal{
+		Subject: claims.Subject,
+		Name:    claims.Subject,
+		Method:  domain.AuthMethodJWT,
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
+
+// AuthenticateAPIKey looks up an API key by its hash and returns the principal it
+// identifies. An unknown or revoked key is reported as domain.ErrUnauthenticated.
+func (s *authService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.AuthenticateAPIKey")
+	defer span.End()
+
+	stored, err := s.apiKeyRepo.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "invalid API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "API key %s has been revoked", stored.Prefix)
+	}
+	return &domain.Principal{
+		Subject: "api-key:" + stored.ID,
+		Name:    stored.Name,
+		Method:  domain.AuthMethodAPIKey,
+		Scopes:  stored.Scopes,
+	}, nil
+}
+
+// CreateAPIKey generates and stores a new API key granted the given scopes. It returns
+// the stored record and the key itself, which is not stored and cannot be retrieved again.
+func (s *authService) CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateAPIKey")
+	defer span.End()
+
+	apiKey := &domain.APIKey{Name: name, Scopes: scopes}
+	if err := validator.ValidateAPIKey(apiKey); err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey.ID = uuid.New().String()
+	apiKey.Prefix = key[:apiKeyDisplayLength]
+	apiKey.Hash = hashAPIKey(key)
+	apiKey.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	logger.FromContext(ctx).Info("Creating API key", "key_id", apiKey.ID, "name", apiKey.Name)
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+// ListAPIKeys returns every API key, including revoked ones, newest first.
+func (s *authService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ListAPIKeys")
+	defer span.End()
+
+	return s.apiKeyRepo.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes an API key; requests using it are rejected from then on.
+func (s *authService) RevokeAPIKey(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeAPIKey")
+	defer span.End()
+
+	if id == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "API key ID cannot be empty")
+	}
+	logger.FromContext(ctx).Info("Revoking API key", "key_id", id)
+	return s.apiKeyRepo.RevokeAPIKey(ctx, id, time.Now().UTC())
+}
+
+// hashAPIKey returns the hex SHA-256 digest under which a key is stored. API keys are
+// 256-bit random values, so a fast hash is enough to make stored hashes useless
+// to an attacker.
+func hashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,257 @@
+// internal/service/game_log_service.go
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// GameLogService defines operations for listing the stat lines of a player or team
+// game by game, and of a game as its box score.
+type GameLogService interface {
+	ListPlayerGames(ctx context.Context, playerID string, filter domain.GameLogFilter) (*domain.GameLog, error)
+	ListTeamGames(ctx context.Context, teamID string, filter domain.GameLogFilter) (*domain.GameLog, error)
+	GetBoxScore(ctx context.Context, gameID string) (*domain.GameBoxScore, error)
+}
+
+type gameLogService struct {
+	statsRepo  repository.PlayerStatsRepository
+	playerRepo repository.PlayerRepository
+	teamRepo   repository.TeamRepository
+	gameRepo   repository.GameRepository
+	seasonRepo repository.SeasonRepository
+}
+
+// NewGameLogService creates a new instance of GameLogService.
+func NewGameLogService(statsRepo repository.PlayerStatsRepository, playerRepo repository.PlayerRepository, teamRepo repository.TeamRepository, gameRepo repository.GameRepository, seasonRepo repository.SeasonRepository) GameLogService {
+	return &gameLogService{statsRepo: statsRepo, playerRepo: playerRepo, teamRepo: teamRepo, gameRepo: gameRepo, seasonRepo: seasonRepo}
+}
+
+// ListPlayerGames returns one page of a player's game log: the player's lines for
+// the games selected by the filter. An unknown player is reported as domain.ErrNotFound.
+func (s *gameLogService) ListPlayerGames(ctx context.Context, playerID string, filter domain.GameLogFilter) (*domain.GameLog, error) {
+	ctx, span := tracing.Start(ctx, "GameLogService.ListPlayerGames")
+	defer span.End()
+
+	if _, err := s.playerRepo.GetPlayerByID(ctx, playerID); err != nil {
+		return nil, err
+	}
+	filter.PlayerID, filter.TeamID = playerID, ""
+	return s.list(ctx, filter)
+}
+
+// ListTeamGames returns one page of a team's game log: the lines logged for the team
+// in the games selected by the filter, one per player and game. An unknown team is
+// reported as domain.ErrNotFound.
+func (s *gameLogService) ListTeamGames(ctx context.Context, teamID string, filter domain.GameLogFilter) (*domain.GameLog, error) {
+	ctx, span := tracing.Start(ctx, "GameLogService.ListTeamGames")
+	defer span.End()
+
+	if _, err := s.teamRepo.GetTeamByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+	filter.PlayerID, filter.TeamID = "", teamID
+	return s.list(ctx, filter)
+}
+
+// GetBoxScore returns every line logged for a game, grouped by the team each line
+// counts towards, with the team's totals and the names of its players. Both teams of
+// the game are listed, even before their first line is logged. An unknown game is
+// reported as domain.ErrNotFound.
+func (s *gameLogService) GetBoxScore(ctx context.Context, gameID string) (*domain.GameBoxScore, error) {
+	ctx, span := tracing.Start(ctx, "GameLogService.GetBoxScore")
+	defer span.End()
+
+	game, err := s.gameRepo.GetGameByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Debug("Fetching box score", "game_id", gameID)
+	lines, err := s.statsRepo.FetchGameLog(ctx, domain.GameLogFilter{GameID: gameID, Sort: domain.GameLogSortDate}, nil)
+	if err != nil {
+		return nil, err
+	}
+	playerIDs := make([]string, len(lines))
+	for i, line := range lines {
+		playerIDs[i] = line.PlayerID
+	}
+	players, err := s.playerRepo.ListPlayers(ctx, domain.PlayerFilter{IDs: playerIDs})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(players))
+	for _, p := range players {
+		names[p.ID] = p.Name
+	}
+
+	byTeam := map[string]*domain.TeamBoxScore{}
+	teamIDs := []string{game.HomeTeam, game.AwayTeam}
+	for _, teamID := range teamIDs {
+		byTeam[teamID] = &domain.TeamBoxScore{TeamID: teamID, Home: teamID == game.HomeTeam, Lines: []domain.BoxScoreLine{}}
+	}
+	var others []string
+	for _, line := range lines {
+		team, ok := byTeam[line.TeamID]
+		if !ok {
+			team = &domain.TeamBoxScore{TeamID: line.TeamID, Lines: []domain.BoxScoreLine{}}
+			byTeam[line.TeamID] = team
+			others = append(others, line.TeamID)
+		}
+		team.Lines = append(team.Lines, domain.BoxScoreLine{PlayerGameStats: line.PlayerGameStats, PlayerName: names[line.PlayerID]})
+	}
+	sort.Strings(others)
+
+	boxScore := &domain.GameBoxScore{Game: *game}
+	for _, teamID := range append(teamIDs, others...) {
+		team := byTeam[teamID]
+		if t, err := s.teamRepo.GetTeamByID(ctx, teamID); err == nil {
+			team.TeamName = t.Name
+		} else if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		sortBoxScoreLines(team.Lines)
+		team.Totals = boxScoreTotals(team.Lines)
+		boxScore.Teams = append(boxScore.Teams, *team)
+	}
+	return boxScore, nil
+}
+
+// sortBoxScoreLines orders the lines of a team's box score: starters, then the bench,
+// then lines not marked either way, each by minutes played and then by player ID.
+func sortBoxScoreLines(lines []domain.BoxScoreLine) {
+	role := func(line domain.BoxScoreLine) int {
+		switch {
+		case line.Starter == nil:
+			return 2
+		case *line.Starter:
+			return 0
+		}
+		return 1
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if role(a) != role(b) {
+			return role(a) < role(b)
+		}
+		if a.MinutesPlayed != b.MinutesPlayed {
+			return a.MinutesPlayed > b.MinutesPlayed
+		}
+		return a.PlayerID < b.PlayerID
+	})
+}
+
+// boxScoreTotals sums the lines of a team's box score.
+func boxScoreTotals(lines []domain.BoxScoreLine) domain.BoxScoreTotals {
+	var t domain.BoxScoreTotals
+	for _, line := range lines {
+		t.Points += line.Points
+		t.Rebounds += line.Rebounds
+		t.Assists += line.Assists
+		t.Steals += line.Steals
+		t.Blocks += line.Blocks
+		t.Fouls += line.Fouls
+		t.Turnovers += line.Turnovers
+		t.MinutesPlayed += line.MinutesPlayed
+		t.FieldGoalsMade += line.FieldGoalsMade
+		t.FieldGoalsAttempted += line.FieldGoalsAttempted
+		t.ThreePointersMade += line.ThreePointersMade
+		t.ThreePointersAttempted += line.ThreePointersAttempted
+		t.FreeThrowsMade += line.FreeThrowsMade
+		t.FreeThrowsAttempted += line.FreeThrowsAttempted
+		t.OffensiveRebounds += line.OffensiveRebounds
+		t.DefensiveRebounds += line.DefensiveRebounds
+	}
+	t.FieldGoalPct = ratio(float64(t.FieldGoalsMade), float64(t.FieldGoalsAttempted))
+	t.ThreePointPct = ratio(float64(t.ThreePointersMade), float64(t.ThreePointersAttempted))
+	t.FreeThrowPct = ratio(float64(t.FreeThrowsMade), float64(t.FreeThrowsAttempted))
+	return t
+}
+
+// list validates the filter and fetches the page it selects, one line more than
+// requested to tell whether another page follows. Invalid filters and cursors are
+// reported as domain.ErrInvalidInput.
+func (s *gameLogService) list(ctx context.Context, filter domain.GameLogFilter) (*domain.GameLog, error) {
+	if filter.Sort == "" {
+		filter.Sort = domain.GameLogSortDate
+	}
+	if _, ok := (&domain.PlayerGameStats{}).Stat(filter.Sort); !ok && filter.Sort != domain.GameLogSortDate {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "unknown sort %q", filter.Sort)
+	}
+	switch filter.Location {
+	case "", domain.GameLocationHome, domain.GameLocationAway:
+	default:
+		return nil, domain.Errorf(domain.ErrInvalidInput, "location must be %q or %q", domain.GameLocationHome, domain.GameLocationAway)
+	}
+	statsFilter, err := resolveStatsFilter(ctx, s.seasonRepo, filter.StatsFilter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	filter.StatsFilter = statsFilter
+	after, err := decodeGameLogCursor(filter)
+	if err != nil {
+		return nil, err
+	}
+	limit, _ := normalizePage(filter.Limit, 0)
+	filter.Limit = limit + 1
+
+	logger.FromContext(ctx).Debug("Fetching game log", "filter", filter)
+	lines, err := s.statsRepo.FetchGameLog(ctx, filter, after)
+	if err != nil {
+		return nil, err
+	}
+	log := &domain.GameLog{Lines: lines}
+	if len(lines) > limit {
+		log.Lines = lines[:limit]
+		log.NextCursor = encodeGameLogCursor(filter, log.Lines[limit-1])
+	}
+	return log, nil
+}
+
+// gameLogCursor is the content of an encoded game log cursor. It records the sort the
+// cursor was issued for, so that it is not used to page through another order.
+type gameLogCursor struct {
+	Sort      string    `json:"s"`
+	Ascending bool      `json:"a,omitempty"`
+	Value     float64   `json:"v,omitempty"`
+	Date      time.Time `json:"d"`
+	ID        string    `json:"id"`
+}
+
+// encodeGameLogCursor returns the cursor of the page that follows line, the last line
+// of a page fetched with the filter.
+func encodeGameLogCursor(filter domain.GameLogFilter, line domain.GameLogEntry) string {
+	c := gameLogCursor{Sort: filter.Sort, Ascending: filter.Ascending, Date: line.Date, ID: line.ID}
+	c.Value, _ = line.Stat(filter.Sort)
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeGameLogCursor returns the position the filter's cursor encodes, or nil if it
+// has none.
+func decodeGameLogCursor(filter domain.GameLogFilter) (*domain.GameLogCursor, error) {
+	if filter.Cursor == "" {
+		return nil, nil
+	}
+	var c gameLogCursor
+	data, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.ID == "" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "invalid cursor")
+	}
+	if c.Sort != filter.Sort || c.Ascending != filter.Ascending {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "cursor was issued for another sort order")
+	}
+	return &domain.GameLogCursor{Value: c.Value, Date: c.Date, ID: c.ID}, nil
+}
@@ -2,44 +2,312 @@
 package service
 
 import (
+	"context"
 	"errors"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 	"github.com/vgeshiktor/nba-stats/pkg/validator"
 )
 
 // GameService defines the methods related to game management.
 type GameService interface {
-	CreateGame(game *domain.Game) error
-	GetGameByID(id string) (*domain.Game, error)
+	CreateGame(ctx context.Context, game *domain.Game) error
+	GetGameByID(ctx context.Context, id string) (*domain.Game, error)
+	ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error)
+	UpdateGame(ctx context.Context, game *domain.Game) error
+	PatchGame(ctx context.Context, id string, patch *domain.GamePatch) (*domain.Game, error)
+	DeleteGame(ctx context.Context, id string) error
+	AssignScorekeeper(ctx context.Context, gameID, subject string) error
+	UnassignScorekeeper(ctx context.Context, gameID, subject string) error
+	ListScorekeepers(ctx context.Context, gameID string) ([]string, error)
+	AuthorizeScorekeeper(ctx context.Context, principal *domain.Principal, gameID string) error
 }
 
 type gameService struct {
-	gameRepo repository.GameRepository
+	gameRepo   repository.GameRepository
+	seasonRepo repository.SeasonRepository
+	// invalidators are told about the players and teams whose stat lines were moved
+	// to another season or deleted.
+	invalidators []StatsInvalidator
 }
 
-// NewGameService creates a new instance of GameService.
-func NewGameService(gameRepo repository.GameRepository) GameService {
-	return &gameService{gameRepo: gameRepo}
+// NewGameService creates a new instance of GameService. Each invalidator, such as an
+// AggregationCache, is told about the players and teams whose stats change when a game
+// moves to another season or is deleted.
+func NewGameService(gameRepo repository.GameRepository, seasonRepo repository.SeasonRepository, invalidators ...StatsInvalidator) GameService {
+	return &gameService{gameRepo: gameRepo, seasonRepo: seasonRepo, invalidators: invalidators}
 }
 
-// CreateGame validates and inserts a new game into the database.
-func (s *gameService) CreateGame(game *domain.Game) error {
+// CreateGame validates and inserts a new game into the database. A game without a
+// status is scheduled.
+func (s *gameService) CreateGame(ctx context.Context, game *domain.Game) error {
+	ctx, span := tracing.Start(ctx, "GameService.CreateGame")
+	defer span.End()
+
+	if game.Status == "" {
+		game.Status = domain.GameStatusScheduled
+	}
 	if err := validator.ValidateGame(game); err != nil {
 		return err
 	}
-	logger.Info("creating game: %v", game)
-	return s.gameRepo.CreateGame(game)
+	if err := s.assignSeason(ctx, game); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Creating game", "game_id", game.ID)
+	game.UpdatedAt = updatedNow()
+	game.DeriveResult()
+	return s.gameRepo.CreateGame(ctx, game)
 }
 
 // GetGameByID fetches game details by ID.
-func (s *gameService) GetGameByID(id string) (*domain.Game, error) {
+func (s *gameService) GetGameByID(ctx context.Context, id string) (*domain.Game, error) {
+	ctx, span := tracing.Start(ctx, "GameService.GetGameByID")
+	defer span.End()
+
 	if id == "" {
-		return nil, errors.New("game ID cannot be empty")
+		return nil, domain.Errorf(domain.ErrInvalidInput, "game ID cannot be empty")
+	}
+
+	logger.FromContext(ctx).Debug("Getting game", "game_id", id)
+	return s.gameRepo.GetGameByID(ctx, id)
+}
+
+// ListGames returns one page of games matching the filter.
+func (s *gameService) ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error) {
+	ctx, span := tracing.Start(ctx, "GameService.ListGames")
+	defer span.End()
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "game date range start must not be after its end")
 	}
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	logger.FromContext(ctx).Debug("Listing games", "filter", filter)
+	return s.gameRepo.ListGames(ctx, filter)
+}
+
+// UpdateGame validates and replaces an existing game. A game without a status keeps
+// its current one. domain.ErrConflict is returned for a final game, which can no
+// longer be changed, and for a status change domain.CanChangeGameStatus does not
+// allow. A game marked final must have the score its stat lines add up to, if any
+// were logged.
+func (s *gameService) UpdateGame(ctx context.Context, game *domain.Game) error {
+	ctx, span := tracing.Start(ctx, "GameService.UpdateGame")
+	defer span.End()
 
-	logger.Info("Get game by id: %v", id)
-	return s.gameRepo.GetGameByID(id)
+	current, err := s.GetGameByID(ctx, game.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status == domain.GameStatusFinal {
+		return domain.Errorf(domain.ErrConflict, "game %s is final and can no longer be changed", game.ID)
+	}
+	if game.Status == "" {
+		game.Status = current.Status
+	}
+	if err := validator.ValidateGame(game); err != nil {
+		return err
+	}
+	if err := domain.CheckStatusChange(game.ID, current.Status, game.Status); err != nil {
+		return err
+	}
+	if game.Status == domain.GameStatusFinal {
+		if err := s.checkFinalScore(ctx, game); err != nil {
+			return err
+		}
+	}
+	if err := s.assignSeason(ctx, game); err != nil {
+		return err
+	}
+	var playerIDs, teamIDs []string
+	if game.SeasonID != current.SeasonID || game.SeasonType != current.SeasonType {
+		if playerIDs, teamIDs, err = s.gameRepo.GameParticipants(ctx, game.ID); err != nil {
+			return err
+		}
+	}
+	logger.FromContext(ctx).Info("Updating game", "game_id", game.ID, "status", game.Status)
+	game.UpdatedAt = updatedNow()
+	game.DeriveResult()
+	if err := s.gameRepo.UpdateGame(ctx, game); err != nil {
+		return err
+	}
+	invalidateStats(s.invalidators, playerIDs, teamIDs)
+	return nil
+}
+
+// PatchGame applies a partial update to an existing game and returns the result.
+func (s *gameService) PatchGame(ctx context.Context, id string, patch *domain.GamePatch) (*domain.Game, error) {
+	ctx, span := tracing.Start(ctx, "GameService.PatchGame")
+	defer span.End()
+
+	game, err := s.GetGameByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Date != nil {
+		game.Date = *patch.Date
+	}
+	if patch.HomeTeam != nil {
+		game.HomeTeam = *patch.HomeTeam
+	}
+	if patch.AwayTeam != nil {
+		game.AwayTeam = *patch.AwayTeam
+	}
+	if patch.SeasonID != nil {
+		game.SeasonID = *patch.SeasonID
+	}
+	if patch.SeasonType != nil {
+		game.SeasonType = *patch.SeasonType
+	}
+	if patch.Status != nil {
+		game.Status = *patch.Status
+	}
+	if patch.HomeScore != nil {
+		game.HomeScore = *patch.HomeScore
+	}
+	if patch.AwayScore != nil {
+		game.AwayScore = *patch.AwayScore
+	}
+	if patch.Periods != nil {
+		game.Periods = *patch.Periods
+	}
+	if patch.Date != nil && patch.SeasonID == nil && patch.SeasonType == nil {
+		// Re-derive the season from the new date.
+		game.SeasonID, game.SeasonType = "", ""
+	}
+	if err := s.UpdateGame(ctx, game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// DeleteGame removes a game and the statistics logged for it.
+func (s *gameService) DeleteGame(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "GameService.DeleteGame")
+	defer span.End()
+
+	if id == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "game ID cannot be empty")
+	}
+	playerIDs, teamIDs, err := s.gameRepo.GameParticipants(ctx, id)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Deleting game", "game_id", id)
+	if err := s.gameRepo.DeleteGame(ctx, id); err != nil {
+		return err
+	}
+	invalidateStats(s.invalidators, playerIDs, teamIDs)
+	return nil
+}
+
+// AssignScorekeeper allows the principal with the given subject to log stats for a game.
+func (s *gameService) AssignScorekeeper(ctx context.Context, gameID, subject string) error {
+	ctx, span := tracing.Start(ctx, "GameService.AssignScorekeeper")
+	defer span.End()
+
+	if gameID == "" || subject == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "game ID and scorekeeper subject cannot be empty")
+	}
+	logger.FromContext(ctx).Info("Assigning scorekeeper", "game_id", gameID, "subject", subject)
+	return s.gameRepo.AssignScorekeeper(ctx, gameID, subject)
+}
+
+// UnassignScorekeeper removes a scorekeeper from a game.
+func (s *gameService) UnassignScorekeeper(ctx context.Context, gameID, subject string) error {
+	ctx, span := tracing.Start(ctx, "GameService.UnassignScorekeeper")
+	defer span.End()
+
+	if gameID == "" || subject == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "game ID and scorekeeper subject cannot be empty")
+	}
+	logger.FromContext(ctx).Info("Unassigning scorekeeper", "game_id", gameID, "subject", subject)
+	return s.gameRepo.UnassignScorekeeper(ctx, gameID, subject)
+}
+
+// ListScorekeepers returns the subjects assigned to a game.
+func (s *gameService) ListScorekeepers(ctx context.Context, gameID string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "GameService.ListScorekeepers")
+	defer span.End()
+
+	if _, err := s.GetGameByID(ctx, gameID); err != nil {
+		return nil, err
+	}
+	return s.gameRepo.ListScorekeepers(ctx, gameID)
+}
+
+// AuthorizeScorekeeper checks that the principal may log stats for a game: catalog
+// admins may log stats for any game, other principals only for the games they are
+// assigned to. It returns domain.ErrForbidden otherwise.
+func (s *gameService) AuthorizeScorekeeper(ctx context.Context, principal *domain.Principal, gameID string) error {
+	ctx, span := tracing.Start(ctx, "GameService.AuthorizeScorekeeper")
+	defer span.End()
+
+	if principal == nil {
+		return domain.Errorf(domain.ErrUnauthenticated, "authentication required")
+	}
+	if principal.HasScope(domain.ScopeCatalogAdmin) {
+		return nil
+	}
+	assigned, err := s.gameRepo.IsScorekeeper(ctx, gameID, principal.Subject)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return domain.Errorf(domain.ErrForbidden, "%s is not a scorekeeper of game %s", principal.Subject, gameID)
+	}
+	return nil
+}
+
+// checkFinalScore checks that the points of the stat lines logged for a game add up
+// to each team's score. A game without stat lines may have any score. The repository
+// checks again while it holds the game's lock, in case lines were added since.
+func (s *gameService) checkFinalScore(ctx context.Context, game *domain.Game) error {
+	points, err := s.gameRepo.TeamPoints(ctx, game.ID)
+	if err != nil {
+		return err
+	}
+	return game.CheckScore(points)
+}
+
+// assignSeason fills in a game's season and season type. A game without an
+// explicit season is placed in the season whose dates contain it, if any; the
+// season type defaults to playoffs once the season's playoffs have started.
+func (s *gameService) assignSeason(ctx context.Context, game *domain.Game) error {
+	ctx, span := tracing.Start(ctx, "GameService.assignSeason")
+	defer span.End()
+
+	var season *domain.Season
+	var err error
+	if game.SeasonID != "" {
+		season, err = s.seasonRepo.GetSeasonByID(ctx, game.SeasonID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.InvalidField("season_id", domain.CodeNotFound, "season %s does not exist", game.SeasonID)
+		}
+		if err == nil && !season.Contains(game.Date) {
+			return domain.InvalidField("date", domain.CodeOutOfRange, "game date %s is outside season %s", game.Date.Format("2006-01-02"), season.ID)
+		}
+	} else {
+		season, err = s.seasonRepo.GetSeasonByDate(ctx, game.Date)
+		if errors.Is(err, domain.ErrNotFound) {
+			season, err = nil, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if season != nil {
+		game.SeasonID = season.ID
+		if game.SeasonType == "" {
+			game.SeasonType = season.TypeOn(game.Date)
+		}
+	}
+	if game.SeasonType == "" {
+		game.SeasonType = domain.SeasonTypeRegular
+	}
+	return nil
 }
@@ -0,0 +1,68 @@
+// internal/service/leader_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// DefaultLeaderStat is the stat a leaderboard is ranked by when none is requested.
+const DefaultLeaderStat = "avg_points"
+
+// LeaderService defines operations for retrieving leaderboards.
+type LeaderService interface {
+	GetLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error)
+}
+
+type leaderService struct {
+	leaderRepo repository.LeaderRepository
+	seasonRepo repository.SeasonRepository
+}
+
+// NewLeaderService creates a new instance of LeaderService.
+func NewLeaderService(leaderRepo repository.LeaderRepository, seasonRepo repository.SeasonRepository) LeaderService {
+	return &leaderService{leaderRepo: leaderRepo, seasonRepo: seasonRepo}
+}
+
+// GetLeaders validates the filter and returns one page of the leaderboard it selects.
+// Invalid filters are reported as domain.ErrInvalidInput.
+func (s *leaderService) GetLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error) {
+	ctx, span := tracing.Start(ctx, "LeaderService.GetLeaders")
+	defer span.End()
+
+	if filter.Stat == "" {
+		filter.Stat = DefaultLeaderStat
+	}
+	if !repository.IsLeaderStat(filter.Stat) {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "unknown stat %q", filter.Stat)
+	}
+	switch filter.Scope {
+	case "":
+		filter.Scope = domain.LeaderScopePlayer
+	case domain.LeaderScopePlayer, domain.LeaderScopeTeam:
+	default:
+		return nil, domain.Errorf(domain.ErrInvalidInput, "scope must be %q or %q", domain.LeaderScopePlayer, domain.LeaderScopeTeam)
+	}
+	if filter.MinGames < 0 || filter.MinMinutes < 0 {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "qualification thresholds cannot be negative")
+	}
+	statsFilter, err := resolveStatsFilter(ctx, s.seasonRepo, filter.StatsFilter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	filter.StatsFilter = statsFilter
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+
+	logger.FromContext(ctx).Debug("Fetching leaders", "filter", filter)
+	leaders, err := s.leaderRepo.FetchLeaders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	aggregateQueries.Inc("leaders")
+	return leaders, nil
+}
@@ -0,0 +1,11 @@
+// internal/service/metrics.go
+package service
+
+import "github.com/vgeshiktor/nba-stats/pkg/metrics"
+
+var (
+	statLinesIngested = metrics.NewCounter("nba_stats_stat_lines_ingested_total",
+		"Player stat lines stored, by how they were submitted (single or box_score).", "source")
+	aggregateQueries = metrics.NewCounter("nba_stats_aggregate_queries_total",
+		"Aggregate queries served, by kind (player, team, advanced or leaders).", "kind")
+)
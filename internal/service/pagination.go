@@ -0,0 +1,23 @@
+// internal/service/pagination.go
+package service
+
+const (
+	// DefaultListLimit is the page size used when a list request does not specify one.
+	DefaultListLimit = 50
+	// MaxListLimit caps the page size a client may request.
+	MaxListLimit = 500
+)
+
+// normalizePage clamps a requested limit and offset into the supported range.
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -2,44 +2,193 @@
 package service
 
 import (
-	"errors"
+	"context"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 	"github.com/vgeshiktor/nba-stats/pkg/validator"
 )
 
 // PlayerService defines the methods related to player management.
 type PlayerService interface {
-	CreatePlayer(player *domain.Player) error
-	GetPlayerByID(id string) (*domain.Player, error)
+	CreatePlayer(ctx context.Context, player *domain.Player) error
+	GetPlayerByID(ctx context.Context, id string) (*domain.Player, error)
+	ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error)
+	UpdatePlayer(ctx context.Context, player *domain.Player) error
+	PatchPlayer(ctx context.Context, id string, patch *domain.PlayerPatch) (*domain.Player, error)
+	DeletePlayer(ctx context.Context, id string) error
+	RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error
+	ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error)
 }
 
 type playerService struct {
 	playerRepo repository.PlayerRepository
+	// invalidators are told about the players and teams whose stat lines were moved
+	// or deleted.
+	invalidators []StatsInvalidator
 }
 
-// NewPlayerService creates a new instance of PlayerService.
-func NewPlayerService(playerRepo repository.PlayerRepository) PlayerService {
-	return &playerService{playerRepo: playerRepo}
+// NewPlayerService creates a new instance of PlayerService. Each invalidator, such as
+// an AggregationCache, is told about the players and teams whose stats change when a
+// player changes teams or is deleted.
+func NewPlayerService(playerRepo repository.PlayerRepository, invalidators ...StatsInvalidator) PlayerService {
+	return &playerService{playerRepo: playerRepo, invalidators: invalidators}
 }
 
 // CreatePlayer validates and inserts a new player into the database.
-func (s *playerService) CreatePlayer(player *domain.Player) error {
+func (s *playerService) CreatePlayer(ctx context.Context, player *domain.Player) error {
+	ctx, span := tracing.Start(ctx, "PlayerService.CreatePlayer")
+	defer span.End()
+
 	if err := validator.ValidatePlayer(player); err != nil {
 		return err
 	}
-	logger.Info("creating player: %v", player)
-	return s.playerRepo.CreatePlayer(player)
+	logger.FromContext(ctx).Info("Creating player", "player_id", player.ID)
+	player.UpdatedAt = updatedNow()
+	return s.playerRepo.CreatePlayer(ctx, player)
 }
 
 // GetPlayerByID fetches player details by ID.
-func (s *playerService) GetPlayerByID(id string) (*domain.Player, error) {
+func (s *playerService) GetPlayerByID(ctx context.Context, id string) (*domain.Player, error) {
+	ctx, span := tracing.Start(ctx, "PlayerService.GetPlayerByID")
+	defer span.End()
+
+	if id == "" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "player ID cannot be empty")
+	}
+	logger.FromContext(ctx).Debug("Getting player", "player_id", id)
+	return s.playerRepo.GetPlayerByID(ctx, id)
+}
+
+// ListPlayers returns one page of players matching the filter.
+func (s *playerService) ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error) {
+	ctx, span := tracing.Start(ctx, "PlayerService.ListPlayers")
+	defer span.End()
+
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	logger.FromContext(ctx).Debug("Listing players", "filter", filter)
+	return s.playerRepo.ListPlayers(ctx, filter)
+}
+
+// UpdatePlayer validates and replaces an existing player. A change of team takes
+// effect now; see PlayerRepository.UpdatePlayer.
+func (s *playerService) UpdatePlayer(ctx context.Context, player *domain.Player) error {
+	ctx, span := tracing.Start(ctx, "PlayerService.UpdatePlayer")
+	defer span.End()
+
+	if err := validator.ValidatePlayerUpdate(player); err != nil {
+		return err
+	}
+	current, err := s.playerRepo.GetPlayerByID(ctx, player.ID)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Updating player", "player_id", player.ID)
+	player.UpdatedAt = updatedNow()
+	if err := s.playerRepo.UpdatePlayer(ctx, player); err != nil {
+		return err
+	}
+	if current.TeamID != player.TeamID {
+		invalidateStats(s.invalidators, []string{player.ID}, []string{current.TeamID, player.TeamID})
+	}
+	return nil
+}
+
+// PatchPlayer applies a partial update to an existing player and returns the result.
+func (s *playerService) PatchPlayer(ctx context.Context, id string, patch *domain.PlayerPatch) (*domain.Player, error) {
+	ctx, span := tracing.Start(ctx, "PlayerService.PatchPlayer")
+	defer span.End()
+
+	player, err := s.GetPlayerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Name != nil {
+		player.Name = *patch.Name
+	}
+	if patch.TeamID != nil {
+		player.TeamID = *patch.TeamID
+	}
+	if err := s.UpdatePlayer(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// DeletePlayer removes a player and the statistics logged for them.
+func (s *playerService) DeletePlayer(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "PlayerService.DeletePlayer")
+	defer span.End()
+
 	if id == "" {
-		return nil, errors.New("player ID cannot be empty")
+		return domain.Errorf(domain.ErrInvalidInput, "player ID cannot be empty")
+	}
+	teamIDs, err := s.playerRepo.LineTeams(ctx, id)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Deleting player", "player_id", id)
+	if err := s.playerRepo.DeletePlayer(ctx, id); err != nil {
+		return err
+	}
+	invalidateStats(s.invalidators, []string{id}, teamIDs)
+	return nil
+}
+
+// RecordTransaction validates a roster transaction against the player's current team
+// and applies it. A trade moves a player from their team to another and a waiver takes
+// them off their team, while a signing or two-way contract puts a free agent on a
+// team, or converts the contract of a player already on it. A transaction that does
+// not fit the player's current team is reported as domain.ErrConflict.
+func (s *playerService) RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error {
+	ctx, span := tracing.Start(ctx, "PlayerService.RecordTransaction")
+	defer span.End()
+
+	if err := validator.ValidateRosterTransaction(transaction); err != nil {
+		return err
+	}
+	player, err := s.playerRepo.GetPlayerByID(ctx, transaction.PlayerID)
+	if err != nil {
+		return err
+	}
+	switch transaction.Type {
+	case domain.TransactionTrade:
+		if player.TeamID == "" {
+			return domain.Errorf(domain.ErrConflict, "player %s is a free agent and cannot be traded", player.ID)
+		}
+		if player.TeamID == transaction.TeamID {
+			return domain.Errorf(domain.ErrConflict, "player %s is already on team %s", player.ID, player.TeamID)
+		}
+	case domain.TransactionWaiver:
+		if player.TeamID == "" {
+			return domain.Errorf(domain.ErrConflict, "player %s is not on a team", player.ID)
+		}
+	default:
+		if player.TeamID != "" && player.TeamID != transaction.TeamID {
+			return domain.Errorf(domain.ErrConflict, "player %s is on team %s and must be traded or waived first", player.ID, player.TeamID)
+		}
+	}
+
+	logger.FromContext(ctx).Info("Recording roster transaction", "player_id", player.ID, "type", transaction.Type, "team_id", transaction.TeamID)
+	transaction.Date = transaction.Date.UTC()
+	transaction.RecordedAt = updatedNow()
+	if err := s.playerRepo.RecordTransaction(ctx, transaction); err != nil {
+		return err
+	}
+	invalidateStats(s.invalidators, []string{player.ID}, []string{transaction.FromTeamID, transaction.TeamID})
+	return nil
+}
+
+// ListMemberships returns the teams a player has been on, oldest first.
+func (s *playerService) ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error) {
+	ctx, span := tracing.Start(ctx, "PlayerService.ListMemberships")
+	defer span.End()
+
+	if _, err := s.GetPlayerByID(ctx, playerID); err != nil {
+		return nil, err
 	}
-	logger.Info("Getting player by id: %s", id)
-	return s.playerRepo.GetPlayerByID(id)
+	return s.playerRepo.ListMemberships(ctx, playerID)
 }
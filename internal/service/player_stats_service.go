@@ -2,17 +2,22 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"sort"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 	"github.com/vgeshiktor/nba-stats/pkg/validator"
 )
 
 // PlayerStatsService defines operations for logging player game statistics.
 type PlayerStatsService interface {
-	LogPlayerStats(stats *domain.PlayerGameStats) error
+	LogPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error
+	LogBoxScore(ctx context.Context, gameID string, boxScore *domain.BoxScore) error
 }
 
 type playerStatsService struct {
@@ -20,38 +25,201 @@ type playerStatsService struct {
 	teamRepo   repository.TeamRepository
 	gameRepo   repository.GameRepository
 	statsRepo  repository.PlayerStatsRepository
+	// invalidators are told about every player whose stat lines were stored.
+	invalidators []StatsInvalidator
 }
 
-// NewPlayerStatsService creates a new instance of PlayerStatsService.
-func NewPlayerStatsService(playerRepo repository.PlayerRepository, teamRepo repository.TeamRepository, gameRepo repository.GameRepository, statsRepo repository.PlayerStatsRepository) PlayerStatsService {
+// NewPlayerStatsService creates a new instance of PlayerStatsService. Each invalidator,
+// such as an AggregationCache, is told about the players, and their teams, whose stats
+// are logged.
+func NewPlayerStatsService(playerRepo repository.PlayerRepository, teamRepo repository.TeamRepository, gameRepo repository.GameRepository, statsRepo repository.PlayerStatsRepository, invalidators ...StatsInvalidator) PlayerStatsService {
 	return &playerStatsService{
-		playerRepo: playerRepo,
-		teamRepo:   teamRepo,
-		gameRepo:   gameRepo,
-		statsRepo:  statsRepo,
+		playerRepo:   playerRepo,
+		teamRepo:     teamRepo,
+		gameRepo:     gameRepo,
+		statsRepo:    statsRepo,
+		invalidators: invalidators,
 	}
 }
 
-// LogPlayerStats validates and stores player game statistics.
-func (s *playerStatsService) LogPlayerStats(stats *domain.PlayerGameStats) error {
+// LogPlayerStats validates and stores player game statistics, attributed to the team
+// the player was on at game time. A line for a player or game that does not exist, or
+// for a player who was not on a team at the time, is reported as domain.ErrValidation,
+// and a line for a game that is scheduled or cancelled as domain.ErrConflict.
+func (s *playerStatsService) LogPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error {
+	ctx, span := tracing.Start(ctx, "PlayerStatsService.LogPlayerStats")
+	defer span.End()
+
 	if err := validator.ValidatePlayerStats(stats); err != nil {
 		return err
 	}
 
-	logger.Info("Log player stats by id: %s", stats.PlayerID)
+	logger.FromContext(ctx).Info("Logging player stats", "player_id", stats.PlayerID, "game_id", stats.GameID)
 
 	// Ensure player exists
-	_, err := s.playerRepo.GetPlayerByID(stats.PlayerID)
+	if _, err := s.playerRepo.GetPlayerByID(ctx, stats.PlayerID); errors.Is(err, domain.ErrNotFound) {
+		return domain.InvalidField("player_id", domain.CodeNotFound, "player %s does not exist", stats.PlayerID)
+	} else if err != nil {
+		return err
+	}
+
+	// Ensure game exists and has started
+	game, err := s.gameRepo.GetGameByID(ctx, stats.GameID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.InvalidField("game_id", domain.CodeNotFound, "game %s does not exist", stats.GameID)
+	}
 	if err != nil {
-		return errors.New("player not found")
+		return err
+	}
+	if err := domain.CheckStatsAccepted(game.ID, game.Status); err != nil {
+		return err
+	}
+	if err := checkMinutes(stats, game); err != nil {
+		return err
 	}
 
-	// Ensure game exists
-	_, err = s.gameRepo.GetGameByID(stats.GameID)
+	// Attribute the line to the player's team at game time
+	teams, err := s.playerRepo.TeamsOn(ctx, []string{stats.PlayerID}, game.Date)
 	if err != nil {
-		return errors.New("game not found")
+		return err
+	}
+	if teams[stats.PlayerID] == "" {
+		return notOnTeam(stats.PlayerID, game)
 	}
+	stats.TeamID = teams[stats.PlayerID]
 
 	// Store the stats
-	return s.statsRepo.InsertPlayerStats(stats)
+	if err := s.statsRepo.InsertPlayerStats(ctx, stats); err != nil {
+		return err
+	}
+	s.invalidate(stats.PlayerID, stats.TeamID)
+	statLinesIngested.Inc("single")
+	return nil
+}
+
+// LogBoxScore validates and stores every player line of a game's box score at once.
+// Lines may omit the game ID and line ID, which default to the game's ID and
+// "{gameID}-{playerID}". All lines are validated and all players are looked up
+// before anything is written; if any line is rejected nothing is stored and a
+// *domain.BatchError lists every rejected line. As for LogPlayerStats, each line is
+// attributed to the team its player was on at game time. Box scores of games that are scheduled
+// or cancelled are rejected with domain.ErrConflict.
+func (s *playerStatsService) LogBoxScore(ctx context.Context, gameID string, boxScore *domain.BoxScore) error {
+	ctx, span := tracing.Start(ctx, "PlayerStatsService.LogBoxScore")
+	defer span.End()
+
+	if gameID == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "game ID cannot be empty")
+	}
+	if len(boxScore.Lines) == 0 {
+		return domain.InvalidField("lines", domain.CodeRequired, "box score has no lines")
+	}
+	game, err := s.gameRepo.GetGameByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if err := domain.CheckStatsAccepted(game.ID, game.Status); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Logging box score", "game_id", gameID, "lines", len(boxScore.Lines))
+
+	var lineErrors []domain.LineError
+	reject := func(i int, err error) {
+		var validationErr *domain.ValidationError
+		errors.As(err, &validationErr)
+		lineErrors = append(lineErrors, domain.LineError{Index: i, PlayerID: boxScore.Lines[i].PlayerID, Errors: validationErr.Fields})
+	}
+
+	seen := make(map[string]bool, len(boxScore.Lines))
+	playerIDs := make([]string, 0, len(boxScore.Lines))
+	valid := make([]int, 0, len(boxScore.Lines))
+	for i := range boxScore.Lines {
+		line := &boxScore.Lines[i]
+		if line.GameID == "" {
+			line.GameID = gameID
+		}
+		if line.GameID != gameID {
+			reject(i, domain.InvalidField("game_id", domain.CodeMismatch, "line is for game %s, not %s", line.GameID, gameID))
+			continue
+		}
+		if line.ID == "" && line.PlayerID != "" {
+			line.ID = gameID + "-" + line.PlayerID
+		}
+		if err := validator.ValidatePlayerStats(line); err != nil {
+			reject(i, err)
+			continue
+		}
+		if err := checkMinutes(line, game); err != nil {
+			reject(i, err)
+			continue
+		}
+		if seen[line.PlayerID] {
+			reject(i, domain.InvalidField("player_id", domain.CodeDuplicate, "player appears more than once in the box score"))
+			continue
+		}
+		seen[line.PlayerID] = true
+		playerIDs = append(playerIDs, line.PlayerID)
+		valid = append(valid, i)
+	}
+
+	// Check every player, and find their team at game time, in a single query each.
+	players, err := s.playerRepo.ListPlayers(ctx, domain.PlayerFilter{IDs: playerIDs})
+	if err != nil {
+		return err
+	}
+	teams, err := s.playerRepo.TeamsOn(ctx, playerIDs, game.Date)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(players))
+	for _, p := range players {
+		known[p.ID] = true
+	}
+	for _, i := range valid {
+		line := &boxScore.Lines[i]
+		switch {
+		case !known[line.PlayerID]:
+			reject(i, domain.InvalidField("player_id", domain.CodeNotFound, "player %s does not exist", line.PlayerID))
+		case teams[line.PlayerID] == "":
+			reject(i, notOnTeam(line.PlayerID, game))
+		default:
+			line.TeamID = teams[line.PlayerID]
+		}
+	}
+
+	if len(lineErrors) > 0 {
+		sort.Slice(lineErrors, func(a, b int) bool { return lineErrors[a].Index < lineErrors[b].Index })
+		return &domain.BatchError{Lines: lineErrors}
+	}
+	if err := s.statsRepo.InsertBoxScore(ctx, boxScore.Lines); err != nil {
+		return err
+	}
+	for _, line := range boxScore.Lines {
+		s.invalidate(line.PlayerID, line.TeamID)
+	}
+	statLinesIngested.Add(float64(len(boxScore.Lines)), "box_score")
+	return nil
+}
+
+// invalidate tells the invalidators that stat lines of the player, for the team, were
+// stored.
+func (s *playerStatsService) invalidate(playerID, teamID string) {
+	for _, invalidator := range s.invalidators {
+		invalidator.InvalidateStats(playerID, teamID)
+	}
+}
+
+// checkMinutes reports a line with more minutes played than the game lasts: regulation
+// and the overtimes its periods record so far.
+func checkMinutes(stats *domain.PlayerGameStats, game *domain.Game) error {
+	if stats.MinutesPlayed > game.Minutes() {
+		return domain.InvalidField("minutes_played", domain.CodeOutOfRange, "minutes_played cannot exceed %g, the length of game %s", game.Minutes(), game.ID)
+	}
+	return nil
+}
+
+// notOnTeam reports a line for a player who was not on a team when the game was played.
+func notOnTeam(playerID string, game *domain.Game) error {
+	return domain.InvalidField("player_id", domain.CodeInvalid, "player %s was not on a team on %s", playerID, game.Date.Format("2006-01-02"))
 }
@@ -0,0 +1,86 @@
+// internal/service/season_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+	"github.com/vgeshiktor/nba-stats/pkg/validator"
+)
+
+// CurrentSeason is the season alias that resolves to the season in progress.
+const CurrentSeason = "current"
+
+// SeasonService defines the methods related to season management.
+type SeasonService interface {
+	CreateSeason(ctx context.Context, season *domain.Season) error
+	GetSeasonByID(ctx context.Context, id string) (*domain.Season, error)
+	ListSeasons(ctx context.Context) ([]domain.Season, error)
+}
+
+type seasonService struct {
+	seasonRepo repository.SeasonRepository
+}
+
+// NewSeasonService creates a new instance of SeasonService.
+func NewSeasonService(seasonRepo repository.SeasonRepository) SeasonService {
+	return &seasonService{seasonRepo: seasonRepo}
+}
+
+// CreateSeason validates and inserts a new season into the database.
+func (s *seasonService) CreateSeason(ctx context.Context, season *domain.Season) error {
+	ctx, span := tracing.Start(ctx, "SeasonService.CreateSeason")
+	defer span.End()
+
+	if err := validator.ValidateSeason(season); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Creating season", "season_id", season.ID)
+	return s.seasonRepo.CreateSeason(ctx, season)
+}
+
+// GetSeasonByID fetches season details by ID.
+func (s *seasonService) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	ctx, span := tracing.Start(ctx, "SeasonService.GetSeasonByID")
+	defer span.End()
+
+	if id == "" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "season ID cannot be empty")
+	}
+	logger.FromContext(ctx).Debug("Getting season", "season_id", id)
+	return s.seasonRepo.GetSeasonByID(ctx, id)
+}
+
+// ListSeasons returns all seasons, most recent first.
+func (s *seasonService) ListSeasons(ctx context.Context) ([]domain.Season, error) {
+	ctx, span := tracing.Start(ctx, "SeasonService.ListSeasons")
+	defer span.End()
+
+	return s.seasonRepo.ListSeasons(ctx)
+}
+
+// resolveStatsFilter validates a stats filter and expands the "current" season
+// alias into the ID of the season containing the given time. An invalid season type
+// is reported as domain.ErrInvalidInput, and a missing current season as domain.ErrNotFound.
+func resolveStatsFilter(ctx context.Context, seasonRepo repository.SeasonRepository, filter domain.StatsFilter, now time.Time) (domain.StatsFilter, error) {
+	if err := validator.ValidateSeasonType(filter.SeasonType); err != nil {
+		return filter, domain.Errorf(domain.ErrInvalidInput, "%s", err)
+	}
+	if filter.SeasonID == CurrentSeason {
+		season, err := seasonRepo.GetSeasonByDate(ctx, now)
+		if errors.Is(err, domain.ErrNotFound) {
+			return filter, domain.Errorf(domain.ErrNotFound, "no season is currently in progress")
+		}
+		if err != nil {
+			return filter, err
+		}
+		filter.SeasonID = season.ID
+	}
+	return filter, nil
+}
@@ -0,0 +1,78 @@
+// internal/service/standings_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/standings"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// StandingsService defines operations for computing standings.
+type StandingsService interface {
+	GetStandings(ctx context.Context, filter domain.StandingsFilter) (*domain.Standings, error)
+}
+
+type standingsService struct {
+	gameRepo   repository.GameRepository
+	teamRepo   repository.TeamRepository
+	seasonRepo repository.SeasonRepository
+}
+
+// NewStandingsService creates a new instance of StandingsService.
+func NewStandingsService(gameRepo repository.GameRepository, teamRepo repository.TeamRepository, seasonRepo repository.SeasonRepository) StandingsService {
+	return &standingsService{gameRepo: gameRepo, teamRepo: teamRepo, seasonRepo: seasonRepo}
+}
+
+// GetStandings computes a season's standings from its final regular-season games. A
+// filter without a season selects the current one. An unknown group is reported as
+// domain.ErrInvalidInput, and a season that does not exist, or no season in progress,
+// as domain.ErrNotFound.
+func (s *standingsService) GetStandings(ctx context.Context, filter domain.StandingsFilter) (*domain.Standings, error) {
+	ctx, span := tracing.Start(ctx, "StandingsService.GetStandings")
+	defer span.End()
+
+	switch filter.Group {
+	case "":
+		filter.Group = domain.StandingsGroupConference
+	case domain.StandingsGroupLeague, domain.StandingsGroupConference, domain.StandingsGroupDivision:
+	default:
+		return nil, domain.Errorf(domain.ErrInvalidInput, "group must be %q, %q or %q",
+			domain.StandingsGroupLeague, domain.StandingsGroupConference, domain.StandingsGroupDivision)
+	}
+	if filter.SeasonID == "" {
+		filter.SeasonID = CurrentSeason
+	}
+	statsFilter, err := resolveStatsFilter(ctx, s.seasonRepo, domain.StatsFilter{SeasonID: filter.SeasonID}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	season, err := s.seasonRepo.GetSeasonByID(ctx, statsFilter.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Debug("Computing standings", "season_id", season.ID, "group", filter.Group)
+	games, err := s.gameRepo.ListGames(ctx, domain.GameFilter{
+		SeasonID:   season.ID,
+		SeasonType: domain.SeasonTypeRegular,
+		Status:     domain.GameStatusFinal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.teamRepo.ListTeams(ctx, domain.TeamFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Standings{
+		SeasonID: season.ID,
+		Group:    filter.Group,
+		Groups:   standings.Compute(teams, games, filter.Group),
+	}, nil
+}
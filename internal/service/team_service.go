@@ -2,18 +2,24 @@
 package service
 
 import (
-	"errors"
+	"context"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 
-	"github.com/vgeshiktor/nba-stats/pkg/validator"
 	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+	"github.com/vgeshiktor/nba-stats/pkg/validator"
 )
 
 // TeamService defines the methods related to team management.
 type TeamService interface {
-	CreateTeam(team *domain.Team) error
-	GetTeamByID(id string) (*domain.Team, error)
+	CreateTeam(ctx context.Context, team *domain.Team) error
+	GetTeamByID(ctx context.Context, id string) (*domain.Team, error)
+	ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error)
+	UpdateTeam(ctx context.Context, team *domain.Team) error
+	PatchTeam(ctx context.Context, id string, patch *domain.TeamPatch) (*domain.Team, error)
+	DeleteTeam(ctx context.Context, id string) error
 }
 
 type teamService struct {
@@ -26,20 +32,86 @@ func NewTeamService(teamRepo repository.TeamRepository) TeamService {
 }
 
 // CreateTeam validates and inserts a new team into the database.
-func (s *teamService) CreateTeam(team *domain.Team) error {
+func (s *teamService) CreateTeam(ctx context.Context, team *domain.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamService.CreateTeam")
+	defer span.End()
+
 	if err := validator.ValidateTeam(team); err != nil {
 		return err
 	}
 
-	return s.teamRepo.CreateTeam(team)
+	team.UpdatedAt = updatedNow()
+	return s.teamRepo.CreateTeam(ctx, team)
 }
 
 // GetTeamByID fetches team details by ID.
-func (s *teamService) GetTeamByID(id string) (*domain.Team, error) {
+func (s *teamService) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamService.GetTeamByID")
+	defer span.End()
+
 	if id == "" {
-		return nil, errors.New("team ID cannot be empty")
+		return nil, domain.Errorf(domain.ErrInvalidInput, "team ID cannot be empty")
 	}
-	logger.Info("Getting team by id: %s", id)
+	logger.FromContext(ctx).Debug("Getting team", "team_id", id)
+
+	return s.teamRepo.GetTeamByID(ctx, id)
+}
 
-	return s.teamRepo.GetTeamByID(id)
+// ListTeams returns one page of teams matching the filter.
+func (s *teamService) ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamService.ListTeams")
+	defer span.End()
+
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	logger.FromContext(ctx).Debug("Listing teams", "filter", filter)
+	return s.teamRepo.ListTeams(ctx, filter)
+}
+
+// UpdateTeam validates and replaces an existing team.
+func (s *teamService) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamService.UpdateTeam")
+	defer span.End()
+
+	if err := validator.ValidateTeam(team); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Updating team", "team_id", team.ID)
+	team.UpdatedAt = updatedNow()
+	return s.teamRepo.UpdateTeam(ctx, team)
+}
+
+// PatchTeam applies a partial update to an existing team and returns the result.
+func (s *teamService) PatchTeam(ctx context.Context, id string, patch *domain.TeamPatch) (*domain.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamService.PatchTeam")
+	defer span.End()
+
+	team, err := s.GetTeamByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Name != nil {
+		team.Name = *patch.Name
+	}
+	if patch.Conference != nil {
+		team.Conference = *patch.Conference
+	}
+	if patch.Division != nil {
+		team.Division = *patch.Division
+	}
+	if err := s.UpdateTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// DeleteTeam removes a team that no longer has players or games attached.
+func (s *teamService) DeleteTeam(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "TeamService.DeleteTeam")
+	defer span.End()
+
+	if id == "" {
+		return domain.Errorf(domain.ErrInvalidInput, "team ID cannot be empty")
+	}
+	logger.FromContext(ctx).Info("Deleting team", "team_id", id)
+	return s.teamRepo.DeleteTeam(ctx, id)
 }
@@ -0,0 +1,11 @@
+// internal/service/timestamps.go
+package service
+
+import "time"
+
+// updatedNow returns the time to record as a resource's updated_at: now, in UTC and
+// truncated to the second, the precision of Last-Modified and If-Modified-Since.
+func updatedNow() *time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &now
+}
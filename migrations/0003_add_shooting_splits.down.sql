@@ -0,0 +1,8 @@
+ALTER TABLE player_game_stats DROP COLUMN defensive_rebounds;
+ALTER TABLE player_game_stats DROP COLUMN offensive_rebounds;
+ALTER TABLE player_game_stats DROP COLUMN free_throws_attempted;
+ALTER TABLE player_game_stats DROP COLUMN free_throws_made;
+ALTER TABLE player_game_stats DROP COLUMN three_pointers_attempted;
+ALTER TABLE player_game_stats DROP COLUMN three_pointers_made;
+ALTER TABLE player_game_stats DROP COLUMN field_goals_attempted;
+ALTER TABLE player_game_stats DROP COLUMN field_goals_made;
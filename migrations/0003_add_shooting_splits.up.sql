@@ -0,0 +1,9 @@
+-- Record how points were scored and split rebounds into offensive and defensive
+ALTER TABLE player_game_stats ADD COLUMN field_goals_made INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN field_goals_attempted INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN three_pointers_made INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN three_pointers_attempted INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN free_throws_made INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN free_throws_attempted INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN offensive_rebounds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_game_stats ADD COLUMN defensive_rebounds INTEGER NOT NULL DEFAULT 0;
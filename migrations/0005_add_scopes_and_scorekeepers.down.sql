@@ -0,0 +1,2 @@
+DROP TABLE game_scorekeepers;
+ALTER TABLE api_keys DROP COLUMN scopes;
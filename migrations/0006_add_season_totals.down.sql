@@ -0,0 +1,4 @@
+DROP INDEX idx_player_game_stats_game;
+DROP INDEX idx_player_game_stats_player_game;
+DROP TABLE team_season_totals;
+DROP TABLE player_season_totals;
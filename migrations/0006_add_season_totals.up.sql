@@ -0,0 +1,82 @@
+-- Season totals per player and per team, maintained with every stat line so that
+-- aggregates are read from a few rows instead of summed over player_game_stats.
+-- Games without a season are totaled under the season ID ''.
+CREATE TABLE player_season_totals (
+    player_id TEXT NOT NULL,
+    season_id TEXT NOT NULL,
+    season_type TEXT NOT NULL,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    rebounds INTEGER NOT NULL DEFAULT 0,
+    assists INTEGER NOT NULL DEFAULT 0,
+    steals INTEGER NOT NULL DEFAULT 0,
+    blocks INTEGER NOT NULL DEFAULT 0,
+    fouls INTEGER NOT NULL DEFAULT 0,
+    turnovers INTEGER NOT NULL DEFAULT 0,
+    minutes_played FLOAT NOT NULL DEFAULT 0,
+    field_goals_made INTEGER NOT NULL DEFAULT 0,
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0,
+    three_pointers_made INTEGER NOT NULL DEFAULT 0,
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0,
+    free_throws_made INTEGER NOT NULL DEFAULT 0,
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0,
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, season_id, season_type)
+);
+
+CREATE TABLE team_season_totals (
+    team_id TEXT NOT NULL,
+    season_id TEXT NOT NULL,
+    season_type TEXT NOT NULL,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    rebounds INTEGER NOT NULL DEFAULT 0,
+    assists INTEGER NOT NULL DEFAULT 0,
+    steals INTEGER NOT NULL DEFAULT 0,
+    blocks INTEGER NOT NULL DEFAULT 0,
+    fouls INTEGER NOT NULL DEFAULT 0,
+    turnovers INTEGER NOT NULL DEFAULT 0,
+    minutes_played FLOAT NOT NULL DEFAULT 0,
+    field_goals_made INTEGER NOT NULL DEFAULT 0,
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0,
+    three_pointers_made INTEGER NOT NULL DEFAULT 0,
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0,
+    free_throws_made INTEGER NOT NULL DEFAULT 0,
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0,
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (team_id, season_id, season_type)
+);
+
+-- Find the other lines of a player, or of a team, in a game when a line is added.
+CREATE INDEX idx_player_game_stats_player_game ON player_game_stats (player_id, game_id);
+CREATE INDEX idx_player_game_stats_game ON player_game_stats (game_id);
+
+-- Backfill from the stat lines logged so far.
+INSERT INTO player_season_totals (player_id, season_id, season_type, games_played, points, rebounds, assists,
+    steals, blocks, fouls, turnovers, minutes_played, field_goals_made, field_goals_attempted,
+    three_pointers_made, three_pointers_attempted, free_throws_made, free_throws_attempted,
+    offensive_rebounds, defensive_rebounds)
+SELECT ps.player_id, COALESCE(g.season_id, ''), g.season_type, COUNT(DISTINCT ps.game_id),
+    SUM(ps.points), SUM(ps.rebounds), SUM(ps.assists), SUM(ps.steals), SUM(ps.blocks), SUM(ps.fouls),
+    SUM(ps.turnovers), SUM(ps.minutes_played), SUM(ps.field_goals_made), SUM(ps.field_goals_attempted),
+    SUM(ps.three_pointers_made), SUM(ps.three_pointers_attempted), SUM(ps.free_throws_made),
+    SUM(ps.free_throws_attempted), SUM(ps.offensive_rebounds), SUM(ps.defensive_rebounds)
+FROM player_game_stats ps
+JOIN games g ON g.id = ps.game_id
+GROUP BY ps.player_id, COALESCE(g.season_id, ''), g.season_type;
+
+INSERT INTO team_season_totals (team_id, season_id, season_type, games_played, points, rebounds, assists,
+    steals, blocks, fouls, turnovers, minutes_played, field_goals_made, field_goals_attempted,
+    three_pointers_made, three_pointers_attempted, free_throws_made, free_throws_attempted,
+    offensive_rebounds, defensive_rebounds)
+SELECT p.team_id, COALESCE(g.season_id, ''), g.season_type, COUNT(DISTINCT ps.game_id),
+    SUM(ps.points), SUM(ps.rebounds), SUM(ps.assists), SUM(ps.steals), SUM(ps.blocks), SUM(ps.fouls),
+    SUM(ps.turnovers), SUM(ps.minutes_played), SUM(ps.field_goals_made), SUM(ps.field_goals_attempted),
+    SUM(ps.three_pointers_made), SUM(ps.three_pointers_attempted), SUM(ps.free_throws_made),
+    SUM(ps.free_throws_attempted), SUM(ps.offensive_rebounds), SUM(ps.defensive_rebounds)
+FROM player_game_stats ps
+JOIN players p ON p.id = ps.player_id
+JOIN games g ON g.id = ps.game_id
+GROUP BY p.team_id, COALESCE(g.season_id, ''), g.season_type;
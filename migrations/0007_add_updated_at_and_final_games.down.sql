@@ -0,0 +1,4 @@
+ALTER TABLE games DROP COLUMN final;
+ALTER TABLE games DROP COLUMN updated_at;
+ALTER TABLE teams DROP COLUMN updated_at;
+ALTER TABLE players DROP COLUMN updated_at;
@@ -0,0 +1,26 @@
+-- Games move from scheduled through live to final, or are postponed or cancelled, and
+-- record their score by period. Final games keep their status; other games with stat
+-- lines are taken to be live, and games with stat lines are scored from them.
+ALTER TABLE games ADD COLUMN status TEXT NOT NULL DEFAULT 'scheduled';
+ALTER TABLE games ADD COLUMN home_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN away_score INTEGER NOT NULL DEFAULT 0;
+
+UPDATE games SET status = 'final' WHERE final;
+UPDATE games SET status = 'live'
+WHERE status = 'scheduled' AND EXISTS (SELECT 1 FROM player_game_stats s WHERE s.game_id = games.id);
+UPDATE games SET
+    home_score = (SELECT COALESCE(SUM(s.points), 0) FROM player_game_stats s JOIN players p ON p.id = s.player_id
+                  WHERE s.game_id = games.id AND p.team_id = games.home_team),
+    away_score = (SELECT COALESCE(SUM(s.points), 0) FROM player_game_stats s JOIN players p ON p.id = s.player_id
+                  WHERE s.game_id = games.id AND p.team_id = games.away_team);
+
+ALTER TABLE games DROP COLUMN final;
+
+-- Points scored by each team per period: 1 to 4 are the quarters, later periods overtimes.
+CREATE TABLE game_periods (
+    game_id TEXT NOT NULL REFERENCES games(id),
+    period INTEGER NOT NULL,
+    home_points INTEGER NOT NULL,
+    away_points INTEGER NOT NULL,
+    PRIMARY KEY (game_id, period)
+);
@@ -0,0 +1,4 @@
+DROP INDEX idx_games_season_status;
+
+ALTER TABLE teams DROP COLUMN division;
+ALTER TABLE teams DROP COLUMN conference;
@@ -0,0 +1,26 @@
+-- Each stint of a player on a team, from the transaction that put them on it to the one
+-- that took them off. A stint without a start goes back to before the player's history
+-- was recorded, and one without an end is the player's current team. A player has at
+-- most one current stint; a free agent has none.
+CREATE TABLE roster_memberships (
+    player_id TEXT NOT NULL REFERENCES players(id),
+    seq INTEGER NOT NULL,
+    team_id TEXT NOT NULL,
+    joined_by TEXT NOT NULL,
+    start_date TIMESTAMP NULL,
+    end_date TIMESTAMP NULL,
+    ended_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (player_id, seq)
+);
+
+-- Every existing player has been on their team all along.
+INSERT INTO roster_memberships (player_id, seq, team_id, joined_by)
+SELECT id, 1, team_id, 'signing' FROM players;
+
+-- Each stat line counts towards the team the player was on at game time, so that a
+-- trade does not take the player's past lines along to the new team.
+ALTER TABLE player_game_stats ADD COLUMN team_id TEXT NOT NULL DEFAULT '';
+UPDATE player_game_stats SET team_id = (SELECT p.team_id FROM players p WHERE p.id = player_game_stats.player_id);
+
+-- Team totals are refreshed from the lines of a team.
+CREATE INDEX idx_player_game_stats_team_game ON player_game_stats (team_id, game_id);
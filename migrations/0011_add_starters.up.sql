@@ -0,0 +1,3 @@
+-- Record whether each player started the game, so that box scores can tell starters
+-- from the bench. Existing lines, and lines logged without it, are neither.
+ALTER TABLE player_game_stats ADD COLUMN starter BOOLEAN NULL;
@@ -4,21 +4,37 @@ package errors
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
 )
 
-// APIError represents a structured error response.
-type APIError struct {
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+// ProblemContentType is the media type of problem details responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Problem represents a structured error response in the RFC 7807 problem details format.
+type Problem struct {
+	Type     string              `json:"type"`               // URI identifying the kind of problem.
+	Title    string              `json:"title"`              // Short summary of the problem type.
+	Status   int                 `json:"status"`             // HTTP status code.
+	Detail   string              `json:"detail,omitempty"`   // Explanation specific to this occurrence.
+	Instance string              `json:"instance,omitempty"` // Identifies this occurrence; the request ID.
+	Errors   []domain.FieldError `json:"errors,omitempty"`   // Every invalid field, if the problem is a validation failure.
 }
 
-// WriteError writes a structured error response to the client.
-func WriteError(w http.ResponseWriter, statusCode int, message string) {
-	apiError := APIError{
-		Message: message,
-		Code:    statusCode,
+// NewProblem returns a problem of the generic "about:blank" type, titled after the
+// status code.
+func NewProblem(statusCode int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: detail,
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(apiError)
+}
+
+// WriteProblem writes a problem details response to the client.
+func WriteProblem(w http.ResponseWriter, problem *Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
 }
@@ -0,0 +1,311 @@
+// Package jwt verifies JSON Web Tokens (RFC 7519) signed with HS256 or RS256, and
+// checks their expiry, issuer and audience. Tokens with any other algorithm,
+// including "none", are rejected.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// ErrInvalidToken is wrapped by every error Verify returns.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the registered claims of a token that Verify checks or reports, and the
+// "scope" claim of RFC 8693.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"` // Unix time after which the token is rejected.
+	NotBefore int64    `json:"nbf,omitempty"` // Unix time before which the token is rejected.
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Scope     string   `json:"scope,omitempty"` // Space-separated scopes granted to the bearer.
+}
+
+// Audience is the "aud" claim, which may be a single string or an array of strings.
+type Audience []string
+
+// UnmarshalJSON accepts both forms of the claim.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Key is a key that tokens may be signed with.
+type Key struct {
+	ID        string // Key ID matched against the token's "kid" header; empty matches any token.
+	Algorithm string // HS256 or RS256.
+	Secret    []byte // Shared secret of an HS256 key.
+	PublicKey *rsa.PublicKey
+}
+
+// Config configures a Verifier.
+type Config struct {
+	Keys     []Key
+	Issuer   string        // Required "iss" claim, if set.
+	Audience string        // Value the "aud" claim must contain, if set.
+	Leeway   time.Duration // Allowed clock skew when checking "exp" and "nbf".
+}
+
+// Verifier checks the signature and claims of tokens.
+type Verifier struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewVerifier returns a Verifier for the given configuration.
+func NewVerifier(config Config) *Verifier {
+	return &Verifier{config: config, now: time.Now}
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Verify checks a compact serialized token and returns its claims. The token must be
+// signed by one of the configured keys and carry an "exp" claim.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed token")
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, invalid("malformed header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+	if !v.verifySignature(h, parts[0]+"."+parts[1], signature) {
+		return nil, invalid("signature is not valid")
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalid("malformed claims")
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// verifySignature reports whether any configured key for the token's algorithm and
+// key ID produced the signature.
+func (v *Verifier) verifySignature(h header, signingInput string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signingInput))
+	for _, key := range v.config.Keys {
+		if key.Algorithm != h.Algorithm || (key.ID != "" && h.KeyID != "" && key.ID != h.KeyID) {
+			continue
+		}
+		switch key.Algorithm {
+		case HS256:
+			// An empty secret would let anyone sign tokens.
+			if len(key.Secret) == 0 {
+				continue
+			}
+			mac := hmac.New(sha256.New, key.Secret)
+			mac.Write([]byte(signingInput))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case RS256:
+			if rsa.VerifyPKCS1v15(key.PublicKey, crypto.SHA256, digest[:], signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkClaims checks the time window, issuer and audience of a token.
+func (v *Verifier) checkClaims(claims *Claims) error {
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return invalid("token has no expiry")
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.config.Leeway)) {
+		return invalid("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-v.config.Leeway)) {
+		return invalid("token is not valid yet")
+	}
+	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
+		return invalid("token has issuer %q, expected %q", claims.Issuer, v.config.Issuer)
+	}
+	if v.config.Audience != "" && !contains(claims.Audience, v.config.Audience) {
+		return invalid("token is not intended for audience %q", v.config.Audience)
+	}
+	return nil
+}
+
+// SignHS256 returns a token with the given claims signed with an HS256 secret. The
+// server only verifies tokens; this is meant for tests and local tooling.
+func SignHS256(claims Claims, keyID string, secret []byte) (string, error) {
+	return sign(claims, header{Algorithm: HS256, KeyID: keyID, Type: "JWT"}, func(input []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	})
+}
+
+// SignRS256 returns a token with the given claims signed with an RSA private key.
+func SignRS256(claims Claims, keyID string, privateKey *rsa.PrivateKey) (string, error) {
+	return sign(claims, header{Algorithm: RS256, KeyID: keyID, Type: "JWT"}, func(input []byte) ([]byte, error) {
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	})
+}
+
+// sign serializes the header and claims and appends the signature computed over them.
+func sign(claims Claims, h header, signer func([]byte) ([]byte, error)) (string, error) {
+	encodedHeader, err := encodeSegment(h)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodedHeader + "." + encodedClaims
+	signature, err := signer([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ParseRSAPublicKey parses a PEM encoded RSA public key, either as a PKIX
+// "PUBLIC KEY" block or a PKCS #1 "RSA PUBLIC KEY" block.
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// jsonWebKey is a key of a JWKS document (RFC 7517).
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n"` // RSA modulus.
+	E         string `json:"e"` // RSA public exponent.
+	K         string `json:"k"` // Symmetric key value.
+}
+
+// ParseJWKS parses a JWKS document into keys. RSA keys are used for RS256 and
+// symmetric ("oct") keys for HS256; encryption keys and keys for other algorithms
+// are skipped.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	var keys []Key
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch {
+		case jwk.KeyType == "RSA" && (jwk.Algorithm == "" || jwk.Algorithm == RS256):
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: malformed modulus", jwk.KeyID)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			if err != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("key %q: malformed exponent", jwk.KeyID)
+			}
+			publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			keys = append(keys, Key{ID: jwk.KeyID, Algorithm: RS256, PublicKey: publicKey})
+		case jwk.KeyType == "oct" && (jwk.Algorithm == "" || jwk.Algorithm == HS256):
+			secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: malformed key value", jwk.KeyID)
+			}
+			keys = append(keys, Key{ID: jwk.KeyID, Algorithm: HS256, Secret: secret})
+		}
+	}
+	return keys, nil
+}
+
+// invalid returns an error wrapping ErrInvalidToken with the given reason.
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token into v.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeSegment encodes v as a base64url encoded JSON segment of a token.
+func encodeSegment(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// contains reports whether the audience includes the given value.
+func contains(audience Audience, value string) bool {
+	for _, a := range audience {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
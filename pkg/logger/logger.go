@@ -1,62 +1,362 @@
-// pkg/logger/logger.go
+// Package logger writes leveled, structured log entries as lines of JSON. An entry
+// has a message and key/value fields:
+//
+//	logger.Info("Server started", "addr", addr)
+//	logger.FromContext(ctx).Warn("Rate limit store failed", "error", err)
+//
+// Entries below the level set with SetLevel are discarded before any work is done
+// to format them. A Logger returned by FromContext adds the request ID, the fields
+// stored with ContextWith, such as the principal, and the current trace and span of
+// the context. Fields whose key names a credential, such as "authorization" or
+// "password", are redacted.
 package logger
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
 )
 
-// LogEntry defines the structure of log messages in JSON format.
-type LogEntry struct {
-	Level     string `json:"level"`
-	Timestamp string `json:"timestamp"`
-	File      string `json:"file,omitempty"`
-	Message   string `json:"message"`
+// Level is the severity of an entry.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelOff discards every entry when set with SetLevel.
+	LevelOff
+)
+
+var levelNames = [...]string{"DEBUG", "INFO", "WARN", "ERROR", "OFF"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelOff {
+		return fmt.Sprintf("LEVEL(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN"; "warning" and "none" are
+// accepted for LevelWarn and LevelOff.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	case "OFF", "NONE":
+		return LevelOff, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
 }
 
-// Logger instance
 var (
-	infoLogger  = log.New(os.Stdout, "", log.Lshortfile)
-	errorLogger = log.New(os.Stderr, "", log.Lshortfile)
+	level atomic.Int32 // The minimum Level written; LevelInfo until SetLevel is called.
+
+	outputMu sync.Mutex
+	stdout   io.Writer = os.Stdout // Receives DEBUG and INFO entries.
+	stderr   io.Writer = os.Stderr // Receives WARN and ERROR entries.
 )
 
-// logJSON creates a JSON log entry.
-func logJSON(level, format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
-	_, file, line, _ := runtime.Caller(2) // Get caller file and line number
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the minimum level of the entries that are written.
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+// GetLevel returns the minimum level of the entries that are written.
+func GetLevel() Level {
+	return Level(level.Load())
+}
 
-	logEntry := LogEntry{
-		Level:     level,
-		Timestamp: time.Now().Format(time.RFC3339),
-		File:      fmt.Sprintf("%s:%d", file, line),
-		Message:   message,
+// SetOutput sends every entry to w instead of standard output and standard error,
+// e.g. to capture them in tests. A nil w restores the defaults.
+func SetOutput(w io.Writer) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if w == nil {
+		stdout, stderr = os.Stdout, os.Stderr
+		return
 	}
+	stdout, stderr = w, w
+}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(logEntry)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, `{"level": "ERROR", "message": "Failed to log message"}`)
+// Logger writes entries with a fixed set of fields. The zero value is ready to use
+// and Loggers are safe for concurrent use; methods that add to a Logger return a new one.
+type Logger struct {
+	ctx     context.Context // Source of the fields added by FromContext, if not nil.
+	fields  []any           // Key/value pairs.
+	sampler *Sampler
+}
+
+var root = &Logger{}
+
+// With returns a Logger that adds the given key/value pairs to every entry.
+func (l *Logger) With(keyvals ...any) *Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+	fields := make([]any, 0, len(l.fields)+len(keyvals))
+	fields = append(append(fields, l.fields...), keyvals...)
+	return &Logger{ctx: l.ctx, fields: fields, sampler: l.sampler}
+}
+
+// Sampled returns a Logger whose DEBUG and INFO entries are thinned out by s, for
+// messages logged on hot paths.
+func (l *Logger) Sampled(s *Sampler) *Logger {
+	return &Logger{ctx: l.ctx, fields: l.fields, sampler: s}
+}
+
+// Enabled reports whether entries of the level are written, so that callers can skip
+// computing expensive fields.
+func (l *Logger) Enabled(lvl Level) bool {
+	return lvl >= GetLevel() && lvl < LevelOff
+}
+
+// Debug logs a message useful when diagnosing a problem, such as a query being run.
+func (l *Logger) Debug(msg string, keyvals ...any) { l.log(LevelDebug, msg, keyvals) }
+
+// Info logs a message about the normal operation of the service.
+func (l *Logger) Info(msg string, keyvals ...any) { l.log(LevelInfo, msg, keyvals) }
+
+// Warn logs a message about a problem the service recovered from.
+func (l *Logger) Warn(msg string, keyvals ...any) { l.log(LevelWarn, msg, keyvals) }
+
+// Error logs a message about a failed operation.
+func (l *Logger) Error(msg string, keyvals ...any) { l.log(LevelError, msg, keyvals) }
+
+// With returns a Logger that adds the given key/value pairs to every entry.
+func With(keyvals ...any) *Logger { return root.With(keyvals...) }
+
+// Debug logs a message at LevelDebug without fields from a context.
+func Debug(msg string, keyvals ...any) { root.log(LevelDebug, msg, keyvals) }
+
+// Info logs a message at LevelInfo without fields from a context.
+func Info(msg string, keyvals ...any) { root.log(LevelInfo, msg, keyvals) }
+
+// Warn logs a message at LevelWarn without fields from a context.
+func Warn(msg string, keyvals ...any) { root.log(LevelWarn, msg, keyvals) }
+
+// Error logs a message at LevelError without fields from a context.
+func Error(msg string, keyvals ...any) { root.log(LevelError, msg, keyvals) }
+
+type requestIDKey struct{}
+type fieldsKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the request it serves,
+// which every entry logged with FromContext includes.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextWith returns a copy of ctx carrying key/value pairs, added to those already
+// in ctx, which every entry logged with FromContext includes.
+func ContextWith(ctx context.Context, keyvals ...any) context.Context {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	fields = append(fields[:len(fields):len(fields)], keyvals...)
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// FromContext returns a Logger that adds the request ID of ctx, the fields stored with
+// ContextWith and the trace and span IDs of the current span to every entry. They are
+// only read from ctx for entries that are written.
+func FromContext(ctx context.Context) *Logger {
+	return &Logger{ctx: ctx}
+}
+
+// contextFields returns the fields FromContext adds to the entries logged with ctx.
+func contextFields(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	var fields []any
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, "request_id", requestID)
+	}
+	stored, _ := ctx.Value(fieldsKey{}).([]any)
+	fields = append(fields, stored...)
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, "trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String())
+	}
+	return fields
+}
+
+// log writes an entry if its level is enabled and the sampler lets it through. It must
+// be called directly by the exported methods, so that the caller is found at a fixed
+// depth, which is only looked up for entries that are written.
+func (l *Logger) log(lvl Level, msg string, keyvals []any) {
+	if !l.Enabled(lvl) {
+		return
+	}
+	if lvl < LevelWarn && l.sampler != nil && !l.sampler.allow(msg) {
 		return
 	}
 
-	// Print JSON logs
-	if level == "ERROR" {
-		errorLogger.Println(string(jsonData))
-	} else {
-		infoLogger.Println(string(jsonData))
+	var buf bytes.Buffer
+	buf.WriteString(`{"level":`)
+	writeJSON(&buf, lvl.String())
+	buf.WriteString(`,"timestamp":`)
+	writeJSON(&buf, time.Now().Format(time.RFC3339))
+	if _, file, line, ok := runtime.Caller(2); ok {
+		buf.WriteString(`,"caller":`)
+		writeJSON(&buf, fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(file)), filepath.Base(file), line))
+	}
+	buf.WriteString(`,"message":`)
+	writeJSON(&buf, msg)
+	writeFields(&buf, contextFields(l.ctx))
+	writeFields(&buf, l.fields)
+	writeFields(&buf, keyvals)
+	buf.WriteString("}\n")
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	w := stdout
+	if lvl >= LevelWarn {
+		w = stderr
+	}
+	w.Write(buf.Bytes())
+}
+
+// badKey is the key of a value passed without one.
+const badKey = "!BADKEY"
+
+// writeFields writes key/value pairs as members of a JSON object. A key that is not a
+// string, or a trailing value without a key, is written as the value of badKey.
+func writeFields(buf *bytes.Buffer, keyvals []any) {
+	for i := 0; i < len(keyvals); i++ {
+		key, ok := keyvals[i].(string)
+		var value any
+		switch {
+		case !ok:
+			key, value = badKey, keyvals[i]
+		case i+1 < len(keyvals):
+			i++
+			value = keyvals[i]
+		default:
+			key, value = badKey, key
+		}
+		buf.WriteByte(',')
+		writeJSON(buf, key)
+		buf.WriteByte(':')
+		writeJSON(buf, redact(key, value))
+	}
+}
+
+// writeJSON writes v as JSON. Errors and fmt.Stringers are written as their text, and
+// values that cannot be marshaled as formatted with %+v.
+func writeJSON(buf *bytes.Buffer, v any) {
+	switch value := v.(type) {
+	case error:
+		v = value.Error()
+	case fmt.Stringer:
+		v = value.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%+v", v))
+	}
+	buf.Write(data)
+}
+
+// Redacted replaces the value of a sensitive field.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of field keys and header names whose values are
+// credentials, compared case-insensitively.
+var sensitiveKeys = []string{"authorization", "cookie", "password", "secret", "token", "api-key", "api_key", "apikey"}
+
+// isSensitive reports whether a field key or header name names a credential.
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns Redacted for the value of a sensitive key. Headers are copied with
+// the values of their sensitive headers redacted, so that they can be logged whole.
+func redact(key string, value any) any {
+	if isSensitive(key) {
+		return Redacted
+	}
+	if header, ok := value.(http.Header); ok {
+		redacted := make(http.Header, len(header))
+		for name, values := range header {
+			if isSensitive(name) {
+				values = []string{Redacted}
+			}
+			redacted[name] = values
+		}
+		return redacted
 	}
+	return value
+}
+
+// Sampler thins out entries logged on hot paths: of the entries with the same message
+// in each interval, it lets the first through, then every thereafter-th.
+type Sampler struct {
+	first, thereafter int
+	interval          time.Duration
+
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+// sampleCount counts the entries with a message in the current interval.
+type sampleCount struct {
+	start time.Time
+	n     int
 }
 
-// Info logs informational messages in JSON format.
-func Info(format string, v ...interface{}) {
-	logJSON("INFO", format, v...)
+// NewSampler returns a Sampler letting through, per message and interval, the first
+// entries, then every thereafter-th entry, or none if thereafter is 0.
+func NewSampler(first, thereafter int, interval time.Duration) *Sampler {
+	return &Sampler{first: first, thereafter: thereafter, interval: interval, counts: make(map[string]*sampleCount)}
 }
 
-// Error logs error messages in JSON format.
-func Error(format string, v ...interface{}) {
-	logJSON("ERROR", format, v...)
+// allow reports whether an entry with the message is written.
+func (s *Sampler) allow(msg string) bool {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.counts[msg]
+	if !ok || now.Sub(count.start) >= s.interval {
+		count = &sampleCount{start: now}
+		s.counts[msg] = count
+	}
+	count.n++
+	if count.n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (count.n-s.first)%s.thereafter == 0
 }
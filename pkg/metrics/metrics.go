@@ -0,0 +1,345 @@
+// Package metrics keeps counters, histograms and gauges and exposes them in the
+// Prometheus text format (version 0.0.4), so that they can be scraped from /metrics.
+// Metrics are registered by name in a Registry; Default is the registry of the process.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of a latency histogram.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric is a registered metric, able to write its samples.
+type metric interface {
+	kind() string
+	write(w io.Writer, name string)
+}
+
+// Registry holds metrics by name.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+	help    map[string]string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric), help: make(map[string]string)}
+}
+
+// Default is the registry of the process, exposed by Handler.
+var Default = NewRegistry()
+
+// register adds a metric to the registry. A metric registered under a name that is
+// taken replaces the previous one, so that initializing a component again, as tests
+// do, does not fail.
+func (r *Registry) register(name, help string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name] = m
+	r.help[name] = help
+}
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{series: newSeries[float64](labels)}
+	r.register(name, help, c)
+	return c
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds, in increasing
+// order, and label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	bounds := append(append([]float64(nil), buckets...), math.Inf(1))
+	h := &Histogram{series: newSeries[histogramValue](labels), bounds: bounds}
+	r.register(name, help, h)
+	return h
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn when the metrics are written.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, funcMetric{typ: "gauge", fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value is read from fn when the metrics are
+// written; fn must never return a lower value than before.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, funcMetric{typ: "counter", fn: fn})
+}
+
+// WriteTo writes every metric in the text exposition format, ordered by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]metric, len(names))
+	help := make([]string, len(names))
+	for i, name := range names {
+		metrics[i], help[i] = r.metrics[name], r.help[name]
+	}
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	for i, name := range names {
+		fmt.Fprintf(cw, "# HELP %s %s\n", name, escapeHelp(help[i]))
+		fmt.Fprintf(cw, "# TYPE %s %s\n", name, metrics[i].kind())
+		metrics[i].write(cw, name)
+	}
+	return cw.n, cw.err
+}
+
+// Handler returns an http.Handler serving the metrics of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteTo(w)
+	})
+}
+
+// NewCounter registers a counter in the Default registry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewHistogram registers a histogram in the Default registry.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewGaugeFunc registers a gauge in the Default registry.
+func NewGaugeFunc(name, help string, fn func() float64) {
+	Default.NewGaugeFunc(name, help, fn)
+}
+
+// NewCounterFunc registers a counter in the Default registry.
+func NewCounterFunc(name, help string, fn func() float64) {
+	Default.NewCounterFunc(name, help, fn)
+}
+
+// Handler returns an http.Handler serving the metrics of the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// series holds the values of a metric per combination of label values.
+type series[T any] struct {
+	mu     sync.Mutex
+	labels []string
+	values map[string]*labeled[T]
+}
+
+// labeled is the value of a metric for one combination of label values.
+type labeled[T any] struct {
+	labels string // Formatted label pairs, e.g. `method="GET",route="/players"`.
+	value  T
+}
+
+func newSeries[T any](labels []string) series[T] {
+	return series[T]{labels: labels, values: make(map[string]*labeled[T])}
+}
+
+// with returns the value for the label values, creating it if needed; the caller
+// must hold s.mu. It panics if the number of values does not match the labels, which
+// is a programming error.
+func (s *series[T]) with(values []string) *labeled[T] {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(values), s.labels))
+	}
+	key := strings.Join(values, "\xff")
+	v, ok := s.values[key]
+	if !ok {
+		pairs := make([]string, len(values))
+		for i, value := range values {
+			pairs[i] = fmt.Sprintf(`%s="%s"`, s.labels[i], escapeLabel(value))
+		}
+		v = &labeled[T]{labels: strings.Join(pairs, ",")}
+		s.values[key] = v
+	}
+	return v
+}
+
+// get returns the value for the label values, or nil if there is none yet; the caller
+// must hold s.mu.
+func (s *series[T]) get(values []string) *labeled[T] {
+	return s.values[strings.Join(values, "\xff")]
+}
+
+// sorted returns the values ordered by their labels, so that output is stable; the
+// caller must hold s.mu.
+func (s *series[T]) sorted() []*labeled[T] {
+	values := make([]*labeled[T], 0, len(s.values))
+	for _, v := range s.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].labels < values[j].labels })
+	return values
+}
+
+// Counter is a value that only increases, per combination of label values.
+type Counter struct {
+	series series[float64]
+}
+
+// Inc adds one to the counter with the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the counter with the given label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	c.series.with(labelValues).value += v
+}
+
+// Value returns the counter with the given label values, or 0 if it was never added to.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	if v := c.series.get(labelValues); v != nil {
+		return v.value
+	}
+	return 0
+}
+
+func (c *Counter) kind() string { return "counter" }
+
+func (c *Counter) write(w io.Writer, name string) {
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	for _, v := range c.series.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", name, braces(v.labels), formatFloat(v.value))
+	}
+}
+
+// Histogram counts observations in buckets, per combination of label values.
+type Histogram struct {
+	series series[histogramValue]
+	bounds []float64 // Upper bounds of the buckets, ending with +Inf.
+}
+
+// histogramValue holds the observations of one combination of label values.
+type histogramValue struct {
+	counts []uint64 // Observations per bucket, not cumulative.
+	sum    float64
+	count  uint64
+}
+
+// Observe records v in the histogram with the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	hv := &h.series.with(labelValues).value
+	if hv.counts == nil {
+		hv.counts = make([]uint64, len(h.bounds))
+	}
+	hv.counts[sort.SearchFloat64s(h.bounds, v)]++
+	hv.sum += v
+	hv.count++
+}
+
+// Count returns the number of observations of the histogram with the given label values.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	if v := h.series.get(labelValues); v != nil {
+		return v.value.count
+	}
+	return 0
+}
+
+func (h *Histogram) kind() string { return "histogram" }
+
+func (h *Histogram) write(w io.Writer, name string) {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	for _, v := range h.series.sorted() {
+		var cumulative uint64
+		for i, bound := range h.bounds {
+			if v.value.counts != nil {
+				cumulative += v.value.counts[i]
+			}
+			le := fmt.Sprintf("le=%q", formatFloat(bound))
+			if v.labels != "" {
+				le = v.labels + "," + le
+			}
+			fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, le, cumulative)
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, braces(v.labels), formatFloat(v.value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, braces(v.labels), v.value.count)
+	}
+}
+
+// funcMetric is an unlabeled gauge or counter read from a function.
+type funcMetric struct {
+	typ string
+	fn  func() float64
+}
+
+func (f funcMetric) kind() string { return f.typ }
+
+func (f funcMetric) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(f.fn()))
+}
+
+// braces wraps formatted label pairs in braces, if there are any.
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// formatFloat formats a sample value as the exposition format expects.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel escapes backslashes, double quotes and newlines in a label value.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes backslashes and newlines in help text.
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// countingWriter counts the bytes written and remembers the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,156 @@
+// Package ratelimit limits request rates with token buckets. Each key has a bucket
+// holding up to Limit.Burst tokens that refills at Limit.Rate tokens per second; a
+// request takes one token and is denied when the bucket is empty.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the rate and burst a bucket allows.
+type Limit struct {
+	Rate  float64 // Tokens added per second.
+	Burst int     // Capacity of the bucket, i.e. the requests allowed at once after a pause.
+}
+
+// PerMinute returns a limit of requests per minute with the given burst.
+func PerMinute(requests, burst int) Limit {
+	return Limit{Rate: float64(requests) / 60, Burst: burst}
+}
+
+// Enabled reports whether the limit allows a finite rate; a zero limit disables limiting.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed    bool
+	Limit      int           // Capacity of the bucket.
+	Remaining  int           // Whole tokens left after the request.
+	Reset      time.Duration // Time until the bucket is full again.
+	RetryAfter time.Duration // Time until a token is available, if the request was denied.
+}
+
+// Store keeps the buckets of every key. Implementations must be safe for concurrent
+// use; a store shared between instances of the server lets them enforce one limit.
+type Store interface {
+	// Take takes a token from the bucket of key, creating a full bucket if needed.
+	Take(key string, limit Limit) (Decision, error)
+	// Refund returns a token taken from the bucket of key, for a request that turned
+	// out not to count against the limit.
+	Refund(key string, limit Limit) error
+}
+
+// sweepInterval is how often a MemoryStore drops the buckets of idle keys.
+const sweepInterval = time.Minute
+
+// bucket is the state of one key: its limit and its tokens as of the last update.
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore is a Store keeping the buckets of a single process in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore reading the time from clock, or from
+// time.Now if clock is nil.
+func NewMemoryStore(clock func() time.Time) *MemoryStore {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &MemoryStore{buckets: make(map[string]*bucket), now: clock, lastSweep: clock()}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(key string, limit Limit) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.tokens = refill(b, now)
+	b.updated = now
+
+	decision := Decision{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = durationFor(1-b.tokens, limit)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = durationFor(float64(limit.Burst)-b.tokens, limit)
+	return decision, nil
+}
+
+// Refund implements Store. A bucket that was dropped is full already.
+func (s *MemoryStore) Refund(key string, limit Limit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		return nil
+	}
+	now := s.now()
+	b.limit = limit
+	b.tokens = math.Min(float64(limit.Burst), refill(b, now)+1)
+	b.updated = now
+	return nil
+}
+
+// Len returns the number of buckets the store holds.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// sweep drops the buckets that have refilled completely, which behave like new ones,
+// at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if refill(b, now) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// refill returns the tokens of a bucket at the given time. A bucket holding more
+// tokens than its limit allows, because the limit was lowered, is capped.
+func refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		tokens += elapsed * b.limit.Rate
+	}
+	return math.Min(float64(b.limit.Burst), tokens)
+}
+
+// durationFor returns the time the limit takes to add the given number of tokens,
+// rounded up to whole seconds since that is how clients are told to wait.
+func durationFor(tokens float64, limit Limit) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens/limit.Rate)) * time.Second
+}
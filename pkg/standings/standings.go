@@ -0,0 +1,313 @@
+// Package standings ranks teams by their records over final games. Teams with the same
+// winning percentage are ordered by the NBA's tie-breaking rules. Two tied teams are
+// separated by, in order: their head-to-head record, leading a division, division
+// record (same division only), conference record (same conference only), record
+// against playoff-eligible teams of their own and then of the other conference, and
+// point differential. Three or more tied teams are separated by leading a division,
+// their record in the games among them, division and conference record as above,
+// record against playoff-eligible teams of their own conference, and point
+// differential.
+package standings
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+)
+
+// PlayoffTeams is the number of teams of each conference that are eligible for the
+// playoffs, play-in tournament included, as far as the tie-breakers are concerned.
+const PlayoffTeams = 10
+
+// lastGames is the number of most recent games counted by the last-ten record.
+const lastGames = 10
+
+// record is a team's results over the games counted.
+type record struct {
+	team          domain.Team
+	overall       domain.Record
+	home, away    domain.Record
+	conference    domain.Record
+	division      domain.Record
+	results       []bool // Whether each game was won, in the order played.
+	pointsFor     int
+	pointsAgainst int
+	// opponents holds the team's record against each of its opponents.
+	opponents map[string]domain.Record
+}
+
+// against returns the team's record against the given teams, itself excluded.
+func (r *record) against(teams []*record) domain.Record {
+	var total domain.Record
+	for _, t := range teams {
+		if t.team.ID == r.team.ID {
+			continue
+		}
+		vs := r.opponents[t.team.ID]
+		total.Wins += vs.Wins
+		total.Losses += vs.Losses
+	}
+	return total
+}
+
+// sameConference reports whether two teams are in the same, known, conference.
+func sameConference(a, b domain.Team) bool {
+	return a.Conference != "" && a.Conference == b.Conference
+}
+
+// sameDivision reports whether two teams are in the same, known, division.
+func sameDivision(a, b domain.Team) bool {
+	return sameConference(a, b) && a.Division != "" && a.Division == b.Division
+}
+
+// Compute returns the standings of the teams over the given games, ranked within each
+// group: the whole league, each conference or each division, as selected by one of the
+// domain.StandingsGroup values. Games that are not final are ignored. Every team with a
+// conference is listed, even before its first game, as is every team that played in
+// one of the games; teams missing from teams are listed by ID alone. Conferences are
+// listed alphabetically and divisions in the order of domain.Divisions, followed by a
+// group named "" for the teams that have none.
+func Compute(teams []domain.Team, games []domain.Game, group string) []domain.StandingsGroup {
+	t := newTable(teams, games)
+
+	var groups []domain.StandingsGroup
+	switch group {
+	case domain.StandingsGroupLeague:
+		groups = append(groups, t.group(domain.StandingsGroupLeague, func(domain.Team) bool { return true }))
+	case domain.StandingsGroupDivision:
+		for _, conference := range conferences() {
+			for _, division := range domain.Divisions[conference] {
+				groups = append(groups, t.group(division, func(team domain.Team) bool {
+					return team.Conference == conference && team.Division == division
+				}))
+			}
+		}
+		groups = append(groups, t.group("", func(team domain.Team) bool {
+			return !isDivision(team.Conference, team.Division)
+		}))
+	default:
+		for _, conference := range conferences() {
+			groups = append(groups, t.group(conference, func(team domain.Team) bool { return team.Conference == conference }))
+		}
+		groups = append(groups, t.group("", func(team domain.Team) bool {
+			_, ok := domain.Divisions[team.Conference]
+			return !ok
+		}))
+	}
+
+	nonEmpty := groups[:0]
+	for _, g := range groups {
+		if len(g.Teams) > 0 {
+			nonEmpty = append(nonEmpty, g)
+		}
+	}
+	return nonEmpty
+}
+
+// conferences returns the conferences of domain.Divisions in alphabetical order.
+func conferences() []string {
+	names := make([]string, 0, len(domain.Divisions))
+	for name := range domain.Divisions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDivision reports whether division is one of the conference's divisions.
+func isDivision(conference, division string) bool {
+	for _, d := range domain.Divisions[conference] {
+		if d == division {
+			return true
+		}
+	}
+	return false
+}
+
+// table holds the records of every team, together with what the tie-breakers need to
+// know about the league as a whole.
+type table struct {
+	records []*record
+	// leaders holds the IDs of the teams that lead their division.
+	leaders map[string]bool
+	// eligible holds the IDs of the teams eligible for the playoffs.
+	eligible map[string]bool
+}
+
+// newTable tallies the records of the teams over the final games and works out the
+// division leaders and playoff-eligible teams.
+func newTable(teams []domain.Team, games []domain.Game) *table {
+	t := &table{leaders: map[string]bool{}, eligible: map[string]bool{}}
+	byID := make(map[string]*record, len(teams))
+	add := func(team domain.Team) *record {
+		if r, ok := byID[team.ID]; ok {
+			return r
+		}
+		r := &record{team: team, opponents: map[string]domain.Record{}}
+		byID[team.ID] = r
+		t.records = append(t.records, r)
+		return r
+	}
+	for _, team := range teams {
+		if team.Conference != "" {
+			add(team)
+		}
+	}
+	known := make(map[string]domain.Team, len(teams))
+	for _, team := range teams {
+		known[team.ID] = team
+	}
+	teamOf := func(id string) domain.Team {
+		if team, ok := known[id]; ok {
+			return team
+		}
+		return domain.Team{ID: id}
+	}
+
+	final := make([]domain.Game, 0, len(games))
+	for _, game := range games {
+		if game.Status == domain.GameStatusFinal && game.HomeScore != game.AwayScore {
+			final = append(final, game)
+		}
+	}
+	sort.SliceStable(final, func(i, j int) bool {
+		if !final[i].Date.Equal(final[j].Date) {
+			return final[i].Date.Before(final[j].Date)
+		}
+		return final[i].ID < final[j].ID
+	})
+	for _, game := range final {
+		home, away := add(teamOf(game.HomeTeam)), add(teamOf(game.AwayTeam))
+		homeWon := game.HomeScore > game.AwayScore
+		home.tally(away, homeWon, game.HomeScore, game.AwayScore, &home.home)
+		away.tally(home, !homeWon, game.AwayScore, game.HomeScore, &away.away)
+	}
+	sort.Slice(t.records, func(i, j int) bool { return t.records[i].team.ID < t.records[j].team.ID })
+
+	// Ranking a division may take the playoff-eligible teams of either conference.
+	for _, conference := range conferences() {
+		t.markEligible(t.members(func(team domain.Team) bool { return team.Conference == conference }))
+	}
+	for _, conference := range conferences() {
+		for _, division := range domain.Divisions[conference] {
+			teams := t.members(func(team domain.Team) bool {
+				return team.Conference == conference && team.Division == division
+			})
+			if ranked := t.rank(teams); len(ranked) > 0 {
+				t.leaders[ranked[0].team.ID] = true
+			}
+		}
+	}
+	return t
+}
+
+// tally adds a game against opponent to the record; split is the home or away record
+// the game counts towards.
+func (r *record) tally(opponent *record, won bool, pointsFor, pointsAgainst int, split *domain.Record) {
+	records := []*domain.Record{&r.overall, split}
+	if sameConference(r.team, opponent.team) {
+		records = append(records, &r.conference)
+	}
+	if sameDivision(r.team, opponent.team) {
+		records = append(records, &r.division)
+	}
+	vs := r.opponents[opponent.team.ID]
+	records = append(records, &vs)
+	for _, rec := range records {
+		if won {
+			rec.Wins++
+		} else {
+			rec.Losses++
+		}
+	}
+	r.opponents[opponent.team.ID] = vs
+	r.results = append(r.results, won)
+	r.pointsFor += pointsFor
+	r.pointsAgainst += pointsAgainst
+}
+
+// markEligible marks the PlayoffTeams teams of a conference with the best winning
+// percentage as eligible for the playoffs. Teams tied with the last of them are
+// eligible too, since the tie-breakers that would separate them need to know which
+// teams are eligible in the first place.
+func (t *table) markEligible(conference []*record) {
+	teams := append([]*record(nil), conference...)
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].overall.Pct() > teams[j].overall.Pct() })
+	for i, r := range teams {
+		if i >= PlayoffTeams && r.overall.Pct() < teams[PlayoffTeams-1].overall.Pct() {
+			break
+		}
+		t.eligible[r.team.ID] = true
+	}
+}
+
+// members returns the records of the teams for which member returns true, by ID.
+func (t *table) members(member func(domain.Team) bool) []*record {
+	var teams []*record
+	for _, r := range t.records {
+		if member(r.team) {
+			teams = append(teams, r)
+		}
+	}
+	return teams
+}
+
+// group ranks the teams for which member returns true and returns them as a group.
+func (t *table) group(name string, member func(domain.Team) bool) domain.StandingsGroup {
+	ranked := t.rank(t.members(member))
+
+	g := domain.StandingsGroup{Name: name, Teams: make([]domain.Standing, 0, len(ranked))}
+	for i, r := range ranked {
+		s := r.standing()
+		s.Rank = i + 1
+		leader := ranked[0].overall
+		s.GamesBehind = float64((leader.Wins-r.overall.Wins)+(r.overall.Losses-leader.Losses)) / 2
+		g.Teams = append(g.Teams, s)
+	}
+	return g
+}
+
+// standing returns the record as a domain.Standing, without its rank or games behind.
+func (r *record) standing() domain.Standing {
+	s := domain.Standing{
+		TeamID:           r.team.ID,
+		TeamName:         r.team.Name,
+		Conference:       r.team.Conference,
+		Division:         r.team.Division,
+		Wins:             r.overall.Wins,
+		Losses:           r.overall.Losses,
+		WinPct:           math.Round(r.overall.Pct()*1000) / 1000,
+		Home:             r.home,
+		Away:             r.away,
+		ConferenceRecord: r.conference,
+		DivisionRecord:   r.division,
+		PointsFor:        r.pointsFor,
+		PointsAgainst:    r.pointsAgainst,
+		PointDifference:  r.pointsFor - r.pointsAgainst,
+	}
+	recent := r.results
+	if len(recent) > lastGames {
+		recent = recent[len(recent)-lastGames:]
+	}
+	for _, won := range recent {
+		if won {
+			s.LastTen.Wins++
+		} else {
+			s.LastTen.Losses++
+		}
+	}
+	if n := len(r.results); n > 0 {
+		last, streak := r.results[n-1], 0
+		for i := n - 1; i >= 0 && r.results[i] == last; i-- {
+			streak++
+		}
+		if last {
+			s.Streak = "W" + strconv.Itoa(streak)
+		} else {
+			s.Streak = "L" + strconv.Itoa(streak)
+		}
+	}
+	return s
+}
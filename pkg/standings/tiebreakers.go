@@ -0,0 +1,200 @@
+package standings
+
+import (
+	"sort"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+)
+
+// criterion scores each of the tied teams; the higher the score, the better the team
+// ranks. It returns false if it does not apply to the teams.
+type criterion func(t *table, tied []*record) ([]float64, bool)
+
+// twoTeamCriteria break a tie between two teams, in order.
+var twoTeamCriteria = []criterion{
+	headToHead,
+	divisionLeader,
+	divisionPct,
+	conferencePct,
+	ownConferencePlayoffPct,
+	otherConferencePlayoffPct,
+	pointDifferential,
+}
+
+// multiTeamCriteria break a tie between more than two teams, in order.
+var multiTeamCriteria = []criterion{
+	divisionLeader,
+	headToHead,
+	divisionPct,
+	conferencePct,
+	ownConferencePlayoffPct,
+	pointDifferential,
+}
+
+// rank orders the teams by winning percentage and breaks ties with the NBA's
+// tie-breakers. The first criterion that separates the tied teams splits them into
+// smaller groups of teams with the same score, and each group that is still tied is
+// ranked again from the first criterion, with the two-team criteria once only two
+// teams are left. Teams that no criterion separates are ordered by ID.
+func (t *table) rank(teams []*record) []*record {
+	ranked := append([]*record(nil), teams...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].overall.Pct() > ranked[j].overall.Pct() })
+	return t.splitTies(ranked, func(r *record) float64 { return r.overall.Pct() })
+}
+
+// breakTie orders teams with the same winning percentage.
+func (t *table) breakTie(tied []*record) []*record {
+	criteria := multiTeamCriteria
+	if len(tied) == 2 {
+		criteria = twoTeamCriteria
+	}
+	for _, c := range criteria {
+		scores, ok := c(t, tied)
+		if !ok || allEqual(scores) {
+			continue
+		}
+		score := make(map[*record]float64, len(tied))
+		for i, r := range tied {
+			score[r] = scores[i]
+		}
+		ordered := append([]*record(nil), tied...)
+		sort.SliceStable(ordered, func(i, j int) bool { return score[ordered[i]] > score[ordered[j]] })
+		return t.splitTies(ordered, func(r *record) float64 { return score[r] })
+	}
+	ordered := append([]*record(nil), tied...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].team.ID < ordered[j].team.ID })
+	return ordered
+}
+
+// splitTies passes each run of teams with the same score to breakTie and returns the
+// teams with every run reordered. The teams must already be ordered by score.
+func (t *table) splitTies(teams []*record, score func(*record) float64) []*record {
+	ranked := make([]*record, 0, len(teams))
+	for start := 0; start < len(teams); {
+		end := start + 1
+		for end < len(teams) && score(teams[end]) == score(teams[start]) {
+			end++
+		}
+		if end-start == 1 {
+			ranked = append(ranked, teams[start])
+		} else {
+			ranked = append(ranked, t.breakTie(teams[start:end])...)
+		}
+		start = end
+	}
+	return ranked
+}
+
+// allEqual reports whether all scores are the same.
+func allEqual(scores []float64) bool {
+	for _, s := range scores {
+		if s != scores[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// headToHead scores the teams by their winning percentage in the games among them.
+func headToHead(_ *table, tied []*record) ([]float64, bool) {
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		scores[i] = r.against(tied).Pct()
+	}
+	return scores, true
+}
+
+// divisionLeader favors division leaders over teams that do not lead their division.
+// It only applies to teams of different divisions.
+func divisionLeader(t *table, tied []*record) ([]float64, bool) {
+	if allSame(tied, sameDivision) {
+		return nil, false
+	}
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		if t.leaders[r.team.ID] {
+			scores[i] = 1
+		}
+	}
+	return scores, true
+}
+
+// divisionPct scores the teams by their winning percentage against their division. It
+// only applies to teams of the same division.
+func divisionPct(_ *table, tied []*record) ([]float64, bool) {
+	if !allSame(tied, sameDivision) {
+		return nil, false
+	}
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		scores[i] = r.division.Pct()
+	}
+	return scores, true
+}
+
+// conferencePct scores the teams by their winning percentage against their conference.
+// It only applies to teams of the same conference.
+func conferencePct(_ *table, tied []*record) ([]float64, bool) {
+	if !allSame(tied, sameConference) {
+		return nil, false
+	}
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		scores[i] = r.conference.Pct()
+	}
+	return scores, true
+}
+
+// ownConferencePlayoffPct scores the teams by their winning percentage against the
+// playoff-eligible teams of their own conference.
+func ownConferencePlayoffPct(t *table, tied []*record) ([]float64, bool) {
+	return t.playoffPct(tied, true)
+}
+
+// otherConferencePlayoffPct scores the teams by their winning percentage against the
+// playoff-eligible teams of the other conference.
+func otherConferencePlayoffPct(t *table, tied []*record) ([]float64, bool) {
+	return t.playoffPct(tied, false)
+}
+
+// playoffPct scores the teams by their winning percentage against the playoff-eligible
+// teams of their own conference, or of the other one. It only applies to teams that
+// all have a conference.
+func (t *table) playoffPct(tied []*record, own bool) ([]float64, bool) {
+	for _, r := range tied {
+		if r.team.Conference == "" {
+			return nil, false
+		}
+	}
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		var opponents []*record
+		for _, o := range t.records {
+			if t.eligible[o.team.ID] && o.team.Conference != "" && sameConference(r.team, o.team) == own {
+				opponents = append(opponents, o)
+			}
+		}
+		scores[i] = r.against(opponents).Pct()
+	}
+	return scores, true
+}
+
+// pointDifferential scores the teams by their points scored minus points allowed in
+// all games.
+func pointDifferential(_ *table, tied []*record) ([]float64, bool) {
+	scores := make([]float64, len(tied))
+	for i, r := range tied {
+		scores[i] = float64(r.pointsFor - r.pointsAgainst)
+	}
+	return scores, true
+}
+
+// allSame reports whether every team is related to the first by same.
+func allSame(tied []*record, same func(a, b domain.Team) bool) bool {
+	for _, r := range tied[1:] {
+		if !same(tied[0].team, r.team) {
+			return false
+		}
+	}
+	return true
+}
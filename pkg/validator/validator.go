@@ -1,45 +1,349 @@
-// pkg/validator/validator.go
+// Package validator checks domain entities before they are stored. Each function
+// checks every rule and reports all violations at once in a *domain.ValidationError,
+// which wraps domain.ErrValidation.
 package validator
 
 import (
-	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 )
 
+// seasonIDPattern matches season identifiers such as "2025-26".
+var seasonIDPattern = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+
+// violations collects the rules an entity breaks.
+type violations []domain.FieldError
+
+// add records that the named field breaks a rule.
+func (v *violations) add(field, code, format string, args ...interface{}) {
+	*v = append(*v, domain.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// required records a violation if the named field is empty.
+func (v *violations) required(field, value string) {
+	if value == "" {
+		v.add(field, domain.CodeRequired, "%s cannot be empty", field)
+	}
+}
+
+// err returns the collected violations as an error, or nil if there are none.
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return &domain.ValidationError{Fields: v}
+}
+
 // ValidatePlayer ensures a player's data is valid.
 func ValidatePlayer(player *domain.Player) error {
-	if player.ID == "" || player.Name == "" || player.TeamID == "" {
-		return errors.New("player ID, name, and team ID cannot be empty")
+	var v violations
+	v.required("id", player.ID)
+	v.required("name", player.Name)
+	v.required("team_id", player.TeamID)
+	return v.err()
+}
+
+// ValidatePlayerUpdate ensures the new data of an existing player is valid. Unlike a
+// new player, an existing one may be a free agent without a team.
+func ValidatePlayerUpdate(player *domain.Player) error {
+	var v violations
+	v.required("id", player.ID)
+	v.required("name", player.Name)
+	return v.err()
+}
+
+// ValidateRosterTransaction ensures a roster transaction names a player, a known type
+// and a date, and a team unless it waives the player.
+func ValidateRosterTransaction(t *domain.RosterTransaction) error {
+	var v violations
+	v.required("player_id", t.PlayerID)
+	switch {
+	case t.Type == "":
+		v.add("type", domain.CodeRequired, "type cannot be empty")
+	case !isTransactionType(t.Type):
+		v.add("type", domain.CodeInvalid, "type must be one of %s", strings.Join(domain.TransactionTypes, ", "))
+	case t.Type == domain.TransactionWaiver:
+		if t.TeamID != "" {
+			v.add("team_id", domain.CodeInvalid, "team_id must be empty for a waiver")
+		}
+	default:
+		v.required("team_id", t.TeamID)
+	}
+	if t.Date.IsZero() {
+		v.add("date", domain.CodeRequired, "date cannot be empty")
+	}
+	return v.err()
+}
+
+// isTransactionType reports whether transactionType is one of domain.TransactionTypes.
+func isTransactionType(transactionType string) bool {
+	for _, t := range domain.TransactionTypes {
+		if t == transactionType {
+			return true
+		}
 	}
-	return nil
+	return false
 }
 
 // ValidateTeam ensures a team's data is valid.
 func ValidateTeam(team *domain.Team) error {
-	if team.ID == "" || team.Name == "" {
-		return errors.New("team ID and name cannot be empty")
+	var v violations
+	v.required("id", team.ID)
+	v.required("name", team.Name)
+	v.teamPlacement(team)
+	return v.err()
+}
+
+// teamPlacement records a conference that is not one of domain.Divisions' keys, and a
+// division that is not one of its conference's divisions. Both may be empty, but a
+// division needs a conference.
+func (v *violations) teamPlacement(team *domain.Team) {
+	if team.Conference == "" {
+		if team.Division != "" {
+			v.add("conference", domain.CodeRequired, "conference cannot be empty when division is set")
+		}
+		return
+	}
+	divisions, ok := domain.Divisions[team.Conference]
+	if !ok {
+		v.add("conference", domain.CodeInvalid, "conference must be %q or %q", domain.ConferenceEast, domain.ConferenceWest)
+		return
+	}
+	if team.Division == "" {
+		return
+	}
+	for _, division := range divisions {
+		if division == team.Division {
+			return
+		}
 	}
-	return nil
+	v.add("division", domain.CodeMismatch, "division must be one of %s in the %s conference", strings.Join(divisions, ", "), team.Conference)
 }
 
 // ValidateGame ensures a game's data is valid.
 func ValidateGame(game *domain.Game) error {
-	if game.ID == "" || game.HomeTeam == "" || game.AwayTeam == "" {
-		return errors.New("game ID, home team, and away team cannot be empty")
+	var v violations
+	v.required("id", game.ID)
+	v.required("home_team", game.HomeTeam)
+	v.required("away_team", game.AwayTeam)
+	v.seasonType(game.SeasonType)
+	v.gameResult(game)
+	return v.err()
+}
+
+// gameResult records the violations of a game's status, score and periods. Only live
+// and final games have a score; a final game has a winner, and goes to overtime only
+// when tied after the previous period. Periods, when recorded, add up to the score.
+func (v *violations) gameResult(game *domain.Game) {
+	if !isGameStatus(game.Status) {
+		v.add("status", domain.CodeInvalid, "status must be one of %s", strings.Join(domain.GameStatuses, ", "))
+		return
+	}
+	if game.HomeScore < 0 {
+		v.add("home_score", domain.CodeOutOfRange, "home_score cannot be negative")
+	}
+	if game.AwayScore < 0 {
+		v.add("away_score", domain.CodeOutOfRange, "away_score cannot be negative")
+	}
+	switch game.Status {
+	case domain.GameStatusLive, domain.GameStatusFinal:
+	default:
+		if game.HomeScore != 0 || game.AwayScore != 0 || len(game.Periods) > 0 {
+			v.add("status", domain.CodeMismatch, "a %s game cannot have a score", game.Status)
+		}
+		return
+	}
+
+	var home, away int
+	for i, period := range game.Periods {
+		field := fmt.Sprintf("periods[%d]", i)
+		if period.Period != i+1 {
+			v.add(field+".period", domain.CodeInvalid, "periods must be numbered 1, 2, 3, ... in order")
+		}
+		if period.Home < 0 || period.Away < 0 {
+			v.add(field, domain.CodeOutOfRange, "period points cannot be negative")
+		}
+		if game.Status == domain.GameStatusFinal && i >= domain.RegulationPeriods && home != away {
+			v.add(field, domain.CodeMismatch, "overtime is only played when the score is tied, not %d-%d", home, away)
+		}
+		home += period.Home
+		away += period.Away
+	}
+	if len(game.Periods) > 0 {
+		if home != game.HomeScore {
+			v.add("home_score", domain.CodeMismatch, "home_score %d does not match the %d points of the periods", game.HomeScore, home)
+		}
+		if away != game.AwayScore {
+			v.add("away_score", domain.CodeMismatch, "away_score %d does not match the %d points of the periods", game.AwayScore, away)
+		}
+	}
+	if game.Status != domain.GameStatusFinal {
+		return
+	}
+	if len(game.Periods) > 0 && len(game.Periods) < domain.RegulationPeriods {
+		v.add("periods", domain.CodeMismatch, "a final game has at least %d periods", domain.RegulationPeriods)
+	}
+	if game.HomeScore == game.AwayScore {
+		v.add("home_score", domain.CodeMismatch, "a final game cannot be tied")
 	}
-	return nil
 }
 
-// ValidatePlayerStats ensures player statistics are valid.
-func ValidatePlayerStats(stats *domain.PlayerGameStats) error {
-	if stats.PlayerID == "" || stats.GameID == "" {
-		return errors.New("player ID and game ID cannot be empty")
+// isGameStatus reports whether status is one of domain.GameStatuses.
+func isGameStatus(status string) bool {
+	for _, s := range domain.GameStatuses {
+		if s == status {
+			return true
+		}
 	}
+	return false
+}
+
+// ValidateSeason ensures a season's identifier and date ranges are valid.
+func ValidateSeason(season *domain.Season) error {
+	var v violations
+	if m := seasonIDPattern.FindStringSubmatch(season.ID); m == nil {
+		v.add("id", domain.CodeInvalid, "id must have the form YYYY-YY, e.g. 2025-26")
+	} else {
+		startYear, _ := strconv.Atoi(m[1])
+		endYear, _ := strconv.Atoi(m[2])
+		if (startYear+1)%100 != endYear {
+			v.add("id", domain.CodeInvalid, "season %s must span consecutive years", season.ID)
+		}
+	}
+	if season.StartDate.IsZero() {
+		v.add("start_date", domain.CodeRequired, "start_date cannot be empty")
+	}
+	if season.EndDate.IsZero() {
+		v.add("end_date", domain.CodeRequired, "end_date cannot be empty")
+	}
+	if season.StartDate.IsZero() || season.EndDate.IsZero() {
+		return v.err()
+	}
+	if !season.StartDate.Before(season.EndDate) {
+		v.add("end_date", domain.CodeOutOfRange, "end_date must be after start_date")
+	} else if !season.PlayoffsStart.IsZero() && !season.Contains(season.PlayoffsStart) {
+		v.add("playoffs_start", domain.CodeOutOfRange, "playoffs_start must fall within the season")
+	}
+	return v.err()
+}
+
+// ValidateSeasonType ensures a season type is empty, regular or playoffs.
+func ValidateSeasonType(seasonType string) error {
+	var v violations
+	v.seasonType(seasonType)
+	return v.err()
+}
+
+// seasonType records a violation if the season type is neither empty, regular nor playoffs.
+func (v *violations) seasonType(seasonType string) {
+	switch seasonType {
+	case "", domain.SeasonTypeRegular, domain.SeasonTypePlayoffs:
+		return
+	}
+	v.add("season_type", domain.CodeInvalid, "season_type must be %q or %q", domain.SeasonTypeRegular, domain.SeasonTypePlayoffs)
+}
+
+// ValidateAPIKey ensures a new API key is named and granted at least one known scope.
+func ValidateAPIKey(key *domain.APIKey) error {
+	var v violations
+	v.required("name", key.Name)
+	if len(key.Scopes) == 0 {
+		v.add("scopes", domain.CodeRequired, "scopes must include at least one of %s", strings.Join(domain.Scopes, ", "))
+	}
+	for _, scope := range key.Scopes {
+		if !isScope(scope) {
+			v.add("scopes", domain.CodeInvalid, "unknown scope %q", scope)
+		}
+	}
+	return v.err()
+}
+
+// isScope reports whether scope is one of domain.Scopes.
+func isScope(scope string) bool {
+	for _, s := range domain.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePlayerStats ensures player statistics are valid. The minutes played are
+// checked against the length of the game by the service, which knows its overtimes.
+func ValidatePlayerStats(stats *domain.PlayerGameStats) error {
+	var v violations
+	v.required("player_id", stats.PlayerID)
+	v.required("game_id", stats.GameID)
 	if stats.Fouls > 6 {
-		return errors.New("fouls cannot exceed 6")
+		v.add("fouls", domain.CodeOutOfRange, "fouls cannot exceed 6")
+	}
+	if stats.MinutesPlayed < 0 {
+		v.add("minutes_played", domain.CodeOutOfRange, "minutes_played cannot be negative")
+	}
+	if stats.HasShootingSplits() {
+		v.shootingSplits(stats)
+	}
+	if stats.HasReboundSplits() {
+		v.reboundSplits(stats)
+	}
+	return v.err()
+}
+
+// shootingSplits records made shots that exceed attempts, three-pointers not counted
+// within field goals and splits that do not account for exactly the points scored.
+func (v *violations) shootingSplits(stats *domain.PlayerGameStats) {
+	shots := []struct {
+		made, attempted         int
+		madeField, attemptField string
+	}{
+		{stats.FieldGoalsMade, stats.FieldGoalsAttempted, "field_goals_made", "field_goals_attempted"},
+		{stats.ThreePointersMade, stats.ThreePointersAttempted, "three_pointers_made", "three_pointers_attempted"},
+		{stats.FreeThrowsMade, stats.FreeThrowsAttempted, "free_throws_made", "free_throws_attempted"},
+	}
+	valid := true
+	for _, shot := range shots {
+		if shot.made < 0 {
+			v.add(shot.madeField, domain.CodeOutOfRange, "%s cannot be negative", shot.madeField)
+			valid = false
+		} else if shot.made > shot.attempted {
+			v.add(shot.madeField, domain.CodeOutOfRange, "%s cannot exceed %s", shot.madeField, shot.attemptField)
+			valid = false
+		}
+	}
+	if stats.ThreePointersMade > stats.FieldGoalsMade {
+		v.add("three_pointers_made", domain.CodeMismatch, "three_pointers_made must be counted within field_goals_made")
+		valid = false
+	}
+	if stats.ThreePointersAttempted > stats.FieldGoalsAttempted {
+		v.add("three_pointers_attempted", domain.CodeMismatch, "three_pointers_attempted must be counted within field_goals_attempted")
+		valid = false
+	}
+	// The points check is only meaningful once the splits themselves are consistent.
+	if !valid {
+		return
+	}
+	if points := 2*stats.FieldGoalsMade + stats.ThreePointersMade + stats.FreeThrowsMade; points != stats.Points {
+		v.add("points", domain.CodeMismatch, "shooting splits account for %d points but %d were recorded", points, stats.Points)
+	}
+}
+
+// reboundSplits records negative rebound splits or splits that do not add up to the
+// rebounds recorded.
+func (v *violations) reboundSplits(stats *domain.PlayerGameStats) {
+	valid := true
+	if stats.OffensiveRebounds < 0 {
+		v.add("offensive_rebounds", domain.CodeOutOfRange, "offensive_rebounds cannot be negative")
+		valid = false
+	}
+	if stats.DefensiveRebounds < 0 {
+		v.add("defensive_rebounds", domain.CodeOutOfRange, "defensive_rebounds cannot be negative")
+		valid = false
 	}
-	if stats.MinutesPlayed < 0 || stats.MinutesPlayed > 48 {
-		return errors.New("minutes played must be between 0 and 48")
+	if valid && stats.OffensiveRebounds+stats.DefensiveRebounds != stats.Rebounds {
+		v.add("rebounds", domain.CodeMismatch, "offensive and defensive rebounds must add up to %d rebounds", stats.Rebounds)
 	}
-	return nil
 }
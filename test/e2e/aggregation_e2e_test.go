@@ -24,8 +24,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 	reqTeam, _ := http.NewRequest("POST", "/api/v1/teams", bytes.NewBuffer(teamBody))
 	reqTeam.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqTeam.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqTeam.Header.Set("Authorization", authHeader)
 
 	respTeam := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respTeam, reqTeam)
@@ -43,8 +43,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 		reqPlayer, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 		reqPlayer.Header.Set("Content-Type", "application/json")
 
-		// For endpoints with middleware, add a dummy Authorization header.
-		reqPlayer.Header.Set("Authorization", "dummy-token")
+		// For endpoints with middleware, authenticate with the test token.
+		reqPlayer.Header.Set("Authorization", authHeader)
 
 		respPlayer := httptest.NewRecorder()
 		testServer.Handler.ServeHTTP(respPlayer, reqPlayer)
@@ -53,8 +53,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 
 	// --- Step 3: Create Two Games ---
 	games := []domain.Game{
-		{ID: "game1", Date: time.Now(), HomeTeam: "team1", AwayTeam: "team2"},
-		{ID: "game2", Date: time.Now().Add(24 * time.Hour), HomeTeam: "team1", AwayTeam: "team3"},
+		{ID: "game1", Date: time.Now(), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive},
+		{ID: "game2", Date: time.Now().Add(24 * time.Hour), HomeTeam: "team1", AwayTeam: "team3", Status: domain.GameStatusLive},
 	}
 
 	for _, game := range games {
@@ -63,8 +63,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 		reqGame, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 		reqGame.Header.Set("Content-Type", "application/json")
 
-		// For endpoints with middleware, add a dummy Authorization header.
-		reqGame.Header.Set("Authorization", "dummy-token")
+		// For endpoints with middleware, authenticate with the test token.
+		reqGame.Header.Set("Authorization", authHeader)
 
 		respGame := httptest.NewRecorder()
 		testServer.Handler.ServeHTTP(respGame, reqGame)
@@ -85,8 +85,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 		reqStats, _ := http.NewRequest("POST", "/api/v1/player-stats", bytes.NewBuffer(statsBody))
 		reqStats.Header.Set("Content-Type", "application/json")
 
-		// For endpoints with middleware, add a dummy Authorization header.
-		reqStats.Header.Set("Authorization", "dummy-token")
+		// For endpoints with middleware, authenticate with the test token.
+		reqStats.Header.Set("Authorization", authHeader)
 	
 		respStats := httptest.NewRecorder()
 		testServer.Handler.ServeHTTP(respStats, reqStats)
@@ -96,8 +96,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 	// --- Step 5: Retrieve and Verify Player Aggregated Stats ---
 	reqAggPlayer, _ := http.NewRequest("GET", "/api/v1/player-stats/player/player1", nil)
 		
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAggPlayer.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAggPlayer.Header.Set("Authorization", authHeader)
 
 	respAggPlayer := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respAggPlayer, reqAggPlayer)
@@ -117,8 +117,8 @@ func TestAggregationFlowE2E(t *testing.T) {
 	// --- Step 6: Retrieve and Verify Team Aggregated Stats ---
 	reqAggTeam, _ := http.NewRequest("GET", "/api/v1/player-stats/team/team1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAggTeam.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAggTeam.Header.Set("Authorization", authHeader)
 
 	respAggTeam := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respAggTeam, reqAggTeam)
@@ -32,7 +32,7 @@ func TestAuthorizedAccessE2E(t *testing.T) {
 
 	req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer valid-token") // Assuming valid token logic exists
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(resp, req)
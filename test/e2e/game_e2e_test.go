@@ -28,8 +28,8 @@ func TestCreateAndGetGameE2E(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(resp, req)
@@ -38,8 +38,8 @@ func TestCreateAndGetGameE2E(t *testing.T) {
 	// --- Step 2: Retrieve the created game ---
 	reqGet, _ := http.NewRequest("GET", "/api/v1/games/game1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGet.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGet.Header.Set("Authorization", authHeader)
 
 	respGet := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respGet, reqGet)
@@ -0,0 +1,14 @@
+-- Create Seasons table
+CREATE TABLE seasons (
+    id TEXT PRIMARY KEY,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    playoffs_start TIMESTAMP
+);
+
+-- Assign games to seasons
+ALTER TABLE games ADD COLUMN season_id TEXT REFERENCES seasons(id);
+ALTER TABLE games ADD COLUMN season_type TEXT NOT NULL DEFAULT 'regular';
+
+-- Index games by season for season-scoped aggregates
+CREATE INDEX idx_games_season ON games (season_id, season_type);
@@ -0,0 +1,10 @@
+-- Scope API keys and assign scorekeepers to the games they may log stats for.
+-- Keys issued before scopes existed keep the full access they had.
+ALTER TABLE api_keys ADD COLUMN scopes TEXT NOT NULL DEFAULT '';
+UPDATE api_keys SET scopes = 'catalog:admin';
+
+CREATE TABLE game_scorekeepers (
+    game_id TEXT NOT NULL REFERENCES games(id),
+    subject TEXT NOT NULL,
+    PRIMARY KEY (game_id, subject)
+);
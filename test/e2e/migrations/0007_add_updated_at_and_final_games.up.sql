@@ -0,0 +1,8 @@
+-- Record when players, teams and games were last changed, so that clients can make
+-- conditional requests. Rows changed before this migration have no timestamp.
+ALTER TABLE players ADD COLUMN updated_at TIMESTAMP;
+ALTER TABLE teams ADD COLUMN updated_at TIMESTAMP;
+ALTER TABLE games ADD COLUMN updated_at TIMESTAMP;
+
+-- A final game can no longer be changed.
+ALTER TABLE games ADD COLUMN final BOOLEAN NOT NULL DEFAULT FALSE;
@@ -22,8 +22,8 @@ func TestCreateAndGetPlayerE2E(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(resp, req)
@@ -33,8 +33,8 @@ func TestCreateAndGetPlayerE2E(t *testing.T) {
 	// Step 2: Retrieve the player
 	reqGet, _ := http.NewRequest("GET", "/api/v1/players/player1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGet.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGet.Header.Set("Authorization", authHeader)
 
 	respGet := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respGet, reqGet)
@@ -47,8 +47,8 @@ func TestCreateAndGetPlayerE2E(t *testing.T) {
 func TestGetNonExistentPlayerE2E(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/api/v1/players/nonexistent", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(resp, req)
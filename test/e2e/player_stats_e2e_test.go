@@ -26,8 +26,8 @@ func TestPlayerStatsE2E(t *testing.T) {
 	reqTeam, _ := http.NewRequest("POST", "/api/v1/teams", bytes.NewBuffer(teamBody))
 	reqTeam.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqTeam.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqTeam.Header.Set("Authorization", authHeader)
 
 	respTeam := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respTeam, reqTeam)
@@ -44,8 +44,8 @@ func TestPlayerStatsE2E(t *testing.T) {
 	reqPlayer, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	reqPlayer.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqPlayer.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqPlayer.Header.Set("Authorization", authHeader)
 
 	respPlayer := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respPlayer, reqPlayer)
@@ -57,14 +57,15 @@ func TestPlayerStatsE2E(t *testing.T) {
 		Date:     time.Now(),
 		HomeTeam: "team1",
 		AwayTeam: "team2",
+		Status:   domain.GameStatusLive,
 	}
 	gameBody, err := json.Marshal(newGame)
 	assert.NoError(t, err)
 	reqGame, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 	reqGame.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGame.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGame.Header.Set("Authorization", authHeader)
 
 	respGame := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respGame, reqGame)
@@ -106,8 +107,8 @@ func TestPlayerStatsE2E(t *testing.T) {
 		reqStats, _ := http.NewRequest("POST", "/api/v1/player-stats", bytes.NewBuffer(statsBody))
 		reqStats.Header.Set("Content-Type", "application/json")
 
-		// For endpoints with middleware, add a dummy Authorization header.
-		reqStats.Header.Set("Authorization", "dummy-token")
+		// For endpoints with middleware, authenticate with the test token.
+		reqStats.Header.Set("Authorization", authHeader)
 
 		respStats := httptest.NewRecorder()
 		testServer.Handler.ServeHTTP(respStats, reqStats)
@@ -117,8 +118,8 @@ func TestPlayerStatsE2E(t *testing.T) {
 	// --- Step 5: Retrieve and Verify Player Aggregate Stats ---
 	reqAgg, _ := http.NewRequest("GET", "/api/v1/player-stats/player/player1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAgg.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAgg.Header.Set("Authorization", authHeader)
 
 	respAgg := httptest.NewRecorder()
 	testServer.Handler.ServeHTTP(respAgg, reqAgg)
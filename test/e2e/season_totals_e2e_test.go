@@ -0,0 +1,71 @@
+// test/e2e/season_totals_e2e_test.go
+package e2e_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentLinesE2E logs the lines of teammates in the same game at once. Under
+// READ COMMITTED each transaction must still see the lines committed before it, so
+// that the game counts once towards the team's season totals.
+func TestConcurrentLinesE2E(t *testing.T) {
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		testServer.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", domain.Team{ID: "concurrent", Name: "Concurrent"}).Code)
+	const players = 10
+	for i := 0; i < players; i++ {
+		p := domain.Player{ID: fmt.Sprintf("concurrent-p%d", i), Name: fmt.Sprintf("Player %d", i), TeamID: "concurrent"}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	game := domain.Game{ID: "concurrent-g1", Date: time.Now().Add(-time.Hour), HomeTeam: "concurrent", AwayTeam: "team2", Status: domain.GameStatusLive}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+
+	var wg sync.WaitGroup
+	codes := make([]int, players)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			line := domain.PlayerGameStats{ID: fmt.Sprintf("concurrent-s%d", i), PlayerID: fmt.Sprintf("concurrent-p%d", i), GameID: "concurrent-g1", Points: 10, MinutesPlayed: 20}
+			codes[i] = do("POST", "/api/v1/player-stats", line).Code
+		}(i)
+	}
+	wg.Wait()
+	for i, code := range codes {
+		assert.Equal(t, http.StatusCreated, code, "line %d", i)
+	}
+
+	resp := do("GET", "/api/v1/player-stats/team/concurrent", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var team domain.AggregateStats
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &team))
+	assert.Equal(t, 1, team.GamesPlayed)
+	assert.Equal(t, 10*players, team.TotalPoints)
+
+	var out bytes.Buffer
+	assert.NoError(t, app.RunTotalsCommand([]string{"check"}, &out), out.String())
+}
@@ -34,8 +34,8 @@ func TestAggregationServiceIntegration(t *testing.T) {
 	reqTeam, _ := http.NewRequest("POST", "/api/v1/teams", bytes.NewBuffer(teamBody))
 	reqTeam.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqTeam.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqTeam.Header.Set("Authorization", authHeader)
 
 	respTeam := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respTeam, reqTeam)
@@ -52,8 +52,8 @@ func TestAggregationServiceIntegration(t *testing.T) {
 	reqPlayer, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	reqPlayer.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqPlayer.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqPlayer.Header.Set("Authorization", authHeader)
 
 	respPlayer := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respPlayer, reqPlayer)
@@ -65,14 +65,15 @@ func TestAggregationServiceIntegration(t *testing.T) {
 		Date:     time.Now(),
 		HomeTeam: "team1",
 		AwayTeam: "team2",
+		Status:   domain.GameStatusLive,
 	}
 	gameBody, err := json.Marshal(newGame)
 	assert.NoError(t, err)
 	reqGame, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 	reqGame.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGame.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGame.Header.Set("Authorization", authHeader)
 
 	respGame := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respGame, reqGame)
@@ -115,8 +116,8 @@ func TestAggregationServiceIntegration(t *testing.T) {
 		reqStats, _ := http.NewRequest("POST", "/api/v1/player-stats", bytes.NewBuffer(statsBody))
 		reqStats.Header.Set("Content-Type", "application/json")
 
-		// For endpoints with middleware, add a dummy Authorization header.
-		reqStats.Header.Set("Authorization", "dummy-token")
+		// For endpoints with middleware, authenticate with the test token.
+		reqStats.Header.Set("Authorization", authHeader)
 
 		respStats := httptest.NewRecorder()
 		server.Handler.ServeHTTP(respStats, reqStats)
@@ -126,8 +127,8 @@ func TestAggregationServiceIntegration(t *testing.T) {
 	// --- Step 5: Retrieve and Verify Player Aggregate Stats ---
 	reqAggPlayer, _ := http.NewRequest("GET", "/api/v1/player-stats/player/player1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAggPlayer.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAggPlayer.Header.Set("Authorization", authHeader)
 	
 	respAggPlayer := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respAggPlayer, reqAggPlayer)
@@ -137,8 +138,7 @@ func TestAggregationServiceIntegration(t *testing.T) {
 	err = json.Unmarshal(respAggPlayer.Body.Bytes(), &playerAgg)
 	assert.NoError(t, err)
 
-	jsonPlayerAgg, _ := json.MarshalIndent(playerAgg, "", "")
-	logger.Info("Player agg stats: %s", jsonPlayerAgg)
+	logger.Info("Player aggregate", "aggregate", playerAgg)
 
 	// Since we logged two stat entries for one game, GamesPlayed should be 1 if the aggregation is grouped per game.
 	assert.Equal(t, 1, playerAgg.GamesPlayed)
@@ -152,8 +152,8 @@ func TestAggregationServiceIntegration(t *testing.T) {
 	// --- Step 6: Retrieve and Verify Team Aggregate Stats ---
 	reqAggTeam, _ := http.NewRequest("GET", "/api/v1/player-stats/team/team1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAggTeam.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAggTeam.Header.Set("Authorization", authHeader)
 
 	respAggTeam := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respAggTeam, reqAggTeam)
@@ -25,8 +25,8 @@ func TestCreatePlayer(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	server.Handler.ServeHTTP(resp, req)
@@ -48,8 +48,8 @@ func TestGetPlayerByID(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	server.Handler.ServeHTTP(resp, req)
@@ -59,8 +59,8 @@ func TestGetPlayerByID(t *testing.T) {
 
 	req, _ = http.NewRequest("GET", "/api/v1/players/player2", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp = httptest.NewRecorder()
 
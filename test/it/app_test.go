@@ -1,9 +1,11 @@
 package integration_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -23,3 +25,32 @@ func TestAppInitialization(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.Contains(t, resp.Body.String(), `"status":"ok"`)
 }
+
+func TestAppGracefulShutdown(t *testing.T) {
+	t.Setenv("DATABASE_URL", ":memory:")
+	t.Setenv("PORT", "0")
+	t.Setenv("SHUTDOWN_DRAIN_DELAY", "200ms")
+	server := app.Initialize()
+
+	ready := func() int {
+		req, _ := http.NewRequest("GET", "/health/ready", nil)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	assert.Equal(t, http.StatusOK, ready())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+	cancel()
+
+	// Readiness fails while the server drains, then the server stops.
+	assert.Eventually(t, func() bool { return ready() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not shut down")
+	}
+}
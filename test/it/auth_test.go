@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/api"
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthentication issues an API key from the command line, then checks that
+// requests are accepted with a valid JWT or API key and rejected otherwise.
+func TestAuthentication(t *testing.T) {
+	// The command and the server share a database file.
+	os.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "nba.db"))
+	defer os.Setenv("DATABASE_URL", ":memory:")
+	require.NoError(t, app.RunMigrateCommand([]string{"up"}, &bytes.Buffer{}))
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunAPIKeyCommand([]string{"create", "bootstrap", "catalog:admin"}, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	bootstrapKey := lines[1]
+
+	server := app.Initialize()
+	do := func(method, path string, header http.Header, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header = header
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	bearer := func(claims jwt.Claims) http.Header {
+		token, err := jwt.SignHS256(claims, "", []byte(testJWTSecret))
+		require.NoError(t, err)
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+	apiKey := func(key string) http.Header {
+		header := http.Header{}
+		header.Set(api.APIKeyHeader, key)
+		return header
+	}
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	// Rejected credentials.
+	for name, header := range map[string]http.Header{
+		"none":          {},
+		"expired token": bearer(jwt.Claims{Subject: "alice", ExpiresAt: time.Now().Add(-time.Hour).Unix()}),
+		"unknown key":   apiKey("nbak_unknown"),
+		"basic auth":    {"Authorization": {"Basic YWxpY2U6c2VjcmV0"}},
+	} {
+		resp := do("GET", "/api/v1/teams", header, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, name)
+		assert.Equal(t, `Bearer realm="nba-stats"`, resp.Header().Get("WWW-Authenticate"), name)
+	}
+
+	// The bootstrap key and a valid JWT are both accepted.
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/teams", apiKey(bootstrapKey), nil).Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/teams", bearer(jwt.Claims{Subject: "alice", ExpiresAt: expiry, Scope: "stats:read"}), nil).Code)
+
+	// Issue a read-only key over the API, use it, then revoke it.
+	resp := do("POST", "/api/v1/api-keys", apiKey(bootstrapKey), map[string]interface{}{"name": "ci", "scopes": []string{"stats:read"}})
+	require.Equal(t, http.StatusCreated, resp.Code)
+	var created struct {
+		domain.APIKey
+		Key string `json:"key"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/teams", apiKey(created.Key), nil).Code)
+
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/api-keys", apiKey(created.Key), nil).Code)
+
+	resp = do("GET", "/api/v1/api-keys", apiKey(bootstrapKey), nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), created.Key)
+	var listed []domain.APIKey
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/api-keys/"+created.ID, apiKey(bootstrapKey), nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/teams", apiKey(created.Key), nil).Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/teams", apiKey(bootstrapKey), nil).Code)
+
+	out.Reset()
+	require.NoError(t, app.RunAPIKeyCommand([]string{"list"}, &out))
+	assert.Contains(t, out.String(), "bootstrap")
+	assert.Contains(t, out.String(), "catalog:admin")
+	assert.Contains(t, out.String(), "revoked")
+}
@@ -0,0 +1,86 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthorization checks that readers cannot change anything and that scorekeepers
+// may only log stats for the games they are assigned to.
+func TestAuthorization(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	token := func(subject, scope string) string {
+		token, err := jwt.SignHS256(jwt.Claims{Subject: subject, ExpiresAt: time.Now().Add(time.Hour).Unix(), Scope: scope}, "", []byte(testJWTSecret))
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+	reader := token("reader", domain.ScopeStatsRead)
+	scorekeeper := token("scorekeeper", domain.ScopeStatsWrite)
+	do := func(auth, method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if v != nil {
+			var err error
+			body, err = json.Marshal(v)
+			assert.NoError(t, err)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", auth)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Only admins manage the catalog.
+	assert.Equal(t, http.StatusForbidden, do(reader, "POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "team1"}).Code)
+	assert.Equal(t, http.StatusForbidden, do(scorekeeper, "POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "team1"}).Code)
+	assert.Equal(t, http.StatusCreated, do(authHeader, "POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "team1"}).Code)
+	for _, id := range []string{"g1", "g2"} {
+		game := domain.Game{ID: id, Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive}
+		assert.Equal(t, http.StatusCreated, do(authHeader, "POST", "/api/v1/games", game).Code)
+	}
+	assert.Equal(t, http.StatusNoContent, do(authHeader, "PUT", "/api/v1/games/g1/scorekeepers/scorekeeper", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do(authHeader, "PUT", "/api/v1/games/missing/scorekeepers/scorekeeper", nil).Code)
+	resp := do(authHeader, "GET", "/api/v1/games/g1/scorekeepers", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `["scorekeeper"]`, resp.Body.String())
+
+	line := func(gameID string) domain.PlayerGameStats {
+		return domain.PlayerGameStats{ID: "s-" + gameID, PlayerID: "p1", GameID: gameID, Points: 10, MinutesPlayed: 20}
+	}
+	boxScore := domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "p1", Points: 12, MinutesPlayed: 24}}}
+
+	// Readers read but never log stats.
+	assert.Equal(t, http.StatusOK, do(reader, "GET", "/api/v1/games/g1", nil).Code)
+	assert.Equal(t, http.StatusForbidden, do(reader, "POST", "/api/v1/player-stats", line("g1")).Code)
+
+	// The scorekeeper logs stats for its game only.
+	resp = do(scorekeeper, "POST", "/api/v1/player-stats", line("g2"))
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"type":"urn:nba-stats:problem:forbidden"`)
+	assert.Equal(t, http.StatusForbidden, do(scorekeeper, "POST", "/api/v1/games/g2/box-score", boxScore).Code)
+	assert.Equal(t, http.StatusCreated, do(scorekeeper, "POST", "/api/v1/player-stats", line("g1")).Code)
+
+	// Once unassigned, the scorekeeper can no longer log stats for the game.
+	assert.Equal(t, http.StatusNoContent, do(authHeader, "DELETE", "/api/v1/games/g1/scorekeepers/scorekeeper", nil).Code)
+	assert.Equal(t, http.StatusForbidden, do(scorekeeper, "POST", "/api/v1/games/g1/box-score", boxScore).Code)
+
+	// Deleting a game removes its assignments with it.
+	assert.Equal(t, http.StatusNoContent, do(authHeader, "PUT", "/api/v1/games/g2/scorekeepers/scorekeeper", nil).Code)
+	assert.Equal(t, http.StatusNoContent, do(authHeader, "DELETE", "/api/v1/games/g2", nil).Code)
+}
@@ -0,0 +1,159 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogBoxScore(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if v != nil {
+			var err error
+			body, err = json.Marshal(v)
+			assert.NoError(t, err)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		// For endpoints with middleware, authenticate with the test token.
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "team1"}).Code)
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "p2", Name: "Two", TeamID: "team1"}).Code)
+	game := domain.Game{ID: "g1", Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive}
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+
+	// One unknown player rejects the whole box score and nothing is written.
+	rejected := do("POST", "/api/v1/games/g1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "p1", Points: 20, MinutesPlayed: 30},
+		{PlayerID: "nobody", Points: 5, MinutesPlayed: 10},
+	}})
+	assert.Equal(t, http.StatusUnprocessableEntity, rejected.Code)
+	assert.Contains(t, rejected.Body.String(), `{"field":"lines[1].player_id","code":"not_found","message":"player nobody does not exist"}`)
+
+	getAggregate := func() domain.AggregateStats {
+		resp := do("GET", "/api/v1/player-stats/team/team1", nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var agg domain.AggregateStats
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &agg))
+		return agg
+	}
+	assert.Equal(t, 0, getAggregate().TotalPoints)
+
+	accepted := do("POST", "/api/v1/games/g1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "p1", Points: 20, MinutesPlayed: 30},
+		{PlayerID: "p2", Points: 5, MinutesPlayed: 10},
+	}})
+	assert.Equal(t, http.StatusCreated, accepted.Code, accepted.Body.String())
+	agg := getAggregate()
+	assert.Equal(t, 1, agg.GamesPlayed)
+	assert.Equal(t, 25, agg.TotalPoints)
+
+	// Submitting the same box score again collides on the line IDs and stores nothing more.
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/games/g1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "p2", Points: 5, MinutesPlayed: 10},
+		{PlayerID: "p1", Points: 20, MinutesPlayed: 30},
+	}}).Code)
+	assert.Equal(t, 25, getAggregate().TotalPoints)
+
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/v1/games/nope/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "p1"}}}).Code)
+}
+
+func TestGetBoxScore(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if v != nil {
+			var err error
+			body, err = json.Marshal(v)
+			assert.NoError(t, err)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	getBoxScore := func() domain.GameBoxScore {
+		resp := do("GET", "/api/v1/games/g1/box-score", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var boxScore domain.GameBoxScore
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &boxScore))
+		return boxScore
+	}
+
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", domain.Team{ID: "bos", Name: "Celtics"}).Code)
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", domain.Team{ID: "nyk", Name: "Knicks"}).Code)
+	for _, p := range []domain.Player{{ID: "p1", Name: "One", TeamID: "bos"}, {ID: "p2", Name: "Two", TeamID: "bos"}, {ID: "p3", Name: "Three", TeamID: "nyk"}} {
+		assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	game := domain.Game{ID: "g1", Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "nyk", AwayTeam: "bos", Status: domain.GameStatusLive, HomeScore: 9, AwayScore: 25}
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+
+	// Both teams are listed before any line is logged.
+	boxScore := getBoxScore()
+	assert.Equal(t, 25, boxScore.Game.AwayScore)
+	if assert.Len(t, boxScore.Teams, 2) {
+		assert.Equal(t, "nyk", boxScore.Teams[0].TeamID)
+		assert.True(t, boxScore.Teams[0].Home)
+		assert.Empty(t, boxScore.Teams[1].Lines)
+	}
+
+	starter, bench := true, false
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/games/g1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "p2", Points: 5, MinutesPlayed: 12, Starter: &bench, FreeThrowsMade: 1, FreeThrowsAttempted: 2, FieldGoalsMade: 2, FieldGoalsAttempted: 5},
+		{PlayerID: "p1", Points: 20, MinutesPlayed: 30, Starter: &starter, FieldGoalsMade: 8, FieldGoalsAttempted: 15, ThreePointersMade: 2, ThreePointersAttempted: 5, FreeThrowsMade: 2, FreeThrowsAttempted: 2},
+		{PlayerID: "p3", Points: 9, MinutesPlayed: 36},
+	}}).Code)
+
+	boxScore = getBoxScore()
+	if !assert.Len(t, boxScore.Teams, 2) {
+		return
+	}
+	knicks, celtics := boxScore.Teams[0], boxScore.Teams[1]
+	assert.Equal(t, "Knicks", knicks.TeamName)
+	if assert.Len(t, knicks.Lines, 1) {
+		assert.Nil(t, knicks.Lines[0].Starter)
+		assert.Equal(t, "Three", knicks.Lines[0].PlayerName)
+	}
+	assert.Equal(t, 9, knicks.Totals.Points)
+
+	assert.Equal(t, "Celtics", celtics.TeamName)
+	assert.False(t, celtics.Home)
+	if assert.Len(t, celtics.Lines, 2) {
+		assert.Equal(t, "p1", celtics.Lines[0].PlayerID)
+		assert.True(t, *celtics.Lines[0].Starter)
+		assert.Equal(t, "p2", celtics.Lines[1].PlayerID)
+		assert.False(t, *celtics.Lines[1].Starter)
+	}
+	assert.Equal(t, domain.BoxScoreTotals{
+		Points: 25, MinutesPlayed: 42,
+		FieldGoalsMade: 10, FieldGoalsAttempted: 20, ThreePointersMade: 2, ThreePointersAttempted: 5,
+		FreeThrowsMade: 3, FreeThrowsAttempted: 4,
+		FieldGoalPct: 0.5, ThreePointPct: 0.4, FreeThrowPct: 0.75,
+	}, celtics.Totals)
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/games/nope/box-score", nil).Code)
+}
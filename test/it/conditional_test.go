@@ -0,0 +1,73 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConditionalGame checks that a game is revalidated with its ETag and
+// Last-Modified, and that once it is final it is cached as immutable and can no
+// longer be changed.
+func TestConditionalGame(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}, header http.Header) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	game := domain.Game{ID: "cond1", Date: time.Now(), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game, nil).Code)
+
+	resp := do("GET", "/api/v1/games/cond1", nil, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	etag := resp.Header().Get("ETag")
+	lastModified := resp.Header().Get("Last-Modified")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, lastModified)
+	assert.Equal(t, "private, max-age=60", resp.Header().Get("Cache-Control"))
+
+	resp = do("GET", "/api/v1/games/cond1", nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	resp = do("GET", "/api/v1/games/cond1", nil, http.Header{"If-Modified-Since": {lastModified}})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	// Making the game final changes it.
+	final := map[string]interface{}{"status": "final", "home_score": 101, "away_score": 99}
+	resp = do("PATCH", "/api/v1/games/cond1", final, nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	resp = do("GET", "/api/v1/games/cond1", nil, http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+	assert.Equal(t, "private, max-age=86400, immutable", resp.Header().Get("Cache-Control"))
+	assert.Contains(t, resp.Body.String(), `"status":"final"`)
+
+	// After which it can no longer be changed.
+	game.AwayTeam = "team3"
+	assert.Equal(t, http.StatusConflict, do("PUT", "/api/v1/games/cond1", game, nil).Code)
+	assert.Equal(t, http.StatusConflict, do("PATCH", "/api/v1/games/cond1", map[string]string{"status": "live"}, nil).Code)
+}
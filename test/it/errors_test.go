@@ -0,0 +1,58 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatusCodes(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		// For endpoints with middleware, authenticate with the test token.
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/teams/nope", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/games/nope", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("PUT", "/api/v1/teams/nope", domain.Team{Name: "Nobody"}).Code)
+
+	// Validation failures are 422, duplicates 409.
+	assert.Equal(t, http.StatusUnprocessableEntity, do("POST", "/api/v1/players", domain.Player{ID: "p1"}).Code)
+	player := domain.Player{ID: "p1", Name: "Ann", TeamID: "team1"}
+	assert.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", player).Code)
+	duplicate := do("POST", "/api/v1/players", player)
+	assert.Equal(t, http.StatusConflict, duplicate.Code)
+	assert.Contains(t, duplicate.Body.String(), "player p1 already exists")
+
+	// Stats for a game that does not exist refer to a missing entity.
+	line := domain.PlayerGameStats{ID: "s1", PlayerID: "p1", GameID: "nope", MinutesPlayed: 10}
+	assert.Equal(t, http.StatusUnprocessableEntity, do("POST", "/api/v1/player-stats", line).Code)
+
+	game := domain.Game{ID: "g1", Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", SeasonID: "1999-00"}
+	assert.Equal(t, http.StatusUnprocessableEntity, do("POST", "/api/v1/games", game).Code)
+
+	// Malformed query parameters are 400.
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/player-stats/player/p1?season_type=preseason", nil).Code)
+}
@@ -0,0 +1,122 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGameLogs lists a player's and a team's lines game by game, filtered, sorted and
+// paged with cursors while more games are logged.
+func TestGameLogs(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	getLog := func(path string) domain.GameLog {
+		resp := do("GET", path, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var log domain.GameLog
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &log))
+		return log
+	}
+	ids := func(log domain.GameLog) []string {
+		ids := make([]string, len(log.Lines))
+		for i, line := range log.Lines {
+			ids[i] = line.GameID
+		}
+		return ids
+	}
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 19, 0, 0, 0, time.UTC) }
+	game := func(id string, d int, home, away string) {
+		g := domain.Game{ID: id, Date: day(d), HomeTeam: home, AwayTeam: away, Status: domain.GameStatusLive}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", g).Code)
+	}
+	line := func(player, gameID string, points int) {
+		stats := domain.PlayerGameStats{PlayerID: player, GameID: gameID, Points: points, MinutesPlayed: 30}
+		stats.ID = player + "-" + gameID
+		resp := do("POST", "/api/v1/player-stats", stats)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	}
+
+	for _, team := range []string{"bos", "nyk", "mia"} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", domain.Team{ID: team, Name: team}).Code)
+	}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "bos"}).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "p2", Name: "Two", TeamID: "bos"}).Code)
+	game("g1", 1, "bos", "nyk")
+	game("g2", 2, "mia", "bos")
+	game("g3", 3, "nyk", "bos")
+	game("g4", 4, "bos", "mia")
+	for i, points := range []int{20, 35, 20, 10} {
+		line("p1", fmt.Sprintf("g%d", i+1), points)
+	}
+	line("p2", "g1", 12)
+
+	// Most recent games first, seen from the player's team.
+	log := getLog("/api/v1/players/p1/games")
+	require.Equal(t, []string{"g4", "g3", "g2", "g1"}, ids(log))
+	assert.Empty(t, log.NextCursor)
+	assert.Equal(t, "mia", log.Lines[0].OpponentID)
+	assert.True(t, log.Lines[0].Home)
+	assert.Equal(t, "nyk", log.Lines[1].OpponentID)
+	assert.False(t, log.Lines[1].Home)
+	assert.Equal(t, "bos", log.Lines[1].TeamID)
+	assert.True(t, log.Lines[1].Date.Equal(day(3)))
+	assert.Equal(t, 20, log.Lines[1].Points)
+
+	assert.Equal(t, []string{"g3", "g2"}, ids(getLog("/api/v1/players/p1/games?location=away")))
+	assert.Equal(t, []string{"g3", "g1"}, ids(getLog("/api/v1/players/p1/games?opponent=nyk")))
+	assert.Equal(t, []string{"g2", "g3"}, ids(getLog("/api/v1/players/p1/games?from=2026-01-02&to=2026-01-03&order=asc")))
+
+	// Ties on points are broken by line ID, in the same direction.
+	sorted := "/api/v1/players/p1/games?sort=points&limit=2"
+	page := getLog(sorted)
+	require.Equal(t, []string{"g2", "g3"}, ids(page))
+	require.NotEmpty(t, page.NextCursor)
+
+	// Lines logged in the meantime, before or after the cursor, leave the next page as it was.
+	game("g5", 5, "nyk", "bos")
+	line("p1", "g5", 40)
+	game("g6", 6, "mia", "bos")
+	line("p1", "g6", 5)
+	page = getLog(sorted + "&cursor=" + url.QueryEscape(page.NextCursor))
+	require.Equal(t, []string{"g1", "g4"}, ids(page))
+	page = getLog(sorted + "&cursor=" + url.QueryEscape(page.NextCursor))
+	assert.Equal(t, []string{"g6"}, ids(page))
+	assert.Empty(t, page.NextCursor)
+
+	// A team's log lists every line logged for it.
+	team := getLog("/api/v1/teams/bos/games?opponent=nyk&sort=points&order=asc")
+	assert.Equal(t, []string{"g1", "g1", "g3", "g5"}, ids(team))
+	assert.Equal(t, "p2", team.Lines[0].PlayerID)
+
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/players/p1/games?sort=height", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/players/p1/games?cursor=abc", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/players/p1/games?sort=points&order=asc&cursor="+url.QueryEscape(getLog(sorted).NextCursor), nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/players/ghost/games", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/teams/ghost/games", nil).Code)
+}
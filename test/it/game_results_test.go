@@ -0,0 +1,101 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGameResults walks a game through its status lifecycle: stats are rejected until
+// it starts and once it is final, and it can only be made final with the score its stat
+// lines add up to.
+func TestGameResults(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	getGame := func(id string) domain.Game {
+		resp := do("GET", "/api/v1/games/"+id, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var game domain.Game
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &game))
+		return game
+	}
+
+	for _, p := range []domain.Player{{ID: "h1", Name: "Home", TeamID: "home"}, {ID: "a1", Name: "Away", TeamID: "away"}} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	game := domain.Game{ID: "r1", Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "home", AwayTeam: "away"}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	assert.Equal(t, domain.GameStatusScheduled, getGame("r1").Status)
+
+	// No stats before the game starts, and no skipping ahead to final.
+	lines := domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "h1", Points: 104, MinutesPlayed: 48},
+		{PlayerID: "a1", Points: 102, MinutesPlayed: 48},
+	}}
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/games/r1/box-score", lines).Code)
+	assert.Equal(t, http.StatusConflict, do("PATCH", "/api/v1/games/r1", map[string]interface{}{"status": "final", "home_score": 1, "away_score": 0}).Code)
+
+	require.Equal(t, http.StatusOK, do("PATCH", "/api/v1/games/r1", map[string]string{"status": "live"}).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games/r1/box-score", lines).Code)
+
+	// The final score must match the stat lines.
+	periods := []domain.PeriodScore{
+		{Period: 1, Home: 25, Away: 22}, {Period: 2, Home: 20, Away: 28},
+		{Period: 3, Home: 25, Away: 25}, {Period: 4, Home: 25, Away: 27},
+		{Period: 5, Home: 9, Away: 0},
+	}
+	resp := do("PATCH", "/api/v1/games/r1", map[string]interface{}{"status": "final", "home_score": 104, "away_score": 102, "periods": periods})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"field":"periods[4]"`)
+
+	periods[3].Away = 20
+	periods[4].Away = 5
+	resp = do("PATCH", "/api/v1/games/r1", map[string]interface{}{"status": "final", "home_score": 104, "away_score": 100, "periods": periods})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "away_score 100 does not match the 102 points")
+
+	periods[4].Away = 7
+	resp = do("PATCH", "/api/v1/games/r1", map[string]interface{}{"status": "final", "home_score": 104, "away_score": 102, "periods": periods})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	final := getGame("r1")
+	assert.Equal(t, domain.GameStatusFinal, final.Status)
+	assert.Equal(t, periods, final.Periods)
+	assert.Equal(t, 1, final.Overtimes)
+	assert.Equal(t, "home", final.Winner)
+	assert.Equal(t, http.StatusConflict, do("PATCH", "/api/v1/games/r1", map[string]string{"status": "cancelled"}).Code)
+
+	// Nor can lines be added to it, which would break the score it was made final with.
+	late := domain.PlayerGameStats{ID: "r1-h2", PlayerID: "h1", GameID: "r1", Points: 3, MinutesPlayed: 5}
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/player-stats", late).Code)
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/games/r1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{late}}).Code)
+
+	// A cancelled game takes no stats either.
+	game = domain.Game{ID: "r2", Date: time.Date(2025, 11, 2, 19, 0, 0, 0, time.UTC), HomeTeam: "home", AwayTeam: "away", Status: domain.GameStatusCancelled}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	line := domain.PlayerGameStats{ID: "r2-h1", PlayerID: "h1", GameID: "r2", Points: 10, MinutesPlayed: 20}
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/player-stats", line).Code)
+}
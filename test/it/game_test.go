@@ -33,8 +33,8 @@ func TestCreateAndGetGame(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	server.Handler.ServeHTTP(resp, req)
@@ -43,8 +43,8 @@ func TestCreateAndGetGame(t *testing.T) {
 	// Retrieve the created game.
 	reqGet, _ := http.NewRequest("GET", "/api/v1/games/game1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGet.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGet.Header.Set("Authorization", authHeader)
 
 	respGet := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respGet, reqGet)
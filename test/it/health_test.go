@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3" // Import SQLite driver
@@ -36,7 +37,7 @@ func TestReadinessProbe_Healthy(t *testing.T) {
 	defer db.Close()
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health/ready", api.ReadinessProbeHandler(db))
+	mux.HandleFunc("/health/ready", api.ReadinessProbeHandler(db, nil))
 
 	req, _ := http.NewRequest("GET", "/health/ready", nil)
 	resp := httptest.NewRecorder()
@@ -46,3 +47,27 @@ func TestReadinessProbe_Healthy(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.Contains(t, resp.Body.String(), `"status":"ready"`)
 }
+
+func TestReadinessProbe_Draining(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		fmt.Printf("Error during db creation: %v", err)
+	}
+	defer db.Close()
+
+	var draining atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health/ready", api.ReadinessProbeHandler(db, &draining))
+
+	req, _ := http.NewRequest("GET", "/health/ready", nil)
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Once the server starts shutting down, it is reported as unavailable.
+	draining.Store(true)
+	resp = httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"draining"`)
+}
@@ -0,0 +1,107 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaders(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	post := func(path string, v interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(v)
+		assert.NoError(t, err)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		// For endpoints with middleware, authenticate with the test token.
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	get := func(path string) []domain.Leader {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var leaders []domain.Leader
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &leaders))
+		return leaders
+	}
+
+	for _, p := range []domain.Player{
+		{ID: "ann", Name: "Ann", TeamID: "team1"},
+		{ID: "bob", Name: "Bob", TeamID: "team1"},
+		{ID: "cat", Name: "Cat", TeamID: "team2"},
+	} {
+		assert.Equal(t, http.StatusCreated, post("/api/v1/players", p).Code)
+	}
+	for _, id := range []string{"g1", "g2"} {
+		game := domain.Game{ID: id, Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive}
+		assert.Equal(t, http.StatusCreated, post("/api/v1/games", game).Code)
+	}
+
+	// Ann and Bob both average 8 assists over two games; Cat averages 12 but played once.
+	lines := []domain.PlayerGameStats{
+		{ID: "s1", PlayerID: "ann", GameID: "g1", Assists: 6, MinutesPlayed: 30},
+		{ID: "s2", PlayerID: "ann", GameID: "g2", Assists: 10, MinutesPlayed: 30},
+		{ID: "s3", PlayerID: "bob", GameID: "g1", Assists: 8, MinutesPlayed: 20},
+		{ID: "s4", PlayerID: "bob", GameID: "g2", Assists: 8, MinutesPlayed: 20},
+		{ID: "s5", PlayerID: "cat", GameID: "g1", Assists: 12, MinutesPlayed: 25},
+	}
+	for _, line := range lines {
+		assert.Equal(t, http.StatusCreated, post("/api/v1/player-stats", line).Code)
+	}
+
+	all := get("/api/v1/leaders?stat=avg_assists")
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, "cat", all[0].PlayerID)
+		assert.Equal(t, 1, all[0].Rank)
+		assert.Equal(t, 12.0, all[0].Value)
+		// Tied players share a rank and are listed by ID.
+		assert.Equal(t, "ann", all[1].PlayerID)
+		assert.Equal(t, "bob", all[2].PlayerID)
+		assert.Equal(t, 2, all[1].Rank)
+		assert.Equal(t, 2, all[2].Rank)
+		assert.Equal(t, 16, all[1].TotalAssists)
+	}
+
+	qualified := get("/api/v1/leaders?stat=avg_assists&min_games=2&min_minutes=50")
+	if assert.Len(t, qualified, 1) {
+		assert.Equal(t, "ann", qualified[0].PlayerID)
+		assert.Equal(t, 1, qualified[0].Rank)
+	}
+
+	// Ranks are global, so a second page continues the numbering.
+	page := get("/api/v1/leaders?stat=avg_assists&limit=1&offset=2")
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, "bob", page[0].PlayerID)
+		assert.Equal(t, 2, page[0].Rank)
+	}
+
+	teams := get("/api/v1/leaders?stat=total_assists&scope=team")
+	if assert.Len(t, teams, 2) {
+		assert.Equal(t, "team1", teams[0].TeamID)
+		assert.Equal(t, 32.0, teams[0].Value)
+		assert.Equal(t, "team2", teams[1].TeamID)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/leaders?stat=shoe_size", nil)
+	req.Header.Set("Authorization", authHeader)
+	resp := httptest.NewRecorder()
+	server.Handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
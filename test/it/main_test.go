@@ -4,8 +4,17 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
 )
 
+// testJWTSecret is the HS256 secret the server under test accepts tokens for.
+const testJWTSecret = "integration-test-secret"
+
+// authHeader is an Authorization header with a token the server under test accepts.
+var authHeader string
+
 func SetWorkDir() {
 	projectRoot := os.Getenv("PROJECT_ROOT")
 	if projectRoot == "" {
@@ -18,6 +27,13 @@ func setup() {
 	fmt.Println("Setting up before running tests...")
 	// Add any necessary initialization logic here (e.g., database connection, config loading, etc.)
 	SetWorkDir()
+
+	os.Setenv("JWT_HS256_SECRET", testJWTSecret)
+	token, err := jwt.SignHS256(jwt.Claims{Subject: "integration-tests", ExpiresAt: time.Now().Add(time.Hour).Unix(), Scope: "catalog:admin"}, "", []byte(testJWTSecret))
+	if err != nil {
+		panic(err)
+	}
+	authHeader = "Bearer " + token
 }
 
 func teardown() {
@@ -0,0 +1,69 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetrics checks that /metrics exposes the connection pool and counts the stat
+// lines stored and the aggregates served.
+func TestMetrics(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+	server := app.Initialize()
+
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if v != nil {
+			var err error
+			body, err = json.Marshal(v)
+			require.NoError(t, err)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	// sample returns the value of a sample of the exposition, or 0 if it is absent.
+	sample := func(series string) float64 {
+		body := do("GET", "/metrics", nil).Body.String()
+		match := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(series) + ` (\S+)$`).FindStringSubmatch(body)
+		if match == nil {
+			return 0
+		}
+		value, err := strconv.ParseFloat(match[1], 64)
+		require.NoError(t, err)
+		return value
+	}
+
+	ingested := sample(`nba_stats_stat_lines_ingested_total{source="box_score"}`)
+	aggregates := sample(`nba_stats_aggregate_queries_total{kind="team"}`)
+
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "m1", Name: "One", TeamID: "team1"}).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "m2", Name: "Two", TeamID: "team1"}).Code)
+	game := domain.Game{ID: "mg1", Date: time.Date(2025, 11, 2, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games/mg1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "m1", Points: 10, MinutesPlayed: 20},
+		{PlayerID: "m2", Points: 12, MinutesPlayed: 25},
+	}}).Code)
+	require.Equal(t, http.StatusOK, do("GET", "/api/v1/player-stats/team/team1", nil).Code)
+
+	assert.Equal(t, ingested+2, sample(`nba_stats_stat_lines_ingested_total{source="box_score"}`))
+	assert.Equal(t, aggregates+1, sample(`nba_stats_aggregate_queries_total{kind="team"}`))
+	assert.Equal(t, float64(1), sample("nba_stats_db_max_open_connections"))
+	assert.GreaterOrEqual(t, sample(`nba_stats_http_requests_total{method="POST",route="/api/v1/games/{id}/box-score",status="201"}`), float64(1))
+}
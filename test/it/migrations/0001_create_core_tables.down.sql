@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS player_game_stats;
+DROP TABLE IF EXISTS games;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS players;
@@ -1,6 +1,3 @@
--- Drop Players table
-DROP TABLE IF EXISTS players CASCADE;
-
 -- Create Players table
 CREATE TABLE IF NOT EXISTS players (
     id TEXT PRIMARY KEY,
@@ -8,18 +5,12 @@ CREATE TABLE IF NOT EXISTS players (
     team_id TEXT NOT NULL
 );
 
--- Drop Teams table
-DROP TABLE IF EXISTS teams CASCADE;
-
 -- Create Teams table
 CREATE TABLE IF NOT EXISTS teams (
     id TEXT PRIMARY KEY,
     name TEXT NOT NULL
 );
 
--- Drop Games table
-DROP TABLE IF EXISTS games CASCADE;
-
 -- Create Games table
 CREATE TABLE IF NOT EXISTS games (
     id TEXT PRIMARY KEY,
@@ -28,9 +19,6 @@ CREATE TABLE IF NOT EXISTS games (
     away_team TEXT NOT NULL
 );
 
--- Drop PlayerGameStats table
-DROP TABLE IF EXISTS player_game_stats CASCADE;
-
 -- Create PlayerGameStats table
 CREATE TABLE IF NOT EXISTS player_game_stats (
     id TEXT PRIMARY KEY,
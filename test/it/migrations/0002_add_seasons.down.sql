@@ -0,0 +1,4 @@
+DROP INDEX idx_games_season;
+ALTER TABLE games DROP COLUMN season_type;
+ALTER TABLE games DROP COLUMN season_id;
+DROP TABLE seasons;
@@ -0,0 +1,8 @@
+DROP TABLE game_periods;
+
+ALTER TABLE games ADD COLUMN final BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE games SET final = TRUE WHERE status = 'final';
+
+ALTER TABLE games DROP COLUMN away_score;
+ALTER TABLE games DROP COLUMN home_score;
+ALTER TABLE games DROP COLUMN status;
@@ -0,0 +1,7 @@
+-- Place teams in their conference and division, which standings are grouped by.
+-- Existing teams are in neither until they are updated.
+ALTER TABLE teams ADD COLUMN conference TEXT NOT NULL DEFAULT '';
+ALTER TABLE teams ADD COLUMN division TEXT NOT NULL DEFAULT '';
+
+-- Standings read the final games of a season.
+CREATE INDEX idx_games_season_status ON games (season_id, status);
@@ -0,0 +1,4 @@
+DROP INDEX idx_player_game_stats_team_game;
+ALTER TABLE player_game_stats DROP COLUMN team_id;
+
+DROP TABLE roster_memberships;
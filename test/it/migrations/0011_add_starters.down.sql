@@ -0,0 +1 @@
+ALTER TABLE player_game_stats DROP COLUMN starter;
@@ -31,8 +31,8 @@ func TestLogPlayerStatsAndGetAggregate(t *testing.T) {
 	reqPlayer, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	reqPlayer.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqPlayer.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqPlayer.Header.Set("Authorization", authHeader)
 
 	respPlayer := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respPlayer, reqPlayer)
@@ -44,14 +44,15 @@ func TestLogPlayerStatsAndGetAggregate(t *testing.T) {
 		Date:     time.Now(),
 		HomeTeam: "team1",
 		AwayTeam: "team2",
+		Status:   domain.GameStatusLive,
 	}
 	gameBody, err := json.Marshal(newGame)
 	assert.NoError(t, err)
 	reqGame, _ := http.NewRequest("POST", "/api/v1/games", bytes.NewBuffer(gameBody))
 	reqGame.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGame.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGame.Header.Set("Authorization", authHeader)
 
 	respGame := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respGame, reqGame)
@@ -76,8 +77,8 @@ func TestLogPlayerStatsAndGetAggregate(t *testing.T) {
 	reqStats, _ := http.NewRequest("POST", "/api/v1/player-stats", bytes.NewBuffer(statsBody))
 	reqStats.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqStats.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqStats.Header.Set("Authorization", authHeader)
 
 	respStats := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respStats, reqStats)
@@ -86,8 +87,8 @@ func TestLogPlayerStatsAndGetAggregate(t *testing.T) {
 	// Retrieve player aggregate stats.
 	reqAgg, _ := http.NewRequest("GET", "/api/v1/player-stats/player/player1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqAgg.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqAgg.Header.Set("Authorization", authHeader)
 
 	respAgg := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respAgg, reqAgg)
@@ -33,8 +33,8 @@ func TestCreateAndGetPlayer(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(playerBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	server.Handler.ServeHTTP(resp, req)
@@ -43,8 +43,8 @@ func TestCreateAndGetPlayer(t *testing.T) {
 	// Retrieve the created player.
 	reqGet, _ := http.NewRequest("GET", "/api/v1/players/player1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGet.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGet.Header.Set("Authorization", authHeader)
 
 	respGet := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respGet, reqGet)
@@ -57,3 +57,56 @@ func TestCreateAndGetPlayer(t *testing.T) {
 	assert.Equal(t, newPlayer.Name, retrievedPlayer.Name)
 	assert.Equal(t, newPlayer.TeamID, retrievedPlayer.TeamID)
 }
+
+func TestUpdateListAndDeletePlayer(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	// Create two players on the same team.
+	for _, p := range []domain.Player{
+		{ID: "player1", Name: "John Doe", TeamID: "team1"},
+		{ID: "player2", Name: "Mike Smith", TeamID: "team1"},
+	} {
+		body, err := json.Marshal(p)
+		assert.NoError(t, err)
+		req, _ := http.NewRequest("POST", "/api/v1/players", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+	}
+
+	// Move player2 to another team with a PATCH.
+	reqPatch, _ := http.NewRequest("PATCH", "/api/v1/players/player2", bytes.NewBufferString(`{"team_id":"team2"}`))
+	reqPatch.Header.Set("Authorization", authHeader)
+	respPatch := httptest.NewRecorder()
+	server.Handler.ServeHTTP(respPatch, reqPatch)
+	assert.Equal(t, http.StatusOK, respPatch.Code)
+
+	// Only player1 remains on team1's roster.
+	reqList, _ := http.NewRequest("GET", "/api/v1/players?team_id=team1", nil)
+	reqList.Header.Set("Authorization", authHeader)
+	respList := httptest.NewRecorder()
+	server.Handler.ServeHTTP(respList, reqList)
+	assert.Equal(t, http.StatusOK, respList.Code)
+
+	var roster []domain.Player
+	err := json.Unmarshal(respList.Body.Bytes(), &roster)
+	assert.NoError(t, err)
+	assert.Len(t, roster, 1)
+	assert.Equal(t, "player1", roster[0].ID)
+
+	// Delete player1, then make sure it is gone.
+	reqDelete, _ := http.NewRequest("DELETE", "/api/v1/players/player1", nil)
+	reqDelete.Header.Set("Authorization", authHeader)
+	respDelete := httptest.NewRecorder()
+	server.Handler.ServeHTTP(respDelete, reqDelete)
+	assert.Equal(t, http.StatusNoContent, respDelete.Code)
+
+	reqGet, _ := http.NewRequest("GET", "/api/v1/players/player1", nil)
+	reqGet.Header.Set("Authorization", authHeader)
+	respGet := httptest.NewRecorder()
+	server.Handler.ServeHTTP(respGet, reqGet)
+	assert.Equal(t, http.StatusNotFound, respGet.Code)
+}
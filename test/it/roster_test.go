@@ -0,0 +1,128 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRosterTransactions records a player's trade, waiver and signing, and checks that
+// each stat line counts towards the team the player was on at game time, whenever the
+// line or the transaction is recorded.
+func TestRosterTransactions(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	teamPoints := func(team string) int {
+		resp := do("GET", "/api/v1/player-stats/team/"+team, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var agg domain.AggregateStats
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &agg))
+		return agg.TotalPoints
+	}
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 19, 0, 0, 0, time.UTC) }
+	logLine := func(game string, points int) *httptest.ResponseRecorder {
+		return do("POST", "/api/v1/player-stats", domain.PlayerGameStats{ID: "p1-" + game, PlayerID: "p1", GameID: game, Points: points, MinutesPlayed: 30})
+	}
+
+	for _, team := range []string{"bos", "nyk", "mia"} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", domain.Team{ID: team, Name: team}).Code)
+	}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", domain.Player{ID: "p1", Name: "One", TeamID: "bos"}).Code)
+	for d := 1; d <= 5; d++ {
+		game := domain.Game{ID: fmt.Sprintf("g%d", d), Date: day(d), HomeTeam: "bos", AwayTeam: "nyk", Status: domain.GameStatusLive}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	}
+	resp := logLine("g1", 10)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	require.Equal(t, http.StatusCreated, logLine("g3", 35).Code)
+
+	// p1 is traded to nyk before game 3, which is recorded after its line.
+	resp = do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionTrade, TeamID: "nyk", Date: day(2).Add(4 * time.Hour)})
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var trade domain.RosterTransaction
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &trade))
+	assert.Equal(t, "p1", trade.PlayerID)
+	assert.Equal(t, "bos", trade.FromTeamID)
+	assert.Equal(t, 10, teamPoints("bos"))
+	assert.Equal(t, 35, teamPoints("nyk"))
+
+	// A line for a game before the trade still counts towards bos.
+	require.Equal(t, http.StatusCreated, logLine("g2", 20).Code)
+	assert.Equal(t, 30, teamPoints("bos"))
+	assert.Equal(t, 35, teamPoints("nyk"))
+
+	// Transactions must fit the player's team and follow the last one.
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionSigning, TeamID: "mia", Date: day(4)}).Code)
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionTrade, TeamID: "mia", Date: day(1)}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: "loan", TeamID: "mia", Date: day(4)}).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/v1/players/ghost/transactions", domain.RosterTransaction{Type: domain.TransactionWaiver, Date: day(4)}).Code)
+	// Waiving p1 before game 3 would leave its line without a team.
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionWaiver, Date: day(3)}).Code)
+
+	// Waived after game 3, p1 is a free agent until signed by mia before game 5.
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionWaiver, Date: day(3).Add(4 * time.Hour)}).Code)
+	resp = do("GET", "/api/v1/players/p1", nil)
+	assert.Contains(t, resp.Body.String(), `"team_id":""`)
+	resp = logLine("g4", 5)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"field":"player_id"`)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players/p1/transactions", domain.RosterTransaction{Type: domain.TransactionTwoWay, TeamID: "mia", Date: day(5).Add(-time.Hour)}).Code)
+	require.Equal(t, http.StatusCreated, logLine("g5", 7).Code)
+	assert.Equal(t, 7, teamPoints("mia"))
+
+	resp = do("GET", "/api/v1/players/p1/memberships", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var memberships []domain.RosterMembership
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &memberships))
+	require.Len(t, memberships, 3)
+	assert.Equal(t, "bos", memberships[0].TeamID)
+	assert.Nil(t, memberships[0].Start)
+	require.NotNil(t, memberships[0].End)
+	assert.True(t, memberships[0].End.Equal(day(2).Add(4*time.Hour)))
+	assert.Equal(t, domain.TransactionTrade, memberships[0].EndedBy)
+	assert.Equal(t, domain.TransactionWaiver, memberships[1].EndedBy)
+	assert.Equal(t, "mia", memberships[2].TeamID)
+	assert.Equal(t, domain.TransactionTwoWay, memberships[2].JoinedBy)
+	assert.Nil(t, memberships[2].End)
+
+	// Team leaders count each line towards its team too.
+	resp = do("GET", "/api/v1/leaders?stat=total_points&scope=team", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var leaders []domain.Leader
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &leaders))
+	require.Len(t, leaders, 3)
+	assert.Equal(t, "nyk", leaders[0].TeamID)
+	assert.Equal(t, 35.0, leaders[0].Value)
+
+	// A player whose team changes through an update is moved from then on.
+	require.Equal(t, http.StatusOK, do("PATCH", "/api/v1/players/p1", map[string]string{"team_id": "bos"}).Code)
+	assert.Equal(t, 7, teamPoints("mia"))
+	resp = do("GET", "/api/v1/players/p1/memberships", nil)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &memberships))
+	require.Len(t, memberships, 4)
+	assert.Equal(t, domain.TransactionTrade, memberships[3].JoinedBy)
+}
@@ -0,0 +1,91 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeasonScopedAggregates(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+
+	post := func(path string, v interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(v)
+		assert.NoError(t, err)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		// For endpoints with middleware, authenticate with the test token.
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Two seasons, so that last season's games must be excluded.
+	assert.Equal(t, http.StatusCreated, post("/api/v1/seasons", domain.Season{
+		ID:            "2024-25",
+		StartDate:     time.Date(2024, 10, 22, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2025, 6, 22, 0, 0, 0, 0, time.UTC),
+		PlayoffsStart: time.Date(2025, 4, 19, 0, 0, 0, 0, time.UTC),
+	}).Code)
+	assert.Equal(t, http.StatusCreated, post("/api/v1/seasons", domain.Season{
+		ID:            "2025-26",
+		StartDate:     time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC),
+		PlayoffsStart: time.Date(2026, 4, 18, 0, 0, 0, 0, time.UTC),
+	}).Code)
+
+	assert.Equal(t, http.StatusCreated, post("/api/v1/players", domain.Player{ID: "player1", Name: "John Doe", TeamID: "team1"}).Code)
+
+	// Games are assigned to seasons (and regular season vs playoffs) by date.
+	games := []domain.Game{
+		{ID: "old", Date: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive},
+		{ID: "regular", Date: time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive},
+		{ID: "playoff", Date: time.Date(2026, 4, 25, 19, 0, 0, 0, time.UTC), HomeTeam: "team2", AwayTeam: "team1", Status: domain.GameStatusLive},
+	}
+	for _, g := range games {
+		assert.Equal(t, http.StatusCreated, post("/api/v1/games", g).Code)
+	}
+	for i, points := range []int{40, 20, 30} {
+		stats := domain.PlayerGameStats{ID: games[i].ID + "-stats", PlayerID: "player1", GameID: games[i].ID, Points: points, MinutesPlayed: 30}
+		assert.Equal(t, http.StatusCreated, post("/api/v1/player-stats", stats).Code)
+	}
+
+	get := func(path string) domain.AggregateStats {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var agg domain.AggregateStats
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &agg))
+		return agg
+	}
+
+	career := get("/api/v1/player-stats/player/player1")
+	assert.Equal(t, 3, career.GamesPlayed)
+	assert.Equal(t, 90, career.TotalPoints)
+
+	season := get("/api/v1/player-stats/player/player1?season=2025-26")
+	assert.Equal(t, 2, season.GamesPlayed)
+	assert.Equal(t, 50, season.TotalPoints)
+
+	playoffs := get("/api/v1/player-stats/player/player1?season=2025-26&season_type=playoffs")
+	assert.Equal(t, 1, playoffs.GamesPlayed)
+	assert.Equal(t, 30, playoffs.TotalPoints)
+
+	team := get("/api/v1/player-stats/team/team1?season=2025-26&season_type=regular")
+	assert.Equal(t, 1, team.GamesPlayed)
+	assert.Equal(t, 20, team.TotalPoints)
+}
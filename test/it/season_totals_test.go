@@ -0,0 +1,266 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeasonTotals checks that the season totals follow stat lines, roster moves and
+// deleted games immediately, despite the aggregate cache, and that the totals command
+// finds and repairs drifted totals.
+func TestSeasonTotals(t *testing.T) {
+	// The command and the server share a database file.
+	dbPath := filepath.Join(t.TempDir(), "nba.db")
+	os.Setenv("DATABASE_URL", "sqlite://"+dbPath)
+	defer os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	aggregate := func(path string) domain.AggregateStats {
+		resp := do("GET", path, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var agg domain.AggregateStats
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &agg))
+		return agg
+	}
+
+	for _, p := range []domain.Player{
+		{ID: "p1", Name: "One", TeamID: "east"},
+		{ID: "p2", Name: "Two", TeamID: "east"},
+		{ID: "p3", Name: "Three", TeamID: "west"},
+	} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	for _, id := range []string{"g1", "g2"} {
+		game := domain.Game{ID: id, Date: time.Now().Add(-time.Hour), HomeTeam: "east", AwayTeam: "west", Status: domain.GameStatusLive}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	}
+	for _, s := range []domain.PlayerGameStats{
+		{ID: "s1", PlayerID: "p1", GameID: "g1", Points: 10, MinutesPlayed: 30},
+		{ID: "s2", PlayerID: "p2", GameID: "g1", Points: 20, MinutesPlayed: 25},
+		{ID: "s3", PlayerID: "p3", GameID: "g1", Points: 5, MinutesPlayed: 20},
+		{ID: "s4", PlayerID: "p1", GameID: "g2", Points: 7, MinutesPlayed: 15},
+	} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/player-stats", s).Code)
+	}
+
+	east := aggregate("/api/v1/player-stats/team/east")
+	assert.Equal(t, 2, east.GamesPlayed)
+	assert.Equal(t, 37, east.TotalPoints)
+
+	// A player moved to another team leaves the lines of earlier games with the old team.
+	require.Equal(t, http.StatusOK, do("PUT", "/api/v1/players/p2", domain.Player{ID: "p2", Name: "Two", TeamID: "west"}).Code)
+	east = aggregate("/api/v1/player-stats/team/east")
+	assert.Equal(t, 2, east.GamesPlayed)
+	assert.Equal(t, 37, east.TotalPoints)
+	west := aggregate("/api/v1/player-stats/team/west")
+	assert.Equal(t, 1, west.GamesPlayed)
+	assert.Equal(t, 5, west.TotalPoints)
+
+	// A trade recorded back to before the games moves their lines to the new team.
+	trade := domain.RosterTransaction{Type: domain.TransactionTrade, TeamID: "west", Date: time.Now().Add(-2 * time.Hour)}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players/p1/transactions", trade).Code)
+	assert.Equal(t, 20, aggregate("/api/v1/player-stats/team/east").TotalPoints)
+	west = aggregate("/api/v1/player-stats/team/west")
+	assert.Equal(t, 2, west.GamesPlayed)
+	assert.Equal(t, 22, west.TotalPoints)
+
+	// Deleting a game removes its lines from the totals.
+	require.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/games/g2", nil).Code)
+	p1 := aggregate("/api/v1/player-stats/player/p1")
+	assert.Equal(t, 1, p1.GamesPlayed)
+	assert.Equal(t, 10, p1.TotalPoints)
+	assert.Equal(t, 30.0, p1.TotalMinutes)
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunTotalsCommand([]string{"check"}, &out))
+	assert.Contains(t, out.String(), "season totals match")
+
+	// Totals changed behind the repository's back are reported, then rebuilt.
+	db, err := repository.NewDB("sqlite://"+dbPath, 1, 1, time.Minute)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`UPDATE player_season_totals SET points = 99 WHERE player_id = 'p1'`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM team_season_totals WHERE team_id = 'west'`)
+	require.NoError(t, err)
+
+	out.Reset()
+	assert.Error(t, app.RunTotalsCommand([]string{"check"}, &out))
+	assert.Contains(t, out.String(), "player p1 (no season) regular: stored 1 games 99 pts")
+	assert.Contains(t, out.String(), "team west (no season) regular: stored 0 games 0 pts")
+
+	out.Reset()
+	require.NoError(t, app.RunTotalsCommand([]string{"rebuild"}, &out))
+	require.NoError(t, app.RunTotalsCommand([]string{"check"}, &out))
+	assert.Equal(t, 10, aggregate("/api/v1/player-stats/player/p1").TotalPoints)
+	assert.Equal(t, 15, aggregate("/api/v1/player-stats/team/west").TotalPoints)
+
+	assert.EqualError(t, app.RunTotalsCommand(nil, &out), "usage: totals check | rebuild")
+}
+
+// TestSeasonTotals_ConcurrentLines logs the lines of teammates in the same game at
+// once, and checks that the game counts once towards the team's totals.
+func TestSeasonTotals_ConcurrentLines(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "nba.db")
+	os.Setenv("DATABASE_URL", "sqlite://"+dbPath)
+	defer os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	const players = 8
+	for i := 0; i < players; i++ {
+		p := domain.Player{ID: fmt.Sprintf("p%d", i), Name: fmt.Sprintf("Player %d", i), TeamID: "east"}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	game := domain.Game{ID: "g1", Date: time.Now().Add(-time.Hour), HomeTeam: "east", AwayTeam: "west", Status: domain.GameStatusLive}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+
+	var wg sync.WaitGroup
+	codes := make([]int, players)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			line := domain.PlayerGameStats{ID: fmt.Sprintf("s%d", i), PlayerID: fmt.Sprintf("p%d", i), GameID: "g1", Points: 10, MinutesPlayed: 20}
+			codes[i] = do("POST", "/api/v1/player-stats", line).Code
+		}(i)
+	}
+	wg.Wait()
+	for i, code := range codes {
+		assert.Equal(t, http.StatusCreated, code, "line %d", i)
+	}
+
+	resp := do("GET", "/api/v1/player-stats/team/east", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var east domain.AggregateStats
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &east))
+	assert.Equal(t, 1, east.GamesPlayed)
+	assert.Equal(t, 10*players, east.TotalPoints)
+
+	var out bytes.Buffer
+	assert.NoError(t, app.RunTotalsCommand([]string{"check"}, &out), out.String())
+}
+
+// TestSeasonTotals_LinesWhileMadeFinal logs lines for a game while it is made final
+// with the score of the lines logged before. Whatever the order the requests are served
+// in, the final score must match the stat lines the game ends up with: lines logged
+// after the game was made final are rejected, and making it final fails once lines
+// were added.
+func TestSeasonTotals_LinesWhileMadeFinal(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "nba.db")
+	os.Setenv("DATABASE_URL", "sqlite://"+dbPath)
+	defer os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	const players = 8
+	for i := 0; i < players; i++ {
+		p := domain.Player{ID: fmt.Sprintf("p%d", i), Name: fmt.Sprintf("Player %d", i), TeamID: "east"}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/players", p).Code)
+	}
+	game := domain.Game{ID: "g1", Date: time.Now().Add(-time.Hour), HomeTeam: "east", AwayTeam: "west", Status: domain.GameStatusLive}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", game).Code)
+	first := domain.PlayerGameStats{ID: "s0", PlayerID: "p0", GameID: "g1", Points: 10, MinutesPlayed: 20}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/player-stats", first).Code)
+
+	var wg sync.WaitGroup
+	var finalCode int
+	codes := make([]int, players)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		finalCode = do("PATCH", "/api/v1/games/g1", map[string]interface{}{"status": "final", "home_score": 10, "away_score": 0}).Code
+	}()
+	for i := 1; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			line := domain.PlayerGameStats{ID: fmt.Sprintf("s%d", i), PlayerID: fmt.Sprintf("p%d", i), GameID: "g1", Points: 10, MinutesPlayed: 20}
+			codes[i] = do("POST", "/api/v1/player-stats", line).Code
+		}(i)
+	}
+	wg.Wait()
+
+	logged := 1
+	for i := 1; i < players; i++ {
+		assert.Contains(t, []int{http.StatusCreated, http.StatusConflict}, codes[i], "line %d", i)
+		if codes[i] == http.StatusCreated {
+			logged++
+		}
+	}
+	resp := do("GET", "/api/v1/player-stats/team/east", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var east domain.AggregateStats
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &east))
+	assert.Equal(t, 10*logged, east.TotalPoints)
+
+	resp = do("GET", "/api/v1/games/g1", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var g1 domain.Game
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &g1))
+	switch finalCode {
+	case http.StatusOK:
+		assert.Equal(t, domain.GameStatusFinal, g1.Status)
+		assert.Equal(t, east.TotalPoints, g1.HomeScore)
+	case http.StatusUnprocessableEntity:
+		// Lines were added first, so the game is still live and took every line.
+		assert.Equal(t, domain.GameStatusLive, g1.Status)
+		assert.Equal(t, players, logged)
+	default:
+		t.Errorf("expected the game to be made final or rejected with %d, got %d", http.StatusUnprocessableEntity, finalCode)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, app.RunTotalsCommand([]string{"check"}, &out), out.String())
+}
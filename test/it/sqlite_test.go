@@ -0,0 +1,65 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSQLiteFileDatabase runs the server on a SQLite file, restarts it, and checks that
+// data and the migration history survive the restart.
+func TestSQLiteFileDatabase(t *testing.T) {
+	os.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "nba.db"))
+	defer os.Setenv("DATABASE_URL", ":memory:")
+
+	do := func(server *app.Server, method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		// For endpoints with middleware, authenticate with the test token.
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	server := app.Initialize()
+	east := time.FixedZone("EST", -5*3600)
+	games := []domain.Game{
+		// 23:00 EST on November 1st is already November 2nd in UTC.
+		{ID: "late", Date: time.Date(2025, 11, 1, 23, 0, 0, 0, east), HomeTeam: "team1", AwayTeam: "team2"},
+		{ID: "early", Date: time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC), HomeTeam: "team1", AwayTeam: "team2"},
+	}
+	for _, game := range games {
+		assert.Equal(t, http.StatusCreated, do(server, "POST", "/api/v1/games", game).Code)
+	}
+
+	// Starting again on the same file applies no migrations and keeps the data.
+	server = app.Initialize()
+	resp := do(server, "GET", "/api/v1/games?from=2025-11-02T00:00:00Z", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var listed []domain.Game
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listed))
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, "late", listed[0].ID)
+		assert.True(t, games[0].Date.Equal(listed[0].Date))
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, app.RunMigrateCommand([]string{"status"}, &out))
+	assert.Contains(t, out.String(), "0003 add_shooting_splits")
+	assert.NotContains(t, out.String(), "pending")
+}
@@ -0,0 +1,137 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStandings computes standings from the final regular-season games of a season,
+// grouped by conference and division.
+func TestStandings(t *testing.T) {
+	os.Setenv("DATABASE_URL", ":memory:")
+
+	server := app.Initialize()
+	do := func(method, path string, v interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if v != nil {
+			assert.NoError(t, json.NewEncoder(&body).Encode(v))
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authHeader)
+		resp := httptest.NewRecorder()
+		server.Handler.ServeHTTP(resp, req)
+		return resp
+	}
+	getStandings := func(query string) domain.Standings {
+		resp := do("GET", "/api/v1/standings?"+query, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var standings domain.Standings
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &standings))
+		return standings
+	}
+
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/seasons", domain.Season{
+		ID:            "2025-26",
+		StartDate:     time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC),
+		PlayoffsStart: time.Date(2026, 4, 18, 0, 0, 0, 0, time.UTC),
+	}).Code)
+	for _, team := range []domain.Team{
+		{ID: "bos", Name: "Celtics", Conference: "East", Division: "Atlantic"},
+		{ID: "nyk", Name: "Knicks", Conference: "East", Division: "Atlantic"},
+		{ID: "mil", Name: "Bucks", Conference: "East", Division: "Central"},
+		{ID: "lal", Name: "Lakers", Conference: "West", Division: "Pacific"},
+	} {
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/teams", team).Code)
+	}
+	resp := do("POST", "/api/v1/teams", domain.Team{ID: "sea", Name: "Sonics", Conference: "West", Division: "Atlantic"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"field":"division"`)
+
+	day := 0
+	game := func(home, away string, homeScore, awayScore int, status string) {
+		day++
+		g := domain.Game{
+			ID:        fmt.Sprintf("s%d", day),
+			Date:      time.Date(2025, 11, day, 19, 0, 0, 0, time.UTC),
+			HomeTeam:  home,
+			AwayTeam:  away,
+			Status:    status,
+			HomeScore: homeScore,
+			AwayScore: awayScore,
+		}
+		require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", g).Code)
+	}
+	game("bos", "nyk", 110, 100, domain.GameStatusFinal)
+	game("nyk", "mil", 105, 95, domain.GameStatusFinal)
+	game("lal", "bos", 120, 118, domain.GameStatusFinal)
+	game("mil", "lal", 99, 98, domain.GameStatusFinal)
+	game("bos", "mil", 0, 0, domain.GameStatusScheduled) // Not played yet.
+	// Playoff games do not count either.
+	playoff := domain.Game{ID: "p1", Date: time.Date(2026, 4, 20, 19, 0, 0, 0, time.UTC), HomeTeam: "nyk", AwayTeam: "bos", Status: domain.GameStatusFinal, HomeScore: 120, AwayScore: 90}
+	require.Equal(t, http.StatusCreated, do("POST", "/api/v1/games", playoff).Code)
+
+	standings := getStandings("season=2025-26")
+	assert.Equal(t, "2025-26", standings.SeasonID)
+	assert.Equal(t, domain.StandingsGroupConference, standings.Group)
+	require.Len(t, standings.Groups, 2)
+	east := standings.Groups[0]
+	assert.Equal(t, "East", east.Name)
+	// Every team is 1-1. bos, which beat nyk, and mil lead their divisions; bos ranks
+	// ahead of mil with the better conference record.
+	require.Equal(t, []string{"bos", "mil", "nyk"}, []string{east.Teams[0].TeamID, east.Teams[1].TeamID, east.Teams[2].TeamID})
+	bos := east.Teams[0]
+	assert.Equal(t, "bos", bos.TeamID)
+	assert.Equal(t, "Celtics", bos.TeamName)
+	assert.Equal(t, 1, bos.Wins)
+	assert.Equal(t, 1, bos.Losses)
+	assert.Equal(t, 0.5, bos.WinPct)
+	assert.Equal(t, domain.Record{Wins: 1}, bos.Home)
+	assert.Equal(t, domain.Record{Losses: 1}, bos.Away)
+	assert.Equal(t, domain.Record{Wins: 1}, bos.DivisionRecord)
+	assert.Equal(t, "L1", bos.Streak)
+	assert.Equal(t, 228, bos.PointsFor)
+	assert.Equal(t, 220, bos.PointsAgainst)
+	assert.Equal(t, 8, bos.PointDifference)
+
+	west := standings.Groups[1]
+	assert.Equal(t, "West", west.Name)
+	require.Len(t, west.Teams, 1)
+	assert.Equal(t, "lal", west.Teams[0].TeamID)
+
+	divisions := getStandings("season=2025-26&group=division")
+	require.Len(t, divisions.Groups, 3)
+	assert.Equal(t, "Atlantic", divisions.Groups[0].Name)
+	assert.Equal(t, "Central", divisions.Groups[1].Name)
+	assert.Equal(t, "Pacific", divisions.Groups[2].Name)
+	atlantic := divisions.Groups[0].Teams
+	require.Len(t, atlantic, 2)
+	assert.Equal(t, "nyk", atlantic[1].TeamID)
+	assert.Equal(t, 2, atlantic[1].Rank)
+	assert.Equal(t, 0.0, atlantic[1].GamesBehind)
+
+	league := getStandings("season=2025-26&group=league")
+	require.Len(t, league.Groups, 1)
+	// Among the three division leaders, mil beat lal, which beat bos.
+	var ids []string
+	for _, s := range league.Groups[0].Teams {
+		ids = append(ids, s.TeamID)
+	}
+	assert.Equal(t, []string{"mil", "lal", "bos", "nyk"}, ids)
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/standings?season=1999-00", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/standings?season=2025-26&group=city", nil).Code)
+}
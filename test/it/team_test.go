@@ -30,8 +30,8 @@ func TestCreateAndGetTeam(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/api/v1/teams", bytes.NewBuffer(teamBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	req.Header.Set("Authorization", authHeader)
 
 	resp := httptest.NewRecorder()
 	server.Handler.ServeHTTP(resp, req)
@@ -40,8 +40,8 @@ func TestCreateAndGetTeam(t *testing.T) {
 	// Retrieve the team.
 	reqGet, _ := http.NewRequest("GET", "/api/v1/teams/team1", nil)
 
-	// For endpoints with middleware, add a dummy Authorization header.
-	reqGet.Header.Set("Authorization", "dummy-token")
+	// For endpoints with middleware, authenticate with the test token.
+	reqGet.Header.Set("Authorization", authHeader)
 
 	respGet := httptest.NewRecorder()
 	server.Handler.ServeHTTP(respGet, reqGet)
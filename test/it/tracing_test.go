@@ -0,0 +1,63 @@
+package integration_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vgeshiktor/nba-stats/internal/app"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTracing checks that a request continues the caller's trace through the handler,
+// service and repository, and that the spans are written to the trace file.
+func TestTracing(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "traces.jsonl")
+	t.Setenv("DATABASE_URL", ":memory:")
+	t.Setenv("TRACING_EXPORTER", "file")
+	t.Setenv("TRACING_FILE", traceFile)
+	server := app.Initialize()
+	defer tracing.SetExporter(nil)
+
+	create, _ := http.NewRequest("POST", "/api/v1/teams", strings.NewReader(`{"id":"traced","name":"Traced"}`))
+	create.Header.Set("Authorization", authHeader)
+	created := httptest.NewRecorder()
+	server.Handler.ServeHTTP(created, create)
+	require.Equal(t, http.StatusCreated, created.Code)
+
+	req, _ := http.NewRequest("GET", "/api/v1/teams/traced", nil)
+	req.Header.Set("Authorization", authHeader)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	server.Handler.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	file, err := os.Open(traceFile)
+	require.NoError(t, err)
+	defer file.Close()
+	spans := map[string]tracing.SpanData{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span tracing.SpanData
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		spans[span.Name] = span
+	}
+
+	handler, service, repository := spans["GET /api/v1/teams/{id}"], spans["TeamService.GetTeamByID"], spans["TeamRepository.GetTeamByID"]
+	for _, span := range []tracing.SpanData{handler, service, repository} {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	}
+	assert.Equal(t, "00f067aa0ba902b7", handler.ParentID)
+	assert.Equal(t, handler.SpanID, service.ParentID)
+	assert.Equal(t, service.SpanID, repository.ParentID)
+	assert.Equal(t, "200", handler.Attributes["http.status_code"])
+	assert.Equal(t, resp.Header().Get("X-Request-ID"), handler.Attributes["request.id"])
+}
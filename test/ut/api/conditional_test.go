@@ -0,0 +1,142 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/api"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newConditionalTestHandler() *api.Handler {
+	return api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+}
+
+// Test Conditional Requests - a matching ETag gets 304 without a body
+func TestConditionalRequests_ETag(t *testing.T) {
+	handler := newConditionalTestHandler()
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/player/player1?season=2025-26", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp := httptest.NewRecorder()
+		handler.GetPlayerAggregate(resp, req)
+		return resp
+	}
+
+	resp := get("", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	etag := resp.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, "private, no-cache", resp.Header().Get("Cache-Control"))
+	assert.Empty(t, resp.Header().Get("Last-Modified"))
+	assert.Contains(t, resp.Body.String(), `"player_id":"player1"`)
+
+	// The same content always has the same ETag.
+	assert.Equal(t, etag, get("", "").Header().Get("ETag"))
+
+	for _, ifNoneMatch := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		resp = get("If-None-Match", ifNoneMatch)
+		assert.Equal(t, http.StatusNotModified, resp.Code, ifNoneMatch)
+		assert.Empty(t, resp.Body.String())
+		assert.Equal(t, etag, resp.Header().Get("ETag"))
+	}
+	assert.Equal(t, http.StatusOK, get("If-None-Match", `"stale"`).Code)
+
+	// Another season is another representation.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/player/player1?season=2024-25", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	handler.GetPlayerAggregate(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+}
+
+// Test Conditional Requests - Last-Modified comes from the resource's update time
+func TestConditionalRequests_LastModified(t *testing.T) {
+	handler := newConditionalTestHandler()
+	get := func(ifModifiedSince time.Time) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/teams/team1", nil)
+		if !ifModifiedSince.IsZero() {
+			req.Header.Set("If-Modified-Since", ifModifiedSince.Format(http.TimeFormat))
+		}
+		resp := httptest.NewRecorder()
+		handler.GetTeam(resp, req)
+		return resp
+	}
+
+	resp := get(time.Time{})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, mocks.FakeUpdatedAt.Format(http.TimeFormat), resp.Header().Get("Last-Modified"))
+	assert.Equal(t, "private, max-age=60", resp.Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusNotModified, get(mocks.FakeUpdatedAt).Code)
+	assert.Equal(t, http.StatusNotModified, get(mocks.FakeUpdatedAt.Add(time.Hour)).Code)
+	assert.Equal(t, http.StatusOK, get(mocks.FakeUpdatedAt.Add(-time.Second)).Code)
+}
+
+// Test Conditional Requests - final games may be cached as immutable
+func TestConditionalRequests_FinalGame(t *testing.T) {
+	handler := newConditionalTestHandler()
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/games/"+id, nil)
+		resp := httptest.NewRecorder()
+		handler.GetGame(resp, req)
+		return resp
+	}
+
+	resp := get("final")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "private, max-age=86400, immutable", resp.Header().Get("Cache-Control"))
+	assert.Contains(t, resp.Body.String(), `"status":"final"`)
+
+	resp = get("game1")
+	assert.Equal(t, "private, max-age=60", resp.Header().Get("Cache-Control"))
+	assert.NotEqual(t, get("final").Header().Get("ETag"), resp.Header().Get("ETag"))
+
+	// Errors are not cacheable.
+	resp = get("missing")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Empty(t, resp.Header().Get("ETag"))
+	assert.Empty(t, resp.Header().Get("Cache-Control"))
+}
+
+// Test Conditional Requests - Range requests get the whole body
+func TestConditionalRequests_RangeIgnored(t *testing.T) {
+	handler := newConditionalTestHandler()
+	full := httptest.NewRecorder()
+	handler.GetTeam(full, httptest.NewRequest(http.MethodGet, "/api/v1/teams/team1", nil))
+
+	for _, header := range []map[string]string{
+		{"Range": "bytes=0-4"},
+		{"Range": "bytes=5-", "If-Range": full.Header().Get("ETag")},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/teams/team1", nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		resp := httptest.NewRecorder()
+		handler.GetTeam(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, full.Body.String(), resp.Body.String())
+		assert.Empty(t, resp.Header().Get("Content-Range"))
+		assert.Empty(t, resp.Header().Get("Accept-Ranges"))
+	}
+}
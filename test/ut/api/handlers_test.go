@@ -3,14 +3,19 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/api"
 	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/errors"
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
 	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
 )
 
@@ -23,9 +28,15 @@ func TestLogPlayerStatsEndpoint(t *testing.T) {
 	handler := api.NewHandler(
 		&mocks.FakePlayerStatsService{},
 		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
 		&mocks.FakePlayerService{},
 		&mocks.FakeTeamService{},
 		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
 	)
 
 	// Create a sample PlayerGameStats payload.
@@ -45,8 +56,10 @@ func TestLogPlayerStatsEndpoint(t *testing.T) {
 
 	payload, _ := json.Marshal(stats)
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/player-stats", bytes.NewReader(payload))
-	// For endpoints with middleware, add a dummy Authorization header.
-	req.Header.Set("Authorization", "dummy-token")
+	// The handler is called without middleware, so store the principal the
+	// authentication middleware would have.
+	admin := &domain.Principal{Subject: "tester", Scopes: []string{domain.ScopeCatalogAdmin}}
+	req = req.WithContext(context.WithValue(req.Context(), api.PrincipalKey, admin))
 
 	rr := httptest.NewRecorder()
 	handler.LogPlayerStats(rr, req)
@@ -69,12 +82,18 @@ func TestGetPlayerAggregateEndpoint(t *testing.T) {
 	handler := api.NewHandler(
 		&mocks.FakePlayerStatsService{},
 		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
 		&mocks.FakePlayerService{},
 		&mocks.FakeTeamService{},
 		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
 	)
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/player/player1", nil)
-	req.Header.Set("Authorization", "dummy-token")
+	req.Header.Set("Authorization", "Bearer dummy-token")
 	rr := httptest.NewRecorder()
 
 	handler.GetPlayerAggregate(rr, req)
@@ -92,3 +111,1035 @@ func TestGetPlayerAggregateEndpoint(t *testing.T) {
 		t.Errorf("expected playerID 'player1', got %s", agg.PlayerID)
 	}
 }
+
+func TestListPlayersEndpoint(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/players?team_id=team1&limit=10", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.ListPlayers(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, status)
+	}
+
+	var players []domain.Player
+	if err := json.NewDecoder(rr.Body).Decode(&players); err != nil {
+		t.Errorf("failed to decode response: %v", err)
+	}
+	if len(players) != 1 {
+		t.Errorf("expected 1 player, got %d", len(players))
+	}
+}
+
+func TestListPlayersEndpoint_InvalidLimit(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/players?limit=ten", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.ListPlayers(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestUpdatePlayerEndpoint_IDMismatch(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	payload, _ := json.Marshal(domain.Player{ID: "player2", Name: "John Doe", TeamID: "team1"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/players/player1", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.UpdatePlayer(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestDeleteGameEndpoint(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/games/game1", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.DeleteGame(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestGetTeamAggregateEndpoint_SeasonFilter(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/team/team1?season=2025-26&season_type=playoffs", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.GetTeamAggregate(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, status)
+	}
+
+	var agg domain.AggregateStats
+	if err := json.NewDecoder(rr.Body).Decode(&agg); err != nil {
+		t.Errorf("failed to decode response: %v", err)
+	}
+	if agg.SeasonID != "2025-26" || agg.SeasonType != "playoffs" {
+		t.Errorf("expected season filter 2025-26/playoffs, got %q/%q", agg.SeasonID, agg.SeasonType)
+	}
+}
+
+func TestGetPlayerAdvancedEndpoint(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	// Route through the mux to check that the /advanced suffix is dispatched.
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/player/player1/advanced?season=2025-26", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	var adv domain.AdvancedStats
+	if err := json.NewDecoder(rr.Body).Decode(&adv); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if adv.PlayerID != "player1" || adv.SeasonID != "2025-26" || adv.TrueShootingPct != 0.6 {
+		t.Errorf("unexpected advanced stats: %+v", adv)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/player-stats/player/unknown/advanced", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("expected status %d for unknown player, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestGetLeadersEndpoint(t *testing.T) {
+	leaderService := &mocks.FakeLeaderService{}
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		leaderService,
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/leaders?stat=avg_assists&scope=team&limit=25&min_games=20&min_minutes=500.5&order=asc&season=2025-26", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.GetLeaders(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	want := domain.LeaderFilter{
+		StatsFilter: domain.StatsFilter{SeasonID: "2025-26"},
+		Stat:        "avg_assists",
+		Scope:       "team",
+		MinGames:    20,
+		MinMinutes:  500.5,
+		Ascending:   true,
+		Limit:       25,
+	}
+	if leaderService.Filter != want {
+		t.Errorf("expected filter %+v, got %+v", want, leaderService.Filter)
+	}
+	var leaders []domain.Leader
+	if err := json.NewDecoder(rr.Body).Decode(&leaders); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(leaders) != 2 || leaders[0].Rank != leaders[1].Rank {
+		t.Errorf("expected two tied leaders, got %+v", leaders)
+	}
+}
+
+func TestGetLeadersEndpoint_InvalidInput(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	for _, query := range []string{"stat=unknown", "order=sideways", "min_games=many"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/leaders?"+query, nil)
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+
+		handler.GetLeaders(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, status)
+		}
+	}
+}
+
+func TestGetStandingsEndpoint(t *testing.T) {
+	standingsService := &mocks.FakeStandingsService{}
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		standingsService,
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/standings?season=2025-26&group=league", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+
+	handler.GetStandings(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	want := domain.StandingsFilter{SeasonID: "2025-26", Group: "league"}
+	if standingsService.Filter != want {
+		t.Errorf("expected filter %+v, got %+v", want, standingsService.Filter)
+	}
+	if cacheControl := rr.Header().Get("Cache-Control"); cacheControl != "private, no-cache" {
+		t.Errorf("expected standings to be revalidated, got Cache-Control %q", cacheControl)
+	}
+	var standings domain.Standings
+	if err := json.NewDecoder(rr.Body).Decode(&standings); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(standings.Groups) != 1 || len(standings.Groups[0].Teams) != 2 || standings.Groups[0].Teams[1].GamesBehind != 2 {
+		t.Errorf("expected two ranked teams, got %+v", standings)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/standings?season=unknown", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr = httptest.NewRecorder()
+	handler.GetStandings(rr, req)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("expected status %d for unknown season, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestLogBoxScoreEndpoint(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	post := func(path string, boxScore domain.BoxScore) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(boxScore)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("/api/v1/games/game1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "player1"}}})
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	rr = post("/api/v1/games/game1/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "player1"}, {PlayerID: "ghost"}}})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	var resp errors.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "lines[1].player_id" || resp.Errors[0].Code != domain.CodeNotFound {
+		t.Errorf("expected line 1 (ghost) to be rejected, got %+v", resp.Errors)
+	}
+
+	rr = post("/api/v1/games/missing/box-score", domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "player1"}}})
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	tests := []struct {
+		name, method, path, body string
+		status                   int
+		problemType, detail      string
+	}{
+		{"unknown team", http.MethodGet, "/api/v1/teams/missing", "", http.StatusNotFound, "urn:nba-stats:problem:not-found", "team missing not found"},
+		{"unknown game", http.MethodGet, "/api/v1/games/missing", "", http.StatusNotFound, "urn:nba-stats:problem:not-found", "game missing not found"},
+		{"invalid player", http.MethodPost, "/api/v1/players", `{"id":"p1","team_id":"team1"}`, http.StatusUnprocessableEntity, "urn:nba-stats:problem:validation-failed", "name cannot be empty"},
+		{"duplicate player", http.MethodPost, "/api/v1/players", `{"id":"duplicate","name":"Dup","team_id":"team1"}`, http.StatusConflict, "urn:nba-stats:problem:conflict", "player duplicate already exists"},
+		{"invalid filter", http.MethodGet, "/api/v1/leaders?stat=unknown", "", http.StatusBadRequest, "urn:nba-stats:problem:invalid-input", `unknown stat "unknown"`},
+		{"unexpected failure", http.MethodGet, "/api/v1/games/broken", "", http.StatusInternalServerError, "about:blank", "Error fetching game"},
+		{"wrong method", http.MethodDelete, "/api/v1/players", "", http.StatusMethodNotAllowed, "about:blank", "Method not allowed"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != errors.ProblemContentType {
+			t.Errorf("%s: expected content type %q, got %q", tt.name, errors.ProblemContentType, ct)
+		}
+		if tt.status == http.StatusMethodNotAllowed {
+			if allow := rr.Header().Get("Allow"); allow != "GET, POST" {
+				t.Errorf("%s: expected Allow %q, got %q", tt.name, "GET, POST", allow)
+			}
+		}
+		var resp errors.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+		// Unexpected errors are not passed on to the client.
+		if resp.Type != tt.problemType || resp.Detail != tt.detail || resp.Status != tt.status || resp.Title != http.StatusText(tt.status) {
+			t.Errorf("%s: expected %s %q with status %d, got %+v", tt.name, tt.problemType, tt.detail, tt.status, resp)
+		}
+		if resp.Instance == "" || resp.Instance != rr.Header().Get("X-Request-ID") {
+			t.Errorf("%s: expected the request ID as instance, got %q", tt.name, resp.Instance)
+		}
+	}
+}
+
+func TestValidationProblemListsEveryField(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/players", strings.NewReader(`{"team_id":"team1"}`))
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	var resp errors.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []domain.FieldError{
+		{Field: "id", Code: domain.CodeRequired, Message: "id cannot be empty"},
+		{Field: "name", Code: domain.CodeRequired, Message: "name cannot be empty"},
+	}
+	if !reflect.DeepEqual(resp.Errors, want) {
+		t.Errorf("expected errors %+v, got %+v", want, resp.Errors)
+	}
+}
+
+func TestAPIKeyEndpoints(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(api.APIKeyHeader, "valid-key")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/api/v1/api-keys", `{"name":"ci","scopes":["stats:read"]}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID != "key1" || created.Key == "" {
+		t.Errorf("expected the new key in the response, got %+v", created)
+	}
+
+	if rr := do(http.MethodPost, "/api/v1/api-keys", `{}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d for a key without name, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	if rr := do(http.MethodGet, "/api/v1/api-keys", ""); rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), "secret") {
+		t.Errorf("expected keys to be listed without the keys themselves, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodDelete, "/api/v1/api-keys/key1", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+	}
+	if rr := do(http.MethodDelete, "/api/v1/api-keys/missing", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	// Managing keys requires authentication like every other endpoint.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d without credentials, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestScopesAreEnforced(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	boxScore := `{"lines":[{"player_id":"player1"}]}`
+	tests := []struct {
+		name, token, method, path, body string
+		wantStatus                      int
+	}{
+		{"reader reads stats", "reader-token", http.MethodGet, "/api/v1/player-stats/player/player1", "", http.StatusOK},
+		{"reader cannot log stats", "reader-token", http.MethodPost, "/api/v1/games/game1/box-score", boxScore, http.StatusForbidden},
+		{"reader cannot create teams", "reader-token", http.MethodPost, "/api/v1/teams", `{"id":"t1","name":"T"}`, http.StatusForbidden},
+		{"scorekeeper reads stats", "scorekeeper-token", http.MethodGet, "/api/v1/games/game1", "", http.StatusOK},
+		{"scorekeeper logs an assigned game", "scorekeeper-token", http.MethodPost, "/api/v1/games/game1/box-score", boxScore, http.StatusCreated},
+		{"scorekeeper cannot log another game", "scorekeeper-token", http.MethodPost, "/api/v1/games/game2/box-score", boxScore, http.StatusForbidden},
+		{"scorekeeper cannot log single lines of another game", "scorekeeper-token", http.MethodPost, "/api/v1/player-stats", `{"player_id":"player1","game_id":"game2"}`, http.StatusForbidden},
+		{"scorekeeper cannot delete games", "scorekeeper-token", http.MethodDelete, "/api/v1/games/game1", "", http.StatusForbidden},
+		{"scorekeeper cannot issue API keys", "scorekeeper-token", http.MethodPost, "/api/v1/api-keys", `{"name":"x","scopes":["catalog:admin"]}`, http.StatusForbidden},
+		{"admin deletes games", "dummy-token", http.MethodDelete, "/api/v1/games/game1", "", http.StatusNoContent},
+		{"admin logs any game", "dummy-token", http.MethodPost, "/api/v1/player-stats", `{"player_id":"player1","game_id":"game2"}`, http.StatusCreated},
+		{"admin lists scorekeepers", "dummy-token", http.MethodGet, "/api/v1/games/game1/scorekeepers", "", http.StatusOK},
+		{"admin assigns a scorekeeper", "dummy-token", http.MethodPut, "/api/v1/games/game1/scorekeepers/alice", "", http.StatusNoContent},
+		{"admin unassigns a scorekeeper", "dummy-token", http.MethodDelete, "/api/v1/games/game1/scorekeepers/scorekeeper", "", http.StatusNoContent},
+		{"unassigning an unknown scorekeeper", "dummy-token", http.MethodDelete, "/api/v1/games/game1/scorekeepers/bob", "", http.StatusNotFound},
+		{"scorekeeper cannot assign itself", "scorekeeper-token", http.MethodPut, "/api/v1/games/game2/scorekeepers/scorekeeper", "", http.StatusForbidden},
+		{"unsupported method", "reader-token", http.MethodPost, "/api/v1/leaders", "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusForbidden {
+				return
+			}
+			var problem errors.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if problem.Type != "urn:nba-stats:problem:forbidden" || problem.Detail == "" {
+				t.Errorf("expected a forbidden problem with a detail, got %+v", problem)
+			}
+		})
+	}
+}
+
+func TestRoutesCountAgainstTheirRateLimitClass(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	handler.RateLimits = api.RateLimits{
+		Store:      ratelimit.NewMemoryStore(nil),
+		Reads:      ratelimit.PerMinute(60, 5),
+		Aggregates: ratelimit.PerMinute(60, 1),
+	}
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Aggregates share one small bucket.
+	if rr := get("/api/v1/player-stats/team/team1"); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("expected the aggregate limit to apply, got %d with limit %q", rr.Code, rr.Header().Get("RateLimit-Limit"))
+	}
+	if rr := get("/api/v1/leaders"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d once the aggregate limit is used up, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+
+	// Reads are limited separately.
+	if rr := get("/api/v1/teams"); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "5" {
+		t.Errorf("expected the read limit to apply, got %d with limit %q", rr.Code, rr.Header().Get("RateLimit-Limit"))
+	}
+
+	// Writes are not limited when their limit is zero.
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/games/game1", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent || rr.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("expected an unlimited write, got %d with limit %q", rr.Code, rr.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+	// The deadline of every request has passed by the time the game is fetched.
+	server := api.TimeoutMiddleware(0)(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/games/game1", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	var resp errors.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Type != "urn:nba-stats:problem:canceled" {
+		t.Errorf("expected a canceled problem, got %+v", resp)
+	}
+}
+
+func TestRoutesAreMeasuredByTemplate(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	do := func(method, path, token, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	do(http.MethodGet, "/api/v1/games/metrics-game", "dummy-token", "")
+	do(http.MethodGet, "/api/v1/games/metrics-game/scorekeepers", "", "")
+	do(http.MethodPost, "/api/v1/players", "dummy-token", `{"id":"metrics-player","team_id":"team1"}`)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		`nba_stats_http_requests_total{method="GET",route="/api/v1/games/{id}",status="200"}`,
+		`nba_stats_http_requests_total{method="GET",route="/api/v1/games/{id}/scorekeepers",status="401"}`,
+		`nba_stats_http_requests_total{method="POST",route="/api/v1/players",status="422"}`,
+		`nba_stats_http_request_duration_seconds_count{method="GET",route="/api/v1/games/{id}"}`,
+		`nba_stats_validation_rejections_total{field="name",rule="required"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the metrics to contain %s", want)
+		}
+	}
+	if strings.Contains(body, "metrics-game") {
+		t.Errorf("expected route labels without IDs, got:\n%s", body)
+	}
+}
+
+func TestRosterRoutes(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/api/v1/players/player1/transactions", `{"type":"trade","team_id":"team2","date":"2026-02-05T00:00:00Z"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var transaction domain.RosterTransaction
+	if err := json.NewDecoder(rr.Body).Decode(&transaction); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if transaction.PlayerID != "player1" || transaction.FromTeamID != "team1" || transaction.TeamID != "team2" {
+		t.Errorf("expected player1 to move from team1 to team2, got %+v", transaction)
+	}
+
+	if rr := do(http.MethodPost, "/api/v1/players/player1/transactions", `{"player_id":"player2","type":"waiver","date":"2026-02-05T00:00:00Z"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a mismatched player, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := do(http.MethodPost, "/api/v1/players/player1/transactions", `{"type":"waiver","team_id":"team2","date":"2026-02-05T00:00:00Z"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d for a waiver to a team, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	if rr := do(http.MethodGet, "/api/v1/players/player1/transactions", ""); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+
+	rr = do(http.MethodGet, "/api/v1/players/player1/memberships", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var memberships []domain.RosterMembership
+	if err := json.NewDecoder(rr.Body).Decode(&memberships); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(memberships) != 2 || memberships[0].End == nil || memberships[1].Start == nil || memberships[1].End != nil {
+		t.Errorf("expected a closed and a current membership, got %+v", memberships)
+	}
+}
+
+func TestGameLogRoutes(t *testing.T) {
+	gameLogService := &mocks.FakeGameLogService{}
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		gameLogService,
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/api/v1/players/player1/games?season=2025-26&opponent=team2&location=away&from=2026-01-01&to=2026-01-31&sort=points&order=asc&limit=10&cursor=abc")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var log domain.GameLog
+	if err := json.NewDecoder(rr.Body).Decode(&log); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(log.Lines) != 1 || log.Lines[0].Points != 30 || log.Lines[0].OpponentID != "team2" || log.NextCursor != "next" {
+		t.Errorf("unexpected game log %+v", log)
+	}
+	want := domain.GameLogFilter{
+		StatsFilter: domain.StatsFilter{SeasonID: "2025-26"},
+		OpponentID:  "team2",
+		Location:    domain.GameLocationAway,
+		From:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+		Sort:        "points",
+		Ascending:   true,
+		Cursor:      "abc",
+		Limit:       10,
+	}
+	if gameLogService.Filter != want {
+		t.Errorf("expected filter %+v, got %+v", want, gameLogService.Filter)
+	}
+
+	if rr := do("/api/v1/players/unknown/games"); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown player, got %d", http.StatusNotFound, rr.Code)
+	}
+	if rr := do("/api/v1/players/player1/games?order=up"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown order, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := do("/api/v1/teams/team1/games"); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr := do("/api/v1/teams/team1/games?sort=height"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown sort, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := do("/api/v1/teams/team1"); rr.Code != http.StatusOK {
+		t.Errorf("expected status %d for the team itself, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestGetBoxScoreEndpoint(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	do := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer dummy-token")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/api/v1/games/game1/box-score", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var boxScore domain.GameBoxScore
+	if err := json.NewDecoder(rr.Body).Decode(&boxScore); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if boxScore.Game.ID != "game1" || len(boxScore.Teams) != 2 || boxScore.Teams[0].Totals.Points != 20 {
+		t.Errorf("unexpected box score %+v", boxScore)
+	}
+	if line := boxScore.Teams[0].Lines[0]; line.PlayerName != "Player One" || line.Starter == nil || !*line.Starter {
+		t.Errorf("expected a starter's named line, got %+v", line)
+	}
+	// Lines may be logged at any time, so the box score is revalidated every time.
+	if cacheControl := rr.Header().Get("Cache-Control"); cacheControl != "private, no-cache" {
+		t.Errorf("expected the box score to be revalidated, got Cache-Control %q", cacheControl)
+	}
+	if rr := do("/api/v1/games/game1/box-score", rr.Header().Get("ETag")); rr.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for an unchanged box score, got %d", http.StatusNotModified, rr.Code)
+	}
+	if rr := do("/api/v1/games/unknown/box-score", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown game, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+// countingAuthService counts the API keys it is asked to authenticate.
+type countingAuthService struct {
+	mocks.FakeAuthService
+	lookups int
+}
+
+func (s *countingAuthService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	s.lookups++
+	return s.FakeAuthService.AuthenticateAPIKey(ctx, key)
+}
+
+func TestUnauthenticatedRequestsAreRateLimitedByIP(t *testing.T) {
+	auth := &countingAuthService{}
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		auth,
+	)
+	handler.RateLimits = api.RateLimits{
+		Store: ratelimit.NewMemoryStore(nil),
+		IP:    ratelimit.PerMinute(60, 3),
+		Reads: ratelimit.PerMinute(60, 100),
+	}
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	get := func(remoteAddr string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/teams", nil)
+		req.RemoteAddr = remoteAddr
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Requests without credentials and with bad ones share the IP's bucket.
+	if rr := get("192.0.2.1:1000"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without credentials, got %d", http.StatusUnauthorized, rr.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if rr := get("192.0.2.1:1000", api.APIKeyHeader, "guess"); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d for a bad key, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	}
+	if rr := get("192.0.2.1:2000"); rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("expected status %d with Retry-After without credentials, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	if rr := get("192.0.2.1:2000", api.APIKeyHeader, "guess"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d for a bad key, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	// Limited attempts never reach the key lookup.
+	if auth.lookups != 2 {
+		t.Errorf("expected 2 key lookups, got %d", auth.lookups)
+	}
+
+	// Other addresses have buckets of their own, and authenticated requests still count
+	// against their route class.
+	rr := get("198.51.100.7:1000", api.APIKeyHeader, "valid-key")
+	if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "100" {
+		t.Errorf("expected the read limit to apply from another address, got %d with limit %q", rr.Code, rr.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestAuthenticatedClientsBehindOneIPAreNotLimitedByIt(t *testing.T) {
+	handler := api.NewHandler(
+		&mocks.FakePlayerStatsService{},
+		&mocks.FakeAggregationService{},
+		&mocks.FakeAdvancedStatsService{},
+		&mocks.FakeLeaderService{},
+		&mocks.FakeStandingsService{},
+		&mocks.FakeGameLogService{},
+		&mocks.FakePlayerService{},
+		&mocks.FakeTeamService{},
+		&mocks.FakeGameService{},
+		&mocks.FakeSeasonService{},
+		&mocks.FakeAuthService{},
+	)
+	handler.RateLimits = api.RateLimits{
+		Store: ratelimit.NewMemoryStore(nil),
+		IP:    ratelimit.PerMinute(60, 2),
+		Reads: ratelimit.PerMinute(60, 5),
+	}
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux, handler, nil)
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/teams", nil)
+		req.RemoteAddr = "192.0.2.1:1000"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Two principals behind one address each get their full read limit, well over the
+	// IP limit, and neither the other's requests nor its own use up the IP's bucket.
+	for i := 0; i < 5; i++ {
+		for _, token := range []string{"dummy-token", "reader-token"} {
+			if rr := get(token); rr.Code != http.StatusOK {
+				t.Fatalf("request %d of %s: expected status %d, got %d", i, token, http.StatusOK, rr.Code)
+			}
+		}
+	}
+	for _, token := range []string{"dummy-token", "reader-token"} {
+		if rr := get(token); rr.Code != http.StatusTooManyRequests || rr.Header().Get("RateLimit-Limit") != "5" {
+			t.Errorf("%s: expected its read limit to be exhausted, got %d with limit %q", token, rr.Code, rr.Header().Get("RateLimit-Limit"))
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if rr := get(""); rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %d without credentials, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	}
+	if rr := get(""); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the IP limit to apply without credentials, got %d", rr.Code)
+	}
+}
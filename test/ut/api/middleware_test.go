@@ -1,13 +1,22 @@
 package api_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/vgeshiktor/nba-stats/internal/api"
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test Logging Middleware
@@ -33,17 +42,44 @@ func TestLoggingMiddleware(t *testing.T) {
 	assert.Contains(t, resp.Body.String(), `"message": "success"`)
 }
 
+// Test Logging Middleware - entries carry the request ID, principal and status, never credentials
+func TestLoggingMiddleware_ContextFields(t *testing.T) {
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	defer logger.SetOutput(nil)
+
+	handler := api.ChainMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), api.RequestTracingMiddleware, api.AuthenticationMiddleware(&mocks.FakeAuthService{}), api.LoggingMiddleware)
+
+	req := httptest.NewRequest("GET", "/logged", nil)
+	req.Header.Set("Authorization", "Bearer dummy-token")
+	req.Header.Set("X-Request-ID", "logged-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "Request completed", entry["message"])
+	assert.Equal(t, "logged-1", entry["request_id"])
+	assert.Equal(t, "tester", entry["principal"])
+	assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+	assert.NotContains(t, logs.String(), "dummy-token")
+}
+
 // Test Auth Middleware - Success Case
 func TestAuthMiddleware_Success(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := api.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "tester", principal.Subject)
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"message": "authorized"}`))
 	})
 
-	handler := api.AuthenticationMiddleware(nextHandler)
+	handler := api.AuthenticationMiddleware(&mocks.FakeAuthService{})(nextHandler)
 
 	req, _ := http.NewRequest("GET", "/secure", nil)
-	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set("Authorization", "Bearer dummy-token")
 	resp := httptest.NewRecorder()
 
 	handler.ServeHTTP(resp, req)
@@ -52,13 +88,33 @@ func TestAuthMiddleware_Success(t *testing.T) {
 	assert.Contains(t, resp.Body.String(), `"message": "authorized"`)
 }
 
+// Test Auth Middleware - API Key
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := api.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, domain.AuthMethodAPIKey, principal.Method)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := api.AuthenticationMiddleware(&mocks.FakeAuthService{})(nextHandler)
+
+	req, _ := http.NewRequest("GET", "/secure", nil)
+	req.Header.Set(api.APIKeyHeader, "valid-key")
+	resp := httptest.NewRecorder()
+
+	handler.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
 // Test Auth Middleware - No Token
 func TestAuthMiddleware_NoToken(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	handler := api.AuthenticationMiddleware(nextHandler)
+	handler := api.AuthenticationMiddleware(&mocks.FakeAuthService{})(nextHandler)
 
 	req, _ := http.NewRequest("GET", "/secure", nil) // No Auth Header
 	resp := httptest.NewRecorder()
@@ -66,24 +122,153 @@ func TestAuthMiddleware_NoToken(t *testing.T) {
 	handler.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
-	assert.Contains(t, resp.Body.String(), `"Unauthorized: missing token"`)
+	assert.Equal(t, `Bearer realm="nba-stats"`, resp.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, resp.Body.String(), `"Unauthorized: missing bearer token or API key"`)
 }
 
-// TODO: add token validation and uncomment this test
 // Test Auth Middleware - Invalid Token
-// func TestAuthMiddleware_InvalidToken(t *testing.T) {
-// 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		w.WriteHeader(http.StatusOK)
-// 	})
+func TestAuthMiddleware_InvalidToken(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
 
-// 	handler := api.AuthenticationMiddleware(nextHandler)
+	handler := api.AuthenticationMiddleware(&mocks.FakeAuthService{})(nextHandler)
 
-// 	req, _ := http.NewRequest("GET", "/secure", nil)
-// 	req.Header.Set("Authorization", "Bearer invalid-token") // Invalid token
-// 	resp := httptest.NewRecorder()
+	for _, header := range []string{"Bearer invalid-token", "dummy-token", "Basic dXNlcjpwYXNz"} {
+		req, _ := http.NewRequest("GET", "/secure", nil)
+		req.Header.Set("Authorization", header) // Invalid token
+		resp := httptest.NewRecorder()
 
-// 	handler.ServeHTTP(resp, req)
+		handler.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, header)
+	}
+}
+
+// Test Rate Limit Middleware - clients are limited independently
+func TestRateLimitMiddleware(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	store := ratelimit.NewMemoryStore(nil)
+	handler := api.ChainMiddleware(nextHandler,
+		api.AuthenticationMiddleware(&mocks.FakeAuthService{}),
+		api.RateLimitMiddleware(store, "test", ratelimit.PerMinute(60, 2)))
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := do("dummy-token")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, do("dummy-token").Code)
 
-// 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
-// 	assert.Contains(t, resp.Body.String(), `"error": "Invalid token"`)
-// }
+	resp = do("dummy-token")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `"type":"urn:nba-stats:problem:rate-limited"`)
+
+	// Another principal has its own bucket.
+	assert.Equal(t, http.StatusOK, do("reader-token").Code)
+}
+
+// Test Rate Limit Middleware - unauthenticated clients are limited by IP address
+func TestRateLimitMiddleware_ByIP(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := api.RateLimitMiddleware(ratelimit.NewMemoryStore(nil), "test", ratelimit.PerMinute(60, 1))(nextHandler)
+
+	do := func(remoteAddr string) int {
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:5000"))
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.1:5001"))
+	assert.Equal(t, http.StatusOK, do("10.0.0.2:5000"))
+}
+
+// Test IP Rate Limit Middleware - X-Forwarded-For identifies clients only behind trusted proxies
+func TestIPRateLimitMiddleware_TrustedProxies(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	handler := api.IPRateLimitMiddleware(ratelimit.NewMemoryStore(nil), ratelimit.PerMinute(60, 1), proxies,
+		api.AuthenticationMiddleware(&mocks.FakeAuthService{}))(nextHandler)
+
+	do := func(remoteAddr, forwardedFor string) int {
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// Behind a trusted proxy each client has its own bucket, whatever it claims to forward for.
+	assert.Equal(t, http.StatusUnauthorized, do("10.0.0.1:5000", "198.51.100.1, 10.0.0.2"))
+	assert.Equal(t, http.StatusUnauthorized, do("10.0.0.1:5000", "192.0.2.1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.3:5000", "203.0.113.9, 192.0.2.1"))
+
+	// Other peers cannot pick a bucket with the header.
+	assert.Equal(t, http.StatusUnauthorized, do("192.0.2.50:5000", "203.0.113.1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("192.0.2.50:5000", "203.0.113.2"))
+}
+
+// Test Rate Limit Middleware - a zero limit disables limiting
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := api.RateLimitMiddleware(ratelimit.NewMemoryStore(nil), "test", ratelimit.Limit{})(nextHandler)
+
+	for i := 0; i < 3; i++ {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest("GET", "/limited", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, resp.Header().Get("RateLimit-Limit"))
+	}
+}
+
+// Test Request Tracing Middleware - the caller's request ID and trace are kept
+func TestRequestTracingMiddleware(t *testing.T) {
+	var requestID string
+	var spanContext tracing.SpanContext
+	handler := api.ChainMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = logger.RequestIDFromContext(r.Context())
+		spanContext = tracing.SpanContextFromContext(r.Context())
+	}), api.RequestTracingMiddleware, api.SpanMiddleware("/traced/{id}"))
+
+	req := httptest.NewRequest("GET", "/traced/1", nil)
+	req.Header.Set("X-Request-ID", "client-id.1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, "client-id.1", requestID)
+	assert.Equal(t, "client-id.1", resp.Header().Get("X-Request-ID"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID.String())
+	assert.NotEqual(t, "00f067aa0ba902b7", spanContext.SpanID.String())
+
+	// An unsafe request ID is replaced, and a malformed traceparent starts a new trace.
+	req = httptest.NewRequest("GET", "/traced/1", nil)
+	req.Header.Set("X-Request-ID", "bad id\n")
+	req.Header.Set("traceparent", "00-nonsense")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.NotEqual(t, "bad id\n", requestID)
+	assert.Equal(t, requestID, resp.Header().Get("X-Request-ID"))
+	assert.True(t, spanContext.IsValid())
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID.String())
+}
@@ -5,7 +5,8 @@ DROP TABLE IF EXISTS players CASCADE;
 CREATE TABLE IF NOT EXISTS players (
     id TEXT PRIMARY KEY,
     name TEXT NOT NULL,
-    team_id TEXT NOT NULL
+    team_id TEXT NOT NULL,
+    updated_at TIMESTAMP
 );
 
 -- Drop Teams table
@@ -14,7 +15,21 @@ DROP TABLE IF EXISTS teams CASCADE;
 -- Create Teams table
 CREATE TABLE IF NOT EXISTS teams (
     id TEXT PRIMARY KEY,
-    name TEXT NOT NULL
+    name TEXT NOT NULL,
+    updated_at TIMESTAMP,
+    conference TEXT NOT NULL DEFAULT '',
+    division TEXT NOT NULL DEFAULT ''
+);
+
+-- Drop Seasons table
+DROP TABLE IF EXISTS seasons CASCADE;
+
+-- Create Seasons table
+CREATE TABLE IF NOT EXISTS seasons (
+    id TEXT PRIMARY KEY,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    playoffs_start TIMESTAMP
 );
 
 -- Drop Games table
@@ -25,7 +40,13 @@ CREATE TABLE IF NOT EXISTS games (
     id TEXT PRIMARY KEY,
     date TIMESTAMP NOT NULL,
     home_team TEXT NOT NULL,
-    away_team TEXT NOT NULL
+    away_team TEXT NOT NULL,
+    season_id TEXT REFERENCES seasons(id),
+    season_type TEXT NOT NULL DEFAULT 'regular',
+    updated_at TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'scheduled',
+    home_score INTEGER NOT NULL DEFAULT 0,
+    away_score INTEGER NOT NULL DEFAULT 0
 );
 
 -- Drop PlayerGameStats table
@@ -44,6 +65,117 @@ CREATE TABLE IF NOT EXISTS player_game_stats (
     fouls INTEGER NOT NULL,
     turnovers INTEGER NOT NULL,
     minutes_played FLOAT NOT NULL,
+    field_goals_made INTEGER NOT NULL DEFAULT 0,
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0,
+    three_pointers_made INTEGER NOT NULL DEFAULT 0,
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0,
+    free_throws_made INTEGER NOT NULL DEFAULT 0,
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0,
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    team_id TEXT NOT NULL DEFAULT '',
+    starter BOOLEAN NULL,
     FOREIGN KEY (player_id) REFERENCES players(id),
     FOREIGN KEY (game_id) REFERENCES games(id)
 );
+
+-- Index games by season for season-scoped aggregates
+CREATE INDEX IF NOT EXISTS idx_games_season ON games (season_id, season_type);
+
+-- Index final games by season for standings
+CREATE INDEX IF NOT EXISTS idx_games_season_status ON games (season_id, status);
+
+-- API keys for machine clients; only a SHA-256 hash of each key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    scopes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS game_scorekeepers (
+    game_id TEXT NOT NULL REFERENCES games(id),
+    subject TEXT NOT NULL,
+    PRIMARY KEY (game_id, subject)
+);
+
+-- Season totals per player and per team, maintained with every stat line so that
+-- aggregates are read from a few rows instead of summed over player_game_stats.
+-- Games without a season are totaled under the season ID ''.
+CREATE TABLE IF NOT EXISTS player_season_totals (
+    player_id TEXT NOT NULL,
+    season_id TEXT NOT NULL,
+    season_type TEXT NOT NULL,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    rebounds INTEGER NOT NULL DEFAULT 0,
+    assists INTEGER NOT NULL DEFAULT 0,
+    steals INTEGER NOT NULL DEFAULT 0,
+    blocks INTEGER NOT NULL DEFAULT 0,
+    fouls INTEGER NOT NULL DEFAULT 0,
+    turnovers INTEGER NOT NULL DEFAULT 0,
+    minutes_played FLOAT NOT NULL DEFAULT 0,
+    field_goals_made INTEGER NOT NULL DEFAULT 0,
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0,
+    three_pointers_made INTEGER NOT NULL DEFAULT 0,
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0,
+    free_throws_made INTEGER NOT NULL DEFAULT 0,
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0,
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (player_id, season_id, season_type)
+);
+
+CREATE TABLE IF NOT EXISTS team_season_totals (
+    team_id TEXT NOT NULL,
+    season_id TEXT NOT NULL,
+    season_type TEXT NOT NULL,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    rebounds INTEGER NOT NULL DEFAULT 0,
+    assists INTEGER NOT NULL DEFAULT 0,
+    steals INTEGER NOT NULL DEFAULT 0,
+    blocks INTEGER NOT NULL DEFAULT 0,
+    fouls INTEGER NOT NULL DEFAULT 0,
+    turnovers INTEGER NOT NULL DEFAULT 0,
+    minutes_played FLOAT NOT NULL DEFAULT 0,
+    field_goals_made INTEGER NOT NULL DEFAULT 0,
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0,
+    three_pointers_made INTEGER NOT NULL DEFAULT 0,
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0,
+    free_throws_made INTEGER NOT NULL DEFAULT 0,
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0,
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (team_id, season_id, season_type)
+);
+
+-- Find the other lines of a player, or of a team, in a game when a line is added.
+CREATE INDEX IF NOT EXISTS idx_player_game_stats_player_game ON player_game_stats (player_id, game_id);
+CREATE INDEX IF NOT EXISTS idx_player_game_stats_game ON player_game_stats (game_id);
+CREATE INDEX IF NOT EXISTS idx_player_game_stats_team_game ON player_game_stats (team_id, game_id);
+
+-- Points scored by each team per period: 1 to 4 are the quarters, later periods overtimes.
+CREATE TABLE IF NOT EXISTS game_periods (
+    game_id TEXT NOT NULL REFERENCES games(id),
+    period INTEGER NOT NULL,
+    home_points INTEGER NOT NULL,
+    away_points INTEGER NOT NULL,
+    PRIMARY KEY (game_id, period)
+);
+
+-- Each stint of a player on a team. A stint without a start goes back to before the
+-- player's history was recorded, and one without an end is the player's current team.
+CREATE TABLE IF NOT EXISTS roster_memberships (
+    player_id TEXT NOT NULL REFERENCES players(id),
+    seq INTEGER NOT NULL,
+    team_id TEXT NOT NULL,
+    joined_by TEXT NOT NULL,
+    start_date TIMESTAMP NULL,
+    end_date TIMESTAMP NULL,
+    ended_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (player_id, seq)
+);
@@ -0,0 +1,174 @@
+// test/ut/jwt/jwt_test.go
+package jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+)
+
+var secret = []byte("test-secret")
+
+// validClaims returns claims that pass the checks of verifier.
+func validClaims() jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:    "https://issuer.test",
+		Subject:   "alice",
+		Audience:  jwt.Audience{"nba-stats"},
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}
+}
+
+// verifier accepts HS256 tokens signed with secret for the test issuer and audience.
+func verifier(keys ...jwt.Key) *jwt.Verifier {
+	if keys == nil {
+		keys = []jwt.Key{{Algorithm: jwt.HS256, Secret: secret}}
+	}
+	return jwt.NewVerifier(jwt.Config{Keys: keys, Issuer: "https://issuer.test", Audience: "nba-stats", Leeway: 30 * time.Second})
+}
+
+func TestVerify_HS256(t *testing.T) {
+	token, err := jwt.SignHS256(validClaims(), "", secret)
+	require.NoError(t, err)
+
+	claims, err := verifier().Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+}
+
+func TestVerify_RejectsInvalidTokens(t *testing.T) {
+	now := time.Now()
+	tests := map[string]func(c *jwt.Claims){
+		"expired":        func(c *jwt.Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() },
+		"no expiry":      func(c *jwt.Claims) { c.ExpiresAt = 0 },
+		"not yet valid":  func(c *jwt.Claims) { c.NotBefore = now.Add(time.Minute).Unix() },
+		"wrong issuer":   func(c *jwt.Claims) { c.Issuer = "https://evil.test" },
+		"wrong audience": func(c *jwt.Claims) { c.Audience = jwt.Audience{"other-api"} },
+	}
+	for name, mutate := range tests {
+		claims := validClaims()
+		mutate(&claims)
+		token, err := jwt.SignHS256(claims, "", secret)
+		require.NoError(t, err)
+		_, err = verifier().Verify(token)
+		assert.True(t, errors.Is(err, jwt.ErrInvalidToken), "%s: expected ErrInvalidToken, got %v", name, err)
+	}
+
+	// Expiry within the leeway is tolerated.
+	claims := validClaims()
+	claims.ExpiresAt = now.Add(-10 * time.Second).Unix()
+	token, _ := jwt.SignHS256(claims, "", secret)
+	_, err := verifier().Verify(token)
+	assert.NoError(t, err)
+}
+
+func TestVerify_RejectsBadSignatures(t *testing.T) {
+	token, _ := jwt.SignHS256(validClaims(), "", []byte("other-secret"))
+	_, err := verifier().Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// Changing the claims invalidates the signature.
+	token, _ = jwt.SignHS256(validClaims(), "", secret)
+	parts := strings.Split(token, ".")
+	forged := validClaims()
+	forged.Subject = "admin"
+	payload, _ := json.Marshal(forged)
+	_, err = verifier().Verify(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2])
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// Unsigned tokens are never accepted.
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	_, err = verifier().Verify(header + "." + parts[1] + ".")
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// An empty HS256 secret does not verify anything.
+	empty, _ := jwt.SignHS256(validClaims(), "", nil)
+	_, err = verifier(jwt.Key{Algorithm: jwt.HS256}).Verify(empty)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	for _, malformed := range []string{"", "a.b", "a.b.c", "!!.!!.!!"} {
+		_, err = verifier().Verify(malformed)
+		assert.ErrorIs(t, err, jwt.ErrInvalidToken, malformed)
+	}
+}
+
+func TestVerify_RS256WithJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "k1", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes())},
+		{"kty": "RSA", "kid": "k2", "use": "enc",
+			"n": base64.RawURLEncoding.EncodeToString(otherKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(otherKey.E)).Bytes())},
+	}})
+	keys, err := jwt.ParseJWKS(jwks)
+	require.NoError(t, err)
+	require.Len(t, keys, 1, "encryption keys are skipped")
+
+	token, err := jwt.SignRS256(validClaims(), "k1", privateKey)
+	require.NoError(t, err)
+	claims, err := verifier(keys...).Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+
+	// A token naming another key, or signed by a key not in the set, is rejected.
+	token, _ = jwt.SignRS256(validClaims(), "k2", privateKey)
+	_, err = verifier(keys...).Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+	token, _ = jwt.SignRS256(validClaims(), "k1", otherKey)
+	_, err = verifier(keys...).Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// An RS256 public key may not be used as an HS256 secret.
+	der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	token, _ = jwt.SignHS256(validClaims(), "k1", pemKey)
+	_, err = verifier(keys...).Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
+
+func TestParseRSAPublicKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	publicKey, err := jwt.ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.True(t, publicKey.Equal(&privateKey.PublicKey))
+
+	pkcs1 := x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)
+	publicKey, err = jwt.ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkcs1}))
+	require.NoError(t, err)
+	assert.True(t, publicKey.Equal(&privateKey.PublicKey))
+
+	_, err = jwt.ParseRSAPublicKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestAudience_AcceptsStringOrArray(t *testing.T) {
+	var claims jwt.Claims
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":"nba-stats"}`), &claims))
+	assert.Equal(t, jwt.Audience{"nba-stats"}, claims.Audience)
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":["a","nba-stats"]}`), &claims))
+	assert.Equal(t, jwt.Audience{"a", "nba-stats"}, claims.Audience)
+}
@@ -0,0 +1,148 @@
+// test/ut/logger/logger_test.go
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/pkg/logger"
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+// capture sends the log entries to a buffer at the given level for the rest of the
+// test, and returns a function decoding the entries written so far.
+func capture(t *testing.T, level logger.Level) func() []map[string]any {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	logger.SetLevel(level)
+	t.Cleanup(func() {
+		logger.SetOutput(nil)
+		logger.SetLevel(logger.LevelInfo)
+	})
+	return func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]logger.Level{
+		"debug": logger.LevelDebug, "INFO": logger.LevelInfo, "warning": logger.LevelWarn,
+		"Error": logger.LevelError, "off": logger.LevelOff,
+	} {
+		level, err := logger.ParseLevel(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+	_, err := logger.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLevels(t *testing.T) {
+	entries := capture(t, logger.LevelWarn)
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "WARN", got[0]["level"])
+	assert.Equal(t, "warn", got[0]["message"])
+	assert.Equal(t, "ERROR", got[1]["level"])
+	assert.Contains(t, got[1]["caller"], "logger/logger_test.go:")
+
+	logger.SetLevel(logger.LevelOff)
+	logger.Error("silenced")
+	assert.Len(t, entries(), 2)
+}
+
+func TestFields(t *testing.T) {
+	entries := capture(t, logger.LevelInfo)
+	log := logger.With("component", "test")
+	log.Info("Loaded", "count", 3, "took", 2*time.Second, "error", errors.New("boom"), "dangling")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "test", got[0]["component"])
+	assert.Equal(t, float64(3), got[0]["count"])
+	assert.Equal(t, "2s", got[0]["took"])
+	assert.Equal(t, "boom", got[0]["error"])
+	assert.Equal(t, "dangling", got[0]["!BADKEY"])
+}
+
+func TestRedaction(t *testing.T) {
+	entries := capture(t, logger.LevelInfo)
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-token")
+	header.Set("X-API-Key", "nbak_abc")
+	header.Set("Accept", "application/json")
+	logger.Info("Request", "headers", header, "password", "hunter2", "Authorization", "Bearer x", "user", "alice")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, logger.Redacted, got[0]["password"])
+	assert.Equal(t, logger.Redacted, got[0]["Authorization"])
+	assert.Equal(t, "alice", got[0]["user"])
+	headers := got[0]["headers"].(map[string]any)
+	assert.Equal(t, []any{logger.Redacted}, headers["Authorization"])
+	assert.Equal(t, []any{logger.Redacted}, headers["X-Api-Key"])
+	assert.Equal(t, []any{"application/json"}, headers["Accept"])
+	// The logged header is a copy; the original is untouched.
+	assert.Equal(t, "Bearer secret-token", header.Get("Authorization"))
+}
+
+func TestFromContext(t *testing.T) {
+	entries := capture(t, logger.LevelDebug)
+	ctx := logger.ContextWithRequestID(context.Background(), "req-1")
+	ctx = logger.ContextWith(ctx, "principal", "tester")
+	ctx, span := tracing.Start(ctx, "test")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Handled", "status", 200)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "req-1", got[0]["request_id"])
+	assert.Equal(t, "tester", got[0]["principal"])
+	assert.Equal(t, span.SpanContext().TraceID.String(), got[0]["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID.String(), got[0]["span_id"])
+	assert.Equal(t, float64(200), got[0]["status"])
+}
+
+func TestSampler(t *testing.T) {
+	entries := capture(t, logger.LevelInfo)
+	log := logger.With().Sampled(logger.NewSampler(2, 3, time.Hour))
+	for i := 0; i < 8; i++ {
+		log.Info("hot", "i", i)
+	}
+	log.Info("other")
+	log.Warn("hot") // Warnings are never sampled.
+
+	var hot []float64
+	for _, entry := range entries() {
+		if entry["message"] == "hot" && entry["level"] == "INFO" {
+			hot = append(hot, entry["i"].(float64))
+		}
+	}
+	// The first two, then every third.
+	assert.Equal(t, []float64{0, 1, 4, 7}, hot)
+	assert.Len(t, entries(), 6)
+}
@@ -0,0 +1,87 @@
+// test/ut/metrics/metrics_test.go
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vgeshiktor/nba-stats/pkg/metrics"
+)
+
+func TestRegistry_TextFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served.", "method", "status")
+	latency := registry.NewHistogram("latency_seconds", "Request latency.", []float64{0.1, 1}, "method")
+	registry.NewGaugeFunc("connections", "Open connections.", func() float64 { return 3 })
+
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	requests.Add(2, "POST", "201")
+	latency.Observe(0.05, "GET")
+	latency.Observe(0.5, "GET")
+	latency.Observe(5, "GET")
+
+	var out strings.Builder
+	_, err := registry.WriteTo(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP connections Open connections.
+# TYPE connections gauge
+connections 3
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{method="GET",le="0.1"} 1
+latency_seconds_bucket{method="GET",le="1"} 2
+latency_seconds_bucket{method="GET",le="+Inf"} 3
+latency_seconds_sum{method="GET"} 5.55
+latency_seconds_count{method="GET"} 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{method="GET",status="200"} 2
+requests_total{method="POST",status="201"} 2
+`, out.String())
+
+	assert.Equal(t, float64(2), requests.Value("GET", "200"))
+	assert.Equal(t, float64(0), requests.Value("DELETE", "204"))
+	assert.Equal(t, uint64(3), latency.Count("GET"))
+}
+
+func TestRegistry_EscapesLabelValues(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounter("events_total", "Line one\nline two.", "name").Inc("a \"quoted\"\\path\n")
+
+	var out strings.Builder
+	registry.WriteTo(&out)
+	assert.Contains(t, out.String(), `# HELP events_total Line one\nline two.`)
+	assert.Contains(t, out.String(), `events_total{name="a \"quoted\"\\path\n"} 1`)
+}
+
+func TestRegistry_ReplacesMetricsOfTheSameName(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewGaugeFunc("connections", "Open connections.", func() float64 { return 1 })
+	registry.NewGaugeFunc("connections", "Open connections.", func() float64 { return 2 })
+
+	var out strings.Builder
+	registry.WriteTo(&out)
+	assert.Equal(t, 1, strings.Count(out.String(), "# TYPE connections"))
+	assert.Contains(t, out.String(), "connections 2\n")
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounter("requests_total", "Requests served.").Inc()
+
+	resp := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, metrics.ContentType, resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "requests_total 1\n")
+}
+
+func TestCounter_PanicsOnWrongLabelCount(t *testing.T) {
+	counter := metrics.NewRegistry().NewCounter("requests_total", "Requests served.", "method")
+	assert.Panics(t, func() { counter.Inc() })
+}
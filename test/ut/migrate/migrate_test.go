@@ -0,0 +1,200 @@
+// test/ut/migrate/migrate_test.go
+package migrate_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/internal/migrate"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+)
+
+// writeMigrations creates a migrations directory holding the given files.
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, script := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o644))
+	}
+	return dir
+}
+
+// openDB opens an in-memory SQLite database on a single connection.
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tableExists reports whether the named table exists.
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var n int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name=$1", name).Scan(&n))
+	return n > 0
+}
+
+var testMigrations = map[string]string{
+	"0001_create_teams.up.sql":     "CREATE TABLE teams (id TEXT PRIMARY KEY);",
+	"0001_create_teams.down.sql":   "DROP TABLE teams;",
+	"0002_create_players.up.sql":   "CREATE TABLE players (id TEXT PRIMARY KEY);",
+	"0002_create_players.down.sql": "DROP TABLE players;",
+	"0003_create_games.up.sql":     "CREATE TABLE games (id TEXT PRIMARY KEY);",
+	"0003_create_games.down.sql":   "DROP TABLE games;",
+	"README.md":                    "not a migration",
+}
+
+func TestLoad_SortsAndPairsScripts(t *testing.T) {
+	migrations, err := migrate.Load(writeMigrations(t, testMigrations), "sqlite")
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_teams", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE games;", migrations[2].Down)
+}
+
+func TestLoad_MissingDownScript(t *testing.T) {
+	_, err := migrate.Load(writeMigrations(t, map[string]string{"0001_create_teams.up.sql": "CREATE TABLE teams (id TEXT);"}), "sqlite")
+	assert.Error(t, err)
+}
+
+func TestLoad_DialectSpecificScripts(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"0001_create_teams.up.sql":             "CREATE TABLE teams (id TEXT PRIMARY KEY);",
+		"0001_create_teams.up.sqlite.sql":      "CREATE TABLE teams (id TEXT PRIMARY KEY) WITHOUT ROWID;",
+		"0001_create_teams.down.sql":           "DROP TABLE teams;",
+		"0002_add_index.up.postgres.sql":       "CREATE INDEX CONCURRENTLY idx_teams ON teams (id);",
+		"0002_add_index.up.sqlite.sql":         "CREATE INDEX idx_teams ON teams (id);",
+		"0002_add_index.down.sql":              "DROP INDEX idx_teams;",
+		"0003_postgres_only.up.postgres.sql":   "SELECT 1;",
+		"0003_postgres_only.down.postgres.sql": "SELECT 1;",
+	})
+
+	sqlite, err := migrate.Load(dir, "sqlite")
+	require.NoError(t, err)
+	require.Len(t, sqlite, 2)
+	assert.Equal(t, "CREATE TABLE teams (id TEXT PRIMARY KEY) WITHOUT ROWID;", sqlite[0].Up)
+	assert.Equal(t, "CREATE INDEX idx_teams ON teams (id);", sqlite[1].Up)
+	assert.Equal(t, "DROP INDEX idx_teams;", sqlite[1].Down)
+
+	postgres, err := migrate.Load(dir, "postgres")
+	require.NoError(t, err)
+	require.Len(t, postgres, 3)
+	assert.Equal(t, "CREATE TABLE teams (id TEXT PRIMARY KEY);", postgres[0].Up)
+	assert.Equal(t, "CREATE INDEX CONCURRENTLY idx_teams ON teams (id);", postgres[1].Up)
+
+	// A version with scripts for one dialect only is incomplete for the other.
+	_, err = migrate.Load(writeMigrations(t, map[string]string{
+		"0001_create_teams.up.sqlite.sql": "CREATE TABLE teams (id TEXT);",
+		"0001_create_teams.down.sql":      "DROP TABLE teams;",
+	}), "postgres")
+	assert.Error(t, err)
+}
+
+func TestMigrator_UpDownTo(t *testing.T) {
+	db := openDB(t)
+	migrations, err := migrate.Load(writeMigrations(t, testMigrations), "sqlite")
+	require.NoError(t, err)
+	migrator := migrate.New(db, migrations, migrate.NoLock{})
+
+	n, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.True(t, tableExists(t, db, "games"))
+
+	// Running up again is a no-op.
+	n, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = migrator.Down(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.True(t, tableExists(t, db, "teams"))
+	assert.False(t, tableExists(t, db, "players"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+
+	n, err = migrator.To(2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, tableExists(t, db, "players"))
+	assert.False(t, tableExists(t, db, "games"))
+
+	n, err = migrator.To(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.False(t, tableExists(t, db, "teams"))
+
+	_, err = migrator.To(42)
+	assert.Error(t, err)
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := openDB(t)
+	files := map[string]string{
+		"0001_create_teams.up.sql":   "CREATE TABLE teams (id TEXT PRIMARY KEY); INSERT INTO nowhere VALUES (1);",
+		"0001_create_teams.down.sql": "DROP TABLE teams;",
+	}
+	migrations, err := migrate.Load(writeMigrations(t, files), "sqlite")
+	require.NoError(t, err)
+
+	_, err = migrate.New(db, migrations, migrate.NoLock{}).Up()
+	assert.Error(t, err)
+	assert.False(t, tableExists(t, db, "teams"))
+
+	var applied int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 0, applied)
+}
+
+func TestMigrator_DetectsModifiedMigration(t *testing.T) {
+	db := openDB(t)
+	migrations, err := migrate.Load(writeMigrations(t, testMigrations), "sqlite")
+	require.NoError(t, err)
+	_, err = migrate.New(db, migrations, migrate.NoLock{}).Up()
+	require.NoError(t, err)
+
+	// Edit an applied migration after the fact.
+	migrations[0].Up = "CREATE TABLE teams (id TEXT PRIMARY KEY, name TEXT);"
+	migrator := migrate.New(db, migrations, migrate.NoLock{})
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+	assert.False(t, statuses[1].Modified)
+
+	_, err = migrator.Down(1)
+	assert.Error(t, err)
+}
+
+func TestMigrator_AppliesRepositoryMigrations(t *testing.T) {
+	// The real migrations must apply and revert cleanly on the SQLite backend,
+	// which enforces foreign keys.
+	migrations, err := migrate.Load("../../../migrations", string(repository.DialectSQLite))
+	require.NoError(t, err)
+	db, err := repository.NewDB(":memory:", 1, 1, 0)
+	require.NoError(t, err)
+	defer db.Close()
+	migrator := migrate.New(db, migrations, migrate.NoLock{})
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	assert.True(t, tableExists(t, db, "player_game_stats"))
+	assert.True(t, tableExists(t, db, "seasons"))
+
+	_, err = migrator.To(0)
+	require.NoError(t, err)
+	assert.False(t, tableExists(t, db, "players"))
+}
@@ -2,7 +2,9 @@
 package mocks
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 )
@@ -11,25 +13,101 @@ import (
 // Fake Player Repository
 // -------------------------
 
-// FakePlayerRepo implements the repository.PlayerRepository interface.
-type FakePlayerRepo struct{}
+// FakePlayerRepo implements the repository.PlayerRepository interface. It knows the
+// player "valid", on team1, and the free agent "free-agent".
+type FakePlayerRepo struct {
+	Recorded *domain.RosterTransaction // The last transaction recorded.
+}
 
-func (r *FakePlayerRepo) CreatePlayer(player *domain.Player) error {
+func (r *FakePlayerRepo) CreatePlayer(ctx context.Context, player *domain.Player) error {
 	if player.ID == "" {
 		return errors.New("player ID cannot be empty")
 	}
 	return nil
 }
 
-func (r *FakePlayerRepo) GetPlayerByID(id string) (*domain.Player, error) {
-	if id == "valid" {
+func (r *FakePlayerRepo) GetPlayerByID(ctx context.Context, id string) (*domain.Player, error) {
+	switch id {
+	case "valid":
 		return &domain.Player{
 			ID:     "valid",
 			Name:   "Test Player",
 			TeamID: "team1",
 		}, nil
+	case "free-agent":
+		return &domain.Player{ID: "free-agent", Name: "Free Agent"}, nil
+	}
+	return nil, domain.NotFoundError("player", id)
+}
+
+func (r *FakePlayerRepo) ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error) {
+	players := []domain.Player{{ID: "valid", Name: "Test Player", TeamID: "team1"}}
+	if filter.TeamID != "" && filter.TeamID != "team1" {
+		return []domain.Player{}, nil
+	}
+	if filter.IDs != nil {
+		found := []domain.Player{}
+		for _, id := range filter.IDs {
+			switch id {
+			case "valid":
+				found = append(found, players[0])
+			case "free-agent":
+				found = append(found, domain.Player{ID: "free-agent", Name: "Free Agent"})
+			}
+		}
+		return found, nil
+	}
+	return players, nil
+}
+
+func (r *FakePlayerRepo) UpdatePlayer(ctx context.Context, player *domain.Player) error {
+	if player.ID != "valid" {
+		return domain.NotFoundError("player", player.ID)
+	}
+	return nil
+}
+
+func (r *FakePlayerRepo) DeletePlayer(ctx context.Context, id string) error {
+	if id != "valid" {
+		return domain.NotFoundError("player", id)
+	}
+	return nil
+}
+
+func (r *FakePlayerRepo) RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error {
+	player, err := r.GetPlayerByID(ctx, transaction.PlayerID)
+	if err != nil {
+		return err
+	}
+	transaction.FromTeamID = player.TeamID
+	r.Recorded = transaction
+	return nil
+}
+
+func (r *FakePlayerRepo) ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error) {
+	if playerID != "valid" {
+		return []domain.RosterMembership{}, nil
+	}
+	return []domain.RosterMembership{{PlayerID: "valid", TeamID: "team1", JoinedBy: domain.TransactionSigning}}, nil
+}
+
+// LineTeams reports stat lines of "valid" for team1 and team2.
+func (r *FakePlayerRepo) LineTeams(ctx context.Context, playerID string) ([]string, error) {
+	if playerID != "valid" {
+		return nil, nil
 	}
-	return nil, errors.New("player not found")
+	return []string{"team1", "team2"}, nil
+}
+
+// TeamsOn places "valid" on team1 at any time; the free agent is on no team.
+func (r *FakePlayerRepo) TeamsOn(ctx context.Context, playerIDs []string, date time.Time) (map[string]string, error) {
+	teams := make(map[string]string)
+	for _, id := range playerIDs {
+		if id == "valid" {
+			teams[id] = "team1"
+		}
+	}
+	return teams, nil
 }
 
 // -------------------------
@@ -39,21 +117,39 @@ func (r *FakePlayerRepo) GetPlayerByID(id string) (*domain.Player, error) {
 // FakeTeamRepo implements the repository.TeamRepository interface.
 type FakeTeamRepo struct{}
 
-func (r *FakeTeamRepo) CreateTeam(team *domain.Team) error {
+func (r *FakeTeamRepo) CreateTeam(ctx context.Context, team *domain.Team) error {
 	if team.ID == "" {
 		return errors.New("team ID cannot be empty")
 	}
 	return nil
 }
 
-func (r *FakeTeamRepo) GetTeamByID(id string) (*domain.Team, error) {
+func (r *FakeTeamRepo) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
 	if id == "team1" {
 		return &domain.Team{
 			ID:   "team1",
 			Name: "Test Team",
 		}, nil
 	}
-	return nil, errors.New("team not found")
+	return nil, domain.NotFoundError("team", id)
+}
+
+func (r *FakeTeamRepo) ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error) {
+	return []domain.Team{{ID: "team1", Name: "Test Team"}}, nil
+}
+
+func (r *FakeTeamRepo) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	if team.ID != "team1" {
+		return domain.NotFoundError("team", team.ID)
+	}
+	return nil
+}
+
+func (r *FakeTeamRepo) DeleteTeam(ctx context.Context, id string) error {
+	if id != "team1" {
+		return domain.NotFoundError("team", id)
+	}
+	return nil
 }
 
 // -------------------------
@@ -61,24 +157,139 @@ func (r *FakeTeamRepo) GetTeamByID(id string) (*domain.Team, error) {
 // -------------------------
 
 // FakeGameRepo implements the repository.GameRepository interface.
-type FakeGameRepo struct{}
+type FakeGameRepo struct {
+	// Filter records the filter of the last ListGames call.
+	Filter domain.GameFilter
+}
 
-func (r *FakeGameRepo) CreateGame(game *domain.Game) error {
+func (r *FakeGameRepo) CreateGame(ctx context.Context, game *domain.Game) error {
 	if game.ID == "" {
 		return errors.New("game ID cannot be empty")
 	}
 	return nil
 }
 
-func (r *FakeGameRepo) GetGameByID(id string) (*domain.Game, error) {
+func (r *FakeGameRepo) GetGameByID(ctx context.Context, id string) (*domain.Game, error) {
 	if id == "game1" {
 		return &domain.Game{
 			ID:       "game1",
 			HomeTeam: "team1",
 			AwayTeam: "team2",
+			Status:   domain.GameStatusLive,
 		}, nil
 	}
-	return nil, errors.New("game not found")
+	if id == "overtime" {
+		return &domain.Game{ID: "overtime", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive, Periods: []domain.PeriodScore{
+			{Period: 1, Home: 25, Away: 25}, {Period: 2, Home: 25, Away: 25}, {Period: 3, Home: 25, Away: 25}, {Period: 4, Home: 25, Away: 25}, {Period: 5, Home: 3, Away: 0},
+		}}, nil
+	}
+	if id == "scheduled" {
+		return &domain.Game{ID: "scheduled", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusScheduled}, nil
+	}
+	if id == "final" {
+		return &domain.Game{ID: "final", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusFinal, HomeScore: 101, AwayScore: 99}, nil
+	}
+	return nil, domain.NotFoundError("game", id)
+}
+
+func (r *FakeGameRepo) ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error) {
+	r.Filter = filter
+	return []domain.Game{{ID: "game1", HomeTeam: "team1", AwayTeam: "team2"}}, nil
+}
+
+func (r *FakeGameRepo) UpdateGame(ctx context.Context, game *domain.Game) error {
+	if game.ID != "game1" {
+		return domain.NotFoundError("game", game.ID)
+	}
+	return nil
+}
+
+func (r *FakeGameRepo) DeleteGame(ctx context.Context, id string) error {
+	if id != "game1" {
+		return domain.NotFoundError("game", id)
+	}
+	return nil
+}
+
+func (r *FakeGameRepo) AssignScorekeeper(ctx context.Context, gameID, subject string) error {
+	if gameID != "game1" {
+		return domain.NotFoundError("game", gameID)
+	}
+	return nil
+}
+
+func (r *FakeGameRepo) UnassignScorekeeper(ctx context.Context, gameID, subject string) error {
+	if gameID != "game1" || subject != "scorekeeper" {
+		return domain.NotFoundError("scorekeeper", subject+" of game "+gameID)
+	}
+	return nil
+}
+
+func (r *FakeGameRepo) ListScorekeepers(ctx context.Context, gameID string) ([]string, error) {
+	if gameID == "game1" {
+		return []string{"scorekeeper"}, nil
+	}
+	return []string{}, nil
+}
+
+// TeamPoints reports stat lines worth 30 points for team1 and 28 for team2 in game1.
+func (r *FakeGameRepo) TeamPoints(ctx context.Context, gameID string) (map[string]int, error) {
+	if gameID == "game1" {
+		return map[string]int{"team1": 30, "team2": 28}, nil
+	}
+	return map[string]int{}, nil
+}
+
+// GameParticipants reports stat lines of player1 for team1 and of player2 for team2 in game1.
+func (r *FakeGameRepo) GameParticipants(ctx context.Context, gameID string) ([]string, []string, error) {
+	if gameID == "game1" {
+		return []string{"player1", "player2"}, []string{"team1", "team2"}, nil
+	}
+	return nil, nil, nil
+}
+
+// IsScorekeeper reports "scorekeeper" as the only scorekeeper of game1.
+func (r *FakeGameRepo) IsScorekeeper(ctx context.Context, gameID, subject string) (bool, error) {
+	return gameID == "game1" && subject == "scorekeeper", nil
+}
+
+// -------------------------
+// Fake Season Repository
+// -------------------------
+
+// FakeSeasonRepo implements the repository.SeasonRepository interface.
+// It knows a single season, "2025-26", with playoffs starting on 2026-04-18.
+type FakeSeasonRepo struct{}
+
+var fakeSeason = domain.Season{
+	ID:            "2025-26",
+	StartDate:     time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC),
+	EndDate:       time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC),
+	PlayoffsStart: time.Date(2026, 4, 18, 0, 0, 0, 0, time.UTC),
+}
+
+func (r *FakeSeasonRepo) CreateSeason(ctx context.Context, season *domain.Season) error {
+	return nil
+}
+
+func (r *FakeSeasonRepo) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	if id == fakeSeason.ID {
+		season := fakeSeason
+		return &season, nil
+	}
+	return nil, domain.NotFoundError("season", id)
+}
+
+func (r *FakeSeasonRepo) GetSeasonByDate(ctx context.Context, date time.Time) (*domain.Season, error) {
+	if fakeSeason.Contains(date) {
+		season := fakeSeason
+		return &season, nil
+	}
+	return nil, domain.Errorf(domain.ErrNotFound, "no season contains %s", date.Format("2006-01-02"))
+}
+
+func (r *FakeSeasonRepo) ListSeasons(ctx context.Context) ([]domain.Season, error) {
+	return []domain.Season{fakeSeason}, nil
 }
 
 // -------------------------
@@ -88,9 +299,14 @@ func (r *FakeGameRepo) GetGameByID(id string) (*domain.Game, error) {
 // FakePlayerStatsRepo implements the repository.PlayerStatsRepository interface.
 type FakePlayerStatsRepo struct {
 	Inserted bool
+	Lines    []domain.PlayerGameStats // Lines stored by the last InsertBoxScore call.
+
+	GameLog       []domain.GameLogEntry // Lines returned by FetchGameLog, up to the filter's limit.
+	GameLogFilter domain.GameLogFilter  // Filter of the last FetchGameLog call.
+	GameLogCursor *domain.GameLogCursor // Cursor of the last FetchGameLog call.
 }
 
-func (r *FakePlayerStatsRepo) InsertPlayerStats(stats *domain.PlayerGameStats) error {
+func (r *FakePlayerStatsRepo) InsertPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error {
 	if stats.PlayerID == "" || stats.GameID == "" {
 		return errors.New("invalid stats: missing playerID or gameID")
 	}
@@ -98,10 +314,18 @@ func (r *FakePlayerStatsRepo) InsertPlayerStats(stats *domain.PlayerGameStats) e
 	return nil
 }
 
-func (r *FakePlayerStatsRepo) FetchPlayerAggregate(playerID string) (*domain.AggregateStats, error) {
+func (r *FakePlayerStatsRepo) InsertBoxScore(ctx context.Context, lines []domain.PlayerGameStats) error {
+	r.Inserted = true
+	r.Lines = lines
+	return nil
+}
+
+func (r *FakePlayerStatsRepo) FetchPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
 	if playerID == "valid" {
 		return &domain.AggregateStats{
 			PlayerID:    "valid",
+			SeasonID:    filter.SeasonID,
+			SeasonType:  filter.SeasonType,
 			GamesPlayed: 1,
 			TotalPoints: 30,
 			AvgPoints:   30,
@@ -110,10 +334,21 @@ func (r *FakePlayerStatsRepo) FetchPlayerAggregate(playerID string) (*domain.Agg
 	return nil, errors.New("aggregate not found")
 }
 
-func (r *FakePlayerStatsRepo) FetchTeamAggregate(teamID string) (*domain.AggregateStats, error) {
+func (r *FakePlayerStatsRepo) FetchGameLog(ctx context.Context, filter domain.GameLogFilter, after *domain.GameLogCursor) ([]domain.GameLogEntry, error) {
+	r.GameLogFilter, r.GameLogCursor = filter, after
+	lines := r.GameLog
+	if filter.Limit > 0 && len(lines) > filter.Limit {
+		lines = lines[:filter.Limit]
+	}
+	return lines, nil
+}
+
+func (r *FakePlayerStatsRepo) FetchTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
 	if teamID == "team1" {
 		return &domain.AggregateStats{
 			TeamID:      "team1",
+			SeasonID:    filter.SeasonID,
+			SeasonType:  filter.SeasonType,
 			GamesPlayed: 1,
 			TotalPoints: 100,
 			AvgPoints:   100,
@@ -121,3 +356,56 @@ func (r *FakePlayerStatsRepo) FetchTeamAggregate(teamID string) (*domain.Aggrega
 	}
 	return nil, errors.New("aggregate not found")
 }
+
+// -------------------------
+// Fake Leader Repository
+// -------------------------
+
+// FakeLeaderRepo records the filter it was called with and returns a single leader.
+type FakeLeaderRepo struct {
+	Filter domain.LeaderFilter
+}
+
+func (r *FakeLeaderRepo) FetchLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error) {
+	r.Filter = filter
+	return []domain.Leader{{Rank: 1, Value: 30, AggregateStats: domain.AggregateStats{PlayerID: "valid", GamesPlayed: 1}}}, nil
+}
+
+// -------------------------
+// Fake API Key Repository
+// -------------------------
+
+// FakeAPIKeyRepo implements the repository.APIKeyRepository interface in memory.
+type FakeAPIKeyRepo struct {
+	Keys []domain.APIKey
+}
+
+func (r *FakeAPIKeyRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.Keys = append(r.Keys, *key)
+	return nil
+}
+
+func (r *FakeAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	for _, key := range r.Keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, domain.NotFoundError("API key", "with this hash")
+}
+
+func (r *FakeAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return r.Keys, nil
+}
+
+func (r *FakeAPIKeyRepo) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	for i := range r.Keys {
+		if r.Keys[i].ID == id {
+			if r.Keys[i].RevokedAt == nil {
+				r.Keys[i].RevokedAt = &at
+			}
+			return nil
+		}
+	}
+	return domain.NotFoundError("API key", id)
+}
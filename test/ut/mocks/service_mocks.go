@@ -2,54 +2,365 @@
 package mocks
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
 	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/validator"
 )
 
 // --- Fake Service Implementations for API Testing ---
 
+// FakeUpdatedAt is when the teams and the final game of the fake services were last changed.
+var FakeUpdatedAt = time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+
 type FakePlayerStatsService struct{}
 
-func (s *FakePlayerStatsService) LogPlayerStats(stats *domain.PlayerGameStats) error {
+func (s *FakePlayerStatsService) LogPlayerStats(ctx context.Context, stats *domain.PlayerGameStats) error {
 	return nil
 }
 
-type FakeAggregationService struct{}
+func (s *FakePlayerStatsService) LogBoxScore(ctx context.Context, gameID string, boxScore *domain.BoxScore) error {
+	if gameID != "game1" {
+		return domain.NotFoundError("game", gameID)
+	}
+	var lineErrors []domain.LineError
+	for i, line := range boxScore.Lines {
+		if line.PlayerID != "player1" {
+			lineErrors = append(lineErrors, domain.LineError{Index: i, PlayerID: line.PlayerID, Errors: []domain.FieldError{
+				{Field: "player_id", Code: domain.CodeNotFound, Message: "player " + line.PlayerID + " does not exist"},
+			}})
+		}
+	}
+	if lineErrors != nil {
+		return &domain.BatchError{Lines: lineErrors}
+	}
+	return nil
+}
 
-func (s *FakeAggregationService) GetPlayerAggregate(playerID string) (*domain.AggregateStats, error) {
+// FakeAggregationService counts the aggregates it returns. Player "unknown" does not exist.
+type FakeAggregationService struct {
+	Calls   atomic.Int64  // Aggregates requested.
+	Release chan struct{} // If set, every request waits until it is closed.
+}
+
+func (s *FakeAggregationService) wait() {
+	s.Calls.Add(1)
+	if s.Release != nil {
+		<-s.Release
+	}
+}
+
+func (s *FakeAggregationService) GetPlayerAggregate(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	s.wait()
+	if playerID == "unknown" {
+		return nil, domain.NotFoundError("player", playerID)
+	}
 	return &domain.AggregateStats{
 		PlayerID:    playerID,
+		SeasonID:    filter.SeasonID,
+		SeasonType:  filter.SeasonType,
 		GamesPlayed: 1,
 		TotalPoints: 30,
 		AvgPoints:   30,
 	}, nil
 }
 
-func (s *FakeAggregationService) GetTeamAggregate(teamID string) (*domain.AggregateStats, error) {
+func (s *FakeAggregationService) GetTeamAggregate(ctx context.Context, teamID string, filter domain.StatsFilter) (*domain.AggregateStats, error) {
+	s.wait()
 	return &domain.AggregateStats{
 		TeamID:      teamID,
+		SeasonID:    filter.SeasonID,
+		SeasonType:  filter.SeasonType,
 		GamesPlayed: 1,
 		TotalPoints: 100,
 		AvgPoints:   100,
 	}, nil
 }
 
+type FakeAdvancedStatsService struct{}
+
+func (s *FakeAdvancedStatsService) GetPlayerAdvanced(ctx context.Context, playerID string, filter domain.StatsFilter) (*domain.AdvancedStats, error) {
+	if playerID != "player1" {
+		return nil, domain.NotFoundError("player", playerID)
+	}
+	return &domain.AdvancedStats{
+		PlayerID:        playerID,
+		TeamID:          "team1",
+		SeasonID:        filter.SeasonID,
+		SeasonType:      filter.SeasonType,
+		GamesPlayed:     1,
+		TrueShootingPct: 0.6,
+	}, nil
+}
+
+type FakeLeaderService struct {
+	// Filter records the filter of the last GetLeaders call.
+	Filter domain.LeaderFilter
+}
+
+func (s *FakeLeaderService) GetLeaders(ctx context.Context, filter domain.LeaderFilter) ([]domain.Leader, error) {
+	s.Filter = filter
+	if filter.Stat == "unknown" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "unknown stat %q", filter.Stat)
+	}
+	return []domain.Leader{
+		{Rank: 1, Value: 9.5, AggregateStats: domain.AggregateStats{PlayerID: "player1", GamesPlayed: 2}},
+		{Rank: 1, Value: 9.5, AggregateStats: domain.AggregateStats{PlayerID: "player2", GamesPlayed: 2}},
+	}, nil
+}
+
+type FakeStandingsService struct {
+	// Filter records the filter of the last GetStandings call.
+	Filter domain.StandingsFilter
+}
+
+func (s *FakeStandingsService) GetStandings(ctx context.Context, filter domain.StandingsFilter) (*domain.Standings, error) {
+	s.Filter = filter
+	if filter.SeasonID == "unknown" {
+		return nil, domain.Errorf(domain.ErrNotFound, "season %s not found", filter.SeasonID)
+	}
+	return &domain.Standings{SeasonID: filter.SeasonID, Group: domain.StandingsGroupLeague, Groups: []domain.StandingsGroup{{
+		Name: domain.StandingsGroupLeague,
+		Teams: []domain.Standing{
+			{Rank: 1, TeamID: "team1", Wins: 2, WinPct: 1, Streak: "W2"},
+			{Rank: 2, TeamID: "team2", Losses: 2, GamesBehind: 2, Streak: "L2"},
+		},
+	}}}, nil
+}
+
+type FakeGameLogService struct {
+	// Filter records the filter of the last ListPlayerGames or ListTeamGames call.
+	Filter domain.GameLogFilter
+}
+
+func (s *FakeGameLogService) ListPlayerGames(ctx context.Context, playerID string, filter domain.GameLogFilter) (*domain.GameLog, error) {
+	s.Filter = filter
+	if playerID != "player1" {
+		return nil, domain.NotFoundError("player", playerID)
+	}
+	return &domain.GameLog{
+		Lines: []domain.GameLogEntry{{
+			PlayerGameStats: domain.PlayerGameStats{ID: "line1", PlayerID: playerID, GameID: "game1", TeamID: "team1", Points: 30},
+			Date:            FakeUpdatedAt,
+			OpponentID:      "team2",
+			Home:            true,
+		}},
+		NextCursor: "next",
+	}, nil
+}
+
+func (s *FakeGameLogService) ListTeamGames(ctx context.Context, teamID string, filter domain.GameLogFilter) (*domain.GameLog, error) {
+	s.Filter = filter
+	if filter.Sort == "height" {
+		return nil, domain.Errorf(domain.ErrInvalidInput, "unknown sort %q", filter.Sort)
+	}
+	return &domain.GameLog{Lines: []domain.GameLogEntry{}}, nil
+}
+
+func (s *FakeGameLogService) GetBoxScore(ctx context.Context, gameID string) (*domain.GameBoxScore, error) {
+	if gameID != "game1" {
+		return nil, domain.NotFoundError("game", gameID)
+	}
+	starter := true
+	return &domain.GameBoxScore{
+		Game: domain.Game{ID: gameID, HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusLive, HomeScore: 20},
+		Teams: []domain.TeamBoxScore{
+			{
+				TeamID: "team1",
+				Home:   true,
+				Lines: []domain.BoxScoreLine{{
+					PlayerGameStats: domain.PlayerGameStats{ID: "game1-player1", PlayerID: "player1", GameID: gameID, TeamID: "team1", Points: 20, Starter: &starter},
+					PlayerName:      "Player One",
+				}},
+				Totals: domain.BoxScoreTotals{Points: 20},
+			},
+			{TeamID: "team2", Lines: []domain.BoxScoreLine{}},
+		},
+	}, nil
+}
+
 type FakePlayerService struct{}
 
-func (s *FakePlayerService) CreatePlayer(player *domain.Player) error { return nil }
-func (s *FakePlayerService) GetPlayerByID(id string) (*domain.Player, error) {
+// CreatePlayer validates the player like the real service, and reports the ID
+// "duplicate" as already taken.
+func (s *FakePlayerService) CreatePlayer(ctx context.Context, player *domain.Player) error {
+	if err := validator.ValidatePlayer(player); err != nil {
+		return err
+	}
+	if player.ID == "duplicate" {
+		return domain.Errorf(domain.ErrConflict, "player %s already exists", player.ID)
+	}
+	return nil
+}
+func (s *FakePlayerService) GetPlayerByID(ctx context.Context, id string) (*domain.Player, error) {
 	return &domain.Player{ID: id, Name: "Test Player", TeamID: "team1"}, nil
 }
+func (s *FakePlayerService) ListPlayers(ctx context.Context, filter domain.PlayerFilter) ([]domain.Player, error) {
+	return []domain.Player{{ID: "player1", Name: "Test Player", TeamID: "team1"}}, nil
+}
+func (s *FakePlayerService) UpdatePlayer(ctx context.Context, player *domain.Player) error {
+	return nil
+}
+func (s *FakePlayerService) PatchPlayer(ctx context.Context, id string, patch *domain.PlayerPatch) (*domain.Player, error) {
+	player := &domain.Player{ID: id, Name: "Test Player", TeamID: "team1"}
+	if patch.Name != nil {
+		player.Name = *patch.Name
+	}
+	if patch.TeamID != nil {
+		player.TeamID = *patch.TeamID
+	}
+	return player, nil
+}
+func (s *FakePlayerService) DeletePlayer(ctx context.Context, id string) error { return nil }
+
+// RecordTransaction validates the transaction like the real service and moves the
+// player from team1.
+func (s *FakePlayerService) RecordTransaction(ctx context.Context, transaction *domain.RosterTransaction) error {
+	if err := validator.ValidateRosterTransaction(transaction); err != nil {
+		return err
+	}
+	transaction.FromTeamID = "team1"
+	return nil
+}
+func (s *FakePlayerService) ListMemberships(ctx context.Context, playerID string) ([]domain.RosterMembership, error) {
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	return []domain.RosterMembership{
+		{PlayerID: playerID, TeamID: "team1", JoinedBy: domain.TransactionSigning, End: &start, EndedBy: domain.TransactionTrade},
+		{PlayerID: playerID, TeamID: "team2", JoinedBy: domain.TransactionTrade, Start: &start},
+	}, nil
+}
 
 type FakeTeamService struct{}
 
-func (s *FakeTeamService) CreateTeam(team *domain.Team) error { return nil }
-func (s *FakeTeamService) GetTeamByID(id string) (*domain.Team, error) {
-	return &domain.Team{ID: id, Name: "Test Team"}, nil
+func (s *FakeTeamService) CreateTeam(ctx context.Context, team *domain.Team) error { return nil }
+func (s *FakeTeamService) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
+	if id == "missing" {
+		return nil, domain.NotFoundError("team", id)
+	}
+	return &domain.Team{ID: id, Name: "Test Team", UpdatedAt: &FakeUpdatedAt}, nil
+}
+func (s *FakeTeamService) ListTeams(ctx context.Context, filter domain.TeamFilter) ([]domain.Team, error) {
+	return []domain.Team{{ID: "team1", Name: "Test Team"}}, nil
 }
+func (s *FakeTeamService) UpdateTeam(ctx context.Context, team *domain.Team) error { return nil }
+func (s *FakeTeamService) PatchTeam(ctx context.Context, id string, patch *domain.TeamPatch) (*domain.Team, error) {
+	team := &domain.Team{ID: id, Name: "Test Team"}
+	if patch.Name != nil {
+		team.Name = *patch.Name
+	}
+	return team, nil
+}
+func (s *FakeTeamService) DeleteTeam(ctx context.Context, id string) error { return nil }
 
 type FakeGameService struct{}
 
-func (s *FakeGameService) CreateGame(game *domain.Game) error { return nil }
-func (s *FakeGameService) GetGameByID(id string) (*domain.Game, error) {
+func (s *FakeGameService) CreateGame(ctx context.Context, game *domain.Game) error { return nil }
+func (s *FakeGameService) GetGameByID(ctx context.Context, id string) (*domain.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch id {
+	case "missing":
+		return nil, domain.NotFoundError("game", id)
+	case "broken":
+		return nil, errors.New("pq: connection reset by peer")
+	case "final":
+		return &domain.Game{ID: id, HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusFinal, HomeScore: 101, AwayScore: 99, Winner: "team1", UpdatedAt: &FakeUpdatedAt}, nil
+	}
 	return &domain.Game{ID: id, HomeTeam: "team1", AwayTeam: "team2"}, nil
-}
\ No newline at end of file
+}
+func (s *FakeGameService) ListGames(ctx context.Context, filter domain.GameFilter) ([]domain.Game, error) {
+	return []domain.Game{{ID: "game1", HomeTeam: "team1", AwayTeam: "team2"}}, nil
+}
+func (s *FakeGameService) UpdateGame(ctx context.Context, game *domain.Game) error { return nil }
+func (s *FakeGameService) PatchGame(ctx context.Context, id string, patch *domain.GamePatch) (*domain.Game, error) {
+	game := &domain.Game{ID: id, HomeTeam: "team1", AwayTeam: "team2"}
+	if patch.HomeTeam != nil {
+		game.HomeTeam = *patch.HomeTeam
+	}
+	if patch.AwayTeam != nil {
+		game.AwayTeam = *patch.AwayTeam
+	}
+	return game, nil
+}
+func (s *FakeGameService) DeleteGame(ctx context.Context, id string) error { return nil }
+func (s *FakeGameService) AssignScorekeeper(ctx context.Context, gameID, subject string) error {
+	if gameID == "missing" {
+		return domain.NotFoundError("game", gameID)
+	}
+	return nil
+}
+func (s *FakeGameService) UnassignScorekeeper(ctx context.Context, gameID, subject string) error {
+	if subject != "scorekeeper" {
+		return domain.NotFoundError("scorekeeper", subject+" of game "+gameID)
+	}
+	return nil
+}
+func (s *FakeGameService) ListScorekeepers(ctx context.Context, gameID string) ([]string, error) {
+	return []string{"scorekeeper"}, nil
+}
+
+// AuthorizeScorekeeper lets catalog admins log stats for any game, and "scorekeeper"
+// for game1 only.
+func (s *FakeGameService) AuthorizeScorekeeper(ctx context.Context, principal *domain.Principal, gameID string) error {
+	if principal == nil {
+		return domain.Errorf(domain.ErrUnauthenticated, "authentication required")
+	}
+	if principal.HasScope(domain.ScopeCatalogAdmin) || (principal.Subject == "scorekeeper" && gameID == "game1") {
+		return nil
+	}
+	return domain.Errorf(domain.ErrForbidden, "%s is not a scorekeeper of game %s", principal.Subject, gameID)
+}
+
+type FakeSeasonService struct{}
+
+func (s *FakeSeasonService) CreateSeason(ctx context.Context, season *domain.Season) error {
+	return nil
+}
+func (s *FakeSeasonService) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	return &domain.Season{ID: id}, nil
+}
+func (s *FakeSeasonService) ListSeasons(ctx context.Context) ([]domain.Season, error) {
+	return []domain.Season{{ID: "2025-26"}}, nil
+}
+
+// FakeAuthService accepts the bearer tokens "dummy-token", granted every scope,
+// "reader-token", granted stats:read, and "scorekeeper-token", granted stats:write,
+// and the API key "valid-key", granted every scope. It knows a single API key, "key1".
+type FakeAuthService struct{}
+
+func (s *FakeAuthService) AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	switch token {
+	case "dummy-token":
+		return &domain.Principal{Subject: "tester", Name: "tester", Method: domain.AuthMethodJWT, Scopes: []string{domain.ScopeCatalogAdmin}}, nil
+	case "reader-token":
+		return &domain.Principal{Subject: "reader", Name: "reader", Method: domain.AuthMethodJWT, Scopes: []string{domain.ScopeStatsRead}}, nil
+	case "scorekeeper-token":
+		return &domain.Principal{Subject: "scorekeeper", Name: "scorekeeper", Method: domain.AuthMethodJWT, Scopes: []string{domain.ScopeStatsWrite}}, nil
+	}
+	return nil, domain.Errorf(domain.ErrUnauthenticated, "invalid token")
+}
+func (s *FakeAuthService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	if key != "valid-key" {
+		return nil, domain.Errorf(domain.ErrUnauthenticated, "invalid API key")
+	}
+	return &domain.Principal{Subject: "api-key:key1", Name: "ci", Method: domain.AuthMethodAPIKey, Scopes: []string{domain.ScopeCatalogAdmin}}, nil
+}
+func (s *FakeAuthService) CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, error) {
+	if name == "" {
+		return nil, "", domain.InvalidField("name", domain.CodeRequired, "name cannot be empty")
+	}
+	return &domain.APIKey{ID: "key1", Name: name, Prefix: "nbak_abcdef", Scopes: scopes}, "nbak_abcdef-secret", nil
+}
+func (s *FakeAuthService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return []domain.APIKey{{ID: "key1", Name: "ci", Prefix: "nbak_abcdef"}}, nil
+}
+func (s *FakeAuthService) RevokeAPIKey(ctx context.Context, id string) error {
+	if id != "key1" {
+		return domain.NotFoundError("API key", id)
+	}
+	return nil
+}
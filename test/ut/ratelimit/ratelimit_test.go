@@ -0,0 +1,104 @@
+// test/ut/ratelimit/ratelimit_test.go
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/pkg/ratelimit"
+)
+
+// clock is a manually advanced time source.
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	c := &clock{now: time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStore(c.Now)
+	limit := ratelimit.PerMinute(60, 3) // One token per second, three at once.
+
+	// The burst is allowed at once, then requests are denied.
+	for i := 2; i >= 0; i-- {
+		d, err := store.Take("alice", limit)
+		require.NoError(t, err)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 3, d.Limit)
+		assert.Equal(t, i, d.Remaining)
+	}
+	d, _ := store.Take("alice", limit)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, time.Second, d.RetryAfter)
+	assert.Equal(t, 3*time.Second, d.Reset)
+
+	// Other keys have their own bucket.
+	d, _ = store.Take("bob", limit)
+	assert.True(t, d.Allowed)
+
+	// Tokens come back at the limit's rate, up to the burst.
+	c.Advance(1500 * time.Millisecond)
+	d, _ = store.Take("alice", limit)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+	d, _ = store.Take("alice", limit)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, time.Second, d.RetryAfter)
+
+	c.Advance(time.Hour)
+	d, _ = store.Take("alice", limit)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 2, d.Remaining)
+}
+
+func TestMemoryStore_Refund(t *testing.T) {
+	c := &clock{now: time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStore(c.Now)
+	limit := ratelimit.PerMinute(60, 2)
+
+	// A refunded token can be taken again, but the bucket never exceeds its burst.
+	store.Take("alice", limit)
+	store.Take("alice", limit)
+	require.NoError(t, store.Refund("alice", limit))
+	d, _ := store.Take("alice", limit)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+
+	c.Advance(time.Hour)
+	require.NoError(t, store.Refund("alice", limit))
+	d, _ = store.Take("alice", limit)
+	assert.Equal(t, 1, d.Remaining)
+
+	// Refunding a key without a bucket creates none.
+	require.NoError(t, store.Refund("bob", limit))
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestMemoryStore_DropsIdleBuckets(t *testing.T) {
+	c := &clock{now: time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStore(c.Now)
+	slow := ratelimit.PerMinute(1, 2)
+	fast := ratelimit.PerMinute(600, 10)
+
+	store.Take("slow", slow)
+	store.Take("slow", slow)
+	store.Take("fast", fast)
+	assert.Equal(t, 2, store.Len())
+
+	// After a minute the fast bucket is full again and dropped; the slow one is not.
+	c.Advance(59 * time.Second)
+	store.Take("other", fast)
+	assert.Equal(t, 3, store.Len())
+	c.Advance(2 * time.Second)
+	store.Take("other", fast)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestLimit_Enabled(t *testing.T) {
+	assert.True(t, ratelimit.PerMinute(60, 10).Enabled())
+	assert.False(t, ratelimit.PerMinute(0, 10).Enabled())
+	assert.False(t, ratelimit.PerMinute(60, 0).Enabled())
+}
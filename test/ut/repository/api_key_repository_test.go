@@ -0,0 +1,83 @@
+// test/ut/repository/api_key_repository_test.go
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewAPIKeyRepository(db)
+
+	created := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	revoked := created.Add(time.Hour)
+	columns := []string{"id", "name", "prefix", "key_hash", "scopes", "created_at", "revoked_at"}
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash").
+		WithArgs("active").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("k1", "ci", "nbak_abcdef", "active", "stats:read stats:write", created, nil))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash").
+		WithArgs("revoked").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("k2", "old", "nbak_ghijkl", "revoked", "catalog:admin", created, revoked))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash").
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	key, err := repo.GetAPIKeyByHash(context.Background(), "active")
+	if err != nil || key.ID != "k1" || key.RevokedAt != nil {
+		t.Errorf("expected active key k1, got %+v (%v)", key, err)
+	} else if strings.Join(key.Scopes, ",") != "stats:read,stats:write" {
+		t.Errorf("expected the stored scopes to be split, got %v", key.Scopes)
+	}
+	key, err = repo.GetAPIKeyByHash(context.Background(), "revoked")
+	if err != nil || key.RevokedAt == nil || !key.RevokedAt.Equal(revoked) {
+		t.Errorf("expected key k2 revoked at %v, got %+v (%v)", revoked, key, err)
+	}
+	if _, err := repo.GetAPIKeyByHash(context.Background(), "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewAPIKeyRepository(db)
+
+	at := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE api_keys SET revoked_at").
+		WithArgs(at, "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.RevokeAPIKey(context.Background(), "missing", at); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+)
+
+func TestParseConnStr(t *testing.T) {
+	tests := []struct {
+		connStr string
+		dialect repository.Dialect
+		dsn     string
+	}{
+		{":memory:", repository.DialectSQLite, ":memory:"},
+		{"sqlite::memory:", repository.DialectSQLite, ":memory:"},
+		{"sqlite://data/nba.db", repository.DialectSQLite, "data/nba.db"},
+		{"sqlite:///var/lib/nba.db", repository.DialectSQLite, "/var/lib/nba.db"},
+		{"file:nba.db?mode=ro", repository.DialectSQLite, "file:nba.db?mode=ro"},
+		{"postgres://user:pw@localhost:5432/nba?sslmode=disable", repository.DialectPostgres, "postgres://user:pw@localhost:5432/nba?sslmode=disable"},
+		{"host=localhost dbname=nba", repository.DialectPostgres, "host=localhost dbname=nba"},
+	}
+	for _, tt := range tests {
+		dialect, dsn := repository.ParseConnStr(tt.connStr)
+		assert.Equal(t, tt.dialect, dialect, tt.connStr)
+		assert.Equal(t, tt.dsn, dsn, tt.connStr)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `UPDATE t SET a = $2, b = '$1 stays', "$3col" = $10 WHERE id = $1`
+	assert.Equal(t, query, repository.Rebind(repository.DialectPostgres, query))
+	assert.Equal(t, `UPDATE t SET a = ?2, b = '$1 stays', "$3col" = ?10 WHERE id = ?1`,
+		repository.Rebind(repository.DialectSQLite, query))
+}
+
+func TestNewDB_SQLite(t *testing.T) {
+	// The pool settings are overridden: a second connection would see a different, empty database.
+	db, err := repository.NewDB(":memory:", 10, 10, time.Minute)
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+
+	_, err = db.Exec(`CREATE TABLE teams (id TEXT PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE players (id TEXT PRIMARY KEY, name TEXT NOT NULL, team_id TEXT NOT NULL REFERENCES teams(id));
+		CREATE TABLE games (id TEXT PRIMARY KEY, date TIMESTAMP NOT NULL)`)
+	require.NoError(t, err)
+
+	t.Run("placeholders bind by number", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO teams (id, name) VALUES ($1, $2)`, "lal", "Lakers")
+		require.NoError(t, err)
+		// $2 appears before $1, which SQLite would otherwise bind positionally.
+		_, err = db.Exec(`UPDATE teams SET name = $2 WHERE id = $1`, "lal", "Los Angeles Lakers")
+		require.NoError(t, err)
+		var name string
+		require.NoError(t, db.QueryRow(`SELECT name FROM teams WHERE id = $1`, "lal").Scan(&name))
+		assert.Equal(t, "Los Angeles Lakers", name)
+	})
+
+	t.Run("foreign keys are enforced", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO players (id, name, team_id) VALUES ($1, $2, $3)`, "p1", "Nobody", "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("times compare chronologically across zones", func(t *testing.T) {
+		east := time.FixedZone("EST", -5*3600)
+		// 23:00 in New York is 04:00 UTC the next day, after the 01:00 UTC game.
+		_, err := db.Exec(`INSERT INTO games (id, date) VALUES ($1, $2), ($3, $4)`,
+			"late", time.Date(2025, 11, 1, 23, 0, 0, 0, east), "early", time.Date(2025, 11, 2, 1, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		rows, err := db.Query(`SELECT id, date FROM games WHERE date >= $1 ORDER BY date`, time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		defer rows.Close()
+		var ids []string
+		for rows.Next() {
+			var game domain.Game
+			require.NoError(t, rows.Scan(&game.ID, &game.Date))
+			ids = append(ids, game.ID)
+		}
+		assert.Equal(t, []string{"early", "late"}, ids)
+	})
+}
+
+func TestSQLiteConstraintViolations(t *testing.T) {
+	db, err := repository.NewDB(":memory:", 1, 1, 0)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE players (id TEXT PRIMARY KEY, name TEXT NOT NULL, team_id TEXT NOT NULL, updated_at TIMESTAMP);
+		CREATE TABLE roster_memberships (player_id TEXT NOT NULL REFERENCES players(id), seq INTEGER NOT NULL,
+			team_id TEXT NOT NULL, joined_by TEXT NOT NULL, start_date TIMESTAMP NULL, end_date TIMESTAMP NULL,
+			ended_by TEXT NOT NULL DEFAULT '', PRIMARY KEY (player_id, seq));
+		CREATE TABLE games (id TEXT PRIMARY KEY, status TEXT);
+		CREATE TABLE player_game_stats (
+			id TEXT PRIMARY KEY,
+			player_id TEXT NOT NULL REFERENCES players(id),
+			game_id TEXT NOT NULL REFERENCES games(id),
+			points INTEGER, rebounds INTEGER, assists INTEGER, steals INTEGER, blocks INTEGER,
+			fouls INTEGER, turnovers INTEGER, minutes_played FLOAT,
+			field_goals_made INTEGER, field_goals_attempted INTEGER, three_pointers_made INTEGER,
+			three_pointers_attempted INTEGER, free_throws_made INTEGER, free_throws_attempted INTEGER,
+			offensive_rebounds INTEGER, defensive_rebounds INTEGER, team_id TEXT, starter BOOLEAN)`)
+	require.NoError(t, err)
+
+	players := repository.NewPlayerRepository(db)
+	player := &domain.Player{ID: "p1", Name: "Ann", TeamID: "team1"}
+	require.NoError(t, players.CreatePlayer(context.Background(), player))
+	assert.ErrorIs(t, players.CreatePlayer(context.Background(), player), domain.ErrConflict)
+
+	stats := repository.NewPlayerStatsRepository(db)
+	err = stats.InsertPlayerStats(context.Background(), &domain.PlayerGameStats{ID: "s1", PlayerID: "p1", GameID: "missing"})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -2,6 +2,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -26,19 +27,28 @@ func TestCreateGame_Success(t *testing.T) {
 
 	// Prepare a sample game.
 	game := &domain.Game{
-		ID:       "game1",
-		Date:     time.Now(),
-		HomeTeam: "team1",
-		AwayTeam: "team2",
+		ID:        "game1",
+		Date:      time.Now(),
+		HomeTeam:  "team1",
+		AwayTeam:  "team2",
+		Status:    domain.GameStatusLive,
+		HomeScore: 30,
+		AwayScore: 25,
+		Periods:   []domain.PeriodScore{{Period: 1, Home: 30, Away: 25}},
 	}
 
-	// Expect an INSERT statement.
+	// Expect the game and its periods to be inserted in a transaction.
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO games").
-		WithArgs(game.ID, game.Date, game.HomeTeam, game.AwayTeam).
+		WithArgs(game.ID, game.Date, game.HomeTeam, game.AwayTeam, sqlmock.AnyArg(), game.SeasonType, game.Status, game.HomeScore, game.AwayScore, game.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO game_periods").
+		WithArgs(game.ID, 1, 30, 25).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	// Call CreateGame.
-	err = repo.CreateGame(game)
+	err = repo.CreateGame(context.Background(), game)
 	if err != nil {
 		t.Errorf("unexpected error on CreateGame: %s", err)
 	}
@@ -66,14 +76,21 @@ func TestGetGameByID_Success(t *testing.T) {
 	awayTeam := "team2"
 
 	// Set up expected query and result rows.
-	rows := sqlmock.NewRows([]string{"id", "date", "home_team", "away_team"}).
-		AddRow(gameID, gameDate, homeTeam, awayTeam)
-	mock.ExpectQuery("SELECT id, date, home_team, away_team FROM games WHERE id = \\$1").
+	rows := sqlmock.NewRows([]string{"id", "date", "home_team", "away_team", "season_id", "season_type", "status", "home_score", "away_score", "updated_at"}).
+		AddRow(gameID, gameDate, homeTeam, awayTeam, "2025-26", "regular", "final", 110, 112, gameDate)
+	mock.ExpectQuery("SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games WHERE id = \\$1").
 		WithArgs(gameID).
 		WillReturnRows(rows)
+	periods := sqlmock.NewRows([]string{"game_id", "period", "home_points", "away_points"})
+	for period := 1; period <= 5; period++ {
+		periods.AddRow(gameID, period, 22, 22+period/5*2)
+	}
+	mock.ExpectQuery("SELECT game_id, period, home_points, away_points FROM game_periods WHERE game_id IN \\(\\$1\\) ORDER BY game_id, period").
+		WithArgs(gameID).
+		WillReturnRows(periods)
 
 	// Call GetGameByID.
-	game, err := repo.GetGameByID(gameID)
+	game, err := repo.GetGameByID(context.Background(), gameID)
 	if err != nil {
 		t.Errorf("unexpected error on GetGameByID: %s", err)
 	}
@@ -88,6 +105,12 @@ func TestGetGameByID_Success(t *testing.T) {
 	if game.AwayTeam != awayTeam {
 		t.Errorf("expected away team %s, got %s", awayTeam, game.AwayTeam)
 	}
+	if game.Status != domain.GameStatusFinal || game.UpdatedAt == nil || !game.UpdatedAt.Equal(gameDate) {
+		t.Errorf("expected a final game updated at %s, got %s updated at %v", gameDate, game.Status, game.UpdatedAt)
+	}
+	if len(game.Periods) != 5 || game.Overtimes != 1 || game.Winner != awayTeam {
+		t.Errorf("expected 5 periods, 1 overtime and %s winning, got %+v", awayTeam, game)
+	}
 
 	// Ensure that all expectations were met.
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -108,18 +131,113 @@ func TestGetGameByID_NotFound(t *testing.T) {
 	gameID := "nonexistent"
 
 	// Set up expected query returning no rows.
-	mock.ExpectQuery("SELECT id, date, home_team, away_team FROM games WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games WHERE id = \\$1").
 		WithArgs(gameID).
 		WillReturnError(sql.ErrNoRows)
 
 	// Call GetGameByID.
-	_, err = repo.GetGameByID(gameID)
+	_, err = repo.GetGameByID(context.Background(), gameID)
 	if err == nil {
 		t.Error("expected error when game is not found, got nil")
 	}
 	// Optionally, you can check for a specific error:
-	if !errors.Is(err, sql.ErrNoRows) {
-		t.Errorf("expected sql.ErrNoRows error, got: %v", err)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListGames_FilterByTeamAndDateRange(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewGameRepository(db)
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	// Set up expected query and result rows.
+	rows := sqlmock.NewRows([]string{"id", "date", "home_team", "away_team", "season_id", "season_type", "status", "home_score", "away_score", "updated_at"}).
+		AddRow("game1", from.Add(48*time.Hour), "team1", "team2", nil, "regular", "scheduled", 0, 0, nil).
+		AddRow("game2", from.Add(24*time.Hour), "team3", "team1", nil, "regular", "live", 12, 9, nil)
+	mock.ExpectQuery("SELECT id, date, home_team, away_team, season_id, season_type, status, home_score, away_score, updated_at FROM games WHERE \\(home_team = \\$1 OR away_team = \\$1\\) AND date >= \\$2 AND date <= \\$3 ORDER BY date DESC, id LIMIT \\$4").
+		WithArgs("team1", from, to, 50).
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT game_id, period, home_points, away_points FROM game_periods WHERE game_id IN \\(\\$1, \\$2\\)").
+		WithArgs("game1", "game2").
+		WillReturnRows(sqlmock.NewRows([]string{"game_id", "period", "home_points", "away_points"}).AddRow("game2", 1, 12, 9))
+
+	// Call ListGames.
+	games, err := repo.ListGames(context.Background(), domain.GameFilter{TeamID: "team1", From: from, To: to, Limit: 50})
+	if err != nil {
+		t.Errorf("unexpected error on ListGames: %s", err)
+	}
+	if len(games) != 2 || games[0].ID != "game1" || games[1].ID != "game2" {
+		t.Fatalf("expected [game1 game2], got %v", games)
+	}
+	if len(games[0].Periods) != 0 || len(games[1].Periods) != 1 {
+		t.Errorf("expected only game2 to have a period, got %v", games)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteGame_RemovesStatsInTransaction(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewGameRepository(db)
+
+	// Expect the game's stats, its scorekeepers, its periods and then the game to be deleted atomically,
+	// and the season totals of its players and the teams of their lines to be recomputed without them.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT player_id, team_id FROM player_game_stats WHERE game_id = \\$1").
+		WithArgs("game1").
+		WillReturnRows(sqlmock.NewRows([]string{"player_id", "team_id"}).AddRow("player1", "team1").AddRow("player2", "team1"))
+	mock.ExpectExec("DELETE FROM player_game_stats WHERE game_id = \\$1").
+		WithArgs("game1").
+		WillReturnResult(sqlmock.NewResult(0, 26))
+	mock.ExpectExec("DELETE FROM player_season_totals WHERE player_id IN \\(\\$1, \\$2\\)").
+		WithArgs("player1", "player2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO player_season_totals").
+		WithArgs("player1", "player2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM team_season_totals WHERE team_id IN \\(\\$1\\)").
+		WithArgs("team1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO team_season_totals").
+		WithArgs("team1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM game_scorekeepers WHERE game_id = \\$1").
+		WithArgs("game1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM game_periods WHERE game_id = \\$1").
+		WithArgs("game1").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM games WHERE id = \\$1").
+		WithArgs("game1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Call DeleteGame.
+	if err := repo.DeleteGame(context.Background(), "game1"); err != nil {
+		t.Errorf("unexpected error on DeleteGame: %s", err)
 	}
 
 	// Ensure that all expectations were met.
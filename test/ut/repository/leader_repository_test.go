@@ -0,0 +1,91 @@
+// test/ut/repository/leader_repository_test.go
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFetchLeaders_Success(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLeaderRepository(db)
+
+	rows := sqlmock.NewRows([]string{
+		"team_id", "leader_rank", "value",
+		"games_played", "total_points", "total_rebounds", "total_assists", "total_steals",
+		"total_blocks", "total_fouls", "total_turnovers", "total_minutes",
+		"total_field_goals_made", "total_field_goals_attempted", "total_three_pointers_made",
+		"total_three_pointers_attempted", "total_free_throws_made", "total_free_throws_attempted",
+		"total_offensive_rebounds", "total_defensive_rebounds",
+	}).
+		AddRow("team1", 1, 25.0, 20, 2300, 900, 500, 160, 100, 400, 280, 4800.0, 0, 0, 0, 0, 0, 0, 0, 0).
+		AddRow("team2", 1, 25.0, 20, 2200, 880, 500, 150, 90, 410, 300, 4800.0, 0, 0, 0, 0, 0, 0, 0, 0)
+
+	// WHERE, HAVING and pagination placeholders are numbered in order of appearance.
+	mock.ExpectQuery("SELECT team_id, RANK\\(\\) OVER \\(ORDER BY SUM\\(assists\\) \\* 1.0 / COUNT\\(DISTINCT game_id\\) DESC\\) AS leader_rank, (.+) "+
+		"FROM player_game_stats INNER JOIN games g ON game_id = g.id "+
+		"WHERE g.season_id = \\$1 GROUP BY team_id HAVING COUNT\\(DISTINCT game_id\\) >= \\$2 "+
+		"ORDER BY leader_rank, team_id LIMIT \\$3 OFFSET \\$4").
+		WithArgs("2025-26", 20, 25, 50).
+		WillReturnRows(rows)
+
+	filter := domain.LeaderFilter{
+		StatsFilter: domain.StatsFilter{SeasonID: "2025-26"},
+		Stat:        "avg_assists",
+		Scope:       domain.LeaderScopeTeam,
+		MinGames:    20,
+		Limit:       25,
+		Offset:      50,
+	}
+	leaders, err := repo.FetchLeaders(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("unexpected error on FetchLeaders: %v", err)
+	}
+	if len(leaders) != 2 {
+		t.Fatalf("expected 2 leaders, got %d", len(leaders))
+	}
+	if leaders[0].TeamID != "team1" || leaders[0].Rank != 1 || leaders[0].Value != 25 || leaders[0].AvgPoints != 115 {
+		t.Errorf("unexpected first leader: %+v", leaders[0])
+	}
+	if leaders[1].Rank != 1 || leaders[1].SeasonID != "2025-26" {
+		t.Errorf("expected tied second leader in season 2025-26, got %+v", leaders[1])
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFetchLeaders_UnknownStat(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLeaderRepository(db)
+
+	_, err = repo.FetchLeaders(context.Background(), domain.LeaderFilter{Stat: "total_points; DROP TABLE players"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got: %v", err)
+	}
+
+	// Ensure no query was run.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -2,9 +2,11 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
@@ -30,13 +32,18 @@ func TestCreatePlayer_Success(t *testing.T) {
 		TeamID: "team1",
 	}
 
-	// Expect an INSERT statement.
+	// Expect the player and their stint on the team to be inserted atomically.
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO players").
-		WithArgs(player.ID, player.Name, player.TeamID).
+		WithArgs(player.ID, player.Name, player.TeamID, player.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO roster_memberships \\(player_id, seq, team_id, joined_by\\) VALUES \\(\\$1, 1, \\$2, \\$3\\)").
+		WithArgs(player.ID, player.TeamID, domain.TransactionSigning).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	// Call CreatePlayer.
-	err = repo.CreatePlayer(player)
+	err = repo.CreatePlayer(context.Background(), player)
 	if err != nil {
 		t.Errorf("unexpected error on CreatePlayer: %s", err)
 	}
@@ -63,14 +70,14 @@ func TestGetPlayerByID_Success(t *testing.T) {
 	expectedTeamID := "team1"
 
 	// Set up expected query and result rows.
-	rows := sqlmock.NewRows([]string{"id", "name", "team_id"}).
-		AddRow(playerID, expectedName, expectedTeamID)
-	mock.ExpectQuery("SELECT id, name, team_id FROM players WHERE id = \\$1").
+	rows := sqlmock.NewRows([]string{"id", "name", "team_id", "updated_at"}).
+		AddRow(playerID, expectedName, expectedTeamID, nil)
+	mock.ExpectQuery("SELECT id, name, team_id, updated_at FROM players WHERE id = \\$1").
 		WithArgs(playerID).
 		WillReturnRows(rows)
 
 	// Call GetPlayerByID.
-	player, err := repo.GetPlayerByID(playerID)
+	player, err := repo.GetPlayerByID(context.Background(), playerID)
 	if err != nil {
 		t.Errorf("unexpected error on GetPlayerByID: %s", err)
 	}
@@ -105,19 +112,19 @@ func TestGetPlayerByID_NotFound(t *testing.T) {
 	playerID := "nonexistent"
 
 	// Set up expected query returning no rows.
-	mock.ExpectQuery("SELECT id, name, team_id FROM players WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, team_id, updated_at FROM players WHERE id = \\$1").
 		WithArgs(playerID).
 		WillReturnError(sql.ErrNoRows)
 
 	// Call GetPlayerByID.
-	_, err = repo.GetPlayerByID(playerID)
+	_, err = repo.GetPlayerByID(context.Background(), playerID)
 	if err == nil {
 		t.Error("expected error when player not found, got nil")
 	}
 
-	// Optionally, verify the error matches sql.ErrNoRows.
-	if !errors.Is(err, sql.ErrNoRows) {
-		t.Errorf("expected error sql.ErrNoRows, got: %v", err)
+	// Verify that the missing row is reported as domain.ErrNotFound.
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
 	}
 
 	// Ensure that all expectations were met.
@@ -125,3 +132,352 @@ func TestGetPlayerByID_NotFound(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestListPlayers_FilterByTeam(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// Set up expected query and result rows.
+	rows := sqlmock.NewRows([]string{"id", "name", "team_id", "updated_at"}).
+		AddRow("player1", "John Doe", "team1", nil).
+		AddRow("player2", "Mike Smith", "team1", time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+	mock.ExpectQuery("SELECT id, name, team_id, updated_at FROM players WHERE team_id = \\$1 ORDER BY name, id LIMIT \\$2 OFFSET \\$3").
+		WithArgs("team1", 10, 20).
+		WillReturnRows(rows)
+
+	// Call ListPlayers.
+	players, err := repo.ListPlayers(context.Background(), domain.PlayerFilter{TeamID: "team1", Limit: 10, Offset: 20})
+	if err != nil {
+		t.Errorf("unexpected error on ListPlayers: %s", err)
+	}
+	if len(players) != 2 {
+		t.Fatalf("expected 2 players, got %d", len(players))
+	}
+	if players[1].ID != "player2" {
+		t.Errorf("expected second player id player2, got %s", players[1].ID)
+	}
+	if players[0].UpdatedAt != nil || players[1].UpdatedAt == nil {
+		t.Errorf("expected only the second player to have an update time, got %v and %v", players[0].UpdatedAt, players[1].UpdatedAt)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdatePlayer_NotFound(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// Expect the player's current team to be looked up first, which finds no player.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT team_id FROM players WHERE id = \\$1").
+		WithArgs("nonexistent").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}))
+	mock.ExpectRollback()
+
+	// Call UpdatePlayer.
+	err = repo.UpdatePlayer(context.Background(), &domain.Player{ID: "nonexistent", Name: "John Doe", TeamID: "team1"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeletePlayer_RemovesStatsInTransaction(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// Expect the player's stats, season totals, roster history and then the player to be
+	// deleted atomically, and the season totals of every team the player's lines were
+	// logged for to be recomputed without them.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT team_id FROM player_game_stats WHERE player_id = \\$1").
+		WithArgs("player1").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow("team1").AddRow("team2"))
+	mock.ExpectExec("DELETE FROM player_game_stats WHERE player_id = \\$1").
+		WithArgs("player1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM player_season_totals WHERE player_id = \\$1").
+		WithArgs("player1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM roster_memberships WHERE player_id = \\$1").
+		WithArgs("player1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM players WHERE id = \\$1").
+		WithArgs("player1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM team_season_totals WHERE team_id IN \\(\\$1, \\$2\\)").
+		WithArgs("team1", "team2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO team_season_totals (.+) WHERE ps.team_id IN \\(\\$1, \\$2\\)").
+		WithArgs("team1", "team2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Call DeletePlayer.
+	if err := repo.DeletePlayer(context.Background(), "player1"); err != nil {
+		t.Errorf("unexpected error on DeletePlayer: %s", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeletePlayer_NotFoundRollsBack(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT team_id FROM player_game_stats WHERE player_id = \\$1").
+		WithArgs("nonexistent").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}))
+	for _, table := range []string{"player_game_stats", "player_season_totals", "roster_memberships"} {
+		mock.ExpectExec("DELETE FROM " + table + " WHERE player_id = \\$1").
+			WithArgs("nonexistent").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec("DELETE FROM players WHERE id = \\$1").
+		WithArgs("nonexistent").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Call DeletePlayer.
+	err = repo.DeletePlayer(context.Background(), "nonexistent")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListPlayers_FilterByIDs(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// Set up expected query and result rows.
+	rows := sqlmock.NewRows([]string{"id", "name", "team_id", "updated_at"}).
+		AddRow("player1", "John Doe", "team1", nil)
+	mock.ExpectQuery("SELECT id, name, team_id, updated_at FROM players WHERE id IN \\(\\$1, \\$2\\) ORDER BY name, id$").
+		WithArgs("player1", "missing").
+		WillReturnRows(rows)
+
+	// Call ListPlayers without a limit, so that every requested player is returned.
+	players, err := repo.ListPlayers(context.Background(), domain.PlayerFilter{IDs: []string{"player1", "missing"}})
+	if err != nil {
+		t.Errorf("unexpected error on ListPlayers: %s", err)
+	}
+	if len(players) != 1 || players[0].ID != "player1" {
+		t.Errorf("expected only player1, got %v", players)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetPlayerByID_Canceled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// A canceled context aborts the query before it reaches the database.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.GetPlayerByID(ctx, "player1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRecordTransaction_Trade(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	date := time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)
+	recordedAt := time.Date(2026, 2, 6, 12, 0, 0, 0, time.UTC)
+	joined := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	transaction := &domain.RosterTransaction{PlayerID: "player1", Type: domain.TransactionTrade, TeamID: "team2", Date: date, RecordedAt: &recordedAt}
+
+	// Expect the current stint to end and one on the new team to start on the trade date,
+	// the lines of later games to move to the new team, and both teams' totals to be
+	// recomputed, all in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT team_id FROM players WHERE id = \\$1").
+		WithArgs("player1").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow("team1"))
+	mock.ExpectExec("UPDATE players SET team_id = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs("team2", &recordedAt, "player1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT seq, start_date, end_date FROM roster_memberships (.+) ORDER BY seq DESC LIMIT 1").
+		WithArgs("player1").
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "start_date", "end_date"}).AddRow(2, joined, nil))
+	mock.ExpectExec("UPDATE roster_memberships SET end_date = \\$1, ended_by = \\$2 WHERE player_id = \\$3 AND seq = \\$4").
+		WithArgs(date, domain.TransactionTrade, "player1", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO roster_memberships").
+		WithArgs("player1", 3, "team2", domain.TransactionTrade, date).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE player_game_stats SET team_id = \\$1 WHERE player_id = \\$2 AND game_id IN \\(SELECT id FROM games WHERE date >= \\$3\\)").
+		WithArgs("team2", "player1", date).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM team_season_totals WHERE team_id IN \\(\\$1, \\$2\\)").
+		WithArgs("team1", "team2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO team_season_totals (.+) WHERE ps.team_id IN \\(\\$1, \\$2\\)").
+		WithArgs("team1", "team2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if err := repo.RecordTransaction(context.Background(), transaction); err != nil {
+		t.Errorf("unexpected error on RecordTransaction: %s", err)
+	}
+	if transaction.FromTeamID != "team1" {
+		t.Errorf("expected the player to leave team1, got %q", transaction.FromTeamID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRecordTransaction_Conflicts(t *testing.T) {
+	joined := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		transaction domain.RosterTransaction
+		expect      func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:        "before the current stint",
+			transaction: domain.RosterTransaction{PlayerID: "player1", Type: domain.TransactionTrade, TeamID: "team2", Date: joined.AddDate(0, 0, -1)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT seq, start_date, end_date FROM roster_memberships").
+					WithArgs("player1").
+					WillReturnRows(sqlmock.NewRows([]string{"seq", "start_date", "end_date"}).AddRow(2, joined, nil))
+			},
+		},
+		{
+			name:        "waiver with later lines",
+			transaction: domain.RosterTransaction{PlayerID: "player1", Type: domain.TransactionWaiver, Date: joined.AddDate(0, 0, 7)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT seq, start_date, end_date FROM roster_memberships").
+					WithArgs("player1").
+					WillReturnRows(sqlmock.NewRows([]string{"seq", "start_date", "end_date"}).AddRow(2, joined, nil))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM player_game_stats ps JOIN games g (.+) g.date >= \\$2").
+					WithArgs("player1", joined.AddDate(0, 0, 7)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			repo := repository.NewPlayerRepository(db)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT team_id FROM players WHERE id = \\$1").
+				WithArgs("player1").
+				WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow("team1"))
+			mock.ExpectExec("UPDATE players SET team_id").WillReturnResult(sqlmock.NewResult(0, 1))
+			tt.expect(mock)
+			mock.ExpectRollback()
+
+			transaction := tt.transaction
+			if err := repo.RecordTransaction(context.Background(), &transaction); !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("expected domain.ErrConflict, got: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestTeamsOn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerRepository(db)
+
+	// The stint covering the date is the one that started on or before it and ended after it.
+	date := time.Date(2026, 1, 15, 19, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT player_id, team_id FROM roster_memberships WHERE player_id IN \\(\\$1, \\$2\\) "+
+		"AND \\(start_date IS NULL OR start_date <= \\$3\\) AND \\(end_date IS NULL OR end_date > \\$4\\)").
+		WithArgs("player1", "player2", date, date).
+		WillReturnRows(sqlmock.NewRows([]string{"player_id", "team_id"}).AddRow("player1", "team1"))
+
+	teams, err := repo.TeamsOn(context.Background(), []string{"player1", "player2"}, date)
+	if err != nil {
+		t.Errorf("unexpected error on TeamsOn: %s", err)
+	}
+	if len(teams) != 1 || teams["player1"] != "team1" {
+		t.Errorf("expected only player1 on team1, got %v", teams)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -2,9 +2,12 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/repository"
@@ -23,10 +26,12 @@ func TestInsertPlayerStats_Success(t *testing.T) {
 	repo := repository.NewPlayerStatsRepository(db)
 
 	// Prepare a sample PlayerGameStats record.
+	starter := true
 	stats := &domain.PlayerGameStats{
 		ID:            "stats1",
 		PlayerID:      "player1",
 		GameID:        "game1",
+		TeamID:        "team1",
 		Points:        25,
 		Rebounds:      8,
 		Assists:       5,
@@ -35,16 +40,39 @@ func TestInsertPlayerStats_Success(t *testing.T) {
 		Fouls:         3,
 		Turnovers:     2,
 		MinutesPlayed: 35.5,
-	}
-
-	// Expect an INSERT statement.
+		Starter:       &starter,
+
+		FieldGoalsMade:         9,
+		FieldGoalsAttempted:    18,
+		ThreePointersMade:      3,
+		ThreePointersAttempted: 7,
+		FreeThrowsMade:         4,
+		FreeThrowsAttempted:    5,
+		OffensiveRebounds:      2,
+		DefensiveRebounds:      6,
+	}
+
+	// Expect the INSERT to be added to the player's and the team's season totals in one
+	// transaction, which first locks the game and checks that it is still live.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE games SET status = status WHERE id = \\$1").WithArgs("game1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT status FROM games WHERE id = \\$1").WithArgs("game1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("live"))
 	mock.ExpectExec("INSERT INTO player_game_stats").
-		WithArgs(stats.ID, stats.PlayerID, stats.GameID, stats.Points, stats.Rebounds,
-			stats.Assists, stats.Steals, stats.Blocks, stats.Fouls, stats.Turnovers, stats.MinutesPlayed).
+		WithArgs(stats.ID, stats.PlayerID, stats.GameID, stats.TeamID, stats.Points, stats.Rebounds,
+			stats.Assists, stats.Steals, stats.Blocks, stats.Fouls, stats.Turnovers, stats.MinutesPlayed,
+			stats.FieldGoalsMade, stats.FieldGoalsAttempted, stats.ThreePointersMade, stats.ThreePointersAttempted,
+			stats.FreeThrowsMade, stats.FreeThrowsAttempted, stats.OffensiveRebounds, stats.DefensiveRebounds, true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO player_season_totals (.+) WHERE ps.id = \\$1 ON CONFLICT").
+		WithArgs(stats.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO team_season_totals (.+) WHERE ps.id = \\$1 ON CONFLICT").
+		WithArgs(stats.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	// Call InsertPlayerStats.
-	err = repo.InsertPlayerStats(stats)
+	err = repo.InsertPlayerStats(context.Background(), stats)
 	if err != nil {
 		t.Errorf("unexpected error on InsertPlayerStats: %v", err)
 	}
@@ -55,6 +83,30 @@ func TestInsertPlayerStats_Success(t *testing.T) {
 	}
 }
 
+func TestInsertPlayerStats_GameNoLongerLive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	// The game was made final after the service checked it, so nothing is inserted.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE games SET status = status WHERE id = \\$1").WithArgs("game1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT status FROM games WHERE id = \\$1").WithArgs("game1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("final"))
+	mock.ExpectRollback()
+
+	err = repo.InsertPlayerStats(context.Background(), &domain.PlayerGameStats{ID: "stat1", PlayerID: "player1", GameID: "game1", TeamID: "team1"})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
 func TestFetchPlayerAggregate_Success(t *testing.T) {
 	// Create a new sqlmock database connection.
 	db, mock, err := sqlmock.New()
@@ -88,6 +140,14 @@ func TestFetchPlayerAggregate_Success(t *testing.T) {
 		"total_fouls",
 		"total_turnovers",
 		"total_minutes",
+		"total_field_goals_made",
+		"total_field_goals_attempted",
+		"total_three_pointers_made",
+		"total_three_pointers_attempted",
+		"total_free_throws_made",
+		"total_free_throws_attempted",
+		"total_offensive_rebounds",
+		"total_defensive_rebounds",
 	}).AddRow(
 		gamesPlayed,
 		totalPoints,
@@ -98,14 +158,15 @@ func TestFetchPlayerAggregate_Success(t *testing.T) {
 		totalFouls,
 		totalTurnovers,
 		totalMinutes,
+		0, 0, 0, 0, 0, 0, 0, 0,
 	)
 
-	mock.ExpectQuery("SELECT (.+) FROM player_game_stats WHERE player_id = \\$1").
+	mock.ExpectQuery("SELECT (.+) FROM player_season_totals WHERE player_id = \\$1").
 		WithArgs(playerID).
 		WillReturnRows(rows)
 
 	// Call FetchPlayerAggregate.
-	agg, err := repo.FetchPlayerAggregate(playerID)
+	agg, err := repo.FetchPlayerAggregate(context.Background(), playerID, domain.StatsFilter{})
 	if err != nil {
 		t.Errorf("unexpected error on FetchPlayerAggregate: %v", err)
 	}
@@ -164,12 +225,12 @@ func TestFetchPlayerAggregate_Error(t *testing.T) {
 	playerID := "nonexistent"
 
 	// Set up expected query returning an error.
-	mock.ExpectQuery("SELECT (.+) FROM player_game_stats WHERE player_id = \\$1").
+	mock.ExpectQuery("SELECT (.+) FROM player_season_totals WHERE player_id = \\$1").
 		WithArgs(playerID).
 		WillReturnError(sql.ErrNoRows)
 
 	// Call FetchPlayerAggregate.
-	_, err = repo.FetchPlayerAggregate(playerID)
+	_, err = repo.FetchPlayerAggregate(context.Background(), playerID, domain.StatsFilter{})
 	if err == nil {
 		t.Error("expected error when aggregate not found, got nil")
 	}
@@ -216,6 +277,14 @@ func TestFetchTeamAggregate_Success(t *testing.T) {
 		"total_fouls",
 		"total_turnovers",
 		"total_minutes",
+		"total_field_goals_made",
+		"total_field_goals_attempted",
+		"total_three_pointers_made",
+		"total_three_pointers_attempted",
+		"total_free_throws_made",
+		"total_free_throws_attempted",
+		"total_offensive_rebounds",
+		"total_defensive_rebounds",
 	}).AddRow(
 		gamesPlayed,
 		totalPoints,
@@ -226,14 +295,15 @@ func TestFetchTeamAggregate_Success(t *testing.T) {
 		totalFouls,
 		totalTurnovers,
 		totalMinutes,
+		0, 0, 0, 0, 0, 0, 0, 0,
 	)
 
-	mock.ExpectQuery("SELECT (.+) FROM player_game_stats ps INNER JOIN players p ON ps.player_id = p.id WHERE p.team_id = \\$1").
+	mock.ExpectQuery("SELECT (.+) FROM team_season_totals WHERE team_id = \\$1").
 		WithArgs(teamID).
 		WillReturnRows(rows)
 
 	// Call FetchTeamAggregate.
-	agg, err := repo.FetchTeamAggregate(teamID)
+	agg, err := repo.FetchTeamAggregate(context.Background(), teamID, domain.StatsFilter{})
 	if err != nil {
 		t.Errorf("unexpected error on FetchTeamAggregate: %v", err)
 	}
@@ -292,12 +362,12 @@ func TestFetchTeamAggregate_Error(t *testing.T) {
 	teamID := "nonexistent"
 
 	// Set up expected query returning an error.
-	mock.ExpectQuery("SELECT (.+) FROM player_game_stats ps INNER JOIN players p ON ps.player_id = p.id WHERE p.team_id = \\$1").
+	mock.ExpectQuery("SELECT (.+) FROM team_season_totals WHERE team_id = \\$1").
 		WithArgs(teamID).
 		WillReturnError(sql.ErrNoRows)
 
 	// Call FetchTeamAggregate.
-	_, err = repo.FetchTeamAggregate(teamID)
+	_, err = repo.FetchTeamAggregate(context.Background(), teamID, domain.StatsFilter{})
 	if err == nil {
 		t.Error("expected error when team aggregate not found, got nil")
 	}
@@ -310,3 +380,281 @@ func TestFetchTeamAggregate_Error(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %v", err)
 	}
 }
+
+func TestFetchPlayerAggregate_SeasonFilter(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	// Set up expected query and result rows.
+	rows := sqlmock.NewRows([]string{
+		"games_played", "total_points", "total_rebounds", "total_assists", "total_steals",
+		"total_blocks", "total_fouls", "total_turnovers", "total_minutes",
+		"total_field_goals_made", "total_field_goals_attempted", "total_three_pointers_made",
+		"total_three_pointers_attempted", "total_free_throws_made", "total_free_throws_attempted",
+		"total_offensive_rebounds", "total_defensive_rebounds",
+	}).AddRow(2, 50, 10, 8, 2, 1, 4, 3, 70.0, 18, 40, 4, 10, 10, 12, 3, 7)
+
+	mock.ExpectQuery("SELECT (.+) FROM player_season_totals WHERE player_id = \\$1 AND season_id = \\$2 AND season_type = \\$3").
+		WithArgs("player1", "2025-26", domain.SeasonTypePlayoffs).
+		WillReturnRows(rows)
+
+	// Call FetchPlayerAggregate restricted to the 2025-26 playoffs.
+	filter := domain.StatsFilter{SeasonID: "2025-26", SeasonType: domain.SeasonTypePlayoffs}
+	agg, err := repo.FetchPlayerAggregate(context.Background(), "player1", filter)
+	if err != nil {
+		t.Fatalf("unexpected error on FetchPlayerAggregate: %v", err)
+	}
+	if agg.SeasonID != "2025-26" || agg.SeasonType != domain.SeasonTypePlayoffs {
+		t.Errorf("expected aggregate to echo the season filter, got %q/%q", agg.SeasonID, agg.SeasonType)
+	}
+	if agg.AvgPoints != 25 {
+		t.Errorf("expected avg_points 25, got %f", agg.AvgPoints)
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFetchTeamAggregate_NoGames(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	// SUM over no season totals yields NULLs, which must be reported as zeros.
+	rows := sqlmock.NewRows([]string{
+		"games_played", "total_points", "total_rebounds", "total_assists", "total_steals",
+		"total_blocks", "total_fouls", "total_turnovers", "total_minutes",
+		"total_field_goals_made", "total_field_goals_attempted", "total_three_pointers_made",
+		"total_three_pointers_attempted", "total_free_throws_made", "total_free_throws_attempted",
+		"total_offensive_rebounds", "total_defensive_rebounds",
+	}).AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM team_season_totals WHERE team_id = \\$1 AND season_id = \\$2").
+		WithArgs("team1", "2030-31").
+		WillReturnRows(rows)
+
+	agg, err := repo.FetchTeamAggregate(context.Background(), "team1", domain.StatsFilter{SeasonID: "2030-31"})
+	if err != nil {
+		t.Fatalf("unexpected error on FetchTeamAggregate: %v", err)
+	}
+	if agg.GamesPlayed != 0 || agg.TotalPoints != 0 || agg.AvgPoints != 0 || agg.FieldGoalPct != 0 {
+		t.Errorf("expected an empty aggregate, got %+v", agg)
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFetchPlayerAggregate_ShootingPercentages(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	// Two games totalling 18/40 FG, 4/10 3PT and 10/12 FT.
+	rows := sqlmock.NewRows([]string{
+		"games_played", "total_points", "total_rebounds", "total_assists", "total_steals",
+		"total_blocks", "total_fouls", "total_turnovers", "total_minutes",
+		"total_field_goals_made", "total_field_goals_attempted", "total_three_pointers_made",
+		"total_three_pointers_attempted", "total_free_throws_made", "total_free_throws_attempted",
+		"total_offensive_rebounds", "total_defensive_rebounds",
+	}).AddRow(2, 50, 10, 8, 2, 1, 4, 3, 70.0, 18, 40, 4, 10, 10, 12, 3, 7)
+
+	mock.ExpectQuery("SELECT (.+) FROM player_season_totals WHERE player_id = \\$1").
+		WithArgs("player1").
+		WillReturnRows(rows)
+
+	agg, err := repo.FetchPlayerAggregate(context.Background(), "player1", domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error on FetchPlayerAggregate: %v", err)
+	}
+	if agg.TotalFieldGoalsMade != 18 || agg.TotalFieldGoalsAttempted != 40 {
+		t.Errorf("expected 18/40 field goals, got %d/%d", agg.TotalFieldGoalsMade, agg.TotalFieldGoalsAttempted)
+	}
+	if agg.TotalOffensiveRebounds != 3 || agg.TotalDefensiveRebounds != 7 {
+		t.Errorf("expected 3 offensive and 7 defensive rebounds, got %d and %d", agg.TotalOffensiveRebounds, agg.TotalDefensiveRebounds)
+	}
+	if agg.FieldGoalPct != 0.45 {
+		t.Errorf("expected field_goal_pct 0.45, got %f", agg.FieldGoalPct)
+	}
+	if agg.ThreePointPct != 0.4 {
+		t.Errorf("expected three_point_pct 0.4, got %f", agg.ThreePointPct)
+	}
+	if want := 10.0 / 12.0; agg.FreeThrowPct != want {
+		t.Errorf("expected free_throw_pct %f, got %f", want, agg.FreeThrowPct)
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestInsertBoxScore_Transaction(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	lines := []domain.PlayerGameStats{
+		{ID: "game1-player1", PlayerID: "player1", GameID: "game1", TeamID: "team1", Points: 20, MinutesPlayed: 30},
+		{ID: "game1-player2", PlayerID: "player2", GameID: "game1", TeamID: "team2", Points: 10, MinutesPlayed: 25},
+	}
+
+	// Every line goes through one prepared statement inside a single transaction,
+	// and is added to the season totals as it is inserted. The game is locked once.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE games SET status = status WHERE id = \\$1").WithArgs("game1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT status FROM games WHERE id = \\$1").WithArgs("game1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("live"))
+	prep := mock.ExpectPrepare("INSERT INTO player_game_stats")
+	prep.ExpectExec().WithArgs("game1-player1", "player1", "game1", "team1", 20, 0, 0, 0, 0, 0, 0, 30.0, 0, 0, 0, 0, 0, 0, 0, 0, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO player_season_totals").WithArgs("game1-player1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO team_season_totals").WithArgs("game1-player1").WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs("game1-player2", "player2", "game1", "team2", 10, 0, 0, 0, 0, 0, 0, 25.0, 0, 0, 0, 0, 0, 0, 0, 0, nil).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("INSERT INTO player_season_totals").WithArgs("game1-player2").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO team_season_totals").WithArgs("game1-player2").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.InsertBoxScore(context.Background(), lines); err != nil {
+		t.Errorf("unexpected error on InsertBoxScore: %v", err)
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestInsertBoxScore_RollbackOnFailure(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	lines := []domain.PlayerGameStats{
+		{ID: "game1-player1", PlayerID: "player1", GameID: "game1"},
+		{ID: "game1-player2", PlayerID: "player2", GameID: "game1"},
+	}
+
+	// The second insert fails, so the first must be rolled back.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE games SET status = status WHERE id = \\$1").WithArgs("game1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT status FROM games WHERE id = \\$1").WithArgs("game1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("live"))
+	prep := mock.ExpectPrepare("INSERT INTO player_game_stats")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO player_season_totals").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO team_season_totals").WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+
+	if err := repo.InsertBoxScore(context.Background(), lines); err == nil {
+		t.Error("expected error when an insert fails, got nil")
+	}
+
+	// Ensure all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFetchGameLog_AfterCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	date := time.Date(2026, 1, 10, 19, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "player_id", "game_id", "team_id", "points", "rebounds", "assists", "steals", "blocks", "fouls",
+		"turnovers", "minutes_played", "field_goals_made", "field_goals_attempted", "three_pointers_made",
+		"three_pointers_attempted", "free_throws_made", "free_throws_attempted", "offensive_rebounds",
+		"defensive_rebounds", "starter", "date", "season_id", "season_type", "opponent", "home",
+	}).AddRow("line7", "player1", "game7", "team1", 28, 5, 4, 1, 0, 2, 3, 34.5, 10, 20, 2, 6, 6, 7, 1, 4, false,
+		date, "2025-26", domain.SeasonTypeRegular, "team2", false)
+
+	// Lines tied with the cursor on points continue after it by ID.
+	opponent := "CASE WHEN g.home_team = ps.team_id THEN g.away_team ELSE g.home_team END"
+	mock.ExpectQuery("SELECT ps.id, (.+) FROM player_game_stats ps INNER JOIN games g ON ps.game_id = g.id "+
+		"WHERE ps.player_id = \\$1 AND "+regexp.QuoteMeta(opponent)+" = \\$2 AND g.away_team = ps.team_id "+
+		"AND g.season_id = \\$3 AND \\(ps.points < \\$4 OR \\(ps.points = \\$4 AND ps.id < \\$5\\)\\) "+
+		"ORDER BY ps.points DESC, ps.id DESC LIMIT \\$6").
+		WithArgs("player1", "team2", "2025-26", 30.0, "line3", 11).
+		WillReturnRows(rows)
+
+	filter := domain.GameLogFilter{
+		StatsFilter: domain.StatsFilter{SeasonID: "2025-26"},
+		PlayerID:    "player1",
+		OpponentID:  "team2",
+		Location:    domain.GameLocationAway,
+		Sort:        "points",
+		Limit:       11,
+	}
+	lines, err := repo.FetchGameLog(context.Background(), filter, &domain.GameLogCursor{Value: 30, ID: "line3"})
+	if err != nil {
+		t.Fatalf("unexpected error on FetchGameLog: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	line := lines[0]
+	if line.ID != "line7" || line.Points != 28 || line.MinutesPlayed != 34.5 || line.DefensiveRebounds != 4 || line.Starter == nil || *line.Starter {
+		t.Errorf("unexpected stats: %+v", line.PlayerGameStats)
+	}
+	if !line.Date.Equal(date) || line.SeasonID != "2025-26" || line.OpponentID != "team2" || line.Home {
+		t.Errorf("unexpected game: %+v", line)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFetchGameLog_UnknownSort(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPlayerStatsRepository(db)
+
+	// The sort is spliced into the query, so anything but a known column is rejected.
+	_, err = repo.FetchGameLog(context.Background(), domain.GameLogFilter{TeamID: "team1", Sort: "points; DROP TABLE games"}, nil)
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
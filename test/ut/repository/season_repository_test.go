@@ -0,0 +1,117 @@
+// test/ut/repository/season_repository_test.go
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCreateSeason_Success(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeasonRepository(db)
+
+	season := &domain.Season{
+		ID:            "2025-26",
+		StartDate:     time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC),
+		PlayoffsStart: time.Date(2026, 4, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Expect an INSERT statement.
+	mock.ExpectExec("INSERT INTO seasons").
+		WithArgs(season.ID, season.StartDate, season.EndDate, season.PlayoffsStart).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Call CreateSeason.
+	if err := repo.CreateSeason(context.Background(), season); err != nil {
+		t.Errorf("unexpected error on CreateSeason: %s", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetSeasonByDate_Success(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeasonRepository(db)
+
+	start := time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC)
+	gameDate := time.Date(2026, 6, 21, 20, 30, 0, 0, time.UTC) // Evening of the last day.
+
+	rows := sqlmock.NewRows([]string{"id", "start_date", "end_date", "playoffs_start"}).
+		AddRow("2025-26", start, end, nil)
+	mock.ExpectQuery("SELECT id, start_date, end_date, playoffs_start FROM seasons WHERE start_date <= \\$1").
+		WithArgs(gameDate).
+		WillReturnRows(rows)
+
+	// Call GetSeasonByDate.
+	season, err := repo.GetSeasonByDate(context.Background(), gameDate)
+	if err != nil {
+		t.Fatalf("unexpected error on GetSeasonByDate: %s", err)
+	}
+	if season.ID != "2025-26" {
+		t.Errorf("expected season 2025-26, got %s", season.ID)
+	}
+	if !season.PlayoffsStart.IsZero() {
+		t.Errorf("expected no playoffs start, got %s", season.PlayoffsStart)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetSeasonByDate_BetweenSeasons(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeasonRepository(db)
+
+	start := time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC)
+	offseason := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	// The most recent season started before the date but has already ended.
+	rows := sqlmock.NewRows([]string{"id", "start_date", "end_date", "playoffs_start"}).
+		AddRow("2025-26", start, end, nil)
+	mock.ExpectQuery("SELECT id, start_date, end_date, playoffs_start FROM seasons WHERE start_date <= \\$1").
+		WithArgs(offseason).
+		WillReturnRows(rows)
+
+	_, err = repo.GetSeasonByDate(context.Background(), offseason)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
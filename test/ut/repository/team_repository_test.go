@@ -2,6 +2,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/vgeshiktor/nba-stats/internal/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func TestCreateTeam_Success(t *testing.T) {
@@ -25,17 +27,19 @@ func TestCreateTeam_Success(t *testing.T) {
 
 	// Prepare a sample team.
 	team := &domain.Team{
-		ID:   "team1",
-		Name: "Test Team",
+		ID:         "team1",
+		Name:       "Test Team",
+		Conference: domain.ConferenceWest,
+		Division:   "Pacific",
 	}
 
 	// Expect an INSERT statement.
 	mock.ExpectExec("INSERT INTO teams").
-		WithArgs(team.ID, team.Name).
+		WithArgs(team.ID, team.Name, team.Conference, team.Division, team.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Call CreateTeam.
-	err = repo.CreateTeam(team)
+	err = repo.CreateTeam(context.Background(), team)
 	if err != nil {
 		t.Errorf("unexpected error on CreateTeam: %v", err)
 	}
@@ -61,14 +65,14 @@ func TestGetTeamByID_Success(t *testing.T) {
 	expectedName := "Test Team"
 
 	// Set up expected query and result rows.
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow(teamID, expectedName)
-	mock.ExpectQuery("SELECT id, name FROM teams WHERE id = \\$1").
+	rows := sqlmock.NewRows([]string{"id", "name", "conference", "division", "updated_at"}).
+		AddRow(teamID, expectedName, domain.ConferenceWest, "Pacific", nil)
+	mock.ExpectQuery("SELECT id, name, conference, division, updated_at FROM teams WHERE id = \\$1").
 		WithArgs(teamID).
 		WillReturnRows(rows)
 
 	// Call GetTeamByID.
-	team, err := repo.GetTeamByID(teamID)
+	team, err := repo.GetTeamByID(context.Background(), teamID)
 	if err != nil {
 		t.Errorf("unexpected error on GetTeamByID: %v", err)
 	}
@@ -80,6 +84,9 @@ func TestGetTeamByID_Success(t *testing.T) {
 	if team.Name != expectedName {
 		t.Errorf("expected team name %s, got %s", expectedName, team.Name)
 	}
+	if team.Conference != domain.ConferenceWest || team.Division != "Pacific" {
+		t.Errorf("expected the West's Pacific division, got %s %s", team.Conference, team.Division)
+	}
 
 	// Ensure that all expectations were met.
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -100,19 +107,19 @@ func TestGetTeamByID_NotFound(t *testing.T) {
 	teamID := "nonexistent"
 
 	// Set up expected query returning an error (simulate no rows found).
-	mock.ExpectQuery("SELECT id, name FROM teams WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, conference, division, updated_at FROM teams WHERE id = \\$1").
 		WithArgs(teamID).
 		WillReturnError(sql.ErrNoRows)
 
 	// Call GetTeamByID.
-	_, err = repo.GetTeamByID(teamID)
+	_, err = repo.GetTeamByID(context.Background(), teamID)
 	if err == nil {
 		t.Error("expected error when team not found, got nil")
 	}
 
-	// Optionally, verify that the error is sql.ErrNoRows.
-	if !errors.Is(err, sql.ErrNoRows) {
-		t.Errorf("expected error sql.ErrNoRows, got: %v", err)
+	// Verify that the missing row is reported as domain.ErrNotFound.
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
 	}
 
 	// Ensure that all expectations were met.
@@ -120,3 +127,70 @@ func TestGetTeamByID_NotFound(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %v", err)
 	}
 }
+
+func TestDeleteTeam_WithPlayersConflict(t *testing.T) {
+	// Create a new sqlmock database connection.
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTeamRepository(db)
+
+	// The team still has players on its roster, so nothing may be deleted.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM players WHERE team_id = \\$1").
+		WithArgs("team1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM games WHERE home_team = \\$1 OR away_team = \\$1").
+		WithArgs("team1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	// Call DeleteTeam.
+	err = repo.DeleteTeam(context.Background(), "team1")
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected domain.ErrConflict, got: %v", err)
+	}
+
+	// Ensure that all expectations were met.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestCreateTeam_ConstraintViolations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTeamRepository(db)
+	team := &domain.Team{ID: "team1", Name: "Test Team"}
+
+	// PostgreSQL reports a duplicate ID as a unique violation.
+	mock.ExpectExec("INSERT INTO teams").WillReturnError(&pq.Error{Code: "23505"})
+	err = repo.CreateTeam(context.Background(), team)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected domain.ErrConflict, got: %v", err)
+	}
+
+	mock.ExpectExec("INSERT INTO teams").WillReturnError(&pq.Error{Code: "23503"})
+	err = repo.CreateTeam(context.Background(), team)
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected domain.ErrValidation, got: %v", err)
+	}
+
+	// Other driver errors are passed through unchanged.
+	mock.ExpectExec("INSERT INTO teams").WillReturnError(sql.ErrConnDone)
+	err = repo.CreateTeam(context.Background(), team)
+	if !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("expected sql.ErrConnDone, got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// test/ut/service/advanced_stats_service_test.go
+package service_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+// assertClose fails the test if got differs from want by more than 1e-3.
+func assertClose(t *testing.T, name string, want, got float64) {
+	t.Helper()
+	if math.Abs(want-got) > 1e-3 {
+		t.Errorf("expected %s %.4f, got %.4f", name, want, got)
+	}
+}
+
+func TestComputeAdvancedStats_HandComputed(t *testing.T) {
+	// Two games: 18/40 FG, 4/10 3PT, 10/12 FT, 3 offensive and 7 defensive rebounds in 72 minutes.
+	player := &domain.AggregateStats{
+		PlayerID: "player1", GamesPlayed: 2, TotalPoints: 50, TotalRebounds: 10, TotalAssists: 8,
+		TotalSteals: 2, TotalBlocks: 1, TotalFouls: 4, TotalTurnovers: 4, TotalMinutes: 72,
+		TotalFieldGoalsMade: 18, TotalFieldGoalsAttempted: 40, TotalThreePointersMade: 4,
+		TotalThreePointersAttempted: 10, TotalFreeThrowsMade: 10, TotalFreeThrowsAttempted: 12,
+		TotalOffensiveRebounds: 3, TotalDefensiveRebounds: 7,
+	}
+	// The same two games for the team: 480 player-minutes, i.e. two 48-minute games.
+	team := &domain.AggregateStats{
+		TeamID: "team1", GamesPlayed: 2, TotalMinutes: 480, TotalFieldGoalsAttempted: 170,
+		TotalFreeThrowsAttempted: 50, TotalTurnovers: 30, TotalOffensiveRebounds: 20,
+	}
+
+	adv := service.ComputeAdvancedStats(player, team)
+
+	// TS% = 50 / (2 * (40 + 0.44*12)) = 50 / 90.56
+	assertClose(t, "true_shooting_pct", 0.55212, adv.TrueShootingPct)
+	// eFG% = (18 + 0.5*4) / 40
+	assertClose(t, "effective_fg_pct", 0.5, adv.EffectiveFGPct)
+	assertClose(t, "assist_turnover_ratio", 2, adv.AssistTurnoverRatio)
+	// USG% = 100 * 49.28 * 96 / (72 * 222)
+	assertClose(t, "usage_rate", 29.5983, adv.UsageRate)
+	// Possessions = (222 - 20) * 72 / 96
+	assertClose(t, "possessions", 151.5, adv.Possessions)
+	// Game score 35.3 over 72 minutes, scaled to 36.
+	assertClose(t, "per", 17.65, adv.PER)
+	assertClose(t, "per_36.points", 25, adv.Per36.Points)
+	assertClose(t, "per_36.assists", 4, adv.Per36.Assists)
+	assertClose(t, "per_100_possessions.points", 33.0033, adv.Per100.Points)
+
+	if adv.PlayerID != "player1" || adv.TeamID != "team1" || adv.GamesPlayed != 2 {
+		t.Errorf("expected identifiers to be carried over, got %+v", adv)
+	}
+}
+
+func TestComputeAdvancedStats_NoMinutes(t *testing.T) {
+	adv := service.ComputeAdvancedStats(&domain.AggregateStats{PlayerID: "player1"}, &domain.AggregateStats{TeamID: "team1"})
+
+	if adv.TrueShootingPct != 0 || adv.UsageRate != 0 || adv.PER != 0 || adv.Per36.Points != 0 || adv.Per100.Points != 0 {
+		t.Errorf("expected all metrics to be 0 without minutes or attempts, got %+v", adv)
+	}
+}
+
+func TestGetPlayerAdvanced_Success(t *testing.T) {
+	advancedService := service.NewAdvancedStatsService(&mocks.FakePlayerRepo{}, &mocks.FakePlayerStatsRepo{}, &mocks.FakeSeasonRepo{})
+
+	adv, err := advancedService.GetPlayerAdvanced(context.Background(), "valid", domain.StatsFilter{SeasonID: "2025-26"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if adv.TeamID != "team1" || adv.SeasonID != "2025-26" {
+		t.Errorf("expected the player's team and season filter, got %q/%q", adv.TeamID, adv.SeasonID)
+	}
+}
+
+func TestGetPlayerAdvanced_UnknownPlayer(t *testing.T) {
+	advancedService := service.NewAdvancedStatsService(&mocks.FakePlayerRepo{}, &mocks.FakePlayerStatsRepo{}, &mocks.FakeSeasonRepo{})
+
+	if _, err := advancedService.GetPlayerAdvanced(context.Background(), "invalid", domain.StatsFilter{}); err == nil {
+		t.Errorf("expected error for unknown player, got nil")
+	}
+}
@@ -0,0 +1,230 @@
+// test/ut/service/aggregation_cache_test.go
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestAggregationCache_HitsUntilExpired(t *testing.T) {
+	next := &mocks.FakeAggregationService{}
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cache := service.NewAggregationCache(next, time.Minute, 100, clock.Now)
+	ctx := context.Background()
+	season := domain.StatsFilter{SeasonID: "2025-26"}
+
+	for i := 0; i < 3; i++ {
+		agg, err := cache.GetPlayerAggregate(ctx, "player1", season)
+		if err != nil || agg.PlayerID != "player1" || agg.SeasonID != "2025-26" {
+			t.Fatalf("Expected player1's 2025-26 aggregate, got %+v, %v", agg, err)
+		}
+		// Callers get their own copy.
+		agg.TotalPoints = -1
+	}
+	if agg, _ := cache.GetPlayerAggregate(ctx, "player1", season); agg.TotalPoints != 30 {
+		t.Errorf("Expected the cached aggregate to be unaffected by callers, got %d points", agg.TotalPoints)
+	}
+	// Another filter or team is another aggregate.
+	cache.GetPlayerAggregate(ctx, "player1", domain.StatsFilter{})
+	cache.GetTeamAggregate(ctx, "player1", season)
+	if calls := next.Calls.Load(); calls != 3 {
+		t.Errorf("Expected 3 fetches, got %d", calls)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	cache.GetPlayerAggregate(ctx, "player1", season)
+	if calls := next.Calls.Load(); calls != 4 {
+		t.Errorf("Expected an expired aggregate to be fetched again, got %d fetches", calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 4 || stats.Entries != 3 {
+		t.Errorf("Expected 3 hits, 4 misses and 3 entries, got %+v", stats)
+	}
+}
+
+func TestAggregationCache_ErrorsAreNotCached(t *testing.T) {
+	next := &mocks.FakeAggregationService{}
+	cache := service.NewAggregationCache(next, time.Minute, 100, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetPlayerAggregate(context.Background(), "unknown", domain.StatsFilter{}); err == nil {
+			t.Fatal("Expected an error for an unknown player")
+		}
+	}
+	if calls := next.Calls.Load(); calls != 2 {
+		t.Errorf("Expected every request for an unknown player to be fetched, got %d fetches", calls)
+	}
+}
+
+func TestAggregationCache_Evicts(t *testing.T) {
+	next := &mocks.FakeAggregationService{}
+	cache := service.NewAggregationCache(next, time.Minute, 2, nil)
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		cache.GetTeamAggregate(context.Background(), id, domain.StatsFilter{})
+	}
+	if entries := cache.Stats().Entries; entries > 2 {
+		t.Errorf("Expected at most 2 entries, got %d", entries)
+	}
+}
+
+func TestAggregationCache_Coalesces(t *testing.T) {
+	next := &mocks.FakeAggregationService{Release: make(chan struct{})}
+	cache := service.NewAggregationCache(next, time.Minute, 100, nil)
+
+	const requests = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetPlayerAggregate(context.Background(), "star", domain.StatsFilter{})
+			errs <- err
+		}()
+	}
+	// Hold the first fetch until every other request waits for it.
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Stats().Coalesced < requests-1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d coalesced requests, got %+v", requests-1, cache.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(next.Release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected every request to succeed, got %v", err)
+		}
+	}
+	if calls := next.Calls.Load(); calls != 1 {
+		t.Errorf("Expected a single fetch, got %d", calls)
+	}
+}
+
+func TestAggregationCache_CanceledFetchIsRetried(t *testing.T) {
+	next := &mocks.FakeAggregationService{Release: make(chan struct{})}
+	cache := service.NewAggregationCache(next, time.Minute, 100, nil)
+
+	// The first request is canceled while it fetches; the one waiting for it is not.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		cache.GetPlayerAggregate(ctx, "star", domain.StatsFilter{})
+	}()
+	for next.Calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		_, err := cache.GetPlayerAggregate(context.Background(), "star", domain.StatsFilter{})
+		second <- err
+	}()
+	for cache.Stats().Coalesced == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	close(next.Release)
+	<-first
+
+	if err := <-second; err != nil {
+		t.Errorf("Expected the waiting request to fetch again and succeed, got %v", err)
+	}
+}
+
+func TestAggregationCache_InvalidatedByLoggedStats(t *testing.T) {
+	next := &mocks.FakeAggregationService{}
+	cache := service.NewAggregationCache(next, time.Hour, 100, nil)
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakePlayerStatsRepo{}, cache)
+	ctx := context.Background()
+
+	fetchAll := func() {
+		cache.GetPlayerAggregate(ctx, "valid", domain.StatsFilter{})
+		cache.GetPlayerAggregate(ctx, "valid", domain.StatsFilter{SeasonType: domain.SeasonTypePlayoffs})
+		cache.GetTeamAggregate(ctx, "team1", domain.StatsFilter{})
+		cache.GetTeamAggregate(ctx, "team2", domain.StatsFilter{})
+	}
+	fetchAll()
+
+	// A line for "valid", of team1, discards their aggregates but not team2's.
+	stats := &domain.PlayerGameStats{ID: "stats1", PlayerID: "valid", GameID: "game1", Points: 10, MinutesPlayed: 20}
+	if err := statsService.LogPlayerStats(ctx, stats); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	fetchAll()
+	if calls := next.Calls.Load(); calls != 7 {
+		t.Errorf("Expected 3 aggregates to be fetched again, got %d fetches in all", calls)
+	}
+
+	// So does a box score line.
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", Points: 12, MinutesPlayed: 28}}}
+	if err := statsService.LogBoxScore(ctx, "game1", boxScore); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	fetchAll()
+	if calls := next.Calls.Load(); calls != 10 {
+		t.Errorf("Expected 3 aggregates to be fetched again, got %d fetches in all", calls)
+	}
+}
+
+func TestAggregationCache_InvalidatedByRosterMovesAndDeletions(t *testing.T) {
+	next := &mocks.FakeAggregationService{}
+	cache := service.NewAggregationCache(next, time.Hour, 100, nil)
+	playerService := service.NewPlayerService(&mocks.FakePlayerRepo{}, cache)
+	gameService := service.NewGameService(&mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{}, cache)
+	ctx := context.Background()
+
+	fetchAll := func() {
+		cache.GetPlayerAggregate(ctx, "valid", domain.StatsFilter{})
+		cache.GetPlayerAggregate(ctx, "player1", domain.StatsFilter{})
+		cache.GetTeamAggregate(ctx, "team1", domain.StatsFilter{})
+		cache.GetTeamAggregate(ctx, "team2", domain.StatsFilter{})
+		cache.GetTeamAggregate(ctx, "team3", domain.StatsFilter{})
+	}
+	fetchAll()
+
+	// Trading "valid" from team1 to team2 discards the aggregates of the player and of
+	// both teams.
+	trade := &domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionTrade, TeamID: "team2", Date: time.Now()}
+	if err := playerService.RecordTransaction(ctx, trade); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	fetchAll()
+	if calls := next.Calls.Load(); calls != 8 {
+		t.Errorf("Expected 3 aggregates to be fetched again, got %d fetches in all", calls)
+	}
+
+	// Deleting the player discards those of the teams its lines were logged for.
+	if err := playerService.DeletePlayer(ctx, "valid"); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	fetchAll()
+	if calls := next.Calls.Load(); calls != 11 {
+		t.Errorf("Expected 3 aggregates to be fetched again, got %d fetches in all", calls)
+	}
+
+	// Deleting a game discards those of its players and teams.
+	if err := gameService.DeleteGame(ctx, "game1"); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	fetchAll()
+	if calls := next.Calls.Load(); calls != 14 {
+		t.Errorf("Expected 3 aggregates to be fetched again, got %d fetches in all", calls)
+	}
+}
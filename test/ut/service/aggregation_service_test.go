@@ -2,8 +2,10 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/service"
 	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
 )
@@ -11,9 +13,9 @@ import (
 func TestGetPlayerAggregate_Success(t *testing.T) {
 	// Use FakePlayerStatsRepo from our mocks package.
 	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
-	aggService := service.NewAggregationService(fakeStatsRepo)
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
 
-	agg, err := aggService.GetPlayerAggregate("valid")
+	agg, err := aggService.GetPlayerAggregate(context.Background(), "valid", domain.StatsFilter{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -27,10 +29,10 @@ func TestGetPlayerAggregate_Success(t *testing.T) {
 
 func TestGetPlayerAggregate_Invalid(t *testing.T) {
 	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
-	aggService := service.NewAggregationService(fakeStatsRepo)
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
 
 	// Use an ID that is not recognized by the fake repository.
-	_, err := aggService.GetPlayerAggregate("invalid")
+	_, err := aggService.GetPlayerAggregate(context.Background(), "invalid", domain.StatsFilter{})
 	if err == nil {
 		t.Errorf("expected error for invalid playerID, got nil")
 	}
@@ -38,9 +40,9 @@ func TestGetPlayerAggregate_Invalid(t *testing.T) {
 
 func TestGetTeamAggregate_Success(t *testing.T) {
 	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
-	aggService := service.NewAggregationService(fakeStatsRepo)
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
 
-	agg, err := aggService.GetTeamAggregate("team1")
+	agg, err := aggService.GetTeamAggregate(context.Background(), "team1", domain.StatsFilter{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -54,11 +56,34 @@ func TestGetTeamAggregate_Success(t *testing.T) {
 
 func TestGetTeamAggregate_Invalid(t *testing.T) {
 	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
-	aggService := service.NewAggregationService(fakeStatsRepo)
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
 
 	// Use an ID that is not recognized by the fake repository.
-	_, err := aggService.GetTeamAggregate("invalid")
+	_, err := aggService.GetTeamAggregate(context.Background(), "invalid", domain.StatsFilter{})
 	if err == nil {
 		t.Errorf("expected error for invalid teamID, got nil")
 	}
 }
+
+func TestGetPlayerAggregate_SeasonFilter(t *testing.T) {
+	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
+
+	agg, err := aggService.GetPlayerAggregate(context.Background(), "valid", domain.StatsFilter{SeasonID: "2025-26", SeasonType: domain.SeasonTypeRegular})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if agg.SeasonID != "2025-26" || agg.SeasonType != domain.SeasonTypeRegular {
+		t.Errorf("expected season filter to reach the repository, got %q/%q", agg.SeasonID, agg.SeasonType)
+	}
+}
+
+func TestGetTeamAggregate_InvalidSeasonType(t *testing.T) {
+	fakeStatsRepo := &mocks.FakePlayerStatsRepo{}
+	aggService := service.NewAggregationService(fakeStatsRepo, &mocks.FakeSeasonRepo{})
+
+	_, err := aggService.GetTeamAggregate(context.Background(), "team1", domain.StatsFilter{SeasonType: "preseason"})
+	if err == nil {
+		t.Errorf("expected error for unsupported season type, got nil")
+	}
+}
@@ -0,0 +1,115 @@
+// test/ut/service/auth_service_test.go
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/pkg/jwt"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	keyRepo := &mocks.FakeAPIKeyRepo{}
+	authService := service.NewAuthService(nil, keyRepo)
+
+	apiKey, key, err := authService.CreateAPIKey(context.Background(), "ci", []string{domain.ScopeStatsWrite})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(key, service.APIKeyPrefix) || !strings.HasPrefix(key, apiKey.Prefix) {
+		t.Errorf("expected key %q to start with %q and its prefix %q", key, service.APIKeyPrefix, apiKey.Prefix)
+	}
+	// Only a hash of the key is stored.
+	if stored := keyRepo.Keys[0]; stored.Hash == "" || strings.Contains(stored.Hash, key) {
+		t.Errorf("expected only a hash of the key to be stored, got %q", stored.Hash)
+	}
+
+	principal, err := authService.AuthenticateAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatalf("expected the new key to authenticate, got: %v", err)
+	}
+	if principal.Subject != "api-key:"+apiKey.ID || principal.Name != "ci" || principal.Method != domain.AuthMethodAPIKey {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+	if !principal.HasScope(domain.ScopeStatsWrite) || principal.HasScope(domain.ScopeCatalogAdmin) {
+		t.Errorf("expected the key to grant only %s, got %v", domain.ScopeStatsWrite, principal.Scopes)
+	}
+
+	if _, err := authService.AuthenticateAPIKey(context.Background(), key+"x"); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("expected domain.ErrUnauthenticated for an unknown key, got %v", err)
+	}
+
+	if err := authService.RevokeAPIKey(context.Background(), apiKey.ID); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := authService.AuthenticateAPIKey(context.Background(), key); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("expected domain.ErrUnauthenticated for a revoked key, got %v", err)
+	}
+}
+
+func TestCreateAPIKey_RequiresNameAndScopes(t *testing.T) {
+	authService := service.NewAuthService(nil, &mocks.FakeAPIKeyRepo{})
+	tests := []struct {
+		name       string
+		keyName    string
+		scopes     []string
+		wantFields []string
+	}{
+		{"no name", "", []string{domain.ScopeStatsRead}, []string{"name"}},
+		{"no scopes", "ci", nil, []string{"scopes"}},
+		{"unknown scope", "ci", []string{domain.ScopeStatsRead, "stats:delete"}, []string{"scopes"}},
+		{"nothing", "", nil, []string{"name", "scopes"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := authService.CreateAPIKey(context.Background(), tc.keyName, tc.scopes)
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a *domain.ValidationError, got %v", err)
+			}
+			var fields []string
+			for _, f := range validationErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.wantFields, ",") {
+				t.Errorf("expected invalid fields %v, got %v", tc.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier := jwt.NewVerifier(jwt.Config{Keys: []jwt.Key{{Algorithm: jwt.HS256, Secret: secret}}})
+	authService := service.NewAuthService(verifier, &mocks.FakeAPIKeyRepo{})
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	token, _ := jwt.SignHS256(jwt.Claims{Subject: "alice", ExpiresAt: expiry, Scope: "stats:read stats:write"}, "", secret)
+	principal, err := authService.AuthenticateToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if principal.Subject != "alice" || principal.Method != domain.AuthMethodJWT {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+	if strings.Join(principal.Scopes, " ") != "stats:read stats:write" {
+		t.Errorf("expected the scopes of the token, got %v", principal.Scopes)
+	}
+
+	noSubject, _ := jwt.SignHS256(jwt.Claims{ExpiresAt: expiry}, "", secret)
+	if _, err := authService.AuthenticateToken(context.Background(), noSubject); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("expected domain.ErrUnauthenticated for a token without subject, got %v", err)
+	}
+
+	// Without signing keys, bearer tokens are not accepted at all.
+	withoutJWT := service.NewAuthService(nil, &mocks.FakeAPIKeyRepo{})
+	if _, err := withoutJWT.AuthenticateToken(context.Background(), token); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("expected domain.ErrUnauthenticated without a verifier, got %v", err)
+	}
+}
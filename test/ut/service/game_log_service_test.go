@@ -0,0 +1,140 @@
+// test/ut/service/game_log_service_test.go
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+func TestListPlayerGames_Pages(t *testing.T) {
+	date := time.Date(2026, 1, 10, 19, 0, 0, 0, time.UTC)
+	statsRepo := &mocks.FakePlayerStatsRepo{GameLog: []domain.GameLogEntry{
+		{PlayerGameStats: domain.PlayerGameStats{ID: "line1", Points: 30}, Date: date},
+		{PlayerGameStats: domain.PlayerGameStats{ID: "line2", Points: 25}, Date: date.AddDate(0, 0, -2)},
+		{PlayerGameStats: domain.PlayerGameStats{ID: "line3", Points: 20}, Date: date.AddDate(0, 0, -4)},
+	}}
+	gameLogService := service.NewGameLogService(statsRepo, &mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+
+	filter := domain.GameLogFilter{TeamID: "ignored", Sort: "points", Limit: 2}
+	log, err := gameLogService.ListPlayerGames(context.Background(), "valid", filter)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(log.Lines) != 2 || log.NextCursor == "" {
+		t.Fatalf("expected a page of 2 lines and a cursor, got %+v", log)
+	}
+	// One line more than requested is fetched to tell whether another page follows.
+	if got := statsRepo.GameLogFilter; got.PlayerID != "valid" || got.TeamID != "" || got.Limit != 3 {
+		t.Errorf("unexpected filter %+v", got)
+	}
+
+	filter.Cursor = log.NextCursor
+	if _, err := gameLogService.ListPlayerGames(context.Background(), "valid", filter); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if c := statsRepo.GameLogCursor; c == nil || c.ID != "line2" || c.Value != 25 || !c.Date.Equal(date.AddDate(0, 0, -2)) {
+		t.Errorf("expected the next page to start after line2, got %+v", c)
+	}
+
+	// A cursor only pages through the order it was issued for.
+	filter.Ascending = true
+	if _, err := gameLogService.ListPlayerGames(context.Background(), "valid", filter); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a cursor of another order, got %v", err)
+	}
+}
+
+func TestListTeamGames_LastPage(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{GameLog: []domain.GameLogEntry{{PlayerGameStats: domain.PlayerGameStats{ID: "line1"}}}}
+	gameLogService := service.NewGameLogService(statsRepo, &mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+
+	log, err := gameLogService.ListTeamGames(context.Background(), "team1", domain.GameLogFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(log.Lines) != 1 || log.NextCursor != "" {
+		t.Errorf("expected a single page, got %+v", log)
+	}
+	if got := statsRepo.GameLogFilter; got.TeamID != "team1" || got.Sort != domain.GameLogSortDate || got.Limit != service.DefaultListLimit+1 {
+		t.Errorf("unexpected filter %+v", got)
+	}
+}
+
+func TestListPlayerGames_InvalidFilter(t *testing.T) {
+	gameLogService := service.NewGameLogService(&mocks.FakePlayerStatsRepo{}, &mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+
+	tests := []struct {
+		name   string
+		filter domain.GameLogFilter
+	}{
+		{"unknown sort", domain.GameLogFilter{Sort: "height"}},
+		{"unknown location", domain.GameLogFilter{Location: "neutral"}},
+		{"unknown season type", domain.GameLogFilter{StatsFilter: domain.StatsFilter{SeasonType: "preseason"}}},
+		{"malformed cursor", domain.GameLogFilter{Cursor: "not a cursor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gameLogService.ListPlayerGames(context.Background(), "valid", tt.filter)
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+
+	if _, err := gameLogService.ListPlayerGames(context.Background(), "unknown", domain.GameLogFilter{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown player, got %v", err)
+	}
+	if _, err := gameLogService.ListTeamGames(context.Background(), "unknown", domain.GameLogFilter{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown team, got %v", err)
+	}
+}
+
+func TestGetBoxScore_GroupsLinesByTeam(t *testing.T) {
+	starter, bench := true, false
+	statsRepo := &mocks.FakePlayerStatsRepo{GameLog: []domain.GameLogEntry{
+		{PlayerGameStats: domain.PlayerGameStats{ID: "l1", PlayerID: "p1", TeamID: "team1", Points: 8, MinutesPlayed: 20, FieldGoalsMade: 4, FieldGoalsAttempted: 10}},
+		{PlayerGameStats: domain.PlayerGameStats{ID: "l2", PlayerID: "valid", TeamID: "team1", Points: 12, MinutesPlayed: 30, Starter: &bench, FieldGoalsMade: 6, FieldGoalsAttempted: 10}},
+		{PlayerGameStats: domain.PlayerGameStats{ID: "l3", PlayerID: "p3", TeamID: "team1", Points: 2, MinutesPlayed: 10, Starter: &starter}},
+	}}
+	gameLogService := service.NewGameLogService(statsRepo, &mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+
+	boxScore, err := gameLogService.GetBoxScore(context.Background(), "game1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if boxScore.Game.ID != "game1" || statsRepo.GameLogFilter.GameID != "game1" || statsRepo.GameLogFilter.Limit != 0 {
+		t.Errorf("expected every line of game1, got filter %+v", statsRepo.GameLogFilter)
+	}
+	// The away team is listed even without lines.
+	if len(boxScore.Teams) != 2 || boxScore.Teams[1].TeamID != "team2" || len(boxScore.Teams[1].Lines) != 0 {
+		t.Fatalf("expected team1 and team2, got %+v", boxScore.Teams)
+	}
+	home := boxScore.Teams[0]
+	if !home.Home || home.TeamName != "Test Team" {
+		t.Errorf("expected the home team first, got %+v", home)
+	}
+	// Starters come first, then the bench, then lines not marked either way.
+	var order []string
+	for _, line := range home.Lines {
+		order = append(order, line.ID)
+	}
+	if len(order) != 3 || order[0] != "l3" || order[1] != "l2" || order[2] != "l1" {
+		t.Errorf("expected lines l3, l2, l1, got %v", order)
+	}
+	if home.Lines[1].PlayerName != "Test Player" {
+		t.Errorf("expected the player's name, got %q", home.Lines[1].PlayerName)
+	}
+	want := domain.BoxScoreTotals{Points: 22, MinutesPlayed: 60, FieldGoalsMade: 10, FieldGoalsAttempted: 20, FieldGoalPct: 0.5}
+	if home.Totals != want {
+		t.Errorf("expected totals %+v, got %+v", want, home.Totals)
+	}
+
+	if _, err := gameLogService.GetBoxScore(context.Background(), "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown game, got %v", err)
+	}
+}
@@ -2,7 +2,10 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/service"
@@ -11,7 +14,7 @@ import (
 
 func TestCreateGame_Success(t *testing.T) {
 	repo := &mocks.FakeGameRepo{}
-	gameService := service.NewGameService(repo)
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
 
 	game := &domain.Game{
 		ID:       "game1",
@@ -19,7 +22,7 @@ func TestCreateGame_Success(t *testing.T) {
 		AwayTeam: "team2",
 	}
 
-	err := gameService.CreateGame(game)
+	err := gameService.CreateGame(context.Background(), game)
 	if err != nil {
 		t.Errorf("expected success, got error: %v", err)
 	}
@@ -27,7 +30,7 @@ func TestCreateGame_Success(t *testing.T) {
 
 func TestCreateGame_InvalidInput(t *testing.T) {
 	repo := &mocks.FakeGameRepo{}
-	gameService := service.NewGameService(repo)
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
 
 	// Test with missing game ID.
 	game := &domain.Game{
@@ -36,7 +39,7 @@ func TestCreateGame_InvalidInput(t *testing.T) {
 		AwayTeam: "team2",
 	}
 
-	err := gameService.CreateGame(game)
+	err := gameService.CreateGame(context.Background(), game)
 	if err == nil {
 		t.Errorf("expected error for missing game ID, got success")
 	}
@@ -44,9 +47,9 @@ func TestCreateGame_InvalidInput(t *testing.T) {
 
 func TestGetGameByID_Success(t *testing.T) {
 	repo := &mocks.FakeGameRepo{}
-	gameService := service.NewGameService(repo)
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
 
-	game, err := gameService.GetGameByID("game1")
+	game, err := gameService.GetGameByID(context.Background(), "game1")
 	if err != nil {
 		t.Errorf("expected success, got error: %v", err)
 	}
@@ -57,10 +60,222 @@ func TestGetGameByID_Success(t *testing.T) {
 
 func TestGetGameByID_InvalidID(t *testing.T) {
 	repo := &mocks.FakeGameRepo{}
-	gameService := service.NewGameService(repo)
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
 
-	_, err := gameService.GetGameByID("")
+	_, err := gameService.GetGameByID(context.Background(), "")
 	if err == nil {
 		t.Errorf("expected error for empty game ID, got success")
 	}
 }
+
+func TestListGames_InvalidDateRange(t *testing.T) {
+	repo := &mocks.FakeGameRepo{}
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
+
+	from := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := gameService.ListGames(context.Background(), domain.GameFilter{From: from, To: to})
+	if err == nil {
+		t.Errorf("expected error for inverted date range, got success")
+	}
+}
+
+func TestPatchGame_Success(t *testing.T) {
+	repo := &mocks.FakeGameRepo{}
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
+
+	away := "team3"
+	game, err := gameService.PatchGame(context.Background(), "game1", &domain.GamePatch{AwayTeam: &away})
+	if err != nil {
+		t.Errorf("expected success, got error: %v", err)
+	}
+	if game.AwayTeam != away || game.HomeTeam != "team1" {
+		t.Errorf("unexpected patched game: %+v", game)
+	}
+}
+
+func TestPatchGame_Final(t *testing.T) {
+	repo := &mocks.FakeGameRepo{}
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
+
+	final, homeScore, awayScore := domain.GameStatusFinal, 30, 28
+	game, err := gameService.PatchGame(context.Background(), "game1", &domain.GamePatch{Status: &final, HomeScore: &homeScore, AwayScore: &awayScore})
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if game.Status != domain.GameStatusFinal || game.Winner != "team1" || game.UpdatedAt == nil {
+		t.Errorf("expected a final game won by team1 with its update time, got %+v", game)
+	}
+
+	// A final game can no longer be changed.
+	away := "team3"
+	if _, err := gameService.PatchGame(context.Background(), "final", &domain.GamePatch{AwayTeam: &away}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	err = gameService.UpdateGame(context.Background(), &domain.Game{ID: "final", HomeTeam: "team1", AwayTeam: "team3"})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpdateGame_StatusTransitions(t *testing.T) {
+	gameService := service.NewGameService(&mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+	ctx := context.Background()
+
+	// A live game cannot go back to scheduled.
+	game := &domain.Game{ID: "game1", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusScheduled}
+	if err := gameService.UpdateGame(ctx, game); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	// An empty status keeps the current one.
+	game = &domain.Game{ID: "game1", HomeTeam: "team1", AwayTeam: "team2", HomeScore: 12, AwayScore: 9}
+	if err := gameService.UpdateGame(ctx, game); err != nil || game.Status != domain.GameStatusLive {
+		t.Errorf("expected the game to stay live, got %q, %v", game.Status, err)
+	}
+
+	// A final score must match the points of the stat lines.
+	game = &domain.Game{ID: "game1", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusFinal, HomeScore: 30, AwayScore: 27}
+	err := gameService.UpdateGame(ctx, game)
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "away_score" {
+		t.Errorf("expected an away_score mismatch, got %v", err)
+	}
+}
+
+func TestCreateGame_InvalidResult(t *testing.T) {
+	gameService := service.NewGameService(&mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+	quarters := func(home, away []int) []domain.PeriodScore {
+		periods := make([]domain.PeriodScore, len(home))
+		for i := range home {
+			periods[i] = domain.PeriodScore{Period: i + 1, Home: home[i], Away: away[i]}
+		}
+		return periods
+	}
+
+	tests := []struct {
+		name   string
+		game   domain.Game
+		fields []string
+	}{
+		{"unknown status", domain.Game{Status: "halftime"}, []string{"status"}},
+		{"scheduled with a score", domain.Game{Status: domain.GameStatusScheduled, HomeScore: 2}, []string{"status"}},
+		{"tied final", domain.Game{Status: domain.GameStatusFinal, HomeScore: 99, AwayScore: 99}, []string{"home_score"}},
+		{"periods not adding up", domain.Game{Status: domain.GameStatusLive, HomeScore: 30, AwayScore: 20, Periods: quarters([]int{25}, []int{20})}, []string{"home_score"}},
+		{"periods out of order", domain.Game{Status: domain.GameStatusLive, HomeScore: 50, AwayScore: 40, Periods: []domain.PeriodScore{{Period: 2, Home: 25, Away: 20}, {Period: 1, Home: 25, Away: 20}}}, []string{"periods[0].period", "periods[1].period"}},
+		{"final before the fourth quarter", domain.Game{Status: domain.GameStatusFinal, HomeScore: 50, AwayScore: 40, Periods: quarters([]int{25, 25}, []int{20, 20})}, []string{"periods"}},
+		{"overtime without a tie", domain.Game{Status: domain.GameStatusFinal, HomeScore: 110, AwayScore: 100, Periods: quarters([]int{25, 25, 25, 25, 10}, []int{25, 25, 25, 20, 5})}, []string{"periods[4]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game
+			game.ID, game.HomeTeam, game.AwayTeam = "game1", "team1", "team2"
+			err := gameService.CreateGame(context.Background(), &game)
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			var fields []string
+			for _, f := range validationErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("expected fields %v, got %v", tt.fields, fields)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Errorf("expected fields %v, got %v", tt.fields, fields)
+				}
+			}
+		})
+	}
+
+	// An overtime game tied after regulation is won in overtime.
+	game := &domain.Game{ID: "game1", HomeTeam: "team1", AwayTeam: "team2", Status: domain.GameStatusFinal, HomeScore: 110, AwayScore: 112,
+		Periods: quarters([]int{25, 25, 25, 25, 10}, []int{25, 25, 25, 25, 12})}
+	if err := gameService.CreateGame(context.Background(), game); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if game.Overtimes != 1 || game.Winner != "team2" {
+		t.Errorf("expected team2 to win in overtime, got %+v", game)
+	}
+}
+
+func TestCreateGame_AssignsSeasonFromDate(t *testing.T) {
+	repo := &mocks.FakeGameRepo{}
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
+
+	regular := &domain.Game{
+		ID:       "game1",
+		Date:     time.Date(2025, 12, 25, 20, 0, 0, 0, time.UTC),
+		HomeTeam: "team1",
+		AwayTeam: "team2",
+	}
+	if err := gameService.CreateGame(context.Background(), regular); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if regular.SeasonID != "2025-26" || regular.SeasonType != domain.SeasonTypeRegular {
+		t.Errorf("expected 2025-26 regular season, got %q/%q", regular.SeasonID, regular.SeasonType)
+	}
+
+	playoff := &domain.Game{
+		ID:       "game2",
+		Date:     time.Date(2026, 5, 2, 20, 0, 0, 0, time.UTC),
+		HomeTeam: "team1",
+		AwayTeam: "team2",
+	}
+	if err := gameService.CreateGame(context.Background(), playoff); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if playoff.SeasonType != domain.SeasonTypePlayoffs {
+		t.Errorf("expected playoff game, got %q", playoff.SeasonType)
+	}
+}
+
+func TestCreateGame_UnknownSeason(t *testing.T) {
+	repo := &mocks.FakeGameRepo{}
+	gameService := service.NewGameService(repo, &mocks.FakeSeasonRepo{})
+
+	game := &domain.Game{
+		ID:       "game1",
+		Date:     time.Date(2025, 12, 25, 20, 0, 0, 0, time.UTC),
+		HomeTeam: "team1",
+		AwayTeam: "team2",
+		SeasonID: "1999-00",
+	}
+	if err := gameService.CreateGame(context.Background(), game); err == nil {
+		t.Errorf("expected error for unknown season, got success")
+	}
+}
+
+func TestAuthorizeScorekeeper(t *testing.T) {
+	gameService := service.NewGameService(&mocks.FakeGameRepo{}, &mocks.FakeSeasonRepo{})
+	admin := &domain.Principal{Subject: "admin", Scopes: []string{domain.ScopeCatalogAdmin}}
+	scorekeeper := &domain.Principal{Subject: "scorekeeper", Scopes: []string{domain.ScopeStatsWrite}}
+	stranger := &domain.Principal{Subject: "stranger", Scopes: []string{domain.ScopeStatsWrite}}
+
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		gameID    string
+		wantErr   error
+	}{
+		{"admin logs any game", admin, "game2", nil},
+		{"scorekeeper logs an assigned game", scorekeeper, "game1", nil},
+		{"scorekeeper cannot log another game", scorekeeper, "game2", domain.ErrForbidden},
+		{"unassigned writer cannot log", stranger, "game1", domain.ErrForbidden},
+		{"anonymous", nil, "game1", domain.ErrUnauthenticated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := gameService.AuthorizeScorekeeper(context.Background(), tc.principal, tc.gameID)
+			if tc.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// test/ut/service/leader_service_test.go
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+func TestGetLeaders_Defaults(t *testing.T) {
+	leaderRepo := &mocks.FakeLeaderRepo{}
+	leaderService := service.NewLeaderService(leaderRepo, &mocks.FakeSeasonRepo{})
+
+	leaders, err := leaderService.GetLeaders(context.Background(), domain.LeaderFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(leaders) != 1 {
+		t.Fatalf("expected 1 leader, got %d", len(leaders))
+	}
+	f := leaderRepo.Filter
+	if f.Stat != service.DefaultLeaderStat || f.Scope != domain.LeaderScopePlayer || f.Limit != service.DefaultListLimit {
+		t.Errorf("expected default stat, scope and limit, got %+v", f)
+	}
+}
+
+func TestGetLeaders_InvalidFilter(t *testing.T) {
+	leaderService := service.NewLeaderService(&mocks.FakeLeaderRepo{}, &mocks.FakeSeasonRepo{})
+
+	filters := map[string]domain.LeaderFilter{
+		"unknown stat":     {Stat: "shoe_size"},
+		"unknown scope":    {Scope: "league"},
+		"negative games":   {MinGames: -1},
+		"bad season type":  {StatsFilter: domain.StatsFilter{SeasonType: "preseason"}},
+		"negative minutes": {MinMinutes: -10},
+	}
+	for name, filter := range filters {
+		if _, err := leaderService.GetLeaders(context.Background(), filter); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
@@ -2,7 +2,10 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
 	"github.com/vgeshiktor/nba-stats/internal/service"
@@ -20,7 +23,7 @@ func TestCreatePlayer_Success(t *testing.T) {
 		TeamID: "team1",
 	}
 
-	err := playerService.CreatePlayer(player)
+	err := playerService.CreatePlayer(context.Background(), player)
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -37,7 +40,7 @@ func TestCreatePlayer_Invalid(t *testing.T) {
 		TeamID: "team1",
 	}
 
-	err := playerService.CreatePlayer(player)
+	err := playerService.CreatePlayer(context.Background(), player)
 	if err == nil {
 		t.Errorf("expected error for missing name, got nil")
 	}
@@ -47,7 +50,7 @@ func TestGetPlayerByID_Success(t *testing.T) {
 	fakeRepo := &mocks.FakePlayerRepo{}
 	playerService := service.NewPlayerService(fakeRepo)
 
-	player, err := playerService.GetPlayerByID("valid")
+	player, err := playerService.GetPlayerByID(context.Background(), "valid")
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -60,8 +63,134 @@ func TestGetPlayerByID_Invalid(t *testing.T) {
 	fakeRepo := &mocks.FakePlayerRepo{}
 	playerService := service.NewPlayerService(fakeRepo)
 
-	_, err := playerService.GetPlayerByID("invalid")
+	_, err := playerService.GetPlayerByID(context.Background(), "invalid")
 	if err == nil {
 		t.Errorf("expected error for invalid player ID, got nil")
 	}
 }
+
+func TestListPlayers_FilterByTeam(t *testing.T) {
+	fakeRepo := &mocks.FakePlayerRepo{}
+	playerService := service.NewPlayerService(fakeRepo)
+
+	players, err := playerService.ListPlayers(context.Background(), domain.PlayerFilter{TeamID: "team1"})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if len(players) != 1 {
+		t.Errorf("expected 1 player, got: %d", len(players))
+	}
+
+	players, err = playerService.ListPlayers(context.Background(), domain.PlayerFilter{TeamID: "team2"})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if len(players) != 0 {
+		t.Errorf("expected no players, got: %d", len(players))
+	}
+}
+
+func TestPatchPlayer_Success(t *testing.T) {
+	fakeRepo := &mocks.FakePlayerRepo{}
+	playerService := service.NewPlayerService(fakeRepo)
+
+	name := "Jane Doe"
+	player, err := playerService.PatchPlayer(context.Background(), "valid", &domain.PlayerPatch{Name: &name})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if player.Name != name {
+		t.Errorf("expected name %q, got: %q", name, player.Name)
+	}
+	// Fields absent from the patch must be preserved.
+	if player.TeamID != "team1" {
+		t.Errorf("expected team ID 'team1', got: %s", player.TeamID)
+	}
+}
+
+func TestPatchPlayer_InvalidResult(t *testing.T) {
+	fakeRepo := &mocks.FakePlayerRepo{}
+	playerService := service.NewPlayerService(fakeRepo)
+
+	// Clearing the name must fail validation.
+	empty := ""
+	_, err := playerService.PatchPlayer(context.Background(), "valid", &domain.PlayerPatch{Name: &empty})
+	if err == nil {
+		t.Errorf("expected error for empty name, got nil")
+	}
+}
+
+func TestDeletePlayer_Invalid(t *testing.T) {
+	fakeRepo := &mocks.FakePlayerRepo{}
+	playerService := service.NewPlayerService(fakeRepo)
+
+	if err := playerService.DeletePlayer(context.Background(), ""); err == nil {
+		t.Errorf("expected error for empty player ID, got nil")
+	}
+}
+
+func TestUpdatePlayer_FreeAgent(t *testing.T) {
+	playerService := service.NewPlayerService(&mocks.FakePlayerRepo{})
+
+	// An existing player may be left without a team.
+	if err := playerService.UpdatePlayer(context.Background(), &domain.Player{ID: "valid", Name: "John Doe"}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestRecordTransaction(t *testing.T) {
+	date := time.Date(2026, 2, 5, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	tests := []struct {
+		name        string
+		transaction domain.RosterTransaction
+		want        error
+	}{
+		{"trade", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionTrade, TeamID: "team2"}, nil},
+		{"trade to the same team", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionTrade, TeamID: "team1"}, domain.ErrConflict},
+		{"trade of a free agent", domain.RosterTransaction{PlayerID: "free-agent", Type: domain.TransactionTrade, TeamID: "team2"}, domain.ErrConflict},
+		{"waiver", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionWaiver}, nil},
+		{"waiver of a free agent", domain.RosterTransaction{PlayerID: "free-agent", Type: domain.TransactionWaiver}, domain.ErrConflict},
+		{"waiver to a team", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionWaiver, TeamID: "team2"}, domain.ErrValidation},
+		{"signing", domain.RosterTransaction{PlayerID: "free-agent", Type: domain.TransactionSigning, TeamID: "team2"}, nil},
+		{"contract conversion", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionSigning, TeamID: "team1"}, nil},
+		{"signing from another team", domain.RosterTransaction{PlayerID: "valid", Type: domain.TransactionTwoWay, TeamID: "team2"}, domain.ErrConflict},
+		{"unknown type", domain.RosterTransaction{PlayerID: "valid", Type: "loan", TeamID: "team2"}, domain.ErrValidation},
+		{"unknown player", domain.RosterTransaction{PlayerID: "ghost", Type: domain.TransactionSigning, TeamID: "team2"}, domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRepo := &mocks.FakePlayerRepo{}
+			playerService := service.NewPlayerService(fakeRepo)
+
+			transaction := tt.transaction
+			transaction.Date = date
+			err := playerService.RecordTransaction(context.Background(), &transaction)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if fakeRepo.Recorded == nil || !fakeRepo.Recorded.Date.Equal(date) || fakeRepo.Recorded.Date.Location() != time.UTC {
+					t.Errorf("expected the transaction to be recorded in UTC, got %+v", fakeRepo.Recorded)
+				}
+				if transaction.RecordedAt == nil {
+					t.Errorf("expected the recording time to be set")
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got: %v", tt.want, err)
+			}
+			if fakeRepo.Recorded != nil {
+				t.Errorf("expected nothing to be recorded, got %+v", fakeRepo.Recorded)
+			}
+		})
+	}
+}
+
+func TestListMemberships_UnknownPlayer(t *testing.T) {
+	playerService := service.NewPlayerService(&mocks.FakePlayerRepo{})
+
+	if _, err := playerService.ListMemberships(context.Background(), "ghost"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected domain.ErrNotFound, got: %v", err)
+	}
+}
@@ -2,6 +2,8 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
@@ -35,13 +37,16 @@ func TestLogPlayerStats_Success(t *testing.T) {
 		MinutesPlayed: 35.0,
 	}
 
-	err := statsService.LogPlayerStats(stats)
+	err := statsService.LogPlayerStats(context.Background(), stats)
 	if err != nil {
 		t.Errorf("Expected success, got error: %v", err)
 	}
 	if !statsRepo.Inserted {
 		t.Errorf("Expected stats to be inserted")
 	}
+	if stats.TeamID != "team1" {
+		t.Errorf("Expected the line to be attributed to the player's team, got %q", stats.TeamID)
+	}
 }
 
 func TestLogPlayerStats_InvalidFouls(t *testing.T) {
@@ -67,8 +72,282 @@ func TestLogPlayerStats_InvalidFouls(t *testing.T) {
 		MinutesPlayed: 35.0,
 	}
 
-	err := statsService.LogPlayerStats(stats)
+	err := statsService.LogPlayerStats(context.Background(), stats)
 	if err == nil {
 		t.Errorf("Expected error due to invalid fouls, got success")
 	}
 }
+
+func TestLogPlayerStats_ShootingSplits(t *testing.T) {
+	playerRepo := &mocks.FakePlayerRepo{}
+	teamRepo := &mocks.FakeTeamRepo{}
+	gameRepo := &mocks.FakeGameRepo{}
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+
+	statsService := service.NewPlayerStatsService(playerRepo, teamRepo, gameRepo, statsRepo)
+
+	// 11 field goals (4 of them threes) and 4 free throws make 30 points.
+	stats := &domain.PlayerGameStats{
+		ID:                     "stats1",
+		PlayerID:               "valid",
+		GameID:                 "game1",
+		Points:                 30,
+		Rebounds:               5,
+		FieldGoalsMade:         11,
+		FieldGoalsAttempted:    20,
+		ThreePointersMade:      4,
+		ThreePointersAttempted: 9,
+		FreeThrowsMade:         4,
+		FreeThrowsAttempted:    4,
+		OffensiveRebounds:      1,
+		DefensiveRebounds:      4,
+		MinutesPlayed:          35.0,
+	}
+
+	if err := statsService.LogPlayerStats(context.Background(), stats); err != nil {
+		t.Errorf("Expected success, got error: %v", err)
+	}
+	if !statsRepo.Inserted {
+		t.Errorf("Expected stats to be inserted")
+	}
+}
+
+func TestLogPlayerStats_InconsistentSplits(t *testing.T) {
+	playerRepo := &mocks.FakePlayerRepo{}
+	teamRepo := &mocks.FakeTeamRepo{}
+	gameRepo := &mocks.FakeGameRepo{}
+
+	statsService := service.NewPlayerStatsService(playerRepo, teamRepo, gameRepo, &mocks.FakePlayerStatsRepo{})
+
+	valid := domain.PlayerGameStats{
+		ID: "stats1", PlayerID: "valid", GameID: "game1", Points: 30, Rebounds: 5,
+		FieldGoalsMade: 11, FieldGoalsAttempted: 20, ThreePointersMade: 4, ThreePointersAttempted: 9,
+		FreeThrowsMade: 4, FreeThrowsAttempted: 4, OffensiveRebounds: 1, DefensiveRebounds: 4,
+		MinutesPlayed: 35.0,
+	}
+
+	tests := map[string]func(s *domain.PlayerGameStats){
+		"made exceeds attempted":  func(s *domain.PlayerGameStats) { s.FreeThrowsAttempted = 3 },
+		"threes exceed fgs":       func(s *domain.PlayerGameStats) { s.ThreePointersAttempted = 21 },
+		"points do not add up":    func(s *domain.PlayerGameStats) { s.Points = 28 },
+		"rebounds do not add up":  func(s *domain.PlayerGameStats) { s.DefensiveRebounds = 5 },
+		"negative shooting split": func(s *domain.PlayerGameStats) { s.FreeThrowsMade = -1; s.Points = 25 },
+	}
+	for name, mutate := range tests {
+		stats := valid
+		mutate(&stats)
+		if err := statsService.LogPlayerStats(context.Background(), &stats); err == nil {
+			t.Errorf("%s: expected validation error, got success", name)
+		}
+	}
+}
+
+func TestLogPlayerStats_MinutesIncludeOvertimes(t *testing.T) {
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakePlayerStatsRepo{})
+	ctx := context.Background()
+
+	// A game that went to overtime lasts 53 minutes.
+	stats := &domain.PlayerGameStats{ID: "stats1", PlayerID: "valid", GameID: "overtime", Points: 10, MinutesPlayed: 53}
+	if err := statsService.LogPlayerStats(ctx, stats); err != nil {
+		t.Fatalf("expected a 53-minute line in an overtime game to be logged, got %v", err)
+	}
+	stats = &domain.PlayerGameStats{ID: "stats2", PlayerID: "valid", GameID: "overtime", Points: 10, MinutesPlayed: 53.5}
+	var validationErr *domain.ValidationError
+	if err := statsService.LogPlayerStats(ctx, stats); !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "minutes_played" {
+		t.Errorf("expected minutes_played beyond the overtime to be rejected, got %v", err)
+	}
+
+	// Without overtime periods, a game lasts 48 minutes.
+	stats = &domain.PlayerGameStats{ID: "stats3", PlayerID: "valid", GameID: "game1", Points: 10, MinutesPlayed: 50}
+	if err := statsService.LogPlayerStats(ctx, stats); !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "minutes_played" {
+		t.Errorf("expected minutes_played beyond regulation to be rejected, got %v", err)
+	}
+
+	// Box score lines are checked against the same length.
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", Points: 12, MinutesPlayed: 52}}}
+	if err := statsService.LogBoxScore(ctx, "overtime", boxScore); err != nil {
+		t.Errorf("expected a 52-minute box score line in an overtime game to be logged, got %v", err)
+	}
+	boxScore = &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", Points: 12, MinutesPlayed: 52}}}
+	var batchErr *domain.BatchError
+	if err := statsService.LogBoxScore(ctx, "game1", boxScore); !errors.As(err, &batchErr) || batchErr.Lines[0].Errors[0].Field != "minutes_played" {
+		t.Errorf("expected a 52-minute box score line in a regulation game to be rejected, got %v", err)
+	}
+}
+
+func TestLogPlayerStats_ReportsEveryViolation(t *testing.T) {
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, &mocks.FakePlayerStatsRepo{})
+
+	stats := &domain.PlayerGameStats{
+		GameID: "game1", Points: 10, Fouls: 7, MinutesPlayed: -1,
+		FieldGoalsMade: 5, FieldGoalsAttempted: 4,
+	}
+	err := statsService.LogPlayerStats(context.Background(), stats)
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("Expected validation error to wrap ErrValidation")
+	}
+	want := []domain.FieldError{
+		{Field: "player_id", Code: domain.CodeRequired},
+		{Field: "fouls", Code: domain.CodeOutOfRange},
+		{Field: "minutes_played", Code: domain.CodeOutOfRange},
+		{Field: "field_goals_made", Code: domain.CodeOutOfRange},
+	}
+	if len(validationErr.Fields) != len(want) {
+		t.Fatalf("Expected %d violations, got %+v", len(want), validationErr.Fields)
+	}
+	for i, f := range validationErr.Fields {
+		if f.Field != want[i].Field || f.Code != want[i].Code || f.Message == "" {
+			t.Errorf("Expected violation %s/%s, got %+v", want[i].Field, want[i].Code, f)
+		}
+	}
+}
+
+func TestLogBoxScore_Success(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "valid", Points: 12, Rebounds: 4, MinutesPlayed: 28},
+	}}
+
+	if err := statsService.LogBoxScore(context.Background(), "game1", boxScore); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	if len(statsRepo.Lines) != 1 {
+		t.Fatalf("Expected 1 stored line, got %d", len(statsRepo.Lines))
+	}
+	if statsRepo.Lines[0].TeamID != "team1" {
+		t.Errorf("Expected the line to be attributed to the player's team, got %q", statsRepo.Lines[0].TeamID)
+	}
+	// Game and line IDs default from the URL and the player.
+	if line := statsRepo.Lines[0]; line.GameID != "game1" || line.ID != "game1-valid" {
+		t.Errorf("Expected defaulted IDs game1/game1-valid, got %s/%s", line.GameID, line.ID)
+	}
+}
+
+func TestLogStats_PlayerWithoutTeam(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	// A free agent at game time has no team to attribute the line to.
+	stats := &domain.PlayerGameStats{ID: "stats1", PlayerID: "free-agent", GameID: "game1", Points: 10, MinutesPlayed: 20}
+	err := statsService.LogPlayerStats(context.Background(), stats)
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "player_id" || validationErr.Fields[0].Code != domain.CodeInvalid {
+		t.Errorf("Expected an invalid player_id, got %v", err)
+	}
+
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "valid", Points: 12, MinutesPlayed: 28},
+		{PlayerID: "free-agent", Points: 8, MinutesPlayed: 20},
+	}}
+	err = statsService.LogBoxScore(context.Background(), "game1", boxScore)
+	var batchErr *domain.BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Lines) != 1 || batchErr.Lines[0].Index != 1 {
+		t.Fatalf("Expected the second line to be rejected, got %v", err)
+	}
+	if statsRepo.Inserted {
+		t.Errorf("Expected nothing to be inserted")
+	}
+}
+
+func TestLogBoxScore_RejectsWholeBatch(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{
+		{PlayerID: "valid", Points: 12, MinutesPlayed: 28},
+		{PlayerID: "ghost", Points: 8, MinutesPlayed: 20},
+		{PlayerID: "valid", Points: 4, MinutesPlayed: 10},
+		{PlayerID: "other", Fouls: 7, MinutesPlayed: 10},
+		{PlayerID: "valid", GameID: "game2", MinutesPlayed: 10},
+	}}
+
+	err := statsService.LogBoxScore(context.Background(), "game1", boxScore)
+	var batchErr *domain.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a batch error, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("Expected batch error to wrap ErrValidation")
+	}
+	// Every line but the first is rejected, in order.
+	if len(batchErr.Lines) != 4 {
+		t.Fatalf("Expected 4 rejected lines, got %+v", batchErr.Lines)
+	}
+	wantFields := []domain.FieldError{
+		{Field: "player_id", Code: domain.CodeNotFound},
+		{Field: "player_id", Code: domain.CodeDuplicate},
+		{Field: "fouls", Code: domain.CodeOutOfRange},
+		{Field: "game_id", Code: domain.CodeMismatch},
+	}
+	for i, lineErr := range batchErr.Lines {
+		if lineErr.Index != i+1 {
+			t.Errorf("Expected rejected line %d, got %d", i+1, lineErr.Index)
+		}
+		if len(lineErr.Errors) != 1 || lineErr.Errors[0].Field != wantFields[i].Field || lineErr.Errors[0].Code != wantFields[i].Code {
+			t.Errorf("Expected line %d to break %s/%s, got %+v", lineErr.Index, wantFields[i].Field, wantFields[i].Code, lineErr.Errors)
+		}
+	}
+	if statsRepo.Inserted {
+		t.Errorf("Expected nothing to be inserted")
+	}
+}
+
+func TestLogBoxScore_UnknownGame(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", MinutesPlayed: 28}}}
+	if err := statsService.LogBoxScore(context.Background(), "missing", boxScore); err == nil {
+		t.Errorf("Expected error for unknown game, got success")
+	}
+}
+
+func TestLogPlayerStats_ScheduledGame(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	stats := &domain.PlayerGameStats{ID: "stats1", PlayerID: "valid", GameID: "scheduled", Points: 10, MinutesPlayed: 20}
+	if err := statsService.LogPlayerStats(context.Background(), stats); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a game that has not started, got %v", err)
+	}
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", Points: 10, MinutesPlayed: 20}}}
+	if err := statsService.LogBoxScore(context.Background(), "scheduled", boxScore); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a game that has not started, got %v", err)
+	}
+	if statsRepo.Inserted {
+		t.Errorf("Expected no stats to be inserted")
+	}
+}
+
+func TestLogStats_FinalGame(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	// A line would change the score the game was made final with.
+	stats := &domain.PlayerGameStats{ID: "stats1", PlayerID: "valid", GameID: "final", Points: 10, MinutesPlayed: 20}
+	if err := statsService.LogPlayerStats(context.Background(), stats); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a final game, got %v", err)
+	}
+	if statsRepo.Inserted {
+		t.Errorf("Expected no stats to be inserted")
+	}
+}
+
+func TestLogBoxScore_FinalGame(t *testing.T) {
+	statsRepo := &mocks.FakePlayerStatsRepo{}
+	statsService := service.NewPlayerStatsService(&mocks.FakePlayerRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeGameRepo{}, statsRepo)
+
+	boxScore := &domain.BoxScore{Lines: []domain.PlayerGameStats{{PlayerID: "valid", Points: 10, MinutesPlayed: 20}}}
+	if err := statsService.LogBoxScore(context.Background(), "final", boxScore); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a final game, got %v", err)
+	}
+	if statsRepo.Inserted {
+		t.Errorf("Expected no stats to be inserted")
+	}
+}
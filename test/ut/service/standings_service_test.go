@@ -0,0 +1,46 @@
+// test/ut/service/standings_service_test.go
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/internal/service"
+	"github.com/vgeshiktor/nba-stats/test/ut/mocks"
+)
+
+func TestGetStandings_FinalRegularSeasonGames(t *testing.T) {
+	gameRepo := &mocks.FakeGameRepo{}
+	standingsService := service.NewStandingsService(gameRepo, &mocks.FakeTeamRepo{}, &mocks.FakeSeasonRepo{})
+
+	standings, err := standingsService.GetStandings(context.Background(), domain.StandingsFilter{SeasonID: "2025-26"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if standings.SeasonID != "2025-26" || standings.Group != domain.StandingsGroupConference {
+		t.Errorf("expected the 2025-26 standings by conference, got %+v", standings)
+	}
+	want := domain.GameFilter{SeasonID: "2025-26", SeasonType: domain.SeasonTypeRegular, Status: domain.GameStatusFinal}
+	if gameRepo.Filter != want {
+		t.Errorf("expected filter %+v, got %+v", want, gameRepo.Filter)
+	}
+	// The only game is not final and the only team has no conference.
+	if len(standings.Groups) != 0 {
+		t.Errorf("expected no groups, got %+v", standings.Groups)
+	}
+}
+
+func TestGetStandings_InvalidFilter(t *testing.T) {
+	standingsService := service.NewStandingsService(&mocks.FakeGameRepo{}, &mocks.FakeTeamRepo{}, &mocks.FakeSeasonRepo{})
+
+	_, err := standingsService.GetStandings(context.Background(), domain.StandingsFilter{SeasonID: "2025-26", Group: "city"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown group, got %v", err)
+	}
+	_, err = standingsService.GetStandings(context.Background(), domain.StandingsFilter{SeasonID: "1999-00"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown season, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/vgeshiktor/nba-stats/internal/domain"
@@ -19,7 +21,7 @@ func TestCreateTeam_Success(t *testing.T) {
 		Name: "Test Team",
 	}
 
-	err := teamService.CreateTeam(team)
+	err := teamService.CreateTeam(context.Background(), team)
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -35,7 +37,7 @@ func TestCreateTeam_Invalid(t *testing.T) {
 		Name: "",
 	}
 
-	err := teamService.CreateTeam(team)
+	err := teamService.CreateTeam(context.Background(), team)
 	if err == nil {
 		t.Errorf("expected error for missing team name, got nil")
 	}
@@ -45,17 +47,43 @@ func TestCreateTeam_Invalid(t *testing.T) {
 		ID:   "",
 		Name: "Test Team",
 	}
-	err = teamService.CreateTeam(team)
+	err = teamService.CreateTeam(context.Background(), team)
 	if err == nil {
 		t.Errorf("expected error for missing team ID, got nil")
 	}
 }
 
+func TestCreateTeam_Placement(t *testing.T) {
+	teamService := service.NewTeamService(&mocks.FakeTeamRepo{})
+
+	team := &domain.Team{ID: "team1", Name: "Test Team", Conference: domain.ConferenceWest, Division: "Pacific"}
+	if err := teamService.CreateTeam(context.Background(), team); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		conference, division string
+		field                string
+	}{
+		{"North", "", "conference"},
+		{"", "Pacific", "conference"},
+		{domain.ConferenceEast, "Pacific", "division"},
+	}
+	for _, tt := range tests {
+		team := &domain.Team{ID: "team1", Name: "Test Team", Conference: tt.conference, Division: tt.division}
+		err := teamService.CreateTeam(context.Background(), team)
+		var validationErr *domain.ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != tt.field {
+			t.Errorf("%s/%s: expected a %s violation, got %v", tt.conference, tt.division, tt.field, err)
+		}
+	}
+}
+
 func TestGetTeamByID_Success(t *testing.T) {
 	fakeRepo := &mocks.FakeTeamRepo{}
 	teamService := service.NewTeamService(fakeRepo)
 
-	team, err := teamService.GetTeamByID("team1")
+	team, err := teamService.GetTeamByID(context.Background(), "team1")
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -68,8 +96,31 @@ func TestGetTeamByID_Invalid(t *testing.T) {
 	fakeRepo := &mocks.FakeTeamRepo{}
 	teamService := service.NewTeamService(fakeRepo)
 
-	_, err := teamService.GetTeamByID("invalid")
+	_, err := teamService.GetTeamByID(context.Background(), "invalid")
 	if err == nil {
 		t.Errorf("expected error for invalid team ID, got nil")
 	}
 }
+
+func TestPatchTeam_Success(t *testing.T) {
+	fakeRepo := &mocks.FakeTeamRepo{}
+	teamService := service.NewTeamService(fakeRepo)
+
+	name := "Renamed Team"
+	team, err := teamService.PatchTeam(context.Background(), "team1", &domain.TeamPatch{Name: &name})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if team.Name != name {
+		t.Errorf("expected team name %q, got: %q", name, team.Name)
+	}
+}
+
+func TestDeleteTeam_Invalid(t *testing.T) {
+	fakeRepo := &mocks.FakeTeamRepo{}
+	teamService := service.NewTeamService(fakeRepo)
+
+	if err := teamService.DeleteTeam(context.Background(), "invalid"); err == nil {
+		t.Errorf("expected error for unknown team ID, got nil")
+	}
+}
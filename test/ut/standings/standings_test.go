@@ -0,0 +1,240 @@
+// test/ut/standings/standings_test.go
+package standings_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/internal/domain"
+	"github.com/vgeshiktor/nba-stats/pkg/standings"
+)
+
+// schedule builds final games played on consecutive days.
+type schedule []domain.Game
+
+// final adds a final game between home and away.
+func (s *schedule) final(home, away string, homeScore, awayScore int) {
+	*s = append(*s, domain.Game{
+		ID:        fmt.Sprintf("g%02d", len(*s)),
+		Date:      time.Date(2025, 11, 1, 19, 0, 0, 0, time.UTC).AddDate(0, 0, len(*s)),
+		HomeTeam:  home,
+		AwayTeam:  away,
+		Status:    domain.GameStatusFinal,
+		HomeScore: homeScore,
+		AwayScore: awayScore,
+	})
+}
+
+// teamIDs returns the IDs of the teams of a group, in rank order.
+func teamIDs(group domain.StandingsGroup) []string {
+	ids := make([]string, len(group.Teams))
+	for i, s := range group.Teams {
+		ids[i] = s.TeamID
+	}
+	return ids
+}
+
+func TestCompute_Records(t *testing.T) {
+	var games schedule
+	lost := map[int]bool{0: true, 1: true, 5: true, 10: true, 11: true}
+	for i := 0; i < 12; i++ {
+		won, lose := 100, 90
+		if lost[i] {
+			won, lose = lose, won
+		}
+		if i%2 == 0 {
+			games.final("t", "o", won, lose)
+		} else {
+			games.final("o", "t", lose, won)
+		}
+	}
+	// Games that are not final do not count.
+	games = append(games,
+		domain.Game{ID: "live", Date: time.Now(), HomeTeam: "t", AwayTeam: "o", Status: domain.GameStatusLive, HomeScore: 50, AwayScore: 10},
+		domain.Game{ID: "scheduled", Date: time.Now(), HomeTeam: "o", AwayTeam: "t", Status: domain.GameStatusScheduled},
+	)
+
+	groups := standings.Compute([]domain.Team{{ID: "t", Name: "Team"}}, games, domain.StandingsGroupLeague)
+	require.Len(t, groups, 1)
+	assert.Equal(t, domain.StandingsGroupLeague, groups[0].Name)
+	require.Equal(t, []string{"t", "o"}, teamIDs(groups[0]))
+
+	team := groups[0].Teams[0]
+	assert.Equal(t, 1, team.Rank)
+	assert.Equal(t, "Team", team.TeamName)
+	assert.Equal(t, 7, team.Wins)
+	assert.Equal(t, 5, team.Losses)
+	assert.Equal(t, 0.583, team.WinPct)
+	assert.Equal(t, 0.0, team.GamesBehind)
+	assert.Equal(t, domain.Record{Wins: 4, Losses: 2}, team.Home)
+	assert.Equal(t, domain.Record{Wins: 3, Losses: 3}, team.Away)
+	assert.Equal(t, domain.Record{Wins: 7, Losses: 3}, team.LastTen)
+	assert.Equal(t, "L2", team.Streak)
+	assert.Equal(t, 1150, team.PointsFor)
+	assert.Equal(t, 1130, team.PointsAgainst)
+	assert.Equal(t, 20, team.PointDifference)
+	// Neither team has a conference.
+	assert.Equal(t, domain.Record{}, team.ConferenceRecord)
+
+	opponent := groups[0].Teams[1]
+	assert.Equal(t, 2, opponent.Rank)
+	assert.Equal(t, 2.0, opponent.GamesBehind)
+	assert.Equal(t, "W2", opponent.Streak)
+	assert.Equal(t, -20, opponent.PointDifference)
+}
+
+func TestCompute_Groups(t *testing.T) {
+	teams := []domain.Team{
+		{ID: "bos", Conference: domain.ConferenceEast, Division: "Atlantic"},
+		{ID: "lal", Conference: domain.ConferenceWest, Division: "Pacific"},
+		{ID: "den", Conference: domain.ConferenceWest},
+		{ID: "old"}, // No conference and no games, so not listed.
+	}
+	var games schedule
+	games.final("lal", "exp", 110, 100)
+
+	conferences := standings.Compute(teams, games, domain.StandingsGroupConference)
+	require.Len(t, conferences, 3)
+	assert.Equal(t, "East", conferences[0].Name)
+	assert.Equal(t, []string{"bos"}, teamIDs(conferences[0]))
+	assert.Equal(t, "West", conferences[1].Name)
+	assert.Equal(t, []string{"lal", "den"}, teamIDs(conferences[1]))
+	assert.Equal(t, "", conferences[2].Name)
+	assert.Equal(t, []string{"exp"}, teamIDs(conferences[2]))
+	assert.Equal(t, 0.5, conferences[1].Teams[1].GamesBehind)
+
+	divisions := standings.Compute(teams, games, domain.StandingsGroupDivision)
+	require.Len(t, divisions, 3)
+	assert.Equal(t, "Atlantic", divisions[0].Name)
+	assert.Equal(t, "Pacific", divisions[1].Name)
+	assert.Equal(t, "", divisions[2].Name)
+	assert.Equal(t, []string{"den", "exp"}, teamIDs(divisions[2]))
+
+	league := standings.Compute(teams, games, domain.StandingsGroupLeague)
+	require.Len(t, league, 1)
+	assert.Equal(t, []string{"lal", "bos", "den", "exp"}, teamIDs(league[0]))
+	assert.Equal(t, []int{1, 2, 3, 4}, []int{league[0].Teams[0].Rank, league[0].Teams[1].Rank, league[0].Teams[2].Rank, league[0].Teams[3].Rank})
+}
+
+func TestCompute_TieBreakers(t *testing.T) {
+	tests := []struct {
+		name  string
+		teams []domain.Team
+		games func(*schedule)
+		want  []string
+	}{
+		{
+			// h and k are both 2-1; h won their game despite k's better point differential.
+			name: "head-to-head",
+			games: func(s *schedule) {
+				s.final("h", "k", 100, 99)
+				s.final("k", "l", 130, 90)
+				s.final("k", "l", 130, 90)
+				s.final("h", "l", 100, 90)
+				s.final("l", "h", 100, 90)
+			},
+			want: []string{"h", "k", "l"},
+		},
+		{
+			// a and b never played each other and won by the same margin.
+			name: "team ID",
+			games: func(s *schedule) {
+				s.final("b", "c", 100, 90)
+				s.final("a", "c", 100, 90)
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			// p and q are both 2-1 with a split series; p's win over their division rival
+			// r decides. Of the 0-1 teams, e leads its division.
+			name: "division record",
+			teams: []domain.Team{
+				{ID: "p", Conference: domain.ConferenceWest, Division: "Pacific"},
+				{ID: "q", Conference: domain.ConferenceWest, Division: "Pacific"},
+				{ID: "r", Conference: domain.ConferenceWest, Division: "Pacific"},
+				{ID: "e", Conference: domain.ConferenceEast, Division: "Atlantic"},
+			},
+			games: func(s *schedule) {
+				s.final("p", "q", 100, 90)
+				s.final("q", "p", 100, 90)
+				s.final("p", "r", 100, 90)
+				s.final("q", "e", 100, 90)
+			},
+			want: []string{"p", "q", "e", "r"},
+		},
+		{
+			// Tied with the same record and differential, division leaders rank ahead:
+			// a1 ahead of y, and c1 ahead of a2, which trails a1 in its division.
+			name: "division leader",
+			teams: []domain.Team{
+				{ID: "a1", Conference: domain.ConferenceEast, Division: "Atlantic"},
+				{ID: "a2", Conference: domain.ConferenceEast, Division: "Atlantic"},
+				{ID: "c1", Conference: domain.ConferenceEast, Division: "Central"},
+			},
+			games: func(s *schedule) {
+				s.final("a1", "a2", 100, 90)
+				s.final("a2", "x", 100, 90)
+				s.final("c1", "x", 100, 90)
+				s.final("y", "c1", 100, 90)
+			},
+			want: []string{"a1", "y", "c1", "a2", "x"},
+		},
+		{
+			// Only the differential separates three teams that each beat one another.
+			name: "point differential",
+			games: func(s *schedule) {
+				s.final("x", "y", 110, 100)
+				s.final("y", "z", 105, 100)
+				s.final("z", "x", 101, 100)
+			},
+			want: []string{"x", "z", "y"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var games schedule
+			tt.games(&games)
+			groups := standings.Compute(tt.teams, games, domain.StandingsGroupLeague)
+			require.Len(t, groups, 1)
+			assert.Equal(t, tt.want, teamIDs(groups[0]))
+		})
+	}
+}
+
+func TestCompute_MultiTeamTieBreakers(t *testing.T) {
+	teams := []domain.Team{
+		{ID: "a1", Conference: domain.ConferenceEast, Division: "Atlantic"},
+		{ID: "a2", Conference: domain.ConferenceEast, Division: "Atlantic"},
+		{ID: "c1", Conference: domain.ConferenceEast, Division: "Central"},
+		{ID: "c2", Conference: domain.ConferenceEast, Division: "Central"},
+		{ID: "w1", Conference: domain.ConferenceWest, Division: "Pacific"},
+	}
+	var games schedule
+	games.final("a1", "a2", 100, 90)
+	games.final("a2", "a1", 90, 100)
+	games.final("c1", "c2", 110, 90)
+	games.final("c2", "c1", 100, 95)
+	games.final("a2", "w1", 100, 90)
+	games.final("w1", "a2", 90, 100)
+	games.final("c1", "w1", 100, 90)
+	games.final("w1", "c1", 100, 90)
+
+	groups := standings.Compute(teams, games, domain.StandingsGroupConference)
+	require.Len(t, groups, 2)
+	east := groups[0]
+	// a2, c1 and c2 are all .500. c1 leads the Central, which puts it first; a2 and c2
+	// are then separated by their conference records, 0-2 and 1-1.
+	assert.Equal(t, []string{"a1", "c1", "c2", "a2"}, teamIDs(east))
+	assert.Equal(t, 1.0, east.Teams[1].GamesBehind)
+	assert.Equal(t, domain.Record{Wins: 1, Losses: 1}, east.Teams[1].ConferenceRecord)
+	assert.Equal(t, domain.Record{Wins: 1, Losses: 1}, east.Teams[1].DivisionRecord)
+	assert.Equal(t, domain.Record{Wins: 0, Losses: 2}, east.Teams[3].ConferenceRecord)
+	assert.Equal(t, "Central", east.Teams[1].Division)
+
+	assert.Equal(t, []string{"w1"}, teamIDs(groups[1]))
+	assert.Equal(t, domain.Record{}, groups[1].Teams[0].ConferenceRecord)
+}
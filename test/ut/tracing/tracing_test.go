@@ -0,0 +1,129 @@
+// test/ut/tracing/tracing_test.go
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgeshiktor/nba-stats/pkg/tracing"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := tracing.ParseTraceparent(traceparent)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, traceparent, sc.Traceparent())
+
+	// A later version may append fields.
+	_, ok = tracing.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.True(t, ok)
+
+	for _, value := range []string{
+		"",
+		"garbage",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // Version 00 has four fields.
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",       // Invalid version.
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",       // Zero trace ID.
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",       // Zero span ID.
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",       // Uppercase.
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",        // Short trace ID.
+	} {
+		_, ok := tracing.ParseTraceparent(value)
+		assert.False(t, ok, value)
+	}
+}
+
+// recorder is an exporter keeping the spans it receives.
+type recorder struct{ spans []tracing.SpanData }
+
+func (r *recorder) Export(span tracing.SpanData) error {
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func TestStart_ContinuesTheCallersTrace(t *testing.T) {
+	exported := &recorder{}
+	tracing.SetExporter(exported)
+	defer tracing.SetExporter(nil)
+
+	remote, _ := tracing.ParseTraceparent(traceparent)
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), remote)
+	ctx, parent := tracing.Start(ctx, "handler")
+	_, child := tracing.Start(ctx, "service")
+	child.SetAttribute("key", "value")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	parent.End()
+	parent.End() // Only the first call exports the span.
+
+	require.Len(t, exported.spans, 2)
+	service, handler := exported.spans[0], exported.spans[1]
+	assert.Equal(t, "service", service.Name)
+	assert.Equal(t, remote.TraceID.String(), service.TraceID)
+	assert.Equal(t, handler.SpanID, service.ParentID)
+	assert.Equal(t, map[string]string{"key": "value"}, service.Attributes)
+	assert.Equal(t, "failed", service.Error)
+	assert.Equal(t, remote.TraceID.String(), handler.TraceID)
+	assert.Equal(t, remote.SpanID.String(), handler.ParentID)
+	assert.Equal(t, parent.SpanContext(), tracing.SpanContextFromContext(ctx))
+}
+
+func TestStart_NewTrace(t *testing.T) {
+	exported := &recorder{}
+	tracing.SetExporter(exported)
+	defer tracing.SetExporter(nil)
+
+	_, span := tracing.Start(context.Background(), "root")
+	span.End()
+
+	require.Len(t, exported.spans, 1)
+	assert.True(t, span.SpanContext().IsValid())
+	assert.True(t, span.SpanContext().Sampled)
+	assert.Empty(t, exported.spans[0].ParentID)
+}
+
+func TestStart_UnsampledTracesAreNotExported(t *testing.T) {
+	exported := &recorder{}
+	tracing.SetExporter(exported)
+	defer tracing.SetExporter(nil)
+
+	remote, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, span := tracing.Start(tracing.ContextWithRemoteSpanContext(context.Background(), remote), "handler")
+	span.End()
+
+	assert.Equal(t, remote.TraceID, span.SpanContext().TraceID)
+	assert.Empty(t, exported.spans)
+}
+
+func TestWriterExporter(t *testing.T) {
+	var out bytes.Buffer
+	tracing.SetExporter(tracing.NewWriterExporter(&out))
+	defer tracing.SetExporter(nil)
+
+	_, span := tracing.Start(context.Background(), "query")
+	span.End()
+
+	var data tracing.SpanData
+	require.NoError(t, json.Unmarshal(out.Bytes(), &data))
+	assert.Equal(t, "query", data.Name)
+	assert.Equal(t, span.SpanContext().TraceID.String(), data.TraceID)
+}
+
+func TestNilSpan(t *testing.T) {
+	var span *tracing.Span
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	assert.False(t, span.SpanContext().IsValid())
+	assert.Nil(t, tracing.SpanFromContext(context.Background()))
+}